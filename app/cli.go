@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+)
+
+// maintenanceTasks 是维护子命令可以执行的操作
+type maintenanceTasks interface {
+	ReindexBloom(ctx context.Context) error
+	WarmCache(ctx context.Context) error
+	ReindexTitles(ctx context.Context) error
+	NormalizeUsernames(ctx context.Context) error
+	PurgeDeleted(ctx context.Context) error
+}
+
+// commands 维护子命令，不带参数启动时运行 HTTP 服务
+var commands = map[string]func(tasks maintenanceTasks, ctx context.Context) error{
+	"reindex-bloom":  maintenanceTasks.ReindexBloom,
+	"warm-cache":     maintenanceTasks.WarmCache,
+	"reindex-titles": maintenanceTasks.ReindexTitles,
+
+	"normalize-usernames": maintenanceTasks.NormalizeUsernames,
+	"purge-deleted":       maintenanceTasks.PurgeDeleted,
+}
+
+// runCommand 执行 args[0] 指定的子命令
+func runCommand(ctx context.Context, args []string, tasks maintenanceTasks) error {
+	cmd, ok := commands[args[0]]
+	if !ok {
+		return fmt.Errorf("unknown command %q, available commands: %s", args[0], strings.Join(commandNames(), ", "))
+	}
+	if len(args) > 1 {
+		return fmt.Errorf("command %q takes no arguments", args[0])
+	}
+	return cmd(tasks, ctx)
+}
+
+func commandNames() []string {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// maintenance 用服务启动时的依赖实现各个维护操作
+type maintenance struct {
+	articleSvc   domain.ArticleUsecase
+	userSvc      domain.UserUsecase
+	warmer       *cacheWarmer
+	bloomEnabled bool
+	// articleDB 和 uow 用于 purge-deleted，删除超过 purgeAfter 的文章才会被彻底清除
+	articleDB  domain.ArticleDBRepository
+	uow        domain.UnitOfWork
+	purgeAfter time.Duration
+}
+
+// ReindexBloom 把数据库中所有文章ID重新写入布隆过滤器
+func (m *maintenance) ReindexBloom(ctx context.Context) error {
+	if !m.bloomEnabled {
+		return errors.New("bloom filter is disabled by BLOOM_ENABLED")
+	}
+	if err := m.articleSvc.InitBloomFilter(ctx); err != nil {
+		return err
+	}
+	log.Println("bloom filter reindexed")
+	return nil
+}
+
+// WarmCache 预热首页和热榜缓存，部分失败只记录日志
+func (m *maintenance) WarmCache(ctx context.Context) error {
+	m.warmer.warmUp(ctx)
+	return nil
+}
+
+// ReindexTitles 从数据库重建标题联想索引
+func (m *maintenance) ReindexTitles(ctx context.Context) error {
+	if err := m.articleSvc.RebuildTitleIndex(ctx); err != nil {
+		return err
+	}
+	log.Println("title index rebuilt")
+	return nil
+}
+
+// NormalizeUsernames 把已有的用户名改为小写，升级后在 user 表加上唯一索引 uk_username 之前执行。
+// 有只差大小写的用户名时不做任何修改，列出冲突的用户
+func (m *maintenance) NormalizeUsernames(ctx context.Context) error {
+	collisions, err := m.userSvc.NormalizeUsernames(ctx)
+	if err != nil {
+		return err
+	}
+	for _, c := range collisions {
+		log.Printf("username %q is shared by users %v\n", c.Username, c.IDs)
+	}
+	if len(collisions) > 0 {
+		return fmt.Errorf("%d usernames differ only in case, rename these users and run again", len(collisions))
+	}
+	log.Println("usernames normalized")
+	return nil
+}
+
+// purgeBatchSize purge-deleted 每批读取的文章数
+const purgeBatchSize = 100
+
+// PurgeDeleted 彻底清除删除超过 purgeAfter 的文章，每篇文章的评论、表情回应、点赞和标签关联在同一个事务中删除。
+// 清除期间被恢复的文章会跳过，其他错误直接返回，已经清除的文章不受影响
+func (m *maintenance) PurgeDeleted(ctx context.Context) error {
+	before := time.Now().Add(-m.purgeAfter)
+	var purged int
+	for {
+		// 清除或恢复后的文章不会再被读到，每批都从头读取
+		ids, err := m.articleDB.FetchDeletedBefore(ctx, before, purgeBatchSize)
+		if err != nil {
+			return err
+		}
+		for _, id := range ids {
+			err := m.uow.Do(ctx, func(repos domain.Repos) error {
+				if err := repos.Articles.Purge(ctx, id); err != nil {
+					return err
+				}
+				if err := repos.Comments.DeleteByArticle(ctx, id); err != nil {
+					return err
+				}
+				return repos.Reactions.DeleteByArticle(ctx, id)
+			})
+			if errors.Is(err, domain.ErrNotFound) {
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("purge article %d: %w", id, err)
+			}
+			purged++
+		}
+		if len(ids) < purgeBatchSize {
+			break
+		}
+	}
+	log.Printf("purged %d articles deleted before %s\n", purged, before.Format(time.DateTime))
+	return nil
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+)
+
+type stubTasks struct {
+	ran []string
+}
+
+func (s *stubTasks) ReindexBloom(context.Context) error {
+	s.ran = append(s.ran, "reindex-bloom")
+	return nil
+}
+
+func (s *stubTasks) WarmCache(context.Context) error {
+	s.ran = append(s.ran, "warm-cache")
+	return nil
+}
+
+func (s *stubTasks) ReindexTitles(context.Context) error {
+	s.ran = append(s.ran, "reindex-titles")
+	return nil
+}
+
+func (s *stubTasks) NormalizeUsernames(context.Context) error {
+	s.ran = append(s.ran, "normalize-usernames")
+	return nil
+}
+
+func (s *stubTasks) PurgeDeleted(context.Context) error {
+	s.ran = append(s.ran, "purge-deleted")
+	return nil
+}
+
+func TestRunCommand(t *testing.T) {
+	for _, name := range []string{"reindex-bloom", "warm-cache", "reindex-titles", "normalize-usernames", "purge-deleted"} {
+		t.Run(name, func(t *testing.T) {
+			tasks := &stubTasks{}
+			require.NoError(t, runCommand(context.Background(), []string{name}, tasks))
+			assert.Equal(t, []string{name}, tasks.ran)
+		})
+	}
+}
+
+func TestRunCommandRejectsUnknown(t *testing.T) {
+	tasks := &stubTasks{}
+
+	err := runCommand(context.Background(), []string{"serve"}, tasks)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "normalize-usernames, purge-deleted, reindex-bloom, reindex-titles, warm-cache")
+
+	assert.Error(t, runCommand(context.Background(), []string{"warm-cache", "extra"}, tasks))
+	assert.Empty(t, tasks.ran)
+}
+
+// purgeArticles 模拟已删除的文章，Purge 之后不再出现在 FetchDeletedBefore 中
+type purgeArticles struct {
+	domain.ArticleDBRepository
+	deleted  []int64
+	restored map[int64]bool
+	before   time.Time
+}
+
+func (a *purgeArticles) FetchDeletedBefore(_ context.Context, t time.Time, limit int) ([]int64, error) {
+	a.before = t
+	return slices.Clone(a.deleted[:min(limit, len(a.deleted))]), nil
+}
+
+func (a *purgeArticles) Purge(_ context.Context, id int64) error {
+	for i, d := range a.deleted {
+		if d == id {
+			a.deleted = append(a.deleted[:i], a.deleted[i+1:]...)
+			if a.restored[id] {
+				return domain.ErrNotFound
+			}
+			return nil
+		}
+	}
+	return domain.ErrNotFound
+}
+
+type purgeComments struct {
+	domain.CommentRepository
+	err     error
+	deleted []int64
+}
+
+func (c *purgeComments) DeleteByArticle(_ context.Context, id int64) error {
+	if c.err != nil {
+		return c.err
+	}
+	c.deleted = append(c.deleted, id)
+	return nil
+}
+
+type purgeReactions struct {
+	domain.ReactionRepository
+	deleted []int64
+}
+
+func (r *purgeReactions) DeleteByArticle(_ context.Context, id int64) error {
+	r.deleted = append(r.deleted, id)
+	return nil
+}
+
+// fakeUnitOfWork 不开启事务，只把同一组仓储交给 fn
+type fakeUnitOfWork struct {
+	repos domain.Repos
+}
+
+func (u fakeUnitOfWork) Do(_ context.Context, fn func(repos domain.Repos) error) error {
+	return fn(u.repos)
+}
+
+func TestPurgeDeleted(t *testing.T) {
+	// 文章 2 在读取之后被恢复，跳过
+	articles := &purgeArticles{deleted: []int64{1, 2, 3}, restored: map[int64]bool{2: true}}
+	comments, reactions := &purgeComments{}, &purgeReactions{}
+	m := &maintenance{
+		articleDB:  articles,
+		uow:        fakeUnitOfWork{domain.Repos{Articles: articles, Comments: comments, Reactions: reactions}},
+		purgeAfter: 30 * 24 * time.Hour,
+	}
+
+	require.NoError(t, m.PurgeDeleted(context.Background()))
+	assert.WithinDuration(t, time.Now().Add(-30*24*time.Hour), articles.before, time.Minute)
+	assert.Empty(t, articles.deleted)
+	assert.Equal(t, []int64{1, 3}, comments.deleted)
+	assert.Equal(t, []int64{1, 3}, reactions.deleted)
+}
+
+func TestPurgeDeletedStopsOnError(t *testing.T) {
+	articles := &purgeArticles{deleted: []int64{1, 2}}
+	comments, reactions := &purgeComments{err: errors.New("db is down")}, &purgeReactions{}
+	m := &maintenance{
+		articleDB: articles,
+		uow:       fakeUnitOfWork{domain.Repos{Articles: articles, Comments: comments, Reactions: reactions}},
+	}
+
+	err := m.PurgeDeleted(context.Background())
+	require.ErrorIs(t, err, comments.err)
+	assert.Contains(t, err.Error(), "purge article 1")
+	assert.Empty(t, reactions.deleted)
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	defaultDBMaxRetry       = 10
+	defaultDBRetryInterval  = 2 * time.Second
+	defaultDBRetryMaxWait   = 30 * time.Second
+	dbRetryBackoffFactor    = 2
+	dbRetryJitterProportion = 0.5
+)
+
+// dbRetryPolicy 数据库连接的重试策略。第 n 次失败后的等待时间从 Interval 开始每次翻倍，不超过 MaxWait，
+// 再随机缩短至多一半，避免依赖冷启动时所有实例同时重试
+type dbRetryPolicy struct {
+	MaxRetry int
+	Interval time.Duration
+	MaxWait  time.Duration
+	// Rand 返回 [0, 1) 的随机数，为 nil 时使用 math/rand
+	Rand func() float64
+}
+
+// wait 返回第 attempt 次（从 1 开始）失败后的等待时间
+func (p dbRetryPolicy) wait(attempt int) time.Duration {
+	d := p.Interval
+	for i := 1; i < attempt && d < p.MaxWait; i++ {
+		d *= dbRetryBackoffFactor
+	}
+	d = min(d, p.MaxWait)
+
+	random := p.Rand
+	if random == nil {
+		random = rand.Float64
+	}
+	return d - time.Duration(float64(d)*dbRetryJitterProportion*random())
+}
+
+// connectWithRetry 调用 connect 直到成功或用完 MaxRetry 次尝试，两次尝试之间调用 sleep 等待
+func connectWithRetry(connect func() (*gorm.DB, error), policy dbRetryPolicy, sleep func(time.Duration)) (*gorm.DB, error) {
+	var err error
+	for attempt := 1; attempt <= policy.MaxRetry; attempt++ {
+		var db *gorm.DB
+		db, err = connect()
+		if err == nil {
+			return db, nil
+		}
+		log.Printf("failed to connect to database (attempt %d/%d): %v", attempt, policy.MaxRetry, err)
+		if attempt < policy.MaxRetry {
+			sleep(policy.wait(attempt))
+		}
+	}
+	return nil, fmt.Errorf("could not connect to database after %d attempts: %w", policy.MaxRetry, err)
+}
+
+// openDB 打开连接并 ping 一次，失败时关闭已经打开的连接
+func openDB(dialector gorm.Dialector) (*gorm.DB, error) {
+	db, err := gorm.Open(dialector, &gorm.Config{})
+	if err != nil {
+		return nil, err
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	if err := sqlDB.Ping(); err != nil {
+		_ = sqlDB.Close()
+		return nil, err
+	}
+	return db, nil
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// flakyConnector 前 failures 次连接失败，之后成功
+type flakyConnector struct {
+	failures int
+	calls    int
+}
+
+var errConnRefused = errors.New("connection refused")
+
+func (c *flakyConnector) connect() (*gorm.DB, error) {
+	c.calls++
+	if c.calls <= c.failures {
+		return nil, errConnRefused
+	}
+	return &gorm.DB{}, nil
+}
+
+func TestConnectWithRetryBacksOff(t *testing.T) {
+	conn := &flakyConnector{failures: 4}
+	var waits []time.Duration
+	policy := dbRetryPolicy{
+		MaxRetry: 10,
+		Interval: time.Second,
+		MaxWait:  5 * time.Second,
+		Rand:     func() float64 { return 0 },
+	}
+
+	db, err := connectWithRetry(conn.connect, policy, func(d time.Duration) { waits = append(waits, d) })
+	require.NoError(t, err)
+	assert.NotNil(t, db)
+	assert.Equal(t, 5, conn.calls)
+	// 每次翻倍，不超过 MaxWait
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second}, waits)
+}
+
+func TestConnectWithRetryJitter(t *testing.T) {
+	conn := &flakyConnector{failures: 2}
+	var waits []time.Duration
+	policy := dbRetryPolicy{
+		MaxRetry: 10,
+		Interval: time.Second,
+		MaxWait:  5 * time.Second,
+		Rand:     func() float64 { return 0.5 },
+	}
+
+	_, err := connectWithRetry(conn.connect, policy, func(d time.Duration) { waits = append(waits, d) })
+	require.NoError(t, err)
+	// 抖动最多缩短一半等待时间
+	assert.Equal(t, []time.Duration{750 * time.Millisecond, 1500 * time.Millisecond}, waits)
+}
+
+func TestConnectWithRetryGivesUp(t *testing.T) {
+	conn := &flakyConnector{failures: 100}
+	var waits []time.Duration
+	policy := dbRetryPolicy{MaxRetry: 3, Interval: time.Second, MaxWait: 5 * time.Second}
+
+	_, err := connectWithRetry(conn.connect, policy, func(d time.Duration) { waits = append(waits, d) })
+	require.ErrorIs(t, err, errConnRefused)
+	assert.Equal(t, 3, conn.calls)
+	// 最后一次失败后不再等待
+	require.Len(t, waits, 2)
+	for i, d := range waits {
+		full := policy.Interval << i
+		assert.True(t, d > full/2 && d <= full, "wait %d is %v", i, d)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"os"
 	"os/signal"
 	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
@@ -18,6 +19,7 @@ import (
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
 
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
 	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/repository"
 	mysqlRepo "github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/repository/mysql"
 	myRedisCache "github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/repository/redis"
@@ -25,8 +27,11 @@ import (
 
 	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/rest"
 	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/rest/middleware"
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/usecase/activity"
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/usecase/admin"
 	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/usecase/article"
 	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/usecase/comment"
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/usecase/tag"
 	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/usecase/user"
 	"github.com/joho/godotenv"
 )
@@ -36,18 +41,43 @@ const (
 	defaultAddress      = ":9090"
 	defaultCacheDB      = 0
 	defaultBloomBitSize = 10000000
-	dbMaxRetry          = 10
-	dbRetryIntervalSec  = 2
+	defaultBloomEnabled = true
+	// settingsRefreshInterval 运行时配置的刷新周期，修改后最迟在这个时间内在所有实例生效
+	settingsRefreshInterval = 10 * time.Second
+	// defaultCacheMaxContentSize 缓存中文章正文的最大字节数
+	defaultCacheMaxContentSize = 64 * 1024
+	// 点赞数校准每批检查的文章数，以及批次之间的暂停，用于限制对数据库的压力
+	likesReconcileBatchSize = 500
+	likesReconcilePause     = 200 * time.Millisecond
+	// 浏览量每分钟写入数据库一次；流量大时每 10 秒检查一次，缓冲超过阈值就提前写入，限制 Redis 被清空时丢失的浏览量
+	viewsSyncInterval               = time.Minute
+	viewsCheckpointInterval         = 10 * time.Second
+	defaultViewsCheckpointThreshold = 1000
+	// importWorkers 批量导入文章时并发写入的协程数
+	importWorkers = 4
+	// Redis 熔断：窗口内连续失败多少次后打开，以及打开后的探测周期
+	cacheBreakerThreshold     = 5
+	cacheBreakerWindow        = 10 * time.Second
+	cacheBreakerProbeInterval = 2 * time.Second
+	// 流量统计每天 00:10 把前两天的数据写入数据库，Redis 中保留的天数需要覆盖这两天
+	trafficFlushAt   = 10 * time.Minute
+	trafficFlushDays = domain.TrafficCacheDays
+	// 作者仪表盘实时统计的推送周期，以及每个用户同时打开的连接数上限
+	statsPushInterval    = 3 * time.Second
+	maxStatsConnsPerUser = 5
+	// 协调层单次读取缓存和数据库的超时，缓存超时后转而读数据库
+	defaultCacheReadTimeout = 50 * time.Millisecond
+	defaultDBReadTimeout    = 3 * time.Second
+	// purge-deleted 只清除删除超过这么多天的文章，在此之前作者仍然可以恢复
+	defaultPurgeDeletedAfterDays = 30
 )
 
-func init() {
-	err := godotenv.Load()
-	if err != nil {
+func main() {
+	// 放在 main 而不是 init 中，避免 go test 时也要求存在 .env
+	if err := godotenv.Load(); err != nil {
 		log.Fatal("Error loading .env file")
 	}
-}
 
-func main() {
 	//prepare database
 	dbHost := os.Getenv("DATABASE_HOST")
 	dbPort := os.Getenv("DATABASE_PORT")
@@ -60,34 +90,32 @@ func main() {
 	val.Add("loc", "Asia/Jakarta")
 	dsn := fmt.Sprintf("%s?%s", connection, val.Encode())
 
-	var (
-		db  *gorm.DB
-		err error
-	)
-
-	for i := range dbMaxRetry {
-		db, err = gorm.Open(mysql.Open(dsn), &gorm.Config{})
-		if err != nil {
-			log.Printf("failed to open connection to database (attempt %d/%d): %v", i+1, dbMaxRetry, err)
+	// 重试次数和初始间隔（秒）可以配置，未设置或不合法时使用默认值
+	dbRetry := dbRetryPolicy{
+		MaxRetry: defaultDBMaxRetry,
+		Interval: defaultDBRetryInterval,
+		MaxWait:  defaultDBRetryMaxWait,
+	}
+	if v := os.Getenv("DATABASE_MAX_RETRY"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			dbRetry.MaxRetry = n
 		} else {
-			sqlDB, err := db.DB()
-			if err != nil {
-				log.Printf("failed to get sql.DB from gorm.DB (attempt %d/%d): %v", i+1, dbMaxRetry, err)
-				continue
-			}
-			err = sqlDB.Ping()
-			if err == nil {
-				break
-			}
-			log.Printf("failed to ping database (attempt %d/%d): %v", i+1, dbMaxRetry, err)
-			_ = sqlDB.Close()
+			log.Println("failed to parse DATABASE_MAX_RETRY, using default retry count")
 		}
-
-		time.Sleep(dbRetryIntervalSec * time.Second)
 	}
-
+	if v := os.Getenv("DATABASE_RETRY_INTERVAL"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			dbRetry.Interval = time.Duration(n) * time.Second
+			dbRetry.MaxWait = max(dbRetry.MaxWait, dbRetry.Interval)
+		} else {
+			log.Println("failed to parse DATABASE_RETRY_INTERVAL, using default retry interval")
+		}
+	}
+	db, err := connectWithRetry(func() (*gorm.DB, error) {
+		return openDB(mysql.Open(dsn))
+	}, dbRetry, time.Sleep)
 	if err != nil {
-		log.Fatal("could not connect to database after retries:", err)
+		log.Fatal(err)
 	}
 
 	defer func() {
@@ -110,6 +138,16 @@ func main() {
 		log.Println("failed to parse cacheDB, using default cacheDB")
 		cacheDB = defaultCacheDB
 	}
+	// 多个环境共享同一个 Redis 时用前缀隔离 key
+	cacheKeyPrefix := os.Getenv("CACHE_KEY_PREFIX")
+	// 超长正文只缓存截断后的内容，0 表示不截断
+	cacheMaxContentSize, err := strconv.Atoi(os.Getenv("CACHE_MAX_CONTENT_SIZE"))
+	if err != nil || cacheMaxContentSize < 0 {
+		log.Println("failed to parse cache max content size, using default size")
+		cacheMaxContentSize = defaultCacheMaxContentSize
+	}
+	// 热榜等批量读取文章缓存时每条 MGET 的 key 数，未设置或不大于 0 时使用默认值
+	cacheMGetBatchSize, _ := strconv.Atoi(os.Getenv("CACHE_MGET_BATCH_SIZE"))
 	client := redis.NewClient(&redis.Options{
 		Addr:     cacheHost + ":" + cachePort,
 		Password: cachePass,
@@ -127,10 +165,20 @@ func main() {
 		log.Fatal("failed to open connection to cache", err)
 		return
 	}
+	// 运行期间 Redis 不可用时快速失败，读请求回源 MySQL
+	cacheBreaker := myRedisCache.NewBreaker(cacheBreakerThreshold, cacheBreakerWindow, cacheBreakerProbeInterval)
+	client.AddHook(cacheBreaker)
 
 	// prepare gin
-	route := gin.Default()
+	route, err := newRouter(os.Getenv("TRUSTED_PROXIES"))
+	if err != nil {
+		log.Fatal("failed to parse trusted proxies", err)
+	}
 	route.Use(middleware.CORS())
+	// 调试模式下统计每个请求的数据库查询和 Redis 命令数
+	if debugCounters, _ := strconv.ParseBool(os.Getenv("DEBUG_COUNTERS")); debugCounters {
+		route.Use(middleware.DebugCounters(db, client))
+	}
 	timeoutStr := os.Getenv("CONTEXT_TIMEOUT")
 	timeout, err := strconv.Atoi(timeoutStr)
 	if err != nil {
@@ -143,32 +191,72 @@ func main() {
 	// Prepare Repository
 	userRepo := mysqlRepo.NewUserRepository(db)
 	commentRepo := mysqlRepo.NewCommentRepository(db)
+	auditLogRepo := mysqlRepo.NewAuditLogRepository(db)
+	trafficRepo := mysqlRepo.NewTrafficRepository(db)
 
 	// Article相关的三层架构
 	// 1. DB层
-	articleDBRepo := mysqlRepo.NewArticleDBRepository(db)
+	// 文章列表默认不查询、不返回正文
+	listIncludeContent, _ := strconv.ParseBool(os.Getenv("LIST_INCLUDE_CONTENT"))
+	articleDBRepo := mysqlRepo.NewArticleDBRepository(db, listIncludeContent)
+	uow := mysqlRepo.NewUnitOfWork(db, listIncludeContent)
 	// 2. Cache层
-	articleCache := myRedisCache.NewArticleCache(client)
+	articleCache := myRedisCache.NewArticleCache(client, cacheKeyPrefix, cacheMaxContentSize, cacheMGetBatchSize)
+	// 运行时配置，启动时加载一次，之后定期刷新
+	settingsRepo := myRedisCache.NewSettingsRepository(client, cacheKeyPrefix)
+	settings := repository.NewRuntimeSettings(settingsRepo)
+	if err := settings.Refresh(context.Background()); err != nil {
+		log.Printf("failed to load runtime settings, using defaults: %v\n", err)
+	}
 	// 3. Repository协调层
-	articleRepo := repository.NewArticleRepository(articleDBRepo, articleCache, userRepo)
+	// 缓存默认在后台写入，CACHE_WRITE_SYNC=true 时在请求中同步写入
+	cacheWriteSync, _ := strconv.ParseBool(os.Getenv("CACHE_WRITE_SYNC"))
+	// 缓存写入等后台任务交给有界的任务池，关闭时等待队列执行完
+	background := newBackgroundRunner(defaultBackgroundWorkers, defaultBackgroundQueueSize)
+	articleRepo := repository.NewArticleRepository(articleDBRepo, articleCache, userRepo, settings, cacheWriteSync, background)
+	articleRepo.Timeouts = repository.ReadTimeouts{
+		Cache: envMillis("CACHE_READ_TIMEOUT_MS", defaultCacheReadTimeout),
+		DB:    envMillis("DATABASE_READ_TIMEOUT_MS", defaultDBReadTimeout),
+	}
 
-	bloomBitSizeStr := os.Getenv("BLOOM_FILTER_SIZE")
-	bloomBitSize, err := strconv.ParseUint(bloomBitSizeStr, 10, 64)
+	bloomEnabled, err := strconv.ParseBool(os.Getenv("BLOOM_ENABLED"))
 	if err != nil {
-		log.Printf("failed to parse bloom bit size, using default size")
-		bloomBitSize = defaultBloomBitSize
+		bloomEnabled = defaultBloomEnabled
+	}
+	var bloomRepo domain.BloomRepository
+	if bloomEnabled {
+		bloomBitSizeStr := os.Getenv("BLOOM_FILTER_SIZE")
+		bloomBitSize, err := strconv.ParseUint(bloomBitSizeStr, 10, 64)
+		if err != nil {
+			log.Printf("failed to parse bloom bit size, using default size")
+			bloomBitSize = defaultBloomBitSize
+		}
+		bloomRepo = repository.NewToggleBloomRepository(
+			myRedisCache.NewRedisBloomRepo(client, bloomBitSize, cacheKeyPrefix), settings)
+	} else {
+		log.Println("bloom filter is disabled")
+		bloomRepo = repository.NewNoopBloomRepository()
 	}
-	bloomRepo := myRedisCache.NewRedisBloomRepo(client, bloomBitSize)
 
-	// Start worker
+	reactionRepo := mysqlRepo.NewReactionRepository(db)
+	reactionCache := myRedisCache.NewReactionCache(client, cacheKeyPrefix)
+
 	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer stop()
+	go cacheBreaker.Run(ctx, client)
 
-	views_syncer := workers.NewSyncViewWorker(articleDBRepo, articleCache)
-	go views_syncer.Start(ctx)
-
+	viewsCheckpointThreshold, err := strconv.ParseInt(os.Getenv("VIEWS_CHECKPOINT_THRESHOLD"), 10, 64)
+	if err != nil || viewsCheckpointThreshold < 0 {
+		viewsCheckpointThreshold = defaultViewsCheckpointThreshold
+	}
+	views_syncer := workers.NewSyncViewWorker(articleDBRepo, articleCache, viewsSyncInterval, viewsCheckpointInterval, viewsCheckpointThreshold)
 	likes_syncer := workers.NewSyncLikesWorker(articleDBRepo)
-	go likes_syncer.Start(ctx)
+	traffic_flusher := workers.NewFlushTrafficWorker(articleCache, trafficRepo, trafficFlushAt, trafficFlushDays)
+	// 每次成功写入后记录到 worker:status:<name>，健康检查据此发现停止工作的 worker
+	workerStatus := myRedisCache.NewWorkerStatusCache(client, cacheKeyPrefix)
+	views_syncer.Status = workers.NewStatusRecorder(workerStatus, domain.WorkerSyncViews, viewsSyncInterval)
+	likes_syncer.Status = workers.NewStatusRecorder(workerStatus, domain.WorkerSyncLikes, workers.SyncLikesInterval)
+	traffic_flusher.Status = workers.NewStatusRecorder(workerStatus, domain.WorkerFlushTraffic, 24*time.Hour)
 
 	// Build service Layer
 	jwtSecret := []byte(os.Getenv("JWT_SECRET"))
@@ -179,41 +267,235 @@ func main() {
 		jwtTTL = 24
 	}
 	// usecase层只依赖repository接口和cache（用于点赞等特殊操作）
-	articleSvc := article.NewService(articleRepo, articleCache, likes_syncer, bloomRepo)
-	userSvc := user.NewService(userRepo, jwtSecret, time.Duration(jwtTTL)*time.Hour)
-	commentSvc := comment.NewService(commentRepo, bloomRepo)
+	// 文章摘录的生成策略：fixed（默认）、paragraph 或 sentence
+	titleIndex := myRedisCache.NewTitleIndex(client, cacheKeyPrefix)
+	excerptStrategy := domain.ExcerptStrategy(os.Getenv("EXCERPT_STRATEGY"))
+	if !excerptStrategy.IsValid() {
+		if excerptStrategy != "" {
+			log.Printf("unknown excerpt strategy %q, using %q\n", excerptStrategy, domain.ExcerptFixedLength)
+		}
+		excerptStrategy = domain.ExcerptFixedLength
+	}
+	// 自动摘要使用内置的第一段摘要，需要外部摘要服务时在这里传入 domain.Summarizer
+	articleSvc := article.NewService(articleRepo, articleCache, likes_syncer, bloomRepo, reactionRepo, reactionCache, excerptStrategy, titleIndex, commentRepo, nil)
+	signupCache := myRedisCache.NewSignupCache(client, cacheKeyPrefix)
+	userSvc := user.NewService(userRepo, jwtSecret, time.Duration(jwtTTL)*time.Hour, signupCache)
+	commentSvc := comment.NewService(commentRepo, bloomRepo, userRepo, articleRepo)
+	activityRepo := mysqlRepo.NewActivityRepository(db)
+	activitySvc := activity.NewService(activityRepo)
+	tagRepo := repository.NewCachedTagRepository(
+		mysqlRepo.NewTagRepository(db),
+		myRedisCache.NewTagCache(client, cacheKeyPrefix),
+	)
+	tagSvc := tag.NewService(tagRepo)
+	siteStats := repository.NewCachedSiteStatsRepository(
+		mysqlRepo.NewSiteStatsRepository(db),
+		myRedisCache.NewSiteStatsCache(client, cacheKeyPrefix),
+	)
+	likesReconciler := workers.NewLikesReconciler(articleDBRepo, articleCache, myRedisCache.NewCursorStore(client, cacheKeyPrefix), likesReconcileBatchSize, likesReconcilePause)
+	articleImporter := workers.NewArticleImporter(articleSvc, userRepo, bloomRepo, importWorkers)
+	adminSvc := admin.NewService(articleSvc, auditLogRepo, settings, settingsRepo, siteStats, articleCache, likes_syncer, likesReconciler, articleImporter, background, trafficRepo)
+	warmer := &cacheWarmer{
+		articleRepo: articleRepo,
+		articleDB:   articleDBRepo,
+		cache:       articleCache,
+	}
+
+	// 启动自检：确认各个服务的依赖都已装配，MySQL 可用。Redis 故障时服务降级运行，这里不 ping
+	if err := selfTest(ctx, []wiringCheck{
+		{name: "mysql", dep: db, ping: func(ctx context.Context) error {
+			sqlDB, err := db.DB()
+			if err != nil {
+				return err
+			}
+			return sqlDB.PingContext(ctx)
+		}},
+		{name: "redis client", dep: client},
+		{name: "user repository", dep: userRepo},
+		{name: "comment repository", dep: commentRepo},
+		{name: "activity repository", dep: activityRepo},
+		{name: "tag repository", dep: tagRepo},
+		{name: "audit log repository", dep: auditLogRepo},
+		{name: "traffic repository", dep: trafficRepo},
+		{name: "article db repository", dep: articleDBRepo},
+		{name: "unit of work", dep: uow},
+		{name: "article cache", dep: articleCache},
+		{name: "article repository", dep: articleRepo},
+		{name: "bloom repository", dep: bloomRepo},
+		{name: "reaction repository", dep: reactionRepo},
+		{name: "reaction cache", dep: reactionCache},
+		{name: "title index", dep: titleIndex},
+		{name: "runtime settings", dep: settings},
+		{name: "site stats", dep: siteStats},
+		{name: "article service", dep: articleSvc},
+		{name: "user service", dep: userSvc},
+		{name: "comment service", dep: commentSvc},
+		{name: "activity service", dep: activitySvc},
+		{name: "tag service", dep: tagSvc},
+		{name: "admin service", dep: adminSvc},
+	}); err != nil {
+		log.Fatal(err)
+	}
+
+	// 维护子命令复用上面的依赖，执行完直接退出，不启动服务和后台任务
+	if len(os.Args) > 1 {
+		tasks := &maintenance{
+			articleSvc:   articleSvc,
+			userSvc:      userSvc,
+			warmer:       warmer,
+			bloomEnabled: bloomEnabled,
+			articleDB:    articleDBRepo,
+			uow:          uow,
+			purgeAfter:   defaultPurgeDeletedAfterDays * 24 * time.Hour,
+		}
+		if days, err := strconv.Atoi(os.Getenv("PURGE_DELETED_AFTER_DAYS")); err == nil && days > 0 {
+			tasks.purgeAfter = time.Duration(days) * 24 * time.Hour
+		}
+		if err := runCommand(ctx, os.Args[1:], tasks); err != nil {
+			log.Fatalf("%s: %v\n", os.Args[1], err)
+		}
+		return
+	}
+
+	// Start worker
+	go settings.Start(ctx, settingsRefreshInterval)
+	go views_syncer.Start(ctx)
+	go likes_syncer.Start(ctx)
+	go traffic_flusher.Start(ctx)
+
+	statsHub := rest.NewStatsHub(articleSvc, statsPushInterval, maxStatsConnsPerUser)
+	// 仪表盘与 API 不同源时通过 STATS_WS_ALLOWED_ORIGINS 配置，多个来源用逗号分隔
+	if origins := os.Getenv("STATS_WS_ALLOWED_ORIGINS"); origins != "" {
+		statsHub.AllowedOrigins = strings.Split(origins, ",")
+	}
+	go statsHub.Run(ctx)
+
 	articleHandler := rest.NewArticleHandler(articleSvc)
+	articleHandler.ListIncludeContent = listIncludeContent
+	// 创建文章时没有指定语言的使用 DEFAULT_ARTICLE_LANGUAGE，不合法或未配置时不指定
+	if lang := os.Getenv("DEFAULT_ARTICLE_LANGUAGE"); lang != "" {
+		if canonical, ok := domain.NormalizeLanguage(lang); ok {
+			articleHandler.DefaultLanguage = canonical
+		} else {
+			log.Printf("invalid DEFAULT_ARTICLE_LANGUAGE %q, articles are stored without a language\n", lang)
+		}
+	}
+	articleHandler.ProviderName = os.Getenv("SITE_NAME")
+	// 聊天软件抓取链接预览时通常只认文章地址，打开后爬虫拿到的是元数据
+	articleHandler.UnfurlBots, _ = strconv.ParseBool(os.Getenv("UNFURL_BOT_REQUESTS"))
 	userHandler := rest.NewUserHandler(userSvc)
 	commentHandler := rest.NewCommentHandler(commentSvc)
+	activityHandler := rest.NewActivityHandler(activitySvc)
+	tagHandler := rest.NewTagHandler(tagSvc)
+	adminHandler := rest.NewAdminHandler(adminSvc)
 
 	authMiddleware := middleware.AuthMiddleware(string(jwtSecret))
+	optionalAuth := middleware.OptionalAuth(string(jwtSecret))
+	streamAuth := middleware.StreamAuth(string(jwtSecret))
+	// 只有文章详情和点赞计入流量统计
+	clientInfo := middleware.ClientInfo()
 
 	// Prepare bloom filter
-	if err := articleSvc.InitBloomFilter(ctx); err != nil {
-		log.Printf("failed to init bloom filter: %v\n", err)
-		return
+	if bloomEnabled {
+		if err := articleSvc.InitBloomFilter(ctx); err != nil {
+			log.Printf("failed to init bloom filter: %v\n", err)
+			return
+		}
+	}
+
+	// 标题联想索引在后台重建，完成前联想结果可能不完整
+	go func() {
+		if err := articleSvc.RebuildTitleIndex(ctx); err != nil {
+			log.Printf("failed to rebuild title index: %v\n", err)
+		}
+	}()
+
+	// Warm up caches
+	warmUpEnabled, err := strconv.ParseBool(os.Getenv("CACHE_WARMUP_ENABLED"))
+	if err != nil {
+		warmUpEnabled = defaultWarmUpCache
+	}
+	if warmUpEnabled {
+		warmer.warmUp(ctx)
 	}
 
 	// Register routes
+	healthHandler := rest.NewHealthHandler(cacheBreaker)
+	healthHandler.Workers = workerStatus
+	healthHandler.WorkerNames = []string{domain.WorkerSyncViews, domain.WorkerSyncLikes, domain.WorkerFlushTraffic}
+	route.GET("/healthz", healthHandler.Healthz)
 	route.POST("/register", userHandler.Register)
+	route.GET("/auth/username-available", userHandler.UsernameAvailable)
 	route.POST("/login", userHandler.Login)
 
-	route.GET("/articles", articleHandler.FetchArticle)
-	route.GET("/articles/:id", articleHandler.GetByID)
-
-	route.GET("/articles/ranks", articleHandler.FetchRank)
-
-	route.GET("/articles/:id/comments", commentHandler.FetchCommentsByArticle)
+	route.GET("/articles", optionalAuth, articleHandler.FetchArticle)
+	route.GET("/articles/:id", optionalAuth, clientInfo, articleHandler.GetByID)
+	route.GET("/articles/:id/detail", optionalAuth, clientInfo, articleHandler.GetDetail)
+	route.GET("/articles/:id/meta", articleHandler.GetMeta)
+	route.GET("/oembed", articleHandler.OEmbed)
+
+	route.GET("/articles/ranks", optionalAuth, articleHandler.FetchRank)
+	route.GET("/articles/suggest", articleHandler.SuggestTitles)
+	route.GET("/articles/search", articleHandler.Search)
+	route.GET("/tags", articleHandler.ListTags)
+	route.GET("/tags/trending", tagHandler.Trending)
+
+	route.GET("/articles/:id/comments", optionalAuth, commentHandler.FetchCommentsByArticle)
+	route.POST("/articles/engagement", articleHandler.GetEngagement)
+	route.GET("/users/:id/activity", activityHandler.FetchByUser)
+
+	// v1 的只读接口对不合法的分页参数直接返回 400，原路径保持修正参数的兼容行为
+	v1 := route.Group("/api/v1")
+	v1.Use(rest.StrictParams())
+	{
+		v1.GET("/articles", optionalAuth, articleHandler.FetchArticle)
+		v1.GET("/articles/:id", optionalAuth, clientInfo, articleHandler.GetByID)
+		v1.GET("/articles/:id/detail", optionalAuth, clientInfo, articleHandler.GetDetail)
+		v1.GET("/articles/:id/meta", articleHandler.GetMeta)
+		v1.GET("/articles/ranks", optionalAuth, articleHandler.FetchRank)
+		v1.GET("/articles/suggest", articleHandler.SuggestTitles)
+		v1.GET("/articles/search", articleHandler.Search)
+		v1.GET("/tags", articleHandler.ListTags)
+		v1.GET("/tags/trending", tagHandler.Trending)
+		v1.GET("/articles/:id/comments", optionalAuth, commentHandler.FetchCommentsByArticle)
+		v1.GET("/users/:id/activity", activityHandler.FetchByUser)
+	}
 
 	authorized := route.Group("/")
 	authorized.Use(authMiddleware)
 	{
 		authorized.POST("/articles", articleHandler.Store)
+		authorized.PUT("/articles/:id", articleHandler.Update)
 		authorized.DELETE("/articles/:id", articleHandler.Delete)
-		authorized.POST("/articles/:id/like", articleHandler.Like)
+		authorized.POST("/articles/:id/restore", articleHandler.Restore)
+		authorized.POST("/articles/:id/publish", articleHandler.Publish)
+		authorized.GET("/users/me/drafts", articleHandler.FetchDrafts)
+		authorized.POST("/articles/:id/like", clientInfo, articleHandler.Like)
 		authorized.DELETE("/articles/:id/like", articleHandler.Unlike)
+		authorized.POST("/articles/:id/reactions/:type", articleHandler.AddReaction)
+		authorized.DELETE("/articles/:id/reactions/:type", articleHandler.RemoveReaction)
 		authorized.POST("/articles/:id/comments", commentHandler.CreateComment)
-		authorized.DELETE("/articles/:id/comments", commentHandler.DeleteComment)
+		authorized.POST("/articles/:id/comments/lock", articleHandler.LockComments)
+		authorized.DELETE("/articles/:id/comments/lock", articleHandler.UnlockComments)
+		authorized.DELETE("/comments/:id", commentHandler.DeleteComment)
+		authorized.POST("/comments/:id/replies", commentHandler.ReplyComment)
+		authorized.PUT("/users/password", userHandler.EditPassword)
+	}
+
+	route.GET("/ws/articles/:id/stats", streamAuth, statsHub.Stream)
+
+	adminGroup := route.Group("/admin")
+	adminGroup.Use(authMiddleware, middleware.AdminOnly())
+	{
+		adminGroup.GET("/users", userHandler.List)
+		adminGroup.POST("/articles/bulk", adminHandler.BulkModerateArticles)
+		adminGroup.GET("/settings", adminHandler.GetSettings)
+		adminGroup.PUT("/settings", adminHandler.UpdateSettings)
+		adminGroup.GET("/overview", adminHandler.Overview)
+		adminGroup.GET("/traffic", adminHandler.Traffic)
+		adminGroup.POST("/reconcile/likes", adminHandler.ReconcileLikes)
+		adminGroup.POST("/import/articles", adminHandler.ImportArticles)
+		adminGroup.GET("/import/:job", adminHandler.GetImportJob)
 	}
 
 	// Start Server
@@ -243,8 +525,45 @@ func main() {
 		log.Fatal("Server forced to shutdown: ", err)
 	}
 
+	if err := background.Close(shutdownCtx); err != nil {
+		log.Printf("background tasks are not drained: %v, %d tasks left\n", err, background.QueueDepth())
+	}
+
 	log.Println("Waiting for worker to cleanup...")
 	time.Sleep(2 * time.Second)
 
 	log.Println("Server exiting")
 }
+
+// newRouter 创建 gin 引擎，access_token 查询参数不写进访问日志。
+// 只有 trustedProxies（逗号分隔的 IP 或 CIDR）中的反向代理设置的 X-Forwarded-For 才会被采用，
+// 为空时 ClientIP 就是连接的地址，客户端伪造的请求头不能绕过按 IP 的限流
+func newRouter(trustedProxies string) (*gin.Engine, error) {
+	route := gin.New()
+	route.Use(middleware.Logger(), gin.Recovery())
+
+	var proxies []string
+	for _, p := range strings.Split(trustedProxies, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			proxies = append(proxies, p)
+		}
+	}
+	if err := route.SetTrustedProxies(proxies); err != nil {
+		return nil, err
+	}
+	return route, nil
+}
+
+// envMillis 读取以毫秒为单位的时长，未设置或不合法时使用默认值，0 表示不限制
+func envMillis(name string, def time.Duration) time.Duration {
+	v := os.Getenv(name)
+	if v == "" {
+		return def
+	}
+	ms, err := strconv.Atoi(v)
+	if err != nil || ms < 0 {
+		log.Printf("failed to parse %s, using default %v", name, def)
+		return def
+	}
+	return time.Duration(ms) * time.Millisecond
+}
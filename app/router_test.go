@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+	myRedisCache "github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/repository/redis"
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/rest"
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/usecase/user"
+)
+
+// noUsers 没有任何用户，所有用户名都可用
+type noUsers struct {
+	domain.UserRepository
+}
+
+func (noUsers) GetByUsername(context.Context, string) (domain.User, error) {
+	return domain.User{}, domain.ErrNotFound
+}
+
+func TestUsernameAvailableIgnoresSpoofedForwardedFor(t *testing.T) {
+	cases := []struct {
+		name           string
+		trustedProxies string
+		limited        bool
+	}{
+		// 没有配置代理时每次换一个 X-Forwarded-For 仍然是同一个客户端
+		{"no trusted proxies", "", true},
+		// 请求来自受信任的代理时按它转发的地址区分客户端
+		{"trusted proxy", "192.0.2.0/24, 10.0.0.1", false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			mr := miniredis.RunT(t)
+			client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+			t.Cleanup(func() { _ = client.Close() })
+			svc := user.NewService(noUsers{}, []byte("secret"), time.Hour, myRedisCache.NewSignupCache(client, ""))
+
+			route, err := newRouter(tc.trustedProxies)
+			require.NoError(t, err)
+			route.GET("/auth/username-available", rest.NewUserHandler(svc).UsernameAvailable)
+
+			var last int
+			for i := range domain.UsernameCheckLimit + 1 {
+				w := httptest.NewRecorder()
+				req := httptest.NewRequest(http.MethodGet, "/auth/username-available?username=bob", nil)
+				req.Header.Set("X-Forwarded-For", "203.0.113."+strconv.Itoa(i+1))
+				route.ServeHTTP(w, req)
+				last = w.Code
+			}
+			if tc.limited {
+				assert.Equal(t, http.StatusTooManyRequests, last)
+			} else {
+				assert.Equal(t, http.StatusOK, last)
+			}
+		})
+	}
+}
+
+func TestNewRouterRejectsBadProxy(t *testing.T) {
+	_, err := newRouter("not-an-ip")
+	assert.Error(t, err)
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+const selfTestTimeout = 5 * time.Second
+
+// wiringCheck 启动自检的一项。dep 为 nil（包括包在接口里的 nil 指针）时直接判定失败，
+// ping 不为 nil 时再做一次廉价的调用确认依赖可用
+type wiringCheck struct {
+	name string
+	dep  any
+	ping func(ctx context.Context) error
+}
+
+// selfTest 依次执行所有检查，返回的错误列出全部失败项，而不是只报第一个
+func selfTest(ctx context.Context, checks []wiringCheck) error {
+	ctx, cancel := context.WithTimeout(ctx, selfTestTimeout)
+	defer cancel()
+
+	var errs []error
+	for _, c := range checks {
+		if isNilDep(c.dep) {
+			errs = append(errs, fmt.Errorf("%s: dependency is nil", c.name))
+			continue
+		}
+		if c.ping == nil {
+			continue
+		}
+		if err := c.ping(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", c.name, err))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("startup self-test failed: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+func isNilDep(dep any) bool {
+	if dep == nil {
+		return true
+	}
+	v := reflect.ValueOf(dep)
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan:
+		return v.IsNil()
+	}
+	return false
+}
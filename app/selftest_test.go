@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+)
+
+type stubUserRepo struct {
+	domain.UserRepository
+}
+
+func TestSelfTestDetectsNilDependency(t *testing.T) {
+	var nilRepo *stubUserRepo
+	var userRepo domain.UserRepository = nilRepo
+
+	err := selfTest(context.Background(), []wiringCheck{
+		{name: "article service", dep: &stubUserRepo{}},
+		// 接口里包着 nil 指针，直接比较 == nil 发现不了
+		{name: "user repository", dep: userRepo},
+		{name: "comment repository", dep: nil},
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "user repository: dependency is nil")
+	assert.Contains(t, err.Error(), "comment repository: dependency is nil")
+	assert.NotContains(t, err.Error(), "article service")
+}
+
+func TestSelfTestRunsPing(t *testing.T) {
+	errDown := errors.New("connection refused")
+	pinged := false
+
+	err := selfTest(context.Background(), []wiringCheck{
+		{name: "mysql", dep: &stubUserRepo{}, ping: func(context.Context) error { return errDown }},
+		{name: "redis client", dep: &stubUserRepo{}, ping: func(context.Context) error { pinged = true; return nil }},
+		// 依赖为 nil 时不再调用 ping
+		{name: "cache", dep: nil, ping: func(context.Context) error { t.Fatal("ping on nil dependency"); return nil }},
+	})
+	require.ErrorIs(t, err, errDown)
+	assert.True(t, pinged)
+	assert.Contains(t, err.Error(), "mysql: connection refused")
+
+	require.NoError(t, selfTest(context.Background(), []wiringCheck{{name: "ok", dep: &stubUserRepo{}}}))
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"log"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+)
+
+const (
+	defaultBackgroundWorkers   = 8
+	defaultBackgroundQueueSize = 1024
+)
+
+// backgroundRunner 固定数量的 worker 从有界队列中取任务执行，队列满时直接丢弃任务，Submit 返回 false。
+// 缓存预热、重建之类的任务丢弃后只会让下一次读取回源；缓存失效不能丢，由调用方在提交失败时自己执行
+type backgroundRunner struct {
+	tasks chan func(ctx context.Context)
+	// ctx 传给每个任务，Close 等待超时后取消，让还在执行的任务尽快退出
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu      sync.RWMutex // 保护 closed，避免向已关闭的 channel 发送
+	closed  bool
+	dropped atomic.Int64
+}
+
+var _ domain.BackgroundRunner = (*backgroundRunner)(nil)
+
+// newBackgroundRunner 创建并启动 workers 个 worker，队列最多容纳 queueSize 个等待中的任务
+func newBackgroundRunner(workers, queueSize int) *backgroundRunner {
+	ctx, cancel := context.WithCancel(context.Background())
+	r := &backgroundRunner{
+		tasks:  make(chan func(ctx context.Context), queueSize),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+	r.wg.Add(workers)
+	for range workers {
+		go r.work()
+	}
+	return r
+}
+
+func (r *backgroundRunner) Submit(task func(ctx context.Context)) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.closed {
+		r.dropped.Add(1)
+		return false
+	}
+	select {
+	case r.tasks <- task:
+		return true
+	default:
+		r.dropped.Add(1)
+		return false
+	}
+}
+
+func (r *backgroundRunner) QueueDepth() int {
+	return len(r.tasks)
+}
+
+func (r *backgroundRunner) Dropped() int64 {
+	return r.dropped.Load()
+}
+
+// Close 停止接收新任务并等待队列中的任务执行完。
+// ctx 结束时取消正在执行的任务并返回 ctx.Err()，队列中剩下的任务不再执行
+func (r *backgroundRunner) Close(ctx context.Context) error {
+	r.mu.Lock()
+	if !r.closed {
+		r.closed = true
+		close(r.tasks)
+	}
+	r.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		r.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		r.cancel()
+		return nil
+	case <-ctx.Done():
+		r.cancel()
+		return ctx.Err()
+	}
+}
+
+func (r *backgroundRunner) work() {
+	defer r.wg.Done()
+	for task := range r.tasks {
+		if r.ctx.Err() != nil {
+			continue
+		}
+		r.run(task)
+	}
+}
+
+// run 执行单个任务，任务 panic 时记录日志，不影响 worker 继续工作
+func (r *backgroundRunner) run(task func(ctx context.Context)) {
+	defer func() {
+		if p := recover(); p != nil {
+			log.Printf("background task panicked: %v\n%s", p, debug.Stack())
+		}
+	}()
+	task(r.ctx)
+}
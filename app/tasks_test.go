@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackgroundRunnerDrainsOnClose(t *testing.T) {
+	r := newBackgroundRunner(2, 100)
+	var done atomic.Int64
+	for range 50 {
+		require.True(t, r.Submit(func(context.Context) {
+			time.Sleep(time.Millisecond)
+			done.Add(1)
+		}))
+	}
+
+	require.NoError(t, r.Close(context.Background()))
+	assert.Equal(t, int64(50), done.Load())
+
+	// 关闭后提交的任务被丢弃
+	assert.False(t, r.Submit(func(context.Context) {}))
+	assert.Equal(t, int64(1), r.Dropped())
+}
+
+func TestBackgroundRunnerDropsWhenFull(t *testing.T) {
+	r := newBackgroundRunner(1, 1)
+	release := make(chan struct{})
+	started := make(chan struct{})
+	require.True(t, r.Submit(func(context.Context) {
+		close(started)
+		<-release
+	}))
+	<-started
+
+	assert.True(t, r.Submit(func(context.Context) {}))
+	assert.False(t, r.Submit(func(context.Context) {}))
+	assert.Equal(t, 1, r.QueueDepth())
+	assert.Equal(t, int64(1), r.Dropped())
+
+	close(release)
+	require.NoError(t, r.Close(context.Background()))
+}
+
+func TestBackgroundRunnerRecoversPanic(t *testing.T) {
+	r := newBackgroundRunner(1, 10)
+	var ran atomic.Bool
+	require.True(t, r.Submit(func(context.Context) { panic("boom") }))
+	require.True(t, r.Submit(func(context.Context) { ran.Store(true) }))
+
+	require.NoError(t, r.Close(context.Background()))
+	assert.True(t, ran.Load())
+}
+
+func TestBackgroundRunnerCloseTimeout(t *testing.T) {
+	r := newBackgroundRunner(1, 10)
+	canceled := make(chan struct{})
+	require.True(t, r.Submit(func(ctx context.Context) {
+		<-ctx.Done()
+		close(canceled)
+	}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, r.Close(ctx), context.DeadlineExceeded)
+
+	// 等待超时后取消仍在执行的任务
+	select {
+	case <-canceled:
+	case <-time.After(time.Second):
+		t.Fatal("task ctx is not canceled")
+	}
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+)
+
+const (
+	warmUpTimeout      = 30 * time.Second
+	warmUpHomeNum      = 10
+	warmUpRankLimit    = 50
+	warmUpArticleTTL   = 10 * time.Minute
+	defaultWarmUpCache = true
+)
+
+// cacheWarmer 在服务启动前预热首页和热榜缓存，避免发布后的首批请求打到数据库
+type cacheWarmer struct {
+	articleRepo domain.ArticleRepository
+	articleDB   domain.ArticleDBRepository
+	cache       domain.ArticleCache
+}
+
+// warmUp 并发预热各项缓存，失败只记录日志，不阻塞启动
+func (w *cacheWarmer) warmUp(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, warmUpTimeout)
+	defer cancel()
+	start := time.Now()
+
+	var (
+		wg         sync.WaitGroup
+		historyIDs []int64
+		dailyIDs   []int64
+	)
+	wg.Add(3)
+	go func() {
+		defer wg.Done()
+		if _, _, err := w.articleRepo.Fetch(ctx, "", warmUpHomeNum, "", ""); err != nil {
+			log.Printf("warm up: failed to warm home cache: %v", err)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		ids, err := w.warmHistoryRank(ctx)
+		if err != nil {
+			log.Printf("warm up: failed to warm history rank: %v", err)
+			return
+		}
+		historyIDs = ids
+	}()
+	go func() {
+		defer wg.Done()
+		// 读取日榜会触发小时榜的 ZUNIONSTORE 聚合
+		rank, err := w.cache.GetDailyRank(ctx, 0, warmUpRankLimit)
+		if err != nil {
+			log.Printf("warm up: failed to warm daily rank: %v", err)
+			return
+		}
+		for _, ar := range rank {
+			dailyIDs = append(dailyIDs, ar.ID)
+		}
+	}()
+	wg.Wait()
+
+	if err := w.warmRankArticles(ctx, historyIDs, dailyIDs); err != nil {
+		log.Printf("warm up: failed to warm ranked articles: %v", err)
+	}
+
+	log.Printf("warm up: finished in %v", time.Since(start))
+}
+
+func (w *cacheWarmer) warmHistoryRank(ctx context.Context) ([]int64, error) {
+	articles, err := w.articleDB.FetchArticlesByLikes(ctx, warmUpRankLimit)
+	if err != nil {
+		return nil, err
+	}
+	if len(articles) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]int64, len(articles))
+	scores := make([]float64, len(articles))
+	for i, ar := range articles {
+		ids[i] = ar.ID
+		scores[i] = float64(ar.Likes)
+	}
+	if err := w.cache.SetHistoryRank(ctx, ids, scores); err != nil {
+		return nil, err
+	}
+	if err := w.cache.SetStaleHistoryRank(ctx, ids, scores); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// warmRankArticles 缓存两个榜单中排名前 warmUpRankLimit 的文章详情
+func (w *cacheWarmer) warmRankArticles(ctx context.Context, rankIDs ...[]int64) error {
+	seen := make(map[int64]bool)
+	ids := make([]int64, 0, warmUpRankLimit)
+	for _, list := range rankIDs {
+		for _, id := range list {
+			if len(ids) == warmUpRankLimit {
+				break
+			}
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	// 先读版本号，读取期间被局部更新的文章不会被旧数据覆盖
+	versions, err := w.cache.GetArticleVersions(ctx, ids)
+	if err != nil {
+		return err
+	}
+	articles, err := w.articleRepo.GetByIDs(ctx, ids)
+	if err != nil {
+		return err
+	}
+	return w.cache.BatchSetArticleWithLogicalExpire(ctx, articles, versions, warmUpArticleTTL)
+}
@@ -33,7 +33,22 @@ CREATE TABLE `article` (
   `created_at` datetime DEFAULT NULL,
   `views` bigint DEFAULT '0',
   `likes` bigint DEFAULT '0',
-  PRIMARY KEY (`id`)
+  `edited` tinyint(1) DEFAULT '0',
+  `edit_count` bigint DEFAULT '0',
+  `hidden` tinyint(1) NOT NULL DEFAULT '0',
+  `summary` varchar(300) COLLATE utf8_unicode_ci NOT NULL DEFAULT '',
+  `summary_is_auto` tinyint(1) NOT NULL DEFAULT '1',
+  `comments_locked` tinyint(1) NOT NULL DEFAULT '0',
+  `language` varchar(35) COLLATE utf8_unicode_ci NOT NULL DEFAULT '',
+  `fingerprint` char(64) COLLATE utf8_unicode_ci NOT NULL DEFAULT '',
+  `status` varchar(16) COLLATE utf8_unicode_ci NOT NULL DEFAULT 'published',
+  `deleted_at` datetime DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  KEY `idx_language_created_at` (`language`, `created_at`),
+  KEY `idx_hidden_likes` (`hidden`, `likes`, `id`),
+  KEY `idx_fingerprint` (`fingerprint`),
+  KEY `idx_user_status` (`user_id`, `status`),
+  KEY `idx_article_deleted_at` (`deleted_at`)
 ) ENGINE=InnoDB AUTO_INCREMENT=7 DEFAULT CHARSET=utf8 COLLATE=utf8_unicode_ci;
 /*!40101 SET character_set_client = @saved_cs_client */;
 
@@ -43,7 +58,7 @@ CREATE TABLE `article` (
 
 LOCK TABLES `article` WRITE;
 /*!40000 ALTER TABLE `article` DISABLE KEYS */;
-INSERT INTO `article` VALUES (
+INSERT INTO `article` (`id`, `title`, `content`, `user_id`, `updated_at`, `created_at`, `views`, `likes`) VALUES (
     1,
     'Makan Ayam','<p>But I must explain to you how all this mistaken idea of denouncing pleasure and praising pain was born and I will give you a complete account of the system, and expound the actual teachings of the great explorer of the truth, the master-builder of human happiness. No one rejects, dislikes, or avoids pleasure itself, because it is pleasure, but because those who do not know how to pursue pleasure rationally encounter consequences that are extremely painful.</p>\n\n<p>Nor again is there anyone who loves or pursues or desires to obtain pain of itself, because it is pain, but because occasionally circumstances occur in which toil and pain can procure him some great pleasure. To take a trivial example, which of us ever undertakes laborious physical exercise, except to obtain some advantage from it? But who has any right to find fault with a man who chooses to enjoy a pleasure that has no annoying consequences, or one who avoids a pain that produces no resultant pleasure?</p>\n\n<p>On the other hand, we denounce with righteous indignation and dislike men who are so beguiled and demoralized by the charms of pleasure of the moment, so blinded by desire, that they cannot foresee the pain and trouble that are bound to ensue; and equal blame belongs to those who fail in their duty through weakness of will, which is the same as saying through shrinking from toil and pain. These cases are perfectly simple and easy to distinguish.</p>\n\n<p>In a free hour, when our power of choice is untrammelled and when nothing prevents our being able to do what we like best, every pleasure is to be welcomed and every pain avoided. But in certain circumstances and owing to the claims of duty or the obligations of business it will frequently occur that pleasures have to be repudiated and annoyances accepted. The wise man therefore always holds in these matters to this principle of selection: he rejects pleasures to secure other greater pleasures, or else he endures pains to avoid worse pains.</p>\n\n<p>But I must explain to you how all this mistaken idea of denouncing pleasure and praising pain was born and I will give you a complete account of the system, and expound the actual teachings of the great explorer of the truth, the master-builder of human happiness.But who has any right to find fault with a man who chooses to enjoy a pleasure that has no annoying consequences, or one who avoids a pain that produces no resultant pleasure? On the</p>\n\n',
     1,
@@ -163,6 +178,38 @@ INSERT INTO `article_category` VALUES (1,1,1),(2,1,2),(3,1,3),(4,2,1),(5,2,2),(6
 /*!40000 ALTER TABLE `article_category` ENABLE KEYS */;
 UNLOCK TABLES;
 
+--
+-- Table structure for table `tag`
+--
+
+DROP TABLE IF EXISTS `tag`;
+/*!40101 SET @saved_cs_client     = @@character_set_client */;
+/*!40101 SET character_set_client = utf8 */;
+CREATE TABLE `tag` (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `name` varchar(32) COLLATE utf8_unicode_ci NOT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_tag_name` (`name`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8 COLLATE=utf8_unicode_ci;
+/*!40101 SET character_set_client = @saved_cs_client */;
+
+--
+-- Table structure for table `article_tag`
+--
+
+DROP TABLE IF EXISTS `article_tag`;
+/*!40101 SET @saved_cs_client     = @@character_set_client */;
+/*!40101 SET character_set_client = utf8 */;
+CREATE TABLE `article_tag` (
+  `article_id` bigint NOT NULL,
+  `tag_id` bigint NOT NULL,
+  `created_at` datetime DEFAULT NULL,
+  PRIMARY KEY (`article_id`, `tag_id`),
+  KEY `idx_tag_id` (`tag_id`),
+  KEY `idx_article_tag_created_at` (`created_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8 COLLATE=utf8_unicode_ci;
+/*!40101 SET character_set_client = @saved_cs_client */;
+
 --
 -- Table structure for table `user`
 --
@@ -175,9 +222,11 @@ CREATE TABLE `user` (
   `name` varchar(32) COLLATE utf8_bin NOT NULL,
   `username` varchar(32) COLLATE utf8_bin NOT NULL,
   `password` varchar(64) COLLATE utf8_bin NOT NULL,
+  `role` varchar(16) COLLATE utf8_bin NOT NULL DEFAULT 'user',
   `created_at` datetime DEFAULT NULL,
   `updated_at` datetime DEFAULT NULL,
-  PRIMARY KEY (`id`)
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_username` (`username`)
 ) ENGINE=InnoDB AUTO_INCREMENT=2 DEFAULT CHARSET=utf8 COLLATE=utf8_unicode_ci;
 /*!40101 SET character_set_client = @saved_cs_client */;
 
@@ -187,7 +236,7 @@ CREATE TABLE `user` (
 
 LOCK TABLES `user` WRITE;
 /*!40000 ALTER TABLE `user` DISABLE KEYS */;
-INSERT INTO `user` VALUES (1,'Iman Tumorang', 'user1', '$2a$10$VFhN/v29hM3ouMP6tx2aiOHF7.IidOOoolYKGQnwDn4eLq5AV646O', '2017-05-18 13:50:19','2017-05-18 13:50:19');
+INSERT INTO `user` (`id`, `name`, `username`, `password`, `created_at`, `updated_at`) VALUES (1,'Iman Tumorang', 'user1', '$2a$10$VFhN/v29hM3ouMP6tx2aiOHF7.IidOOoolYKGQnwDn4eLq5AV646O', '2017-05-18 13:50:19','2017-05-18 13:50:19');
 /*!40000 ALTER TABLE `user` ENABLE KEYS */;
 UNLOCK TABLES;
 
@@ -217,6 +266,81 @@ INSERT INTO `user_likes` VALUES (1,2,'2017-12-13 17:16:59');
 /*!40000 ALTER TABLE `user_likes` ENABLE KEYS */;
 UNLOCK TABLES;
 
+--
+-- Table structure for table `reactions`
+--
+
+DROP TABLE IF EXISTS `reactions`;
+/*!40101 SET @saved_cs_client     = @@character_set_client */;
+/*!40101 SET character_set_client = utf8 */;
+CREATE TABLE `reactions` (
+  `article_id` bigint NOT NULL,
+  `user_id` bigint NOT NULL,
+  `type` varchar(16) NOT NULL,
+  `created_at` datetime DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`article_id`, `user_id`, `type`),
+  KEY `idx_user_id` (`user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+/*!40101 SET character_set_client = @saved_cs_client */;
+
+--
+-- Table structure for table `audit_logs`
+--
+
+DROP TABLE IF EXISTS `audit_logs`;
+/*!40101 SET @saved_cs_client     = @@character_set_client */;
+/*!40101 SET character_set_client = utf8 */;
+CREATE TABLE `audit_logs` (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `actor_id` bigint NOT NULL,
+  `action` varchar(32) NOT NULL,
+  `target_type` varchar(32) NOT NULL,
+  `target_id` bigint NOT NULL,
+  `result` varchar(16) NOT NULL,
+  `detail` varchar(255) NOT NULL DEFAULT '',
+  `created_at` datetime DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  KEY `idx_actor_id` (`actor_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+/*!40101 SET character_set_client = @saved_cs_client */;
+
+--
+-- Table structure for table `traffic_daily`
+--
+
+DROP TABLE IF EXISTS `traffic_daily`;
+/*!40101 SET @saved_cs_client     = @@character_set_client */;
+/*!40101 SET character_set_client = utf8 */;
+CREATE TABLE `traffic_daily` (
+  `date` date NOT NULL,
+  `metric` varchar(16) NOT NULL,
+  `dimension` varchar(16) NOT NULL,
+  `value` varchar(128) NOT NULL,
+  `count` bigint NOT NULL DEFAULT '0',
+  PRIMARY KEY (`date`, `metric`, `dimension`, `value`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+/*!40101 SET character_set_client = @saved_cs_client */;
+
+--
+-- Table structure for table `activity`
+--
+
+DROP TABLE IF EXISTS `activity`;
+/*!40101 SET @saved_cs_client     = @@character_set_client */;
+/*!40101 SET character_set_client = utf8 */;
+CREATE TABLE `activity` (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `user_id` bigint NOT NULL,
+  `article_id` bigint NOT NULL,
+  `type` varchar(32) NOT NULL,
+  `milestone` bigint NOT NULL DEFAULT 0,
+  `created_at` datetime DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_article_type_milestone` (`article_id`, `type`, `milestone`),
+  KEY `idx_user_id` (`user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+/*!40101 SET character_set_client = @saved_cs_client */;
+
 --
 -- Table structure for table `comment`
 --
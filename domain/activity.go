@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// ActivityType is the kind of event recorded in an author's activity feed
+type ActivityType string
+
+const (
+	// ActivityPublished is recorded when an article is created as published or a draft is published
+	ActivityPublished ActivityType = "published"
+	// ActivityLikesMilestone is recorded when the like count of an article crosses a multiple of LikesMilestoneStep
+	ActivityLikesMilestone ActivityType = "likes_milestone"
+	// ActivityViewsMilestone is recorded when the view count of an article crosses a multiple of ViewsMilestoneStep
+	ActivityViewsMilestone ActivityType = "views_milestone"
+)
+
+const (
+	LikesMilestoneStep int64 = 50
+	ViewsMilestoneStep int64 = 1000
+)
+
+// Activity is an append-only event about an article of UserID.
+// Milestone is the like or view count reached, 0 for publish events.
+// Each (ArticleID, Type, Milestone) is recorded at most once.
+type Activity struct {
+	ID           int64
+	UserID       int64
+	ArticleID    int64
+	ArticleTitle string
+	Type         ActivityType
+	Milestone    int64
+	CreatedAt    time.Time
+}
+
+// ActivityRepository reads the activity feed. Events are written by ArticleDBRepository
+// in the same transaction as the change that produced them.
+type ActivityRepository interface {
+	// FetchByUser pages the activity of userID by id desc, skipping events of deleted, hidden and draft articles.
+	// cursor is the ID of the last activity of the previous page, ErrBadParamInput if it is malformed
+	FetchByUser(ctx context.Context, userID int64, cursor string, num int64) ([]Activity, bool, error)
+}
+
+// ActivityUsecase represents the activity feed's usecases
+type ActivityUsecase interface {
+	// FetchByUser lists the activity of userID newest first, the next cursor is empty on the last page
+	FetchByUser(ctx context.Context, userID int64, cursor string, num int64) ([]Activity, string, error)
+}
@@ -0,0 +1,84 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// ModerationAction is an action admins take on articles in bulk
+type ModerationAction string
+
+const (
+	ModerationDelete ModerationAction = "delete"
+	ModerationHide   ModerationAction = "hide"
+	ModerationUnhide ModerationAction = "unhide"
+
+	// MaxBulkModerationSize is the max number of ids in one bulk moderation request
+	MaxBulkModerationSize = 100
+)
+
+const (
+	BulkResultOK       = "ok"
+	BulkResultNotFound = "not_found"
+	BulkResultError    = "error"
+)
+
+// BulkResult is the outcome of a bulk action on a single id
+type BulkResult struct {
+	ID     int64  `json:"id"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// AuditLog records an action taken by an admin
+type AuditLog struct {
+	ID         int64
+	ActorID    int64
+	Action     string
+	TargetType string
+	TargetID   int64
+	Result     string
+	Detail     string // Optional free-form detail, e.g. the new value of a setting
+	CreatedAt  time.Time
+}
+
+// AuditLogRepository defines the contract for audit log persistence
+type AuditLogRepository interface {
+	// BatchStore appends audit logs, it never modifies existing entries
+	BatchStore(ctx context.Context, logs []AuditLog) error
+}
+
+// AdminUsecase defines the business logic contract for admin operations
+type AdminUsecase interface {
+	// BulkModerateArticles applies the action to every article one by one,
+	// a failure on one id does not stop the rest.
+	// Returns ErrBadParamInput if the action is unknown or ids is empty or too long.
+	BulkModerateArticles(ctx context.Context, actorID int64, action ModerationAction, ids []int64) ([]BulkResult, error)
+
+	// GetSettings returns the runtime settings in effect on this instance
+	GetSettings(ctx context.Context) map[string]any
+
+	// UpdateSettings validates and stores the given settings, other keys keep their values.
+	// Changes take effect on every instance within the settings refresh interval.
+	// Returns ErrBadParamInput if a key is unknown or a value has the wrong type or range.
+	UpdateSettings(ctx context.Context, actorID int64, values map[string]any) (map[string]any, error)
+
+	// Overview collects site metrics for dashboards, a failed source only nulls out its own section
+	Overview(ctx context.Context) SiteOverview
+
+	// ReconcileLikes repairs article likes that drifted from user_likes, resuming from the last run if it stopped early.
+	// The summary covers the work done even if an error is returned.
+	ReconcileLikes(ctx context.Context, actorID int64) (LikesReconcileSummary, error)
+
+	// ImportArticles starts importing NDJSON or a JSON array of articles in the background.
+	// Returns ErrBadParamInput if body is empty, ErrConflict if another import is running.
+	ImportArticles(ctx context.Context, actorID int64, body []byte) (ImportJob, error)
+
+	// GetImportJob returns the progress of an import started on this instance.
+	// Returns ErrNotFound if the job is unknown.
+	GetImportJob(ctx context.Context, id string) (ImportJob, error)
+
+	// Traffic returns the daily traffic breakdown of the last days days including today, oldest first.
+	// Returns ErrBadParamInput if days is out of [1, MaxTrafficDays].
+	Traffic(ctx context.Context, days int) ([]TrafficDay, error)
+}
@@ -11,19 +11,112 @@ type Article struct {
 	Title     string    // Article title
 	Content   string    // Article body content
 	User      User      // Author information
-	UpdatedAt time.Time // Last update timestamp
-	CreatedAt time.Time // Creation timestamp
+	UpdatedAt time.Time // Last update timestamp, maintained by the storage layer
+	CreatedAt time.Time // Creation timestamp, immutable after Store
 	Views     int64     // Number of views
 	Likes     int64     // Number of likes
+	Score     float64   // Ranking score, only set for rank results; may be fractional once views are weighted
+	Edited    bool      // Whether title or content changed after publication
+	EditCount int64     // Number of substantial edits
+	Hidden    bool      // Hidden by moderation, invisible to readers
+	Language  string    // Canonical BCP-47 language tag, e.g. "en" or "zh-CN", empty if unknown
+	Tags      []string  // Normalized tags sorted by name, nil on Update keeps the current tags
+
+	CommentsLocked bool // New comments are rejected, existing ones stay readable
+
+	// Status is draft or published, drafts are only visible to their author. Empty is published on Store
+	Status ArticleStatus
+
+	// ContentTruncated is set when Content was cut to fit the cache, the full content must be read from DB
+	ContentTruncated bool
+
+	Summary       string // Short excerpt shown in listings, at most MaxSummaryRunes runes
+	SummaryIsAuto bool   // Summary was generated from Content and follows it on updates
+	Excerpt       string // Plain text excerpt for feeds and meta descriptions, generated from Content on read
+
+	ViewsDisplay string // Humanized Views for listings, e.g. "10.5k", set by the usecase
+
+	Viewer *ViewerState // State of the requesting user, only set for authenticated detail reads
+
+	// DuplicateOf is an earlier article of the same author with the same ContentFingerprint, only set by Store
+	DuplicateOf int64
+
+	// DeletedAt is when the article was soft-deleted, only set by GetDeleted
+	DeletedAt time.Time
+}
+
+// ArticleStatus is the publication state of an article
+type ArticleStatus string
+
+const (
+	// ArticleStatusDraft articles are only visible to their author. They are still in the bloom filter,
+	// which answers whether an article exists, not whether it is visible
+	ArticleStatusDraft ArticleStatus = "draft"
+	// ArticleStatusPublished articles are visible to everyone unless hidden by moderation
+	ArticleStatusPublished ArticleStatus = "published"
+)
+
+// IsValid reports whether s is a known status
+func (s ArticleStatus) IsValid() bool {
+	return s == ArticleStatusDraft || s == ArticleStatusPublished
+}
+
+// ArticleDetail is everything the article detail page shows, assembled in one call
+type ArticleDetail struct {
+	Article    Article    // Tags are never nil, Viewer is set for authenticated requests
+	Engagement Engagement // Likes equals Article.Likes
+}
+
+// ArticleMeta is the public metadata of an article used for link previews, it never carries the content
+type ArticleMeta struct {
+	ID          int64
+	Title       string
+	Summary     string // falls back to the excerpt of the content when the article has no summary
+	AuthorName  string
+	PublishedAt time.Time
 }
 
+// MaxSummaryRunes is the max length of Article.Summary in runes
+const MaxSummaryRunes = 300
+
+// Summarizer generates Article.Summary from the content when the author didn't write one.
+// Results longer than MaxSummaryRunes are truncated by the caller, and on error the built-in
+// first paragraph summary is used instead, so an external summarizer can never block a write
+type Summarizer interface {
+	Summarize(ctx context.Context, content string) (string, error)
+}
+
+// ArticlePage is one page of the article list, HasMore reports whether more articles follow it
+type ArticlePage struct {
+	Articles []Article
+	HasMore  bool
+}
+
+// MaxArticlesByLikesLimit is the max number of articles one likes ranking query may return
+const MaxArticlesByLikesLimit = 500
+
+// MinSearchQueryRunes is the shortest query accepted by article search
+const MinSearchQueryRunes = 2
+
+// MaxSearchQueryRunes is the longest query accepted by article search. A LIKE with a leading
+// wildcard scans every visible article, a long pattern only makes each comparison slower
+const MaxSearchQueryRunes = 64
+
+// MaxFingerprintMatches is the max number of articles FindByFingerprint returns
+const MaxFingerprintMatches = 20
+
 // ArticleRepository defines the contract for article data persistence
 type ArticleRepository interface {
 	// Fetch retrieves a paginated list of articles.
-	// cursor: for pagination, pass the last article ID or empty string for the first page.
+	// cursor: opaque keyset cursor encoding the (created_at, id) of the last article of the previous page,
+	// as built by repository.EncodeCursor; empty string for the first page. It is not an article ID.
+	// Returns ErrBadParamInput if the cursor cannot be decoded.
 	// num: number of articles to fetch per page.
-	// Returns: articles, next cursor for the next page, and error if any.
-	Fetch(ctx context.Context, cursor string, num int64) (res []Article, err error)
+	// lang: only return articles in this language tag, empty for all languages.
+	// tag: only return articles carrying this normalized tag, empty for all articles.
+	// Returns: at most num articles and whether more articles follow this page. The database reads num+1 rows
+	// to decide hasMore, so a last page of exactly num articles reports false.
+	Fetch(ctx context.Context, cursor string, num int64, lang, tag string) (res []Article, hasMore bool, err error)
 
 	// GetByID retrieves a single article by its ID.
 	// Returns ErrNotFound if the article doesn't exist.
@@ -36,6 +129,11 @@ type ArticleRepository interface {
 	// GetByTitle retrieves an article by its title.
 	GetByTitle(ctx context.Context, title string) (Article, error)
 
+	// GetForComment returns an article that accepts comments, drafts included because their links can be shared.
+	// Only ID, User.ID, Status and CommentsLocked are guaranteed to be set.
+	// Returns ErrNotFound if the article doesn't exist or is hidden
+	GetForComment(ctx context.Context, id int64) (Article, error)
+
 	// UpdateViews increments the view count of an article.
 	AddViews(ctx context.Context, id int64, deltaViews int64) error
 
@@ -46,13 +144,23 @@ type ArticleRepository interface {
 	// Store creates a new article in the repository.
 	Store(ctx context.Context, a *Article) error
 
-	// Delete removes an article by its ID.
+	// Delete soft-deletes an article by its ID, its comments and reactions are kept.
 	// Returns ErrNotFount if not exists
 	Delete(ctx context.Context, id int64) error
+	// GetDeleted returns a soft-deleted article with its tags and author, it is never cached.
+	// Returns ErrNotFound if the article doesn't exist or is not deleted
+	GetDeleted(ctx context.Context, id int64) (Article, error)
+	// Restore undoes Delete and drops the cached home page.
+	// Returns ErrNotFound if the article doesn't exist or is not deleted
+	Restore(ctx context.Context, id int64) error
 
 	// AddLikes add the likes of an article by deltaLikes
 	AddLikes(ctx context.Context, id int64, deltaLikes int64) error
 
+	// SetHidden hides or unhides an article.
+	// Returns ErrNotFound if not exists
+	SetHidden(ctx context.Context, id int64, hidden bool) error
+
 	// FetchUserLikedArticles 从 user_likes 表中按 article_id DESC 排序选择 user_id=? 的记录，限制条数
 	FetchUserLikedArticles(ctx context.Context, uid int64, limit int64) ([]int64, error)
 
@@ -61,9 +169,28 @@ type ArticleRepository interface {
 	FetchArticlesByLikes(ctx context.Context, limit int64) ([]Article, error)
 
 	FetchIDs(ctx context.Context, cursor, limit int64) ([]int64, error)
+	SetCommentsLocked(ctx context.Context, id int64, locked bool) error
+	// FetchTags returns every tag used by a visible article with its article count, most used first
+	FetchTags(ctx context.Context) ([]TagCount, error)
+	// GetDraft returns the draft id of userID, it is never cached.
+	// Returns ErrNotFound if there is no such draft, also when it belongs to someone else or is published
+	GetDraft(ctx context.Context, id, userID int64) (Article, error)
+	// FetchDrafts returns the drafts of userID newest first, paged like Search
+	FetchDrafts(ctx context.Context, userID int64, cursor string, num int64) (res []Article, hasMore bool, err error)
+	// Publish makes a draft visible and drops the cached home page.
+	// Returns ErrNotFound if id is not a draft
+	Publish(ctx context.Context, id int64) error
+	// FindByFingerprint returns the articles whose content has the given ContentFingerprint, oldest first.
+	// Only ID and User.ID are set
+	FindByFingerprint(ctx context.Context, fp string) ([]Article, error)
+	// Search returns visible articles whose title or content contains query, newest first.
+	// cursor is the ID of the last article of the previous page, hasMore reports whether more results follow
+	Search(ctx context.Context, query, cursor string, num int64) (res []Article, hasMore bool, err error)
+	// FetchTitles returns up to limit visible articles with id > cursor in id order, only ID and Title are set
+	FetchTitles(ctx context.Context, cursor, limit int64) ([]TitleSuggestion, error)
 
-	// 热榜相关
-	GetDailyRank(ctx context.Context, limit int64) ([]Article, error)
+	// 热榜相关，今日热榜从第 offset 名（从 0 开始）开始返回 limit 篇
+	GetDailyRank(ctx context.Context, offset, limit int64) ([]Article, error)
 	GetHistoryRank(ctx context.Context, limit int64) ([]Article, error)
 }
 
@@ -71,65 +198,204 @@ type ArticleRepository interface {
 type ArticleDBRepository interface {
 	// DB operations only
 	GetByID(ctx context.Context, id int64) (Article, error)
+	// GetByIDs returns the visible articles among ids with only User.ID of the author set.
+	// Fill the users before caching the result, ArticleRepository.GetByIDs does
 	GetByIDs(ctx context.Context, ids []int64) ([]Article, error)
 	GetByTitle(ctx context.Context, title string) (Article, error)
+	// GetForComment reads ID, User.ID, Status and CommentsLocked of a visible article in any status.
+	// Returns ErrNotFound if the article doesn't exist or is hidden
+	GetForComment(ctx context.Context, id int64) (Article, error)
 	Store(ctx context.Context, a *Article) error
-	Update(ctx context.Context, ar *Article) error
+	// FindByFingerprint returns up to MaxFingerprintMatches articles, hidden ones included, whose content
+	// has the given ContentFingerprint in id order. Only ID and User.ID are set
+	FindByFingerprint(ctx context.Context, fp string) ([]Article, error)
+	// Update returns the changed fields keyed by Article field name
+	Update(ctx context.Context, ar *Article) (changed map[string]any, err error)
+	// Delete sets deleted_at, soft-deleted rows are left out of every other query
 	Delete(ctx context.Context, id int64) error
-	Fetch(ctx context.Context, cursor string, num int64) ([]Article, error)
+	// GetDeleted returns a soft-deleted article with its tags and DeletedAt, only User.ID of the author is set.
+	// Returns ErrNotFound if the article doesn't exist or is not deleted
+	GetDeleted(ctx context.Context, id int64) (Article, error)
+	// Restore clears deleted_at. Returns ErrNotFound if the article doesn't exist or is not deleted
+	Restore(ctx context.Context, id int64) error
+	// FetchDeletedBefore returns up to limit IDs of articles soft-deleted before t, in id order
+	FetchDeletedBefore(ctx context.Context, t time.Time, limit int) ([]int64, error)
+	// Purge removes a soft-deleted article for good together with its tag links, likes and activity.
+	// Comments and reactions belong to their own repositories, run all of them in one UnitOfWork.
+	// Returns ErrNotFound if the article doesn't exist or is not deleted
+	Purge(ctx context.Context, id int64) error
+	// Fetch reads num+1 rows to tell whether more articles follow the page, only num are returned
+	Fetch(ctx context.Context, cursor string, num int64, lang, tag string) ([]Article, bool, error)
+	// FetchTags returns every tag used by a visible article with its article count, ties are broken by name
+	FetchTags(ctx context.Context) ([]TagCount, error)
+	GetDraft(ctx context.Context, id, userID int64) (Article, error)
+	// FetchDrafts pages the drafts of userID by id desc, cursor is the ID of the last draft of the previous page.
+	// Returns ErrBadParamInput if cursor is not an article ID
+	FetchDrafts(ctx context.Context, userID int64, cursor string, num int64) ([]Article, bool, error)
+	// Publish sets a draft to published, its publication time becomes its CreatedAt.
+	// Returns ErrNotFound if id is not a draft
+	Publish(ctx context.Context, id int64) error
+	// Search is like Fetch but matches query in title or content and pages by id desc.
+	// Returns ErrBadParamInput if cursor is not an article ID
+	Search(ctx context.Context, query, cursor string, num int64) ([]Article, bool, error)
 	AddViews(ctx context.Context, id int64, deltaViews int64) error
 	AddLikes(ctx context.Context, id int64, deltaLikes int64) error
+	// ReconcileLikes checks up to limit articles with id > afterID in id order,
+	// and sets likes to the number of user_likes rows where they differ
+	ReconcileLikes(ctx context.Context, afterID int64, limit int) (LikesReconcileBatch, error)
+	SetHidden(ctx context.Context, id int64, hidden bool) error
+	SetCommentsLocked(ctx context.Context, id int64, locked bool) error
 	ApplyLikeChanges(ctx context.Context, changes LikeStateChanges) error
 	FetchUserLikedArticles(ctx context.Context, uid int64, limit int64) ([]int64, error)
+	// FetchArticlesByLikes returns visible articles by likes desc, ties are broken by id desc.
+	// Returns ErrBadParamInput if limit is not in 1..MaxArticlesByLikesLimit
 	FetchArticlesByLikes(ctx context.Context, limit int64) ([]Article, error)
+	// FetchIDs pages through the IDs of every non-deleted article, drafts and hidden ones included
 	FetchIDs(ctx context.Context, cursor, limit int64) ([]int64, error)
+	FetchTitles(ctx context.Context, cursor, limit int64) ([]TitleSuggestion, error)
 }
 
 type ArticleCache interface {
 	// Article related - 支持逻辑过期
-	GetHomeWithLogicalExpire(context.Context) ([]Article, bool, error) // 返回数据、是否过期、错误
-	SetHomeWithLogicalExpire(context.Context, []Article, time.Duration) error
+	GetHomeWithLogicalExpire(context.Context) (ArticlePage, bool, error) // 返回数据、是否过期、错误
+	SetHomeWithLogicalExpire(context.Context, ArticlePage, time.Duration) error
+	// DeleteHome 删除首页缓存，下次读取时从数据库重建
+	DeleteHome(ctx context.Context) error
 	GetArticleWithLogicalExpire(ctx context.Context, id int64) (Article, bool, error)
 	GetArticleByIDsWithLogicalExpire(ctx context.Context, ids []int64) ([]Article, error)
-	SetArticleWithLogicalExpire(ctx context.Context, ar *Article, ttl time.Duration) error
-	BatchSetArticleWithLogicalExpire(ctx context.Context, ars []Article, ttl time.Duration) error
+	// GetArticleVersions 返回文章缓存的版本号，PatchArticle、DeleteArticle 和 PurgeArticle 都会增加版本号。
+	// 回源前读取版本号并传给 Set 方法，版本号变化时不写入，局部更新之前读出的旧数据不会覆盖缓存
+	GetArticleVersions(ctx context.Context, ids []int64) (map[int64]int64, error)
+	SetArticleWithLogicalExpire(ctx context.Context, ar *Article, version int64, ttl time.Duration) error
+	BatchSetArticleWithLogicalExpire(ctx context.Context, ars []Article, versions map[int64]int64, ttl time.Duration) error
 
-	// Del delete article, views and likes in cache
+	// DeleteArticle drops the cached article only, it is rebuilt from the database on the next read
 	DeleteArticle(ctx context.Context, id int64) error
+	// PurgeArticle removes everything cached about a deleted article: the article, its like count,
+	// its buffered views and its entries in the daily and history ranks
+	PurgeArticle(ctx context.Context, id int64) error
+
+	// PatchArticle 只改写缓存文章中变化的字段并刷新逻辑过期时间，fields 的 key 为 Article 的字段名。
+	// 缓存中没有该文章时只增加版本号
+	PatchArticle(ctx context.Context, id int64, fields map[string]any) error
 
 	// Views related
 	IncrViews(ctx context.Context, id int64) (views int64, err error)
 	FetchAndResetViews(ctx context.Context) (map[int64]int64, error)
+	// MGetBufferedViews 返回尚未落库的浏览量增量，没有增量的文章不在结果中
+	MGetBufferedViews(ctx context.Context, ids []int64) (map[int64]int64, error)
+	// SumBufferedViews 返回所有文章尚未落库的浏览量之和
+	SumBufferedViews(ctx context.Context) (int64, error)
 
 	// Likes related
 	GetLikeCount(ctx context.Context, articleID int64) (int64, error)
+	// MGetLikeCounts 返回缓存中的点赞数，没有计数的文章不在结果中
 	MGetLikeCounts(ctx context.Context, articleIDs []int64) (map[int64]int64, error)
 	SetLikeCount(ctx context.Context, articleID int64, likes int64) error
+	// InitLikeCount 缓存中没有计数时写入 likes，返回写入后缓存中的点赞数。
+	// 已有的计数包含尚未落库的点赞和取消，不会被数据库中的旧值覆盖
+	InitLikeCount(ctx context.Context, articleID int64, likes int64) (int64, error)
 	MSetLikeCount(ctx context.Context, articleIDs []int64, likes []int64) error
 
 	AddLikeRecord(ctx context.Context, likeRecord UserLike) (bool, error)
 	DecrLikeRecord(ctx context.Context, likeRecord UserLike) (bool, error)
 	IsLiked(ctx context.Context, likeRecord UserLike) (bool, error)
 	IsLikedBatch(ctx context.Context, userID int64, articleIDs []int64) (map[int64]bool, error)
+	// SetUserLikedArticles replaces the cached set of articles liked by the user. An empty list still
+	// marks the set as loaded, so later checks report false instead of a cache miss
 	SetUserLikedArticles(ctx context.Context, UserID int64, articleIDs []int64) error
+	// GetViewerState 一次往返读出用户对文章的点赞状态，点赞集合不存在时 Liked 为 nil
+	GetViewerState(ctx context.Context, userID int64, articleID int64) (ViewerState, error)
 
 	GetDailyRankWithLogicalExpire(ctx context.Context, limit int64) ([]Article, bool, error) // 支持逻辑过期
 	SetDailyRankWithLogicalExpire(ctx context.Context, articles []Article, ttl time.Duration) error
-	GetDailyRank(ctx context.Context, limit int64) ([]Article, error)
+	GetDailyRank(ctx context.Context, offset, limit int64) ([]Article, error)
+	// AggregateDailyRank 重新聚合最近 24 小时的分桶，刷新今日热榜
+	AggregateDailyRank(ctx context.Context) error
 	IncrDailyRankScore(ctx context.Context, aid int64, scoreDelta float64) error
 	GetHistoryRank(ctx context.Context, limit int64) ([]Article, error)
+	// SetHistoryRank 整体替换历史热榜，热榜在一段时间后过期
+	SetHistoryRank(ctx context.Context, articleIDs []int64, scores []float64) error
+	// GetStaleHistoryRank/SetStaleHistoryRank 读写长期保留的历史热榜旧副本，热榜过期重建期间用它兜底
+	GetStaleHistoryRank(ctx context.Context, limit int64) ([]Article, error)
+	SetStaleHistoryRank(ctx context.Context, articleIDs []int64, scores []float64) error
 	SetHistoryRankWithLogicalExpire(ctx context.Context, articleIDs []int64, scores []float64, ttl time.Duration) error
+	// RemoveFromRanks 从今日热榜和历史热榜中移除已经不存在或被隐藏的文章
+	RemoveFromRanks(ctx context.Context, articleIDs []int64) error
+
+	// Traffic related
+	// RecordTraffic 按来源域名和设备类型累加 day 当天 metric 的计数，只保留最近几天
+	RecordTraffic(ctx context.Context, metric TrafficMetric, day time.Time, info ClientInfo) error
+	// GetTraffic 返回 day 当天 metric 的计数，没有记录时返回空的计数
+	GetTraffic(ctx context.Context, metric TrafficMetric, day time.Time) (TrafficCounts, error)
 }
 
 type ArticleUsecase interface {
-	Fetch(ctx context.Context, cursor string, num int64) ([]Article, string, error)
+	// Fetch lists articles page by page, a non-empty lang keeps only articles in that language
+	// and a non-empty tag only articles carrying it. The next cursor is empty when no more articles follow the page.
+	// Returns ErrBadParamInput if lang is not a valid BCP-47 tag
+	Fetch(ctx context.Context, cursor string, num int64, lang, tag string) ([]Article, string, error)
+	// ListTags returns every tag used by a visible article with its article count, most used first
+	ListTags(ctx context.Context) ([]TagCount, error)
 	GetByID(ctx context.Context, id int64) (Article, error)
+	// GetByIDForViewer 与 GetByID 相同，viewerID 大于 0 时额外填充 Article.Viewer
+	GetByIDForViewer(ctx context.Context, id int64, viewerID int64) (Article, error)
+	// GetDeleted returns a soft-deleted article for admins auditing content, without counting a view.
+	// Returns ErrNotFound if the article doesn't exist or is not deleted
+	GetDeleted(ctx context.Context, id int64) (Article, error)
+	// GetDetail returns the article with its tags, like and comment counts, and the viewer's state
+	// when viewerID is greater than 0. Returns ErrNotFound if the article doesn't exist
+	GetDetail(ctx context.Context, id int64, viewerID int64) (ArticleDetail, error)
+	// GetMeta returns the link preview metadata of a published article without counting a view.
+	// Returns ErrNotFound if the article doesn't exist or isn't visible
+	GetMeta(ctx context.Context, id int64) (ArticleMeta, error)
+	// FetchDrafts lists the drafts of userID newest first, the next cursor is empty on the last page
+	FetchDrafts(ctx context.Context, userID int64, cursor string, num int64) ([]Article, string, error)
+	// Publish makes a draft of userID visible to everyone and returns the published article.
+	// Returns ErrNotFound if id is not a draft of userID
+	Publish(ctx context.Context, id, userID int64) (Article, error)
 	Store(ctx context.Context, ar *Article) error
+	// Import stores an article like Store but keeps a non-zero CreatedAt and leaves the bloom filter
+	// to the caller, so bulk imports can add ids in batches. Returns ErrConflict if the title exists.
+	Import(ctx context.Context, ar *Article) error
+	// Update edits an article. When ar.User.ID is set it is the editor, who must be the author or
+	// ErrForbidden is returned, and the fields that cannot be edited are filled from the stored article
 	Update(ctx context.Context, ar *Article) error
-	Delete(ctx context.Context, id int64) error
-	AddLikeRecord(ctx context.Context, likeRecord UserLike) (bool, error)
-	RemoveLikeRecord(ctx context.Context, likeRecord UserLike) (bool, error)
-	FetchDailyRank(ctx context.Context, limit int64) ([]Article, error)
+	// Delete removes an article. A non-zero userID must be the author or ErrForbidden is returned,
+	// zero skips the check for moderation by admins
+	Delete(ctx context.Context, id int64, userID int64) error
+	// Restore brings back a deleted article, userID is checked like Delete.
+	// Returns ErrNotFound if the article doesn't exist or is not deleted
+	Restore(ctx context.Context, id int64, userID int64) error
+	SetHidden(ctx context.Context, id int64, hidden bool) error
+	// SetCommentsLocked opens or closes the discussion on an article.
+	// Only the author and admins may do it, others get ErrForbidden
+	SetCommentsLocked(ctx context.Context, id int64, actor User, locked bool) error
+	// AuthorizeStats returns ErrForbidden unless actor is the author of the article or an admin
+	AuthorizeStats(ctx context.Context, id int64, actor User) error
+	// LiveStats returns the current stats of the given articles from the cache, missing articles are
+	// left out. The database is only read for articles evicted from the cache
+	LiveStats(ctx context.Context, ids []int64) (map[int64]ArticleStats, error)
+	// GetEngagement returns the like and comment counts of every given article, unknown ids get zero counts.
+	// Returns ErrBadParamInput if there are more than MaxEngagementBatch ids
+	GetEngagement(ctx context.Context, ids []int64) (map[int64]Engagement, error)
+	// AddLikeRecord likes the article and returns the like state and count after the request
+	AddLikeRecord(ctx context.Context, likeRecord UserLike) (LikeState, error)
+	// RemoveLikeRecord takes the like back and returns the like state and count after the request
+	RemoveLikeRecord(ctx context.Context, likeRecord UserLike) (LikeState, error)
+	AddReaction(ctx context.Context, r Reaction) (bool, ReactionCounts, error)
+	RemoveReaction(ctx context.Context, r Reaction) (bool, ReactionCounts, error)
+	GetReactionCounts(ctx context.Context, articleID int64) (ReactionCounts, error)
+	FetchDailyRank(ctx context.Context, offset, limit int64) ([]Article, error)
 	FetchHistoryRank(ctx context.Context, limit int64) ([]Article, error)
+	// SuggestTitles returns visible articles whose title starts with query.
+	// Returns ErrBadParamInput if query is shorter than MinSuggestQueryRunes
+	SuggestTitles(ctx context.Context, query string, limit int64) ([]TitleSuggestion, error)
+	// Search returns a page of visible articles whose title or content contains query, newest first,
+	// and the cursor of the next page. Returns ErrBadParamInput if query is shorter than MinSearchQueryRunes
+	// or longer than MaxSearchQueryRunes
+	Search(ctx context.Context, query, cursor string, num int64) ([]Article, string, error)
 	InitBloomFilter(ctx context.Context) error
+	// RebuildTitleIndex rebuilds the title suggestion index from the database
+	RebuildTitleIndex(ctx context.Context) error
 }
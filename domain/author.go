@@ -2,20 +2,55 @@ package domain
 
 import (
 	"context"
+	"strings"
 	"time"
 )
 
+const (
+	// RoleUser is the default role of a registered user
+	RoleUser = "user"
+	// RoleAdmin can access the /admin endpoints
+	RoleAdmin = "admin"
+)
+
 // User represents a user entity in the system.
 // A user can register, login, and perform actions like writing articles.
 type User struct {
 	ID        int64     // Unique identifier
 	Name      string    // Display name
-	Username  string    // Login username (unique)
+	Username  string    // Login username (unique), stored in the NormalizeUsername form
 	Password  string    // Bcrypt hashed password
+	Role      string    // RoleUser or RoleAdmin
 	CreatedAt time.Time // Account creation timestamp
 	UpdatedAt time.Time // Last profile update timestamp
 }
 
+// NormalizeUsername returns the form usernames are stored and looked up in,
+// so usernames that only differ in casing belong to the same account
+func NormalizeUsername(username string) string {
+	return strings.ToLower(strings.TrimSpace(username))
+}
+
+// UsernameCollision is a group of users whose usernames are equal after NormalizeUsername
+type UsernameCollision struct {
+	Username string
+	IDs      []int64
+}
+
+// DeletedUserName is the display name of an author whose account no longer exists
+const DeletedUserName = "[deleted]"
+
+// DeletedUser returns the placeholder rendered in place of a removed author,
+// so articles and comments of deleted accounts still render instead of failing
+func DeletedUser() User {
+	return User{ID: 0, Name: DeletedUserName}
+}
+
+// IsDeleted reports whether u is the DeletedUser placeholder
+func (u User) IsDeleted() bool {
+	return u.ID == 0 && u.Name == DeletedUserName
+}
+
 // UserRepository defines the contract for user data persistence.
 type UserRepository interface {
 	// GetByID retrieves a user by their ID.
@@ -29,18 +64,24 @@ type UserRepository interface {
 	// Update modifies an existing user's information.
 	Update(ctx context.Context, u *User) error
 
-	// GetByUsername retrieves a user by their username.
+	// GetByUsername retrieves a user by their username, compared in the NormalizeUsername form.
 	// Used during login to verify credentials.
+	// Returns ErrNotFound if the user doesn't exist.
 	GetByUsername(ctx context.Context, username string) (User, error)
 
 	GetByIDs(ctx context.Context, userIDs []int64) ([]User, error)
+
+	// List retrieves users ordered by ID, starting after the given cursor (last seen ID).
+	// search filters by username or name prefix, empty means no filter.
+	List(ctx context.Context, cursor string, limit int64, search string) ([]User, error)
 }
 
 // UserUsecase defines the business logic contract for user operations.
 // Handles authentication, registration, and user management.
 type UserUsecase interface {
-	// Register creates a new user account.
-	// Returns ErrConflict if the username already exists.
+	// Register creates a new user account. The username is stored in the NormalizeUsername form,
+	// an empty name defaults to the username as typed.
+	// Returns ErrConflict if the username already exists in any casing.
 	Register(ctx context.Context, name, username, password string) error
 
 	// Login verifies user credentials and returns a JWT token.
@@ -48,6 +89,16 @@ type UserUsecase interface {
 	// Returns ErrBadParamInput if the password is incorrect.
 	Login(ctx context.Context, username, password string) (string, error)
 
-	// EditPassword verifies user credentials and change the password by given new password
+	// EditPassword verifies user credentials and change the password by given new password.
+	// Returns ErrBadParamInput if oldPassword is wrong
 	EditPassword(ctx context.Context, id int64, oldPassword, newPassword string) error
+
+	// List returns a page of users and the cursor of the next page.
+	// The next cursor is empty if there are no more users.
+	List(ctx context.Context, cursor string, limit int64, search string) ([]User, string, error)
+
+	// NormalizeUsernames rewrites the usernames of existing users into the NormalizeUsername form.
+	// If some users only differ in the casing of their username nothing is changed
+	// and the colliding groups are returned instead
+	NormalizeUsernames(ctx context.Context) ([]UsernameCollision, error)
 }
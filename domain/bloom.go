@@ -11,6 +11,9 @@ type BloomRepository interface {
 	// 返回 false: 绝对不存在 (直接返回 404)
 	Exists(ctx context.Context, id int64) (bool, error)
 
+	// ExistsBatch 一次往返检查多个 ID，结果语义同 Exists
+	ExistsBatch(ctx context.Context, ids []int64) (map[int64]bool, error)
+
 	// BulkAdd 用于大量添加 ID
 	BulkAdd(ctx context.Context, ids []int64) error
 }
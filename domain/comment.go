@@ -23,18 +23,26 @@ type Comment struct {
 
 // CommentUsecase 业务逻辑接口
 type CommentUsecase interface {
+	// Create 发表评论。ParentID 不为 0 时是回复，RootID 由父评论决定，ArticleID 为 0 时使用父评论所在的文章；
+	// 父评论不存在或属于其他文章时返回 ErrNotFound
 	Create(ctx context.Context, c *Comment) error
-	Delete(ctx context.Context, articleID int64, userID int64) error
+	// Delete 删除一条评论，只有评论作者可以删除：评论不存在时返回 ErrNotFound，不是作者时返回 ErrForbidden
+	Delete(ctx context.Context, commentID int64, userID int64) error
 	FetchByArticle(ctx context.Context, articleID int64, cursor string, limit int64) ([]*Comment, string, error)
 }
 
 // CommentRepository 数据存取接口
 type CommentRepository interface {
 	Store(ctx context.Context, c *Comment) error
-	Delete(ctx context.Context, articleID int64, userID int64) error
+	// Delete 删除 id 为 commentID 且作者为 userID 的评论，不存在时返回 ErrNotFound，作者不是 userID 时返回 ErrForbidden
+	Delete(ctx context.Context, commentID int64, userID int64) error
 	GetByID(ctx context.Context, id int64) (*Comment, error)
 	// FetchRoots 获取一级评论
 	FetchRoots(ctx context.Context, articleID int64, cursor string, limit int64) ([]*Comment, error)
 	// FetchReplies 获取指定根评论ID列表的所有子回复
 	FetchReplies(ctx context.Context, rootIDs []int64) ([]*Comment, error)
+	// CountByArticles 统计每篇文章的评论数（含回复），没有评论的文章不在结果中
+	CountByArticles(ctx context.Context, articleIDs []int64) (map[int64]int64, error)
+	// DeleteByArticle 删除文章下的全部评论和回复，彻底清除已删除的文章时与 ArticleDBRepository.Purge 在同一个工作单元中调用
+	DeleteByArticle(ctx context.Context, articleID int64) error
 }
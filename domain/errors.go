@@ -1,6 +1,9 @@
 package domain
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 var (
 	// ErrInternalServerError will throw if any the Internal Server Error happen
@@ -11,8 +14,8 @@ var (
 	ErrConflict = errors.New("your Item already exist")
 	// ErrBadParamInput will throw if the given request-body or params is not valid
 	ErrBadParamInput = errors.New("given Param is not valid")
-	// ErrUnauthorized will throw if the user is unauthorized to access the resource
-	ErrUserAlreadyExists = errors.New("user with given username already exists")
+	// ErrUserAlreadyExists will throw if the username is taken, it is also an ErrConflict
+	ErrUserAlreadyExists = fmt.Errorf("user with given username already exists: %w", ErrConflict)
 	// ErrUnauthorized will throw if the user is unauthorized to access the resource
 	ErrUnauthorized = errors.New("you are unauthorized to access this resource")
 	// ErrUserNotFound will throw if the requested user is not exists
@@ -23,4 +26,24 @@ var (
 	ErrCacheMiss = errors.New("cache miss")
 	// ErrForbidden will throw if the user is forbidden to access the resource
 	ErrForbidden = errors.New("you are forbidden to access this resource")
+	// ErrTooManyRequests will throw if the user exceeds the rate limit of an action
+	ErrTooManyRequests = errors.New("too many requests, please try again later")
+	// ErrCacheUnavailable will throw if the cache is down, it is also an ErrCacheMiss so reads fall back to the primary datastore
+	ErrCacheUnavailable = fmt.Errorf("cache is unavailable: %w", ErrCacheMiss)
+	// ErrCommentsLocked will throw when commenting on an article whose author closed the discussion
+	ErrCommentsLocked = fmt.Errorf("comments are locked on this article: %w", ErrForbidden)
 )
+
+// ConflictError is ErrConflict caused by an existing article, errors.Is(err, ErrConflict) holds for it
+type ConflictError struct {
+	ExistingID       int64 // ID of the article that already exists
+	DuplicateContent bool  // The existing article has the same content, otherwise the same title
+}
+
+func (e *ConflictError) Error() string {
+	return ErrConflict.Error()
+}
+
+func (e *ConflictError) Unwrap() error {
+	return ErrConflict
+}
@@ -0,0 +1,25 @@
+package domain
+
+// MaxExcerptRunes is the max length of Article.Excerpt in runes, about the length of a meta description
+const MaxExcerptRunes = 160
+
+// ExcerptStrategy decides how Article.Excerpt is cut from the plain text of the content
+type ExcerptStrategy string
+
+const (
+	// ExcerptFixedLength takes the first MaxExcerptRunes runes
+	ExcerptFixedLength ExcerptStrategy = "fixed"
+	// ExcerptFirstParagraph takes the first paragraph with text
+	ExcerptFirstParagraph ExcerptStrategy = "paragraph"
+	// ExcerptFirstSentence takes the first sentence
+	ExcerptFirstSentence ExcerptStrategy = "sentence"
+)
+
+func (s ExcerptStrategy) IsValid() bool {
+	switch s {
+	case ExcerptFixedLength, ExcerptFirstParagraph, ExcerptFirstSentence:
+		return true
+	default:
+		return false
+	}
+}
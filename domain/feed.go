@@ -0,0 +1,29 @@
+package domain
+
+import "context"
+
+// FeedSource tells where the home feed of a request was served from
+type FeedSource string
+
+const (
+	FeedSourceCache   FeedSource = "cache"   // fresh home cache
+	FeedSourceDB      FeedSource = "db"      // cache miss, built from DB
+	FeedSourceRebuild FeedSource = "rebuild" // logically expired cache, a rebuild was triggered
+)
+
+type feedSourceKey struct{}
+
+// WithFeedSourceRecorder returns a context in which ArticleRepository.Fetch records
+// the source of the home feed. The recorded value is read through the returned pointer
+// and stays empty if the feed was not fetched.
+func WithFeedSourceRecorder(ctx context.Context) (context.Context, *FeedSource) {
+	src := new(FeedSource)
+	return context.WithValue(ctx, feedSourceKey{}, src), src
+}
+
+// RecordFeedSource records src if ctx carries a recorder
+func RecordFeedSource(ctx context.Context, src FeedSource) {
+	if p, ok := ctx.Value(feedSourceKey{}).(*FeedSource); ok {
+		*p = src
+	}
+}
@@ -0,0 +1,41 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ContentFingerprint returns the hex SHA-256 of content after lowercasing it and collapsing every run of
+// whitespace into one space, so reposts that only differ in case or spacing share a fingerprint.
+// Returns an empty string for blank content. It is a single pass over content and cheap enough to run inline
+func ContentFingerprint(content string) string {
+	var b strings.Builder
+	b.Grow(len(content))
+	space := false
+	for _, r := range content {
+		if unicode.IsSpace(r) {
+			space = b.Len() > 0
+			continue
+		}
+		if space {
+			b.WriteByte(' ')
+			space = false
+		}
+		if r < utf8.RuneSelf {
+			if 'A' <= r && r <= 'Z' {
+				r += 'a' - 'A'
+			}
+			b.WriteByte(byte(r))
+			continue
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	if b.Len() == 0 {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(b.String()))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,18 @@
+package domain
+
+import "time"
+
+// Circuit breaker states
+const (
+	BreakerClosed = "closed" // requests go through
+	BreakerOpen   = "open"   // requests fail fast with ErrCacheUnavailable until a probe succeeds
+)
+
+// BreakerState is a snapshot of a circuit breaker for health checks and metrics
+type BreakerState struct {
+	State               string     `json:"state"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	OpenedAt            *time.Time `json:"opened_at,omitempty"`
+	Trips               int64      `json:"trips"`    // Times the breaker opened since start
+	Rejected            int64      `json:"rejected"` // Commands failed fast while open
+}
@@ -0,0 +1,57 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	// MaxImportBytes is the max size of one article import request body
+	MaxImportBytes = 32 << 20
+
+	// BulkResultDuplicate means an imported record was skipped because an article with the same title exists
+	BulkResultDuplicate = "duplicate"
+)
+
+// ImportStatus is the state of an article import job
+type ImportStatus string
+
+const (
+	ImportRunning ImportStatus = "running"
+	ImportDone    ImportStatus = "done"
+	// ImportFailed means the body could not be read to the end, records before the error were still imported
+	ImportFailed ImportStatus = "failed"
+)
+
+// ImportRecordResult reports a record that was not imported
+type ImportRecordResult struct {
+	Index  int    `json:"index"` // 0-based position of the record in the body
+	Title  string `json:"title,omitempty"`
+	Status string `json:"status"` // BulkResultDuplicate or BulkResultError
+	Error  string `json:"error,omitempty"`
+}
+
+// ImportJob is the progress of an article import
+type ImportJob struct {
+	ID         string               `json:"id"`
+	Status     ImportStatus         `json:"status"`
+	Read       int                  `json:"read"` // Records read from the body so far
+	Imported   int                  `json:"imported"`
+	Duplicates int                  `json:"duplicates"`
+	Failed     int                  `json:"failed"`
+	Error      string               `json:"error,omitempty"`   // Why the body could not be read to the end
+	Records    []ImportRecordResult `json:"records,omitempty"` // Skipped and failed records ordered by Index
+	StartedAt  time.Time            `json:"started_at"`
+	FinishedAt *time.Time           `json:"finished_at,omitempty"`
+}
+
+// ArticleImporter imports articles from NDJSON or a JSON array in the background
+type ArticleImporter interface {
+	// Start begins importing body and returns the new job immediately.
+	// Returns ErrConflict if another import is still running on this instance.
+	Start(ctx context.Context, body []byte) (ImportJob, error)
+
+	// Get returns a snapshot of the job.
+	// Returns ErrNotFound if the job is unknown or has been forgotten.
+	Get(ctx context.Context, id string) (ImportJob, error)
+}
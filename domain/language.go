@@ -0,0 +1,19 @@
+package domain
+
+import "golang.org/x/text/language"
+
+// MaxLanguageLength is the longest language tag stored with an article
+const MaxLanguageLength = 35
+
+// NormalizeLanguage validates a BCP-47 language tag and returns its canonical form, e.g. "zh-cn" becomes "zh-CN".
+// ok is false for malformed tags and for "und"
+func NormalizeLanguage(tag string) (canonical string, ok bool) {
+	if tag == "" || len(tag) > MaxLanguageLength {
+		return "", false
+	}
+	t, err := language.Parse(tag)
+	if err != nil || t == language.Und {
+		return "", false
+	}
+	return t.String(), true
+}
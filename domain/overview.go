@@ -0,0 +1,47 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Site stats names, each is counted and cached separately
+const (
+	StatsArticles = "articles"
+	StatsUsers    = "users"
+	StatsComments = "comments"
+)
+
+// SiteCounts is the total number of some records and how many were created today
+type SiteCounts struct {
+	Total int64 `json:"total"`
+	Today int64 `json:"today"`
+}
+
+// SiteOverview is a snapshot of site metrics for dashboards.
+// A section is nil if its source failed, the failure is described in Errors.
+type SiteOverview struct {
+	Articles           *SiteCounts `json:"articles"`
+	Users              *SiteCounts `json:"users"`
+	Comments           *SiteCounts `json:"comments"`
+	TrendingArticleIDs []int64     `json:"trending_article_ids"`
+	LikeQueueDepth     *int64      `json:"like_queue_depth"`
+	BufferedViews      *int64      `json:"buffered_views"`
+	// BackgroundQueueDepth and BackgroundTasksDropped describe the background runner of cache writes
+	BackgroundQueueDepth   *int64    `json:"background_queue_depth"`
+	BackgroundTasksDropped *int64    `json:"background_tasks_dropped"`
+	Errors                 []string  `json:"errors"`
+	GeneratedAt            time.Time `json:"generated_at"`
+}
+
+// SiteStatsRepository counts records of the named stats (StatsArticles etc.),
+// Today counts the records created at or after since.
+type SiteStatsRepository interface {
+	Count(ctx context.Context, name string, since time.Time) (SiteCounts, error)
+}
+
+// SiteStatsCache caches SiteCounts by key, returns ErrCacheMiss if absent
+type SiteStatsCache interface {
+	GetCounts(ctx context.Context, key string) (SiteCounts, error)
+	SetCounts(ctx context.Context, key string, counts SiteCounts, ttl time.Duration) error
+}
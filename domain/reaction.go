@@ -0,0 +1,66 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// ReactionType is the kind of reaction a user leaves on an article
+type ReactionType string
+
+const (
+	ReactionLike ReactionType = "like"
+	ReactionLove ReactionType = "love"
+	ReactionWow  ReactionType = "wow"
+)
+
+// ReactionTypes lists every supported reaction type, in display order
+var ReactionTypes = []ReactionType{ReactionLike, ReactionLove, ReactionWow}
+
+// IsValid reports whether t is a supported reaction type
+func (t ReactionType) IsValid() bool {
+	for _, rt := range ReactionTypes {
+		if t == rt {
+			return true
+		}
+	}
+	return false
+}
+
+// Reaction is representing a reaction record
+type Reaction struct {
+	ArticleID int64
+	UserID    int64
+	Type      ReactionType
+	CreatedAt time.Time
+}
+
+// ReactionCounts maps each reaction type to its count on an article
+type ReactionCounts map[ReactionType]int64
+
+// ReactionRepository defines the contract for reaction persistence.
+// The like reaction keeps living in user_likes and is handled by ArticleRepository.
+type ReactionRepository interface {
+	// Add stores a reaction, returns false if it already exists
+	Add(ctx context.Context, r Reaction) (bool, error)
+
+	// Remove deletes a reaction, returns false if it does not exist
+	Remove(ctx context.Context, r Reaction) (bool, error)
+
+	// CountByArticle counts reactions of every type on an article, including likes
+	CountByArticle(ctx context.Context, articleID int64) (ReactionCounts, error)
+
+	// DeleteByArticle removes every reaction on an article, called with ArticleDBRepository.Purge in one UnitOfWork
+	DeleteByArticle(ctx context.Context, articleID int64) error
+}
+
+// ReactionCache caches per-type reaction counts of articles.
+// The like count is cached by ArticleCache and is not part of it.
+type ReactionCache interface {
+	// GetReactionCounts returns ErrCacheMiss if the counts of any type are not cached
+	GetReactionCounts(ctx context.Context, articleID int64) (ReactionCounts, error)
+	SetReactionCounts(ctx context.Context, articleID int64, counts ReactionCounts) error
+
+	// IncrReactionCount changes the cached count by delta, only if it is cached
+	IncrReactionCount(ctx context.Context, articleID int64, t ReactionType, delta int64) error
+}
@@ -0,0 +1,31 @@
+package domain
+
+import "context"
+
+// Runtime setting keys, the values are shared by all instances and can be changed by admins without restart
+const (
+	SettingBloomEnabled        = "bloom_enabled"
+	SettingViewCountingEnabled = "view_counting_enabled"
+	SettingRankViewWeight      = "rank_view_weight"
+)
+
+// Settings exposes runtime-tunable knobs to the components consuming them.
+// Values may change at any time, read them on every use instead of keeping a copy.
+type Settings interface {
+	// BloomEnabled reports whether existence checks consult the bloom filter
+	BloomEnabled() bool
+
+	// ViewCountingEnabled reports whether article views are counted
+	ViewCountingEnabled() bool
+
+	// RankViewWeight is the daily rank score added by each article view
+	RankViewWeight() float64
+}
+
+// SettingsRepository stores raw setting values shared by all instances
+type SettingsRepository interface {
+	GetAll(ctx context.Context) (map[string]string, error)
+
+	// SetMany stores the given values, other keys keep their values
+	SetMany(ctx context.Context, values map[string]string) error
+}
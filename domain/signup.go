@@ -0,0 +1,36 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	// UsernameCheckLimit is how many usernames a client may look up per UsernameCheckWindow,
+	// low enough that harvesting registered usernames one by one is impractical
+	UsernameCheckLimit  = 10
+	UsernameCheckWindow = time.Minute
+)
+
+// Registration is the result of a register request that carried a client token,
+// a retried submit with the same token gets it back instead of a conflict
+type Registration struct {
+	UserID   int64  `json:"user_id"`
+	Username string `json:"username"`
+}
+
+// SignupCache keeps the short lived state of the signup form in Redis
+type SignupCache interface {
+	// ClaimRegistration reserves token for ttl. Returns false if the token is already claimed
+	ClaimRegistration(ctx context.Context, token string, ttl time.Duration) (bool, error)
+	// SaveRegistration stores the result of a claimed token for ttl
+	SaveRegistration(ctx context.Context, token string, res Registration, ttl time.Duration) error
+	// GetRegistration returns the stored result of token.
+	// Returns ErrCacheMiss while the first submit is still running, or when the token is unknown or expired
+	GetRegistration(ctx context.Context, token string) (Registration, error)
+	// ReleaseRegistration drops the claim of a failed registration so a retry registers again
+	ReleaseRegistration(ctx context.Context, token string) error
+	// AllowUsernameCheck counts one username lookup of client in the current window of the given length.
+	// Returns false once client has made more than limit lookups in the window
+	AllowUsernameCheck(ctx context.Context, client string, limit int64, window time.Duration) (bool, error)
+}
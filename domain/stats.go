@@ -0,0 +1,17 @@
+package domain
+
+// ArticleStats is a live snapshot of the counters of an article, pushed to the author dashboard.
+// Views include the increments still buffered in the cache
+type ArticleStats struct {
+	Views int64 `json:"views"`
+	Likes int64 `json:"likes"`
+}
+
+// Engagement is the like and comment count of an article, comments include replies
+type Engagement struct {
+	Likes    int64 `json:"likes"`
+	Comments int64 `json:"comments"`
+}
+
+// MaxEngagementBatch is the max number of articles in one GetEngagement call
+const MaxEngagementBatch = 100
@@ -0,0 +1,25 @@
+package domain
+
+import "context"
+
+// MinSuggestQueryRunes is the shortest query accepted by title suggestions
+const MinSuggestQueryRunes = 2
+
+// TitleSuggestion is an article title matching a search-as-you-type query
+type TitleSuggestion struct {
+	ID    int64
+	Title string
+}
+
+// TitleIndex is a prefix index over the titles of visible articles
+type TitleIndex interface {
+	// Set adds the article to the index, replacing its previous title
+	Set(ctx context.Context, id int64, title string) error
+	// Rename replaces the title of an indexed article, articles not in the index stay out of it
+	Rename(ctx context.Context, id int64, title string) error
+	Remove(ctx context.Context, id int64) error
+	// Suggest returns up to limit titles starting with prefix, ignoring case, in lexical order
+	Suggest(ctx context.Context, prefix string, limit int64) ([]TitleSuggestion, error)
+	// Replace rebuilds the whole index from titles
+	Replace(ctx context.Context, titles []TitleSuggestion) error
+}
@@ -0,0 +1,80 @@
+package domain
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// MaxTagsPerArticle is the max number of tags on one article
+	MaxTagsPerArticle = 10
+	// MaxTagRunes is the max length of a tag in runes
+	MaxTagRunes = 32
+)
+
+const (
+	// DefaultTrendingTagsWindow is how far back trending tags are counted when no window is given
+	DefaultTrendingTagsWindow = 7 * 24 * time.Hour
+	// MaxTrendingTagsWindow is the longest window trending tags can be counted over
+	MaxTrendingTagsWindow = 30 * 24 * time.Hour
+)
+
+// TagCount is a tag and the number of visible articles carrying it
+type TagCount struct {
+	Name     string
+	Articles int64
+}
+
+// TagRepository counts how tags are used over time
+type TagRepository interface {
+	// Trending counts, for each tag, the visible articles it was added to at or after since,
+	// most used first and ties broken by name. At most limit tags are returned
+	Trending(ctx context.Context, since time.Time, limit int64) ([]TagCount, error)
+}
+
+// TagCache caches trending tags by key, returns ErrCacheMiss if absent
+type TagCache interface {
+	GetTrending(ctx context.Context, key string) ([]TagCount, error)
+	SetTrending(ctx context.Context, key string, tags []TagCount, ttl time.Duration) error
+}
+
+// TagUsecase represents the tags' usecases
+type TagUsecase interface {
+	// Trending returns the tags most added to visible articles within the window ending now.
+	// Returns ErrBadParamInput if window is not positive or longer than MaxTrendingTagsWindow
+	Trending(ctx context.Context, window time.Duration, limit int64) ([]TagCount, error)
+}
+
+// NormalizeTag trims and lowercases a tag, tags are compared in this form
+func NormalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimSpace(tag))
+}
+
+// NormalizeTags normalizes every tag, drops empty ones and duplicates and sorts the rest by name.
+// A nil slice stays nil so updates can tell "keep the tags" from "remove all tags".
+// Returns ErrBadParamInput if more than MaxTagsPerArticle tags remain or a tag is longer than MaxTagRunes
+func NormalizeTags(tags []string) ([]string, error) {
+	if tags == nil {
+		return nil, nil
+	}
+	res := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = NormalizeTag(tag)
+		if tag == "" {
+			continue
+		}
+		if utf8.RuneCountInString(tag) > MaxTagRunes {
+			return nil, ErrBadParamInput
+		}
+		res = append(res, tag)
+	}
+	slices.Sort(res)
+	res = slices.Compact(res)
+	if len(res) > MaxTagsPerArticle {
+		return nil, ErrBadParamInput
+	}
+	return res, nil
+}
@@ -0,0 +1,118 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// TrafficMetric is the kind of event counted in the daily traffic stats
+type TrafficMetric string
+
+const (
+	TrafficViews TrafficMetric = "views"
+	TrafficLikes TrafficMetric = "likes"
+)
+
+// TrafficMetrics lists every metric of the daily traffic stats
+var TrafficMetrics = []TrafficMetric{TrafficViews, TrafficLikes}
+
+// DeviceClass is a coarse device class parsed from the User-Agent
+type DeviceClass string
+
+const (
+	DeviceDesktop DeviceClass = "desktop"
+	DeviceMobile  DeviceClass = "mobile"
+	DeviceTablet  DeviceClass = "tablet"
+	DeviceBot     DeviceClass = "bot"
+	DeviceOther   DeviceClass = "other"
+)
+
+// Referrer buckets that are not a domain
+const (
+	ReferrerDirect   = "direct"   // no or unparsable Referer
+	ReferrerInternal = "internal" // Referer on the site itself
+)
+
+const (
+	// DefaultTrafficDays and MaxTrafficDays bound how many recent days the traffic report covers
+	DefaultTrafficDays = 14
+	MaxTrafficDays     = 90
+	// TrafficCacheDays is how many recent days, including today, are read from the cache
+	// instead of the database, the older days are flushed nightly
+	TrafficCacheDays = 2
+	// TrafficDateLayout is the format of TrafficDay.Date
+	TrafficDateLayout = "2006-01-02"
+)
+
+// ClientInfo is the client metadata recorded in the traffic stats
+type ClientInfo struct {
+	Referrer string // referrer domain, ReferrerDirect or ReferrerInternal
+	Device   DeviceClass
+}
+
+type clientInfoKey struct{}
+
+// WithClientInfo returns a context carrying info, the view and like paths record traffic from it
+func WithClientInfo(ctx context.Context, info ClientInfo) context.Context {
+	return context.WithValue(ctx, clientInfoKey{}, info)
+}
+
+// ClientInfoFrom returns the client info of ctx, ok is false for requests that should not be counted
+func ClientInfoFrom(ctx context.Context) (info ClientInfo, ok bool) {
+	info, ok = ctx.Value(clientInfoKey{}).(ClientInfo)
+	return info, ok
+}
+
+// TrafficCounts counts the events of one metric on one day by referrer domain and by device class
+type TrafficCounts struct {
+	Referrers map[string]int64 `json:"referrers"`
+	Devices   map[string]int64 `json:"devices"`
+}
+
+// NewTrafficCounts returns empty counts, the maps are non-nil so they encode as {}
+func NewTrafficCounts() TrafficCounts {
+	return TrafficCounts{
+		Referrers: map[string]int64{},
+		Devices:   map[string]int64{},
+	}
+}
+
+// IsEmpty reports whether nothing was counted
+func (c TrafficCounts) IsEmpty() bool {
+	return len(c.Referrers) == 0 && len(c.Devices) == 0
+}
+
+// TrafficDay is the traffic breakdown of one day
+type TrafficDay struct {
+	Date  string        `json:"date"`
+	Views TrafficCounts `json:"views"`
+	Likes TrafficCounts `json:"likes"`
+}
+
+// NewTrafficDay returns an empty breakdown of the day of t
+func NewTrafficDay(t time.Time) TrafficDay {
+	return TrafficDay{
+		Date:  t.Format(TrafficDateLayout),
+		Views: NewTrafficCounts(),
+		Likes: NewTrafficCounts(),
+	}
+}
+
+// Counts returns the counts of metric m, nil for unknown metrics
+func (d *TrafficDay) Counts(m TrafficMetric) *TrafficCounts {
+	switch m {
+	case TrafficViews:
+		return &d.Views
+	case TrafficLikes:
+		return &d.Likes
+	}
+	return nil
+}
+
+// TrafficRepository stores the daily traffic stats flushed from the cache
+type TrafficRepository interface {
+	// StoreTraffic overwrites the counts of metric on the day of day, storing the same day again is harmless
+	StoreTraffic(ctx context.Context, day time.Time, metric TrafficMetric, counts TrafficCounts) error
+	// FetchTraffic returns the stored days in [from, to], days without any record are absent
+	FetchTraffic(ctx context.Context, from, to time.Time) ([]TrafficDay, error)
+}
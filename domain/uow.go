@@ -0,0 +1,17 @@
+package domain
+
+import "context"
+
+// Repos 一次工作单元中可用的仓储，它们共享同一个事务
+type Repos struct {
+	Articles  ArticleDBRepository
+	Comments  CommentRepository
+	Reactions ReactionRepository
+	Users     UserRepository
+}
+
+// UnitOfWork 把跨多个仓储的写入放在同一个事务中。
+// fn 返回错误时整个事务回滚，任何一个仓储的写入都不会留下
+type UnitOfWork interface {
+	Do(ctx context.Context, fn func(repos Repos) error) error
+}
@@ -1,6 +1,9 @@
 package domain
 
-import "time"
+import (
+	"context"
+	"time"
+)
 
 const (
 	// 默认每个用户只加载最近发布的300篇文章的点赞
@@ -14,7 +17,49 @@ type UserLike struct {
 	CreatedAt time.Time
 }
 
+// LikeState is the result of a like or unlike request
+type LikeState struct {
+	Changed bool  // false if the user had already liked, or had not liked, the article
+	Liked   bool  // whether the user likes the article after the request
+	Likes   int64 // like count of the article after the request, including likes not yet synced to the database
+}
+
 type LikeStateChanges struct {
 	ToAdd    []UserLike
 	ToRemove []UserLike
 }
+
+// LikeDrift is an article whose stored likes differed from the number of its user_likes rows
+type LikeDrift struct {
+	ArticleID int64
+	Stored    int64
+	Actual    int64
+}
+
+// LikesReconcileBatch is the result of reconciling one page of articles
+type LikesReconcileBatch struct {
+	Checked  int
+	LastID   int64 // ID of the last checked article, the cursor of the next page
+	Repaired []LikeDrift
+}
+
+// LikesReconcileSummary reports a reconciliation run
+type LikesReconcileSummary struct {
+	Checked      int64 `json:"checked"`
+	Repaired     int64 `json:"repaired"`
+	LargestDrift int64 `json:"largest_drift"` // largest |stored - actual| seen in this run
+	Done         bool  `json:"done"`          // false if the run stopped early, the next run resumes from Cursor
+	Cursor       int64 `json:"cursor"`
+}
+
+// LikesReconciler repairs drift between article likes and user_likes
+type LikesReconciler interface {
+	Run(ctx context.Context) (LikesReconcileSummary, error)
+}
+
+// CursorStore persists the progress of resumable jobs by name
+type CursorStore interface {
+	// GetCursor returns 0 if the job has no saved progress
+	GetCursor(ctx context.Context, name string) (int64, error)
+	SetCursor(ctx context.Context, name string, cursor int64) error
+}
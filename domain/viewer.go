@@ -0,0 +1,7 @@
+package domain
+
+// ViewerState is the relation between the current user and an article.
+// A nil field means the state is unknown because its cache structure does not exist
+type ViewerState struct {
+	Liked *bool
+}
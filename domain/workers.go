@@ -1,6 +1,9 @@
 package domain
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 type LikeAction int8
 
@@ -25,4 +28,53 @@ type SyncLikesWorker interface {
 
 	// Send adds a like record if action == Like, and removes a like record if action == Unlike
 	Send(likeRecord UserLike, action LikeAction)
+
+	// QueueDepth is the number of like tasks waiting to be written to DB
+	QueueDepth() int
+}
+
+// BackgroundRunner runs best-effort background tasks, such as cache writes, with bounded concurrency
+type BackgroundRunner interface {
+	// Submit queues the task, it returns false if the task is dropped because the queue is full or the runner is closed.
+	// The ctx passed to the task is canceled when shutdown gives up waiting
+	Submit(task func(ctx context.Context)) bool
+
+	// QueueDepth is the number of tasks waiting to run
+	QueueDepth() int
+
+	// Dropped is the number of tasks dropped since start
+	Dropped() int64
+}
+
+// Names of the workers that record a WorkerStatus
+const (
+	WorkerSyncViews    = "sync_views"
+	WorkerSyncLikes    = "sync_likes"
+	WorkerFlushTraffic = "flush_traffic"
+)
+
+// WorkerStaleFactor is how many intervals a worker may go without a successful flush before it is reported stale
+const WorkerStaleFactor = 3
+
+// WorkerStatus is the bookkeeping record a worker writes after every successful flush
+type WorkerStatus struct {
+	Name       string    `json:"name"`
+	LastRun    time.Time `json:"last_run"`
+	Items      int64     `json:"items"`       // Items written by the last flush
+	DurationMs int64     `json:"duration_ms"` // How long the last flush took
+	Errors     int64     `json:"errors"`      // Failed flushes and items since the worker started
+	IntervalMs int64     `json:"interval_ms"` // How often the worker is expected to flush
+}
+
+// Stale reports whether the worker has not flushed within WorkerStaleFactor intervals of now
+func (s WorkerStatus) Stale(now time.Time) bool {
+	interval := time.Duration(s.IntervalMs) * time.Millisecond
+	return interval > 0 && now.Sub(s.LastRun) > WorkerStaleFactor*interval
+}
+
+// WorkerStatusRepository stores the last WorkerStatus of each worker, GetWorkerStatus returns ErrCacheMiss
+// if the worker has never recorded one
+type WorkerStatusRepository interface {
+	SetWorkerStatus(ctx context.Context, status WorkerStatus) error
+	GetWorkerStatus(ctx context.Context, name string) (WorkerStatus, error)
 }
@@ -3,7 +3,8 @@ package repository
 import (
 	"context"
 	"errors"
-	"sync"
+	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
@@ -11,79 +12,285 @@ import (
 	"golang.org/x/sync/singleflight"
 )
 
+// historyRankSize 历史热榜缓存的文章数，不小于接口允许的最大 limit
+const historyRankSize = 100
+
 // articleRepository 协调层，协调缓存和数据库
 type articleRepository struct {
-	db            domain.ArticleDBRepository
-	cache         domain.ArticleCache
-	userRepo      domain.UserRepository
-	rebuildGroup  singleflight.Group
-	rankGroup     singleflight.Group
-	mu            sync.Mutex
-	rebuildingMap map[int64]bool // 正在重建的文章ID
+	db       domain.ArticleDBRepository
+	cache    domain.ArticleCache
+	userRepo domain.UserRepository
+	settings domain.Settings
+	// rebuildGroup 合并同一篇文章（以及首页）的并发回源，回源中的请求直接共享结果
+	rebuildGroup Deduper
+	rankGroup    Deduper
+	// syncCacheWrites 为 true 时缓存写入在当前请求中完成，否则提交给 background 执行
+	syncCacheWrites bool
+	background      domain.BackgroundRunner
+
+	// Timeouts 读操作的超时，默认不限制
+	Timeouts ReadTimeouts
+}
+
+// ReadTimeouts 单次读取缓存和数据库的超时，叠加在请求自身的超时之下，为 0 时不额外限制。
+// 缓存读取超时按未命中处理，转而读取数据库，一次慢的 Redis 调用不会耗尽整个请求的时间
+type ReadTimeouts struct {
+	Cache time.Duration
+	DB    time.Duration
+}
+
+// Deduper 合并相同 key 的并发调用，*singleflight.Group 实现了该接口
+type Deduper interface {
+	Do(key string, fn func() (any, error)) (v any, err error, shared bool)
 }
 
 var _ domain.ArticleRepository = (*articleRepository)(nil)
 
-// NewArticleRepository 创建协调层repository
-func NewArticleRepository(db domain.ArticleDBRepository, cache domain.ArticleCache, userRepo domain.UserRepository) *articleRepository {
+// NewArticleRepository 创建协调层repository。
+// syncCacheWrites 为 true 时缓存写入同步执行，写入后立即可读，适合测试和低流量部署；
+// 逻辑过期后的重建总是交给 background，background 为 nil 时才在当前调用中重建
+func NewArticleRepository(
+	db domain.ArticleDBRepository,
+	cache domain.ArticleCache,
+	userRepo domain.UserRepository,
+	settings domain.Settings,
+	syncCacheWrites bool,
+	background domain.BackgroundRunner,
+) *articleRepository {
+	return NewArticleRepositoryWithDedupers(db, cache, userRepo, settings, syncCacheWrites, background, &singleflight.Group{}, &singleflight.Group{})
+}
+
+// NewArticleRepositoryWithDedupers 与 NewArticleRepository 相同，但回源和热榜构建的去重由调用方提供，便于测试
+func NewArticleRepositoryWithDedupers(
+	db domain.ArticleDBRepository,
+	cache domain.ArticleCache,
+	userRepo domain.UserRepository,
+	settings domain.Settings,
+	syncCacheWrites bool,
+	background domain.BackgroundRunner,
+	rebuild, rank Deduper,
+) *articleRepository {
 	return &articleRepository{
-		db:            db,
-		cache:         cache,
-		userRepo:      userRepo,
-		rebuildingMap: make(map[int64]bool),
+		db:              db,
+		cache:           cache,
+		userRepo:        userRepo,
+		settings:        settings,
+		rebuildGroup:    rebuild,
+		rankGroup:       rank,
+		syncCacheWrites: syncCacheWrites,
+		background:      background,
+	}
+}
+
+// cacheReadCtx 返回读取缓存使用的 ctx，调用方读完后调用 cancel
+func (r *articleRepository) cacheReadCtx(ctx context.Context) (context.Context, context.CancelFunc) {
+	return withOptionalTimeout(ctx, r.Timeouts.Cache)
+}
+
+// dbReadCtx 返回读取数据库使用的 ctx，调用方读完后调用 cancel
+func (r *articleRepository) dbReadCtx(ctx context.Context) (context.Context, context.CancelFunc) {
+	return withOptionalTimeout(ctx, r.Timeouts.DB)
+}
+
+func withOptionalTimeout(ctx context.Context, d time.Duration) (context.Context, context.CancelFunc) {
+	if d <= 0 {
+		return ctx, func() {}
 	}
+	return context.WithTimeout(ctx, d)
 }
 
-// Fetch 获取文章列表
-func (r *articleRepository) Fetch(ctx context.Context, cursor string, num int64) ([]domain.Article, error) {
-	if cursor == "" {
-		articles, expired, err := r.cache.GetHomeWithLogicalExpire(ctx)
+// writeCache 执行一次尽力而为的缓存写入（预热、回填），失败时记录日志。
+// 同步模式下在当前调用中完成，使用不随请求取消的 ctx；否则提交给后台任务池，队列满时放弃本次写入，下一次读取回源
+func (r *articleRepository) writeCache(ctx context.Context, op string, fn func(ctx context.Context) error) {
+	if r.syncCacheWrites {
+		runCacheWrite(context.WithoutCancel(ctx), op, fn)
+		return
+	}
+	if !r.background.Submit(func(ctx context.Context) { runCacheWrite(ctx, op, fn) }) {
+		logrus.Warnf("background queue is full, skip %s", op)
+	}
+}
+
+// rebuildCache 在后台执行一次逻辑过期后的重建，不受同步模式影响：请求已经拿到了旧数据，
+// 不应该再等待回源。队列满时放弃本次重建，下一次读到过期数据时再重建；
+// 没有后台任务池时（只有同步模式允许）在当前调用中重建
+func (r *articleRepository) rebuildCache(ctx context.Context, op string, fn func(ctx context.Context) error) {
+	if r.background == nil {
+		runCacheWrite(context.WithoutCancel(ctx), op, fn)
+		return
+	}
+	if !r.background.Submit(func(ctx context.Context) { runCacheWrite(ctx, op, fn) }) {
+		logrus.Warnf("background queue is full, skip %s", op)
+	}
+}
+
+// invalidateCache 执行一次缓存失效（删除、局部改写），与 writeCache 相同，但队列满时在当前调用中完成。
+// 失效不能丢弃，否则已删除或已隐藏的文章会一直留在缓存和热榜中，直到过期
+func (r *articleRepository) invalidateCache(ctx context.Context, op string, fn func(ctx context.Context) error) {
+	if !r.syncCacheWrites {
+		if r.background.Submit(func(ctx context.Context) { runCacheWrite(ctx, op, fn) }) {
+			return
+		}
+		logrus.Warnf("background queue is full, %s inline", op)
+	}
+	runCacheWrite(context.WithoutCancel(ctx), op, fn)
+}
+
+func runCacheWrite(ctx context.Context, op string, fn func(ctx context.Context) error) {
+	if err := fn(ctx); err != nil {
+		logrus.Warnf("failed to %s: %v", op, err)
+	}
+}
+
+// Fetch 获取文章列表，只有不按语言过滤的首页走首页缓存
+func (r *articleRepository) Fetch(ctx context.Context, cursor string, num int64, lang, tag string) ([]domain.Article, bool, error) {
+	home := cursor == "" && lang == "" && tag == ""
+	if home {
+		cctx, cancel := r.cacheReadCtx(ctx)
+		page, expired, err := r.cache.GetHomeWithLogicalExpire(cctx)
+		cancel()
 		if err == nil {
 			if expired {
-				go r.rebuildHomeCache(context.Background(), num)
+				r.rebuildCache(ctx, "rebuild home cache", func(ctx context.Context) error {
+					r.rebuildHomeCache(ctx, num)
+					return nil
+				})
+				domain.RecordFeedSource(ctx, domain.FeedSourceRebuild)
+			} else {
+				domain.RecordFeedSource(ctx, domain.FeedSourceCache)
 			}
-			return articles, nil
+			return page.Articles, page.HasMore, nil
 		}
 	}
 
 	// 从数据库获取
-	articles, err := r.db.Fetch(ctx, cursor, num)
+	dctx, cancel := r.dbReadCtx(ctx)
+	defer cancel()
+	articles, hasMore, err := r.db.Fetch(dctx, cursor, num, lang, tag)
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
 
 	// 填充用户信息
-	articles, err = r.fillUserDetails(ctx, articles)
+	articles, err = r.fillUserDetails(dctx, articles)
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
 
 	// 如果是首页，异步更新缓存
-	if cursor == "" {
-		go func(data []domain.Article) {
-			_ = r.cache.SetHomeWithLogicalExpire(context.Background(), data, 30*time.Second)
-		}(articles)
+	if home {
+		domain.RecordFeedSource(ctx, domain.FeedSourceDB)
+		page := domain.ArticlePage{Articles: articles, HasMore: hasMore}
+		r.writeCache(ctx, "set home cache", func(ctx context.Context) error {
+			return r.cache.SetHomeWithLogicalExpire(ctx, page, 30*time.Second)
+		})
 	}
 
-	return articles, nil
+	return articles, hasMore, nil
+}
+
+// FetchTags 获取标签及文章数，直接查数据库
+func (r *articleRepository) FetchTags(ctx context.Context) ([]domain.TagCount, error) {
+	dctx, cancel := r.dbReadCtx(ctx)
+	defer cancel()
+	return r.db.FetchTags(dctx)
+}
+
+// GetDraft 读取作者的草稿，草稿不进入缓存
+func (r *articleRepository) GetDraft(ctx context.Context, id, userID int64) (domain.Article, error) {
+	dctx, cancel := r.dbReadCtx(ctx)
+	defer cancel()
+	ar, err := r.db.GetDraft(dctx, id, userID)
+	if err != nil {
+		return domain.Article{}, err
+	}
+	if ar.User, err = r.resolveAuthor(dctx, ar.User.ID); err != nil {
+		return domain.Article{}, err
+	}
+	return ar, nil
+}
+
+// FetchDrafts 获取作者的草稿列表，不缓存
+func (r *articleRepository) FetchDrafts(ctx context.Context, userID int64, cursor string, num int64) ([]domain.Article, bool, error) {
+	dctx, cancel := r.dbReadCtx(ctx)
+	defer cancel()
+	articles, hasMore, err := r.db.FetchDrafts(dctx, userID, cursor, num)
+	if err != nil {
+		return nil, false, err
+	}
+
+	articles, err = r.fillUserDetails(dctx, articles)
+	if err != nil {
+		return nil, false, err
+	}
+	return articles, hasMore, nil
+}
+
+// Publish 发布草稿。草稿从未写入文章缓存，这里仍然删除一次以防万一；首页缓存直接删除，下次读取时重建
+func (r *articleRepository) Publish(ctx context.Context, id int64) error {
+	if err := r.db.Publish(ctx, id); err != nil {
+		return err
+	}
+
+	r.invalidateCache(ctx, "delete article cache", func(ctx context.Context) error {
+		return r.cache.DeleteArticle(ctx, id)
+	})
+	r.invalidateCache(ctx, "delete home cache", func(ctx context.Context) error {
+		return r.cache.DeleteHome(ctx)
+	})
+	return nil
+}
+
+// Search 搜索文章，结果不缓存
+func (r *articleRepository) Search(ctx context.Context, query, cursor string, num int64) ([]domain.Article, bool, error) {
+	dctx, cancel := r.dbReadCtx(ctx)
+	defer cancel()
+	articles, hasMore, err := r.db.Search(dctx, query, cursor, num)
+	if err != nil {
+		return nil, false, err
+	}
+
+	articles, err = r.fillUserDetails(dctx, articles)
+	if err != nil {
+		return nil, false, err
+	}
+	return articles, hasMore, nil
 }
 
 // GetByID 根据ID获取文章，使用逻辑过期策略避免缓存击穿
 func (r *articleRepository) GetByID(ctx context.Context, id int64) (domain.Article, error) {
 	// 1. 先从缓存获取
-	article, expired, err := r.cache.GetArticleWithLogicalExpire(ctx, id)
+	cctx, cancel := r.cacheReadCtx(ctx)
+	article, expired, err := r.cache.GetArticleWithLogicalExpire(cctx, id)
+	cancel()
 	if err == nil {
 		// 缓存命中
 		if expired {
-			go r.rebuildArticleCache(context.Background(), id)
+			r.rebuildCache(ctx, "rebuild article cache", func(ctx context.Context) error {
+				r.rebuildArticleCache(ctx, id)
+				return nil
+			})
+		}
+
+		// 缓存中的正文被截断过，详情页需要从数据库读取完整正文
+		if article.ContentTruncated {
+			dctx, cancel := r.dbReadCtx(ctx)
+			full, err := r.db.GetByID(dctx, id)
+			cancel()
+			if err != nil {
+				return domain.Article{}, err
+			}
+			article.Content = full.Content
+			article.ContentTruncated = false
 		}
 
 		// 更新浏览量（先增加缓存中的浏览量）
-		deltaViews, _ := r.cache.IncrViews(ctx, id)
-		article.Views += deltaViews
+		article.Views += r.countView(ctx, id)
 
-		// 获取最新的点赞数
-		newLikes, err := r.cache.GetLikeCount(ctx, id)
+		// 获取最新的点赞数，读不到时使用文章缓存中的值
+		cctx, cancel := r.cacheReadCtx(ctx)
+		newLikes, err := r.cache.GetLikeCount(cctx, id)
+		cancel()
 		if err == nil {
 			article.Likes = newLikes
 		}
@@ -92,30 +299,9 @@ func (r *articleRepository) GetByID(ctx context.Context, id int64) (domain.Artic
 	}
 
 	// 2. 缓存未命中，使用singleflight避免缓存击穿
-	key := "article:" + string(rune(id))
-	result, err, _ := r.rebuildGroup.Do(key, func() (interface{}, error) {
-		// 从数据库加载
-		art, err := r.db.GetByID(ctx, id)
-		if err != nil {
-			return nil, err
-		}
-
-		// 填充用户信息
-		user, err := r.userRepo.GetByID(ctx, art.User.ID)
-		if err != nil {
-			return nil, err
-		}
-		art.User = user
-
-		// 更新缓存（使用逻辑过期）
-		_ = r.cache.SetArticleWithLogicalExpire(context.Background(), &art, 10*time.Minute)
-
-		// 初始化点赞数缓存
-		_ = r.cache.SetLikeCount(ctx, art.ID, art.Likes)
-
-		return art, nil
+	result, err, _ := r.rebuildGroup.Do(articleRebuildKey(id), func() (any, error) {
+		return r.loadArticle(ctx, id)
 	})
-
 	if err != nil {
 		return domain.Article{}, err
 	}
@@ -123,12 +309,30 @@ func (r *articleRepository) GetByID(ctx context.Context, id int64) (domain.Artic
 	article = result.(domain.Article)
 
 	// 更新浏览量
-	deltaViews, _ := r.cache.IncrViews(ctx, id)
-	article.Views += deltaViews
+	article.Views += r.countView(ctx, id)
 
 	return article, nil
 }
 
+// countView 记录一次浏览并按配置的权重增加今日热榜分数，返回缓存中尚未同步的浏览量。
+// 请求带有客户端信息时同时计入流量统计
+func (r *articleRepository) countView(ctx context.Context, id int64) int64 {
+	if !r.settings.ViewCountingEnabled() {
+		return 0
+	}
+
+	deltaViews, _ := r.cache.IncrViews(ctx, id)
+	if weight := r.settings.RankViewWeight(); weight > 0 {
+		_ = r.cache.IncrDailyRankScore(ctx, id, weight)
+	}
+	if info, ok := domain.ClientInfoFrom(ctx); ok {
+		if err := r.cache.RecordTraffic(ctx, domain.TrafficViews, time.Now(), info); err != nil {
+			logrus.Debugf("failed to record view traffic of article %d: %v", id, err)
+		}
+	}
+	return deltaViews
+}
+
 // GetByIDs 批量获取文章
 func (r *articleRepository) GetByIDs(ctx context.Context, ids []int64) ([]domain.Article, error) {
 	if len(ids) == 0 {
@@ -136,32 +340,54 @@ func (r *articleRepository) GetByIDs(ctx context.Context, ids []int64) ([]domain
 	}
 
 	// 先从缓存批量获取
-	cachedArticles, err := r.cache.GetArticleByIDsWithLogicalExpire(ctx, ids)
+	cctx, cancel := r.cacheReadCtx(ctx)
+	cachedArticles, err := r.cache.GetArticleByIDsWithLogicalExpire(cctx, ids)
+	cancel()
 	if err == nil && len(cachedArticles) == len(ids) {
 		// 全部命中
 		return cachedArticles, nil
 	}
 
-	// 部分未命中，从数据库获取
-	articles, err := r.db.GetByIDs(ctx, ids)
+	// 部分未命中或缓存读取失败，从数据库获取
+	versions, versionsOK := r.articleVersions(ctx, ids)
+	dctx, cancel := r.dbReadCtx(ctx)
+	defer cancel()
+	articles, err := r.db.GetByIDs(dctx, ids)
 	if err != nil {
 		return nil, err
 	}
 
 	// 填充用户信息
-	articles, err = r.fillUserDetails(ctx, articles)
+	articles, err = r.fillUserDetails(dctx, articles)
 	if err != nil {
 		return nil, err
 	}
 
-	// 异步更新缓存
-	go func(arts []domain.Article) {
-		_ = r.cache.BatchSetArticleWithLogicalExpire(context.Background(), arts, 10*time.Minute)
-	}(articles)
+	// 更新缓存
+	if versionsOK {
+		r.writeCache(ctx, "batch set article cache", func(ctx context.Context) error {
+			return r.cache.BatchSetArticleWithLogicalExpire(ctx, articles, versions, 10*time.Minute)
+		})
+	}
 
 	return articles, nil
 }
 
+// GetForComment 读取评论需要的文章状态。已发布的文章通常在缓存中，锁定状态随文章一起缓存；
+// 草稿从不写入缓存，未命中时直接读数据库，结果也不缓存
+func (r *articleRepository) GetForComment(ctx context.Context, id int64) (domain.Article, error) {
+	cctx, cancel := r.cacheReadCtx(ctx)
+	cached, err := r.cache.GetArticleByIDsWithLogicalExpire(cctx, []int64{id})
+	cancel()
+	if err == nil && len(cached) == 1 {
+		return cached[0], nil
+	}
+
+	dctx, cancel := r.dbReadCtx(ctx)
+	defer cancel()
+	return r.db.GetForComment(dctx, id)
+}
+
 // GetByTitle 根据标题获取文章
 func (r *articleRepository) GetByTitle(ctx context.Context, title string) (domain.Article, error) {
 	// 直接从数据库查询（标题查询不常用，不走缓存）
@@ -171,7 +397,7 @@ func (r *articleRepository) GetByTitle(ctx context.Context, title string) (domai
 	}
 
 	// 填充用户信息
-	user, err := r.userRepo.GetByID(ctx, article.User.ID)
+	user, err := r.resolveAuthor(ctx, article.User.ID)
 	if err != nil {
 		return domain.Article{}, err
 	}
@@ -180,37 +406,137 @@ func (r *articleRepository) GetByTitle(ctx context.Context, title string) (domai
 	return article, nil
 }
 
-// Store 创建文章
+// FindByFingerprint 查找正文指纹相同的文章，直接查数据库
+func (r *articleRepository) FindByFingerprint(ctx context.Context, fp string) ([]domain.Article, error) {
+	return r.db.FindByFingerprint(ctx, fp)
+}
+
+// Store 创建文章，并补全作者信息，调用方可以直接用 a 作为创建结果返回。
+// 文章已经写入，查询作者失败时只记录日志，不返回错误
 func (r *articleRepository) Store(ctx context.Context, a *domain.Article) error {
-	return r.db.Store(ctx, a)
+	if err := r.db.Store(ctx, a); err != nil {
+		return err
+	}
+
+	user, err := r.resolveAuthor(ctx, a.User.ID)
+	if err != nil {
+		logrus.Warnf("failed to resolve author of new article %d: %v", a.ID, err)
+		return nil
+	}
+	a.User = user
+	return nil
 }
 
 // Update 更新文章
 func (r *articleRepository) Update(ctx context.Context, ar *domain.Article) error {
-	err := r.db.Update(ctx, ar)
+	changed, err := r.db.Update(ctx, ar)
 	if err != nil {
 		return err
 	}
 
-	// 异步删除缓存
-	go func(id int64) {
-		_ = r.cache.DeleteArticle(context.Background(), id)
-	}(ar.ID)
+	// 局部更新缓存，避免下次读取时回源数据库；失败时退化为删除缓存
+	id := ar.ID
+	r.invalidateCache(ctx, "patch article cache", func(ctx context.Context) error {
+		if err := r.cache.PatchArticle(ctx, id, changed); err != nil {
+			logrus.Warnf("failed to patch article cache, ID: %d, err: %v", id, err)
+			return r.cache.DeleteArticle(ctx, id)
+		}
+		return nil
+	})
+	// 首页缓存中的标题和摘要也可能变化，直接删除，下次读取时重建
+	r.invalidateCache(ctx, "delete home cache", func(ctx context.Context) error {
+		return r.cache.DeleteHome(ctx)
+	})
 
 	return nil
 }
 
-// Delete 删除文章
+// Delete 软删除文章，评论、表情回应和标签都保留，恢复后原样可见
 func (r *articleRepository) Delete(ctx context.Context, id int64) error {
-	err := r.db.Delete(ctx, id)
+	if err := r.db.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	// 清理文章在缓存中的全部数据，热榜中不再出现已删除的文章
+	r.invalidateCache(ctx, "purge article cache", func(ctx context.Context) error {
+		return r.cache.PurgeArticle(ctx, id)
+	})
+	// 首页缓存中保存的是完整文章，同样删除，下次读取时重建
+	r.invalidateCache(ctx, "delete home cache", func(ctx context.Context) error {
+		return r.cache.DeleteHome(ctx)
+	})
+
+	return nil
+}
+
+// GetDeleted 读取已删除的文章并填充作者，不经过缓存
+func (r *articleRepository) GetDeleted(ctx context.Context, id int64) (domain.Article, error) {
+	dctx, cancel := r.dbReadCtx(ctx)
+	defer cancel()
+	article, err := r.db.GetDeleted(dctx, id)
 	if err != nil {
+		return domain.Article{}, err
+	}
+	article.User, err = r.resolveAuthor(dctx, article.User.ID)
+	if err != nil {
+		return domain.Article{}, err
+	}
+	return article, nil
+}
+
+// Restore 恢复已删除的文章。删除时缓存已经清理，这里再删一次文章缓存，
+// 并让首页缓存失效，恢复的文章重新出现在列表中
+func (r *articleRepository) Restore(ctx context.Context, id int64) error {
+	if err := r.db.Restore(ctx, id); err != nil {
 		return err
 	}
 
-	// 异步删除缓存
-	go func(id int64) {
-		_ = r.cache.DeleteArticle(context.Background(), id)
-	}(id)
+	r.invalidateCache(ctx, "delete article cache", func(ctx context.Context) error {
+		return r.cache.DeleteArticle(ctx, id)
+	})
+	r.invalidateCache(ctx, "invalidate home cache", func(ctx context.Context) error {
+		return r.cache.DeleteHome(ctx)
+	})
+
+	return nil
+}
+
+// SetHidden 隐藏或取消隐藏文章。首页缓存与 Update、Restore 一样直接删除，下次读取时重建；
+// 隐藏时同时从热榜中移除，取消隐藏后随热榜重建或新的点赞回到热榜
+func (r *articleRepository) SetHidden(ctx context.Context, id int64, hidden bool) error {
+	err := r.db.SetHidden(ctx, id, hidden)
+	if err != nil {
+		return err
+	}
+
+	r.invalidateCache(ctx, "delete article cache", func(ctx context.Context) error {
+		return r.cache.DeleteArticle(ctx, id)
+	})
+	r.invalidateCache(ctx, "delete home cache", func(ctx context.Context) error {
+		return r.cache.DeleteHome(ctx)
+	})
+	if hidden {
+		r.invalidateCache(ctx, "remove hidden article from ranks", func(ctx context.Context) error {
+			return r.cache.RemoveFromRanks(ctx, []int64{id})
+		})
+	}
+
+	return nil
+}
+
+// SetCommentsLocked 开启或关闭评论，只改写缓存中的对应字段，评论时从缓存读取锁定状态
+func (r *articleRepository) SetCommentsLocked(ctx context.Context, id int64, locked bool) error {
+	if err := r.db.SetCommentsLocked(ctx, id, locked); err != nil {
+		return err
+	}
+
+	r.invalidateCache(ctx, "patch comments lock", func(ctx context.Context) error {
+		if err := r.cache.PatchArticle(ctx, id, map[string]any{"CommentsLocked": locked}); err != nil {
+			logrus.Warnf("failed to patch comments lock, ID: %d, err: %v", id, err)
+			return r.cache.DeleteArticle(ctx, id)
+		}
+		return nil
+	})
 
 	return nil
 }
@@ -245,6 +571,11 @@ func (r *articleRepository) FetchIDs(ctx context.Context, cursor, limit int64) (
 	return r.db.FetchIDs(ctx, cursor, limit)
 }
 
+// FetchTitles 获取可见文章的标题，用于重建标题索引
+func (r *articleRepository) FetchTitles(ctx context.Context, cursor, limit int64) ([]domain.TitleSuggestion, error) {
+	return r.db.FetchTitles(ctx, cursor, limit)
+}
+
 // fillUserDetails 批量填充用户详细信息
 func (r *articleRepository) fillUserDetails(ctx context.Context, articles []domain.Article) ([]domain.Article, error) {
 	if len(articles) == 0 {
@@ -273,20 +604,31 @@ func (r *articleRepository) fillUserDetails(ctx context.Context, articles []doma
 		userMap[u.ID] = u
 	}
 
-	// 填充回Article
+	// 填充回Article，作者账号已删除时使用占位用户
 	for i := range articles {
 		if u, ok := userMap[articles[i].User.ID]; ok {
 			articles[i].User = u
+		} else {
+			articles[i].User = domain.DeletedUser()
 		}
 	}
 
 	return articles, nil
 }
 
+// resolveAuthor 查询文章作者，账号已删除时返回占位用户而不是错误
+func (r *articleRepository) resolveAuthor(ctx context.Context, uid int64) (domain.User, error) {
+	user, err := r.userRepo.GetByID(ctx, uid)
+	if errors.Is(err, domain.ErrNotFound) {
+		return domain.DeletedUser(), nil
+	}
+	return user, err
+}
+
 // rebuildHomeCache 异步重建首页缓存
 func (r *articleRepository) rebuildHomeCache(ctx context.Context, num int64) {
 	_, err, _ := r.rebuildGroup.Do("home", func() (any, error) {
-		articles, err := r.db.Fetch(ctx, "", num)
+		articles, hasMore, err := r.db.Fetch(ctx, "", num, "", "")
 		if err != nil {
 			logrus.Errorf("failed to rebuild home cache from db: %v", err)
 			return nil, err
@@ -298,7 +640,7 @@ func (r *articleRepository) rebuildHomeCache(ctx context.Context, num int64) {
 			return nil, err
 		}
 
-		err = r.cache.SetHomeWithLogicalExpire(ctx, articles, 30*time.Second)
+		err = r.cache.SetHomeWithLogicalExpire(ctx, domain.ArticlePage{Articles: articles, HasMore: hasMore}, 30*time.Second)
 		if err != nil {
 			logrus.Errorf("failed to set home cache: %v", err)
 			return nil, err
@@ -314,68 +656,86 @@ func (r *articleRepository) rebuildHomeCache(ctx context.Context, num int64) {
 
 // rebuildArticleCache 异步重建文章缓存
 func (r *articleRepository) rebuildArticleCache(ctx context.Context, id int64) {
-	// 检查是否已经在重建中
-	r.mu.Lock()
-	if r.rebuildingMap[id] {
-		r.mu.Unlock()
-		return
+	// 与缓存未命中共用同一个 key，同一篇文章同时只会回源一次
+	_, err, _ := r.rebuildGroup.Do(articleRebuildKey(id), func() (any, error) {
+		return r.loadArticle(ctx, id)
+	})
+
+	if err != nil {
+		logrus.Errorf("rebuildArticleCache failed for id %d: %v", id, err)
 	}
-	r.rebuildingMap[id] = true
-	r.mu.Unlock()
+}
 
-	// 完成后清除标记
-	defer func() {
-		r.mu.Lock()
-		delete(r.rebuildingMap, id)
-		r.mu.Unlock()
-	}()
+// articleRebuildKey 文章回源在 rebuildGroup 中的 key
+func articleRebuildKey(id int64) string {
+	return "article:" + strconv.FormatInt(id, 10)
+}
 
-	// 使用singleflight避免并发重建
-	key := "rebuild:" + string(rune(id))
-	_, err, _ := r.rebuildGroup.Do(key, func() (any, error) {
-		article, err := r.db.GetByID(ctx, id)
-		if err != nil {
-			if errors.Is(err, domain.ErrNotFound) {
-				// 文章不存在，删除缓存
-				_ = r.cache.DeleteArticle(ctx, id)
-			}
-			return nil, err
-		}
+// articleVersions 在回源前读取文章缓存的版本号，读取失败时返回 false，调用方不写入缓存
+func (r *articleRepository) articleVersions(ctx context.Context, ids []int64) (map[int64]int64, bool) {
+	cctx, cancel := r.cacheReadCtx(ctx)
+	defer cancel()
+	versions, err := r.cache.GetArticleVersions(cctx, ids)
+	if err != nil {
+		logrus.Warnf("failed to get article cache versions: %v", err)
+		return nil, false
+	}
+	return versions, true
+}
 
-		// 填充用户信息
-		user, err := r.userRepo.GetByID(ctx, article.User.ID)
-		if err != nil {
-			logrus.Errorf("failed to get user: %v", err)
-			return nil, err
+// loadArticle 从数据库加载文章并写入缓存，文章不存在时删除缓存
+func (r *articleRepository) loadArticle(ctx context.Context, id int64) (domain.Article, error) {
+	versions, versionsOK := r.articleVersions(ctx, []int64{id})
+	dctx, cancel := r.dbReadCtx(ctx)
+	defer cancel()
+	article, err := r.db.GetByID(dctx, id)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			_ = r.cache.DeleteArticle(ctx, id)
 		}
-		article.User = user
+		return domain.Article{}, err
+	}
 
-		// 更新缓存
-		err = r.cache.SetArticleWithLogicalExpire(ctx, &article, 10*time.Minute)
-		if err != nil {
-			logrus.Errorf("failed to set article cache: %v", err)
-			return nil, err
-		}
+	// 填充用户信息
+	user, err := r.resolveAuthor(dctx, article.User.ID)
+	if err != nil {
+		return domain.Article{}, err
+	}
+	article.User = user
 
-		return nil, nil
-	})
+	// 点赞数以 Redis 中的计数为准：它包含同步任务尚未落库的点赞和取消，比 article.likes 新。
+	// 数据库中的值只用来初始化不存在的计数，与命中缓存时的详情和文章列表一致
+	if likes, err := r.cache.InitLikeCount(ctx, article.ID, article.Likes); err != nil {
+		logrus.Warnf("failed to init like count of article %d: %v", article.ID, err)
+	} else {
+		article.Likes = likes
+	}
 
-	if err != nil {
-		logrus.Errorf("rebuildArticleCache failed for id %d: %v", id, err)
+	// 更新缓存（使用逻辑过期）
+	if versionsOK {
+		if err := r.cache.SetArticleWithLogicalExpire(context.Background(), &article, versions[id], 10*time.Minute); err != nil {
+			logrus.Errorf("failed to set article cache: %v", err)
+		}
 	}
+
+	return article, nil
 }
 
 // GetDailyRank 获取每日热榜
-func (r *articleRepository) GetDailyRank(ctx context.Context, limit int64) ([]domain.Article, error) {
+func (r *articleRepository) GetDailyRank(ctx context.Context, offset, limit int64) ([]domain.Article, error) {
 	// 先尝试从缓存获取
-	articles, err := r.cache.GetDailyRank(ctx, limit)
+	cctx, cancel := r.cacheReadCtx(ctx)
+	articles, err := r.cache.GetDailyRank(cctx, offset, limit)
+	cancel()
 	if err == nil {
 		return r.fillRankArticles(ctx, articles)
 	}
 
-	// 缓存未命中
-	result, err, _ := r.rankGroup.Do("daily", func() (any, error) {
-		return r.buildDailyRank(ctx, limit)
+	logrus.Warnf("failed to get daily rank from cache, rebuilding: %v", err)
+
+	// 缓存未命中或读取超时，不同页分别重建
+	result, err, _ := r.rankGroup.Do(fmt.Sprintf("daily:%d:%d", offset, limit), func() (any, error) {
+		return r.buildDailyRank(ctx, offset, limit)
 	})
 
 	if err != nil {
@@ -385,88 +745,111 @@ func (r *articleRepository) GetDailyRank(ctx context.Context, limit int64) ([]do
 	return result.([]domain.Article), nil
 }
 
-// GetHistoryRank 获取历史热榜
+// GetHistoryRank 获取历史热榜。
+// 热榜过期时先返回旧副本并在后台重建，只有连旧副本都没有（冷启动）时才在请求中等待重建
 func (r *articleRepository) GetHistoryRank(ctx context.Context, limit int64) ([]domain.Article, error) {
-	articles, err := r.cache.GetHistoryRank(ctx, limit)
+	cctx, cancel := r.cacheReadCtx(ctx)
+	articles, err := r.cache.GetHistoryRank(cctx, limit)
+	cancel()
 	if err == nil {
 		// 填充完整文章信息
 		return r.fillRankArticles(ctx, articles)
 	}
 
-	// 缓存未命中
+	// 缓存未命中，有旧副本时直接返回旧副本
+	cctx, cancel = r.cacheReadCtx(ctx)
+	stale, err := r.cache.GetStaleHistoryRank(cctx, limit)
+	cancel()
+	if err == nil {
+		r.rebuildCache(ctx, "rebuild history rank", func(ctx context.Context) error {
+			r.rebuildHistoryRank(ctx)
+			return nil
+		})
+		return r.fillRankArticles(ctx, stale)
+	}
+
+	// 冷启动
 	result, err, _ := r.rankGroup.Do("history", func() (any, error) {
-		return r.buildHistoryRank(ctx, limit)
+		return r.buildHistoryRank(ctx)
 	})
 
 	if err != nil {
 		return nil, err
 	}
 
-	return result.([]domain.Article), nil
+	articles = result.([]domain.Article)
+	if int64(len(articles)) > limit {
+		articles = articles[:limit]
+	}
+	return articles, nil
 }
 
-// buildDailyRank 构建每日热榜
-func (r *articleRepository) buildDailyRank(ctx context.Context, limit int64) ([]domain.Article, error) {
-	// // 从数据库按点赞数获取
-	// articles, err := r.db.FetchArticlesByLikes(ctx, limit)
-	// if err != nil {
-	// 	return nil, err
-	// }
-
-	// // 填充用户信息
-	// articles, err = r.fillUserDetails(ctx, articles)
-	// if err != nil {
-	// 	return nil, err
-	// }
-
-	// // 更新缓存（逻辑过期，5分钟TTL）
-	// go func(arts []domain.Article) {
-	// 	_ = r.cache.SetDailyRankWithLogicalExpire(context.Background(), arts, 5*time.Minute)
-	// }(articles)
+// buildDailyRank 重新聚合最近 24 小时的分桶并读取一页今日热榜。
+// 今日热榜只保存在 Redis 中，数据库里没有对应的数据可以回源，
+// 所以 Redis 不可用时返回空榜单而不是错误，首页其余部分照常展示
+func (r *articleRepository) buildDailyRank(ctx context.Context, offset, limit int64) ([]domain.Article, error) {
+	if err := r.cache.AggregateDailyRank(ctx); err != nil {
+		logrus.Errorf("failed to aggregate daily rank: %v", err)
+		return []domain.Article{}, nil
+	}
 
-	// return articles, nil
+	articles, err := r.cache.GetDailyRank(ctx, offset, limit)
+	if err != nil {
+		logrus.Errorf("failed to get daily rank after aggregation: %v", err)
+		return []domain.Article{}, nil
+	}
 
-	panic("Unreachable: unimplement")
+	// 填充完整文章和作者信息
+	return r.fillRankArticles(ctx, articles)
 }
 
-// buildHistoryRank 构建历史热榜
-func (r *articleRepository) buildHistoryRank(ctx context.Context, limit int64) ([]domain.Article, error) {
+// buildHistoryRank 构建历史热榜，总是构建 historyRankSize 篇，调用方按需截断
+func (r *articleRepository) buildHistoryRank(ctx context.Context) ([]domain.Article, error) {
 	// 从数据库按点赞数获取
-	articles, err := r.db.FetchArticlesByLikes(ctx, limit)
+	dctx, cancel := r.dbReadCtx(ctx)
+	defer cancel()
+	articles, err := r.db.FetchArticlesByLikes(dctx, historyRankSize)
 	if err != nil {
 		return nil, err
 	}
 
 	// 填充用户信息
-	articles, err = r.fillUserDetails(ctx, articles)
+	articles, err = r.fillUserDetails(dctx, articles)
 	if err != nil {
 		return nil, err
 	}
+	if len(articles) == 0 {
+		return articles, nil
+	}
 
-	// 准备缓存数据
+	// 准备缓存数据，冷启动直接返回的文章带上与热榜相同的分数，与命中缓存时一致
 	aids := make([]int64, len(articles))
 	scores := make([]float64, len(articles))
 	for i, art := range articles {
 		aids[i] = art.ID
 		scores[i] = float64(art.Likes)
+		articles[i].Score = scores[i]
 	}
 
-	// 更新缓存（使用逻辑过期，1小时TTL）
-	go func() {
-		_ = r.cache.SetHistoryRankWithLogicalExpire(context.Background(), aids, scores, 1*time.Hour)
-	}()
+	// 同时刷新热榜和旧副本
+	if err := r.cache.SetHistoryRank(ctx, aids, scores); err != nil {
+		logrus.Errorf("failed to set history rank cache: %v", err)
+	}
+	if err := r.cache.SetStaleHistoryRank(ctx, aids, scores); err != nil {
+		logrus.Errorf("failed to set stale history rank cache: %v", err)
+	}
 
 	return articles, nil
 }
 
-// rebuildDailyRank 异步重建每日热榜
-func (r *articleRepository) rebuildDailyRank(ctx context.Context, limit int64) {
-	_, err, _ := r.rebuildGroup.Do("rebuild_daily", func() (any, error) {
-		return r.buildDailyRank(ctx, limit)
+// rebuildHistoryRank 异步重建历史热榜，与冷启动共用 rankGroup，同一时间只会有一次重建
+func (r *articleRepository) rebuildHistoryRank(ctx context.Context) {
+	_, err, _ := r.rankGroup.Do("history", func() (any, error) {
+		return r.buildHistoryRank(ctx)
 	})
 
 	if err != nil {
-		logrus.Errorf("rebuildDailyRank failed: %v", err)
+		logrus.Errorf("rebuildHistoryRank failed: %v", err)
 	}
 }
 
@@ -484,23 +867,46 @@ func (r *articleRepository) fillRankArticles(ctx context.Context, rankArticles [
 
 	// 批量从缓存/数据库获取完整文章信息
 	articles, err := r.GetByIDs(ctx, ids)
+	healing := err == nil
 	if err != nil {
-		// 如果获取失败，返回基本的排名信息
-		logrus.Warnf("failed to fill rank articles: %v", err)
-		return rankArticles, nil
+		// 数据库失败时尽量使用缓存中已有的文章，只有缓存里也没有的才退化为基本的排名信息
+		logrus.Warnf("failed to fill rank articles, falling back to cache: %v", err)
+		cctx, cancel := r.cacheReadCtx(ctx)
+		articles, err = r.cache.GetArticleByIDsWithLogicalExpire(cctx, ids)
+		cancel()
+		if err != nil {
+			logrus.Warnf("failed to get rank articles from cache: %v", err)
+			return rankArticles, nil
+		}
 	}
 
-	// 保持排名顺序，并合并点赞数
+	// 保持排名顺序，并合并排名分数
 	articleMap := make(map[int64]domain.Article)
 	for _, art := range articles {
 		articleMap[art.ID] = art
 	}
 
+	// GetByIDs 会静默丢掉已删除（或已隐藏）的文章，把它们从热榜中移除，热榜会逐渐自愈。
+	// 退化到缓存时缺失只说明没有缓存，不能据此删除
+	if healing {
+		var stale []int64
+		for _, id := range ids {
+			if _, ok := articleMap[id]; !ok {
+				stale = append(stale, id)
+			}
+		}
+		if len(stale) > 0 {
+			r.writeCache(ctx, "remove stale rank articles", func(ctx context.Context) error {
+				return r.cache.RemoveFromRanks(ctx, stale)
+			})
+		}
+	}
+
 	result := make([]domain.Article, 0, len(rankArticles))
 	for _, rankArt := range rankArticles {
 		if fullArt, ok := articleMap[rankArt.ID]; ok {
-			// 使用热榜中的点赞数（可能更新）
-			fullArt.Likes = rankArt.Likes
+			// 点赞数以文章为准，分数只用于排名
+			fullArt.Score = rankArt.Score
 			result = append(result, fullArt)
 		} else {
 			// 如果找不到完整信息，使用基本信息
@@ -0,0 +1,724 @@
+package repository_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/repository"
+	myRedis "github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/repository/redis"
+)
+
+func TestHistoryRankPartialEnrichment(t *testing.T) {
+	cache := &fakeCache{
+		articles: map[int64]domain.Article{
+			1: {ID: 1, Title: "first", Likes: 1},
+			3: {ID: 3, Title: "third", Likes: 1},
+		},
+		history: []domain.Article{{ID: 1, Score: 30}, {ID: 2, Score: 20}, {ID: 3, Score: 10}},
+	}
+	repo := newArticleRepo(&fakeDB{err: errDB}, cache)
+
+	rank, err := repo.GetHistoryRank(context.Background(), 10)
+	require.NoError(t, err)
+
+	require.Len(t, rank, 3)
+	assert.Equal(t, domain.Article{ID: 1, Title: "first", Likes: 1, Score: 30}, rank[0])
+	// 缓存和数据库都拿不到的文章退化为基本排名信息
+	assert.Equal(t, domain.Article{ID: 2, Score: 20}, rank[1])
+	assert.Equal(t, domain.Article{ID: 3, Title: "third", Likes: 1, Score: 10}, rank[2])
+}
+
+func TestHistoryRankFullEnrichment(t *testing.T) {
+	cache := &fakeCache{
+		articles: map[int64]domain.Article{},
+		history:  []domain.Article{{ID: 2, Score: 20}, {ID: 1, Score: 10}},
+	}
+	db := &fakeDB{articles: map[int64]domain.Article{
+		1: {ID: 1, Title: "first", User: domain.User{ID: 7}},
+		2: {ID: 2, Title: "second", User: domain.User{ID: 7}},
+	}}
+	repo := newArticleRepo(db, cache)
+
+	rank, err := repo.GetHistoryRank(context.Background(), 10)
+	require.NoError(t, err)
+
+	require.Len(t, rank, 2)
+	assert.Equal(t, "second", rank[0].Title)
+	assert.Equal(t, float64(20), rank[0].Score)
+	assert.Equal(t, "first", rank[1].Title)
+}
+
+func TestUpdatePatchesCacheWithoutRebuild(t *testing.T) {
+	ctx := context.Background()
+	db := &fakeDB{articles: map[int64]domain.Article{1: {ID: 1, Title: "title", User: domain.User{ID: 7}}}}
+	cache := &fakeCache{articles: map[int64]domain.Article{}}
+	repo := newArticleRepo(db, cache)
+
+	_, err := repo.GetByID(ctx, 1)
+	require.NoError(t, err)
+	require.Equal(t, 1, db.rebuilds())
+
+	require.NoError(t, repo.Update(ctx, &domain.Article{ID: 1, Title: "new title"}))
+	assert.Eventually(t, func() bool {
+		ar, _ := cache.cached(1)
+		return ar.Title == "new title"
+	}, time.Second, 10*time.Millisecond)
+
+	got, err := repo.GetByID(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "new title", got.Title)
+	assert.Equal(t, 1, db.rebuilds(), "reads after update should be served by the patched cache")
+	// 首页缓存里的标题也过时了，需要删除
+	assert.Eventually(t, func() bool { return cache.homeDeletes() == 1 }, time.Second, 10*time.Millisecond)
+}
+
+func TestUpdateDeletesCacheWhenPatchFails(t *testing.T) {
+	ctx := context.Background()
+	db := &fakeDB{articles: map[int64]domain.Article{1: {ID: 1, Title: "title"}}}
+	cache := &fakeCache{
+		articles: map[int64]domain.Article{1: {ID: 1, Title: "title"}},
+		patchErr: errDB,
+	}
+	repo := newArticleRepo(db, cache)
+
+	require.NoError(t, repo.Update(ctx, &domain.Article{ID: 1, Title: "new title"}))
+	assert.Eventually(t, func() bool {
+		_, ok := cache.cached(1)
+		return !ok
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestGetByIDReturnsFullContentWhenCacheTruncated(t *testing.T) {
+	ctx := context.Background()
+	client := redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	cache := myRedis.NewArticleCache(client, "", 8, 0)
+
+	content := "a very long article body"
+	db := &fakeDB{articles: map[int64]domain.Article{1: {ID: 1, Title: "title", Content: content, User: domain.User{ID: 7}}}}
+	repo := newArticleRepo(db, cache)
+
+	// 第一次读取回源数据库并写入缓存
+	got, err := repo.GetByID(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, content, got.Content)
+
+	cached, _, err := cache.GetArticleWithLogicalExpire(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "a very l", cached.Content)
+	assert.True(t, cached.ContentTruncated)
+
+	// 列表/热榜直接使用缓存中截断的正文
+	list, err := repo.GetByIDs(ctx, []int64{1})
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.True(t, list[0].ContentTruncated)
+
+	// 详情页命中缓存后仍然返回完整正文
+	got, err = repo.GetByID(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, content, got.Content)
+	assert.False(t, got.ContentTruncated)
+	assert.Equal(t, "title", got.Title)
+}
+
+func rankIDs(articles []domain.Article) []int64 {
+	ids := make([]int64, len(articles))
+	for i, ar := range articles {
+		ids[i] = ar.ID
+	}
+	return ids
+}
+
+func TestHistoryRankColdStart(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	db := &rankDB{articles: []domain.Article{
+		{ID: 3, Title: "third", Likes: 30},
+		{ID: 1, Title: "first", Likes: 20},
+		{ID: 2, Title: "second", Likes: 10},
+	}}
+	repo := newArticleRepo(db, myRedis.NewArticleCache(client, "", 0, 0))
+
+	// 没有任何缓存时只能在请求中等待重建
+	rank, err := repo.GetHistoryRank(context.Background(), 2)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{3, 1}, rankIDs(rank))
+	assert.Equal(t, 1, db.fetches())
+	// 冷启动返回的分数与之后命中缓存时一致
+	assert.Equal(t, []float64{30, 20}, []float64{rank[0].Score, rank[1].Score})
+	cached, err := repo.GetHistoryRank(context.Background(), 2)
+	require.NoError(t, err)
+	assert.Equal(t, []float64{30, 20}, []float64{cached[0].Score, cached[1].Score})
+
+	// 热榜和旧副本都已写入，热榜会过期，旧副本长期保留
+	assert.True(t, mr.Exists("article:hot:history:rank"))
+	assert.True(t, mr.Exists("article:hot:history:rank:stale"))
+	assert.Greater(t, mr.TTL("article:hot:history:rank:stale"), mr.TTL("article:hot:history:rank"))
+}
+
+func TestHistoryRankServesStaleWhileRebuilding(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	cache := myRedis.NewArticleCache(client, "", 0, 0)
+	db := &rankDB{
+		articles: []domain.Article{
+			{ID: 2, Title: "second", Likes: 50},
+			{ID: 1, Title: "first", Likes: 20},
+		},
+		release: make(chan struct{}),
+	}
+	repo := newArticleRepo(db, cache)
+
+	// 热榜已过期，只剩上一次的旧副本
+	require.NoError(t, cache.SetStaleHistoryRank(ctx, []int64{1, 2}, []float64{20, 10}))
+	require.False(t, mr.Exists("article:hot:history:rank"))
+
+	// 重建被阻塞，请求仍然立即拿到旧副本
+	rank, err := repo.GetHistoryRank(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1, 2}, rankIDs(rank))
+	assert.Equal(t, "first", rank[0].Title)
+
+	// 重建期间的请求同样返回旧副本
+	rank, err = repo.GetHistoryRank(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1, 2}, rankIDs(rank))
+
+	close(db.release)
+	require.Eventually(t, func() bool { return mr.Exists("article:hot:history:rank") }, time.Second, 10*time.Millisecond)
+
+	// 重建完成后返回新的排名，旧副本也被刷新
+	rank, err = repo.GetHistoryRank(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{2, 1}, rankIDs(rank))
+	assert.Equal(t, int64(50), rank[0].Likes)
+
+	stale, err := cache.GetStaleHistoryRank(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{2, 1}, rankIDs(stale))
+}
+
+// waitBlocked 给并发的请求一点时间进入 singleflight，再放行被阻塞的回源
+func waitBlocked(release chan struct{}) {
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+}
+
+func TestGetByIDConcurrentMissLoadsOnce(t *testing.T) {
+	db := &fakeDB{
+		articles: map[int64]domain.Article{1: {ID: 1, Title: "title", User: domain.User{ID: 7}}},
+		release:  make(chan struct{}),
+	}
+	cache := &fakeCache{articles: map[int64]domain.Article{}}
+	repo := newArticleRepo(db, cache)
+
+	var wg sync.WaitGroup
+	for range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ar, err := repo.GetByID(context.Background(), 1)
+			assert.NoError(t, err)
+			assert.Equal(t, "title", ar.Title)
+		}()
+	}
+	waitBlocked(db.release)
+	wg.Wait()
+
+	assert.Equal(t, 1, db.rebuilds())
+	_, ok := cache.cached(1)
+	assert.True(t, ok)
+}
+
+func TestGetByIDConcurrentExpiredRebuildsOnce(t *testing.T) {
+	db := &fakeDB{
+		articles: map[int64]domain.Article{1: {ID: 1, Title: "new", User: domain.User{ID: 7}}},
+		release:  make(chan struct{}),
+	}
+	cache := &fakeCache{articles: map[int64]domain.Article{1: {ID: 1, Title: "old"}}, expired: true}
+	repo := newArticleRepo(db, cache)
+
+	// 过期的缓存立即返回，重建在后台进行
+	var wg sync.WaitGroup
+	for range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ar, err := repo.GetByID(context.Background(), 1)
+			assert.NoError(t, err)
+			assert.Equal(t, "old", ar.Title)
+		}()
+	}
+	wg.Wait()
+	waitBlocked(db.release)
+
+	require.Eventually(t, func() bool {
+		ar, _ := cache.cached(1)
+		return ar.Title == "new"
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, 1, db.rebuilds())
+}
+
+func TestGetByIDLargeIDsDoNotShareRebuild(t *testing.T) {
+	// 这两个 ID 超出 Unicode 范围，转成 rune 后都是 U+FFFD
+	const a, b = 0x110000, 0x110001
+	db := &fakeDB{articles: map[int64]domain.Article{
+		a: {ID: a, Title: "a"},
+		b: {ID: b, Title: "b"},
+	}, release: make(chan struct{})}
+	repo := newArticleRepo(db, &fakeCache{articles: map[int64]domain.Article{}})
+
+	titles := make([]string, 2)
+	var wg sync.WaitGroup
+	for i, id := range []int64{a, b} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ar, err := repo.GetByID(context.Background(), id)
+			assert.NoError(t, err)
+			titles[i] = ar.Title
+		}()
+	}
+	waitBlocked(db.release)
+	wg.Wait()
+
+	assert.Equal(t, []string{"a", "b"}, titles)
+	assert.Equal(t, 2, db.rebuilds())
+}
+
+func TestGetByIDMissDedupedByInjectedDeduper(t *testing.T) {
+	db := &fakeDB{articles: map[int64]domain.Article{1: {ID: 1, Title: "title", User: domain.User{ID: 7}}}}
+	// 缓存始终未命中，每个请求都会走到回源
+	cache := &missCache{fakeCache: &fakeCache{articles: map[int64]domain.Article{}}}
+	dedup := newOnceDeduper()
+	repo := repository.NewArticleRepositoryWithDedupers(db, cache, fakeUserRepo{}, repository.NewRuntimeSettings(emptySettingsRepo{}), false, goRunner{}, dedup, newOnceDeduper())
+
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ar, err := repo.GetByID(context.Background(), 1)
+			assert.NoError(t, err)
+			assert.Equal(t, "title", ar.Title)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 20, dedup.callsOf("article:1"))
+	assert.Equal(t, 1, db.rebuilds())
+}
+
+func TestSyncCacheWritesAreImmediatelyReadable(t *testing.T) {
+	ctx := context.Background()
+	db := &fakeDB{articles: map[int64]domain.Article{}}
+	cache := &fakeCache{articles: map[int64]domain.Article{}}
+	repo := repository.NewArticleRepository(db, cache, fakeUserRepo{}, repository.NewRuntimeSettings(emptySettingsRepo{}), true, nil)
+
+	ar := &domain.Article{Title: "title", User: domain.User{ID: 7}}
+	require.NoError(t, repo.Store(ctx, ar))
+	_, err := repo.GetByIDs(ctx, []int64{ar.ID})
+	require.NoError(t, err)
+
+	// 同步模式下返回时缓存已经写好，不需要等待
+	cached, ok := cache.cached(ar.ID)
+	require.True(t, ok)
+	assert.Equal(t, "title", cached.Title)
+
+	require.NoError(t, repo.Delete(ctx, ar.ID))
+	_, ok = cache.cached(ar.ID)
+	assert.False(t, ok)
+}
+
+func TestRankFillRemovesDeletedArticles(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	cache := myRedis.NewArticleCache(client, "", 0, 0)
+	// 文章 1 仍在热榜中，但已经从数据库删除
+	db := &rankDB{articles: []domain.Article{{ID: 2, Title: "second"}}}
+	repo := repository.NewArticleRepository(db, cache, fakeUserRepo{}, repository.NewRuntimeSettings(emptySettingsRepo{}), true, nil)
+
+	require.NoError(t, cache.SetHistoryRank(ctx, []int64{1, 2}, []float64{20, 10}))
+	require.NoError(t, cache.IncrDailyRankScore(ctx, 1, 5))
+	require.NoError(t, cache.IncrDailyRankScore(ctx, 2, 3))
+
+	_, err := repo.GetHistoryRank(ctx, 10)
+	require.NoError(t, err)
+	_, err = repo.GetDailyRank(ctx, 0, 10)
+	require.NoError(t, err)
+
+	history, err := mr.ZMembers("article:hot:history:rank")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2"}, history)
+	daily, err := mr.ZMembers("article:hot:daily:rank")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2"}, daily)
+	raw, err := mr.ZMembers("article:hot:daily:raw:" + time.Now().Format("2006010215"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2"}, raw)
+
+	// 之后的请求不再出现已删除的文章
+	rank, err := repo.GetHistoryRank(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{2}, rankIDs(rank))
+}
+
+// namedUserRepo 返回带名字的作者，用来检查写入缓存的文章是否填充了作者信息
+type namedUserRepo struct {
+	domain.UserRepository
+}
+
+func (namedUserRepo) GetByIDs(_ context.Context, ids []int64) ([]domain.User, error) {
+	res := make([]domain.User, len(ids))
+	for i, id := range ids {
+		res[i] = domain.User{ID: id, Name: fmt.Sprintf("author %d", id)}
+	}
+	return res, nil
+}
+
+func TestRankFillCachesAuthorNames(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	cache := myRedis.NewArticleCache(client, "", 0, 0)
+	// 和 MySQL 的 GetByIDs 一样只返回作者 ID
+	db := &rankDB{articles: []domain.Article{
+		{ID: 1, Title: "first", User: domain.User{ID: 7}},
+		{ID: 2, Title: "second", User: domain.User{ID: 8}},
+	}}
+	repo := repository.NewArticleRepository(db, cache, namedUserRepo{}, repository.NewRuntimeSettings(emptySettingsRepo{}), true, nil)
+
+	require.NoError(t, cache.SetHistoryRank(ctx, []int64{1}, []float64{20}))
+	require.NoError(t, cache.IncrDailyRankScore(ctx, 2, 5))
+	_, err := repo.GetHistoryRank(ctx, 10)
+	require.NoError(t, err)
+	_, err = repo.GetDailyRank(ctx, 0, 10)
+	require.NoError(t, err)
+
+	// 热榜回源写入的文章缓存带有作者名，之后命中缓存的请求不会显示空作者
+	cached, err := cache.GetArticleByIDsWithLogicalExpire(ctx, []int64{1, 2})
+	require.NoError(t, err)
+	require.Len(t, cached, 2)
+	for _, ar := range cached {
+		assert.Equal(t, fmt.Sprintf("author %d", ar.User.ID), ar.User.Name, "article %d", ar.ID)
+	}
+
+	rank, err := repo.GetDailyRank(ctx, 0, 10)
+	require.NoError(t, err)
+	require.Len(t, rank, 1)
+	assert.Equal(t, "author 8", rank[0].User.Name)
+}
+
+func TestDeletePurgesRanks(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	cache := myRedis.NewArticleCache(client, "", 0, 0)
+	db := &fakeDB{articles: map[int64]domain.Article{1: {ID: 1, Title: "first"}, 2: {ID: 2, Title: "second"}}}
+	repo := repository.NewArticleRepository(db, cache, fakeUserRepo{}, repository.NewRuntimeSettings(emptySettingsRepo{}), true, nil)
+
+	require.NoError(t, cache.SetHistoryRank(ctx, []int64{1, 2}, []float64{20, 10}))
+	require.NoError(t, cache.IncrDailyRankScore(ctx, 1, 5))
+	require.NoError(t, cache.IncrDailyRankScore(ctx, 2, 3))
+	require.NoError(t, cache.SetLikeCount(ctx, 1, 5))
+	require.NoError(t, cache.SetHomeWithLogicalExpire(ctx, domain.ArticlePage{Articles: []domain.Article{{ID: 1}, {ID: 2}}}, time.Minute))
+
+	require.NoError(t, repo.Delete(ctx, 1))
+	// 首页缓存中也不再有已删除的文章
+	assert.False(t, mr.Exists("article:home"))
+
+	// 删除时就从热榜中移除，不需要等下一次读取热榜时发现文章不存在
+	history, err := mr.ZMembers("article:hot:history:rank")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2"}, history)
+	assert.False(t, mr.Exists("article:likes:1"))
+
+	daily, err := repo.GetDailyRank(ctx, 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{2}, rankIDs(daily))
+}
+
+func TestGetByIDPrefersBufferedLikes(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	cache := myRedis.NewArticleCache(client, "", 0, 0)
+	db := &fakeDB{articles: map[int64]domain.Article{
+		1: {ID: 1, Title: "first", Likes: 10},
+		2: {ID: 2, Title: "second", Likes: 10},
+		3: {ID: 3, Title: "third", Likes: 10},
+	}}
+	repo := repository.NewArticleRepository(db, cache, fakeUserRepo{}, repository.NewRuntimeSettings(emptySettingsRepo{}), true, nil)
+
+	// 文章缓存过期而点赞计数还在：新的点赞和取消都还没有落库
+	require.NoError(t, cache.SetLikeCount(ctx, 1, 12))
+	require.NoError(t, cache.SetLikeCount(ctx, 2, 9))
+
+	for id, want := range map[int64]int64{1: 12, 2: 9, 3: 10} {
+		ar, err := repo.GetByID(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, want, ar.Likes, "article %d", id)
+
+		// 计数没有被数据库中的旧值覆盖，之后命中缓存的请求返回同一个值
+		likes, err := cache.GetLikeCount(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, want, likes, "article %d", id)
+		ar, err = repo.GetByID(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, want, ar.Likes, "article %d", id)
+	}
+}
+
+func TestRedisOutageFallsBackToDB(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr(), MaxRetries: -1})
+	t.Cleanup(func() { _ = client.Close() })
+	breaker := myRedis.NewBreaker(2, time.Minute, time.Second)
+	client.AddHook(breaker)
+
+	cache := myRedis.NewArticleCache(client, "", 0, 0)
+	db := &fakeDB{articles: map[int64]domain.Article{1: {ID: 1, Title: "from db"}}}
+	repo := repository.NewArticleRepository(db, cache, fakeUserRepo{}, repository.NewRuntimeSettings(emptySettingsRepo{}), true, nil)
+
+	mr.Close()
+	// 熔断打开前后，读请求都从数据库返回
+	for i := 0; i < 3; i++ {
+		ar, err := repo.GetByID(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, "from db", ar.Title)
+	}
+	assert.Equal(t, domain.BreakerOpen, breaker.State().State)
+
+	ars, err := repo.GetByIDs(ctx, []int64{1})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1}, rankIDs(ars))
+}
+
+func TestDailyRankKeepsFractionalScore(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	cache := myRedis.NewArticleCache(client, "", 0, 0)
+	db := &rankDB{articles: []domain.Article{{ID: 1, Title: "first", Likes: 3}}}
+	repo := repository.NewArticleRepository(db, cache, fakeUserRepo{}, repository.NewRuntimeSettings(emptySettingsRepo{}), true, nil)
+
+	// 一次点赞加上两次带权重的浏览
+	require.NoError(t, cache.IncrDailyRankScore(ctx, 1, 1))
+	require.NoError(t, cache.IncrDailyRankScore(ctx, 1, 0.25))
+	require.NoError(t, cache.IncrDailyRankScore(ctx, 1, 0.25))
+
+	rank, err := repo.GetDailyRank(ctx, 0, 10)
+	require.NoError(t, err)
+	require.Len(t, rank, 1)
+	assert.Equal(t, 1.5, rank[0].Score)
+	// 点赞数来自文章本身，不会被分数覆盖或截断
+	assert.Equal(t, int64(3), rank[0].Likes)
+}
+
+// flakyRankCache 第一次读今日热榜失败（模拟读取超时），重新聚合后才能读到
+type flakyRankCache struct {
+	domain.ArticleCache
+	aggregated bool
+}
+
+func (c *flakyRankCache) GetDailyRank(context.Context, int64, int64) ([]domain.Article, error) {
+	if !c.aggregated {
+		return nil, context.DeadlineExceeded
+	}
+	return []domain.Article{{ID: 1, Score: 4}}, nil
+}
+
+func (c *flakyRankCache) AggregateDailyRank(context.Context) error {
+	c.aggregated = true
+	return nil
+}
+
+func (c *flakyRankCache) GetArticleByIDsWithLogicalExpire(context.Context, []int64) ([]domain.Article, error) {
+	return nil, nil
+}
+
+func (c *flakyRankCache) GetArticleVersions(context.Context, []int64) (map[int64]int64, error) {
+	return map[int64]int64{}, nil
+}
+
+func (c *flakyRankCache) BatchSetArticleWithLogicalExpire(context.Context, []domain.Article, map[int64]int64, time.Duration) error {
+	return nil
+}
+
+func TestDailyRankRebuildsOnCacheMiss(t *testing.T) {
+	cache := &flakyRankCache{}
+	db := &rankDB{articles: []domain.Article{{ID: 1, Title: "first"}}}
+	repo := repository.NewArticleRepository(db, cache, fakeUserRepo{}, repository.NewRuntimeSettings(emptySettingsRepo{}), true, nil)
+
+	rank, err := repo.GetDailyRank(context.Background(), 0, 10)
+	require.NoError(t, err)
+	assert.True(t, cache.aggregated)
+	require.Len(t, rank, 1)
+	assert.Equal(t, "first", rank[0].Title)
+	assert.Equal(t, float64(4), rank[0].Score)
+}
+
+func TestDailyRankEmptyWhenRedisDown(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr(), MaxRetries: -1})
+	t.Cleanup(func() { _ = client.Close() })
+
+	cache := myRedis.NewArticleCache(client, "", 0, 0)
+	repo := repository.NewArticleRepository(&rankDB{}, cache, fakeUserRepo{}, repository.NewRuntimeSettings(emptySettingsRepo{}), true, nil)
+
+	// 还没有任何小时分桶时返回空榜单
+	rank, err := repo.GetDailyRank(context.Background(), 0, 10)
+	require.NoError(t, err)
+	assert.Empty(t, rank)
+
+	// Redis 不可用时同样返回空榜单，不影响请求
+	mr.Close()
+	rank, err = repo.GetDailyRank(context.Background(), 0, 10)
+	require.NoError(t, err)
+	assert.Empty(t, rank)
+}
+
+func TestSetCommentsLockedPatchesCache(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	cache := myRedis.NewArticleCache(client, "", 0, 0)
+	db := &fakeDB{articles: map[int64]domain.Article{1: {ID: 1, Title: "title"}}}
+	repo := repository.NewArticleRepository(db, cache, fakeUserRepo{}, repository.NewRuntimeSettings(emptySettingsRepo{}), true, nil)
+
+	// 第一次读取后文章进入缓存
+	_, err := repo.GetByIDs(ctx, []int64{1})
+	require.NoError(t, err)
+	require.NoError(t, repo.SetCommentsLocked(ctx, 1, true))
+
+	// fakeDB 不保存锁定状态，读到的结果只能来自被改写的缓存
+	ars, err := repo.GetByIDs(ctx, []int64{1})
+	require.NoError(t, err)
+	require.Len(t, ars, 1)
+	assert.True(t, ars[0].CommentsLocked)
+	assert.Equal(t, "title", ars[0].Title)
+}
+
+func TestExpiredArticleRebuildsInBackgroundInSyncMode(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	cache := myRedis.NewArticleCache(client, "", 0, 0)
+	require.NoError(t, cache.SetArticleWithLogicalExpire(ctx, &domain.Article{ID: 1, Title: "old"}, 0, -time.Minute))
+	// 回源会阻塞到 release 被关闭
+	db := &fakeDB{articles: map[int64]domain.Article{1: {ID: 1, Title: "new"}}, release: make(chan struct{})}
+	repo := repository.NewArticleRepository(db, cache, fakeUserRepo{}, repository.NewRuntimeSettings(emptySettingsRepo{}), true, goRunner{})
+
+	// 同步写入模式下逻辑过期的重建仍然在后台进行，请求直接拿到旧数据
+	done := make(chan domain.Article, 1)
+	go func() {
+		ar, err := repo.GetByID(ctx, 1)
+		assert.NoError(t, err)
+		done <- ar
+	}()
+	select {
+	case ar := <-done:
+		assert.Equal(t, "old", ar.Title)
+	case <-time.After(time.Second):
+		close(db.release)
+		require.FailNow(t, "GetByID waited for the rebuild")
+	}
+
+	close(db.release)
+	assert.Eventually(t, func() bool {
+		ar, expired, err := cache.GetArticleWithLogicalExpire(ctx, 1)
+		return err == nil && !expired && ar.Title == "new"
+	}, time.Second, 10*time.Millisecond)
+}
+
+// fullRunner 模拟队列已满，所有任务都被丢弃
+type fullRunner struct{ goRunner }
+
+func (fullRunner) Submit(func(ctx context.Context)) bool { return false }
+
+func TestInvalidationRunsInlineWhenQueueIsFull(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	cache := myRedis.NewArticleCache(client, "", 0, 0)
+	db := &fakeDB{articles: map[int64]domain.Article{1: {ID: 1, Title: "title"}}}
+	settings := repository.NewRuntimeSettings(emptySettingsRepo{})
+	// 先用同步写入把文章放进缓存
+	_, err := repository.NewArticleRepository(db, cache, fakeUserRepo{}, settings, true, nil).GetByIDs(ctx, []int64{1})
+	require.NoError(t, err)
+	_, _, err = cache.GetArticleWithLogicalExpire(ctx, 1)
+	require.NoError(t, err)
+
+	repo := repository.NewArticleRepository(db, cache, fakeUserRepo{}, settings, false, fullRunner{})
+	require.NoError(t, repo.Delete(ctx, 1))
+
+	// 队列满时删除缓存不能被丢弃，已删除的文章不会留在缓存中
+	_, _, err = cache.GetArticleWithLogicalExpire(ctx, 1)
+	assert.ErrorIs(t, err, redis.Nil)
+}
+
+func TestSetHiddenInvalidatesHomeAndRanks(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	cache := myRedis.NewArticleCache(client, "", 0, 0)
+	db := &fakeDB{articles: map[int64]domain.Article{1: {ID: 1, Title: "spam"}, 2: {ID: 2, Title: "ok"}}}
+	repo := repository.NewArticleRepository(db, cache, fakeUserRepo{}, repository.NewRuntimeSettings(emptySettingsRepo{}), true, nil)
+
+	// 文章 1 在首页缓存和历史热榜中
+	home := domain.ArticlePage{Articles: []domain.Article{{ID: 1, Title: "spam"}, {ID: 2, Title: "ok"}}}
+	require.NoError(t, cache.SetHomeWithLogicalExpire(ctx, home, time.Hour))
+	_, err := mr.ZAdd(myRedis.KeyHotHistoryRank, 10, "1")
+	require.NoError(t, err)
+	_, err = mr.ZAdd(myRedis.KeyHotHistoryRank, 5, "2")
+	require.NoError(t, err)
+
+	require.NoError(t, repo.SetHidden(ctx, 1, true))
+
+	_, _, err = cache.GetHomeWithLogicalExpire(ctx)
+	assert.Error(t, err, "the home cache is dropped")
+	members, err := mr.ZMembers(myRedis.KeyHotHistoryRank)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2"}, members)
+
+	// 取消隐藏同样让首页缓存失效，文章重新出现在列表中
+	require.NoError(t, cache.SetHomeWithLogicalExpire(ctx, domain.ArticlePage{Articles: home.Articles[1:]}, time.Hour))
+	require.NoError(t, repo.SetHidden(ctx, 1, false))
+	_, _, err = cache.GetHomeWithLogicalExpire(ctx)
+	assert.Error(t, err)
+}
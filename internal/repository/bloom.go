@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+)
+
+// noopBloomRepository 关闭布隆过滤器时使用，所有ID都视为可能存在（fail-open）
+type noopBloomRepository struct{}
+
+var _ domain.BloomRepository = noopBloomRepository{}
+
+// NewNoopBloomRepository 创建不依赖 Redis 的空布隆过滤器
+func NewNoopBloomRepository() domain.BloomRepository {
+	return noopBloomRepository{}
+}
+
+func (noopBloomRepository) Add(context.Context, int64) error {
+	return nil
+}
+
+func (noopBloomRepository) Exists(context.Context, int64) (bool, error) {
+	return true, nil
+}
+
+func (noopBloomRepository) ExistsBatch(_ context.Context, ids []int64) (map[int64]bool, error) {
+	res := make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		res[id] = true
+	}
+	return res, nil
+}
+
+func (noopBloomRepository) BulkAdd(context.Context, []int64) error {
+	return nil
+}
+
+// toggleBloomRepository 根据运行时配置决定是否使用布隆过滤器。
+// 关闭期间仍然写入，保证重新打开后不会误判新文章不存在
+type toggleBloomRepository struct {
+	domain.BloomRepository
+	settings domain.Settings
+}
+
+// NewToggleBloomRepository 包装 inner，关闭时 Exists 总是返回 true（fail-open）
+func NewToggleBloomRepository(inner domain.BloomRepository, settings domain.Settings) domain.BloomRepository {
+	return &toggleBloomRepository{
+		BloomRepository: inner,
+		settings:        settings,
+	}
+}
+
+func (r *toggleBloomRepository) Exists(ctx context.Context, id int64) (bool, error) {
+	if !r.settings.BloomEnabled() {
+		return true, nil
+	}
+	return r.BloomRepository.Exists(ctx, id)
+}
+
+func (r *toggleBloomRepository) ExistsBatch(ctx context.Context, ids []int64) (map[int64]bool, error) {
+	if !r.settings.BloomEnabled() {
+		return noopBloomRepository{}.ExistsBatch(ctx, ids)
+	}
+	return r.BloomRepository.ExistsBatch(ctx, ids)
+}
@@ -0,0 +1,29 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/repository"
+)
+
+func TestNoopBloomRepositoryAlwaysExists(t *testing.T) {
+	bloom := repository.NewNoopBloomRepository()
+	ctx := context.Background()
+
+	require.NoError(t, bloom.Add(ctx, 1))
+	require.NoError(t, bloom.BulkAdd(ctx, []int64{2, 3}))
+
+	for _, id := range []int64{1, 2, 42, -1} {
+		exists, err := bloom.Exists(ctx, id)
+		require.NoError(t, err)
+		assert.True(t, exists, "id %d", id)
+	}
+
+	got, err := bloom.ExistsBatch(ctx, []int64{1, 42})
+	require.NoError(t, err)
+	assert.Equal(t, map[int64]bool{1: true, 42: true}, got)
+}
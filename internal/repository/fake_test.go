@@ -0,0 +1,351 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/repository"
+)
+
+var errDB = errors.New("db is down")
+
+// 测试用的内存实现，只实现用到的方法，其余方法由内嵌接口兜底（调用即 panic）
+
+type fakeDB struct {
+	domain.ArticleDBRepository
+	articles map[int64]domain.Article
+	err      error
+	release  chan struct{} // 不为 nil 时 GetByID 阻塞到它被关闭
+
+	mu       sync.Mutex
+	getByIDs int // GetByID 被调用的次数，即回源次数
+}
+
+func (f *fakeDB) GetByID(_ context.Context, id int64) (domain.Article, error) {
+	f.mu.Lock()
+	f.getByIDs++
+	f.mu.Unlock()
+	if f.release != nil {
+		<-f.release
+	}
+	ar, ok := f.articles[id]
+	if !ok {
+		return domain.Article{}, domain.ErrNotFound
+	}
+	return ar, nil
+}
+
+func (f *fakeDB) Update(_ context.Context, ar *domain.Article) (map[string]any, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	old := f.articles[ar.ID]
+	changed := make(map[string]any)
+	if ar.Title != old.Title {
+		old.Title = ar.Title
+		changed["Title"] = ar.Title
+	}
+	f.articles[ar.ID] = old
+	return changed, nil
+}
+
+func (f *fakeDB) Store(_ context.Context, ar *domain.Article) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	ar.ID = int64(len(f.articles) + 1)
+	f.articles[ar.ID] = *ar
+	return nil
+}
+
+func (f *fakeDB) Delete(_ context.Context, id int64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.articles, id)
+	return nil
+}
+
+func (f *fakeDB) SetHidden(_ context.Context, id int64, hidden bool) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	ar := f.articles[id]
+	ar.Hidden = hidden
+	f.articles[id] = ar
+	return nil
+}
+
+func (f *fakeDB) SetCommentsLocked(context.Context, int64, bool) error {
+	return nil
+}
+
+func (f *fakeDB) rebuilds() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.getByIDs
+}
+
+func (f *fakeDB) GetByIDs(_ context.Context, ids []int64) ([]domain.Article, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	var res []domain.Article
+	for _, id := range ids {
+		if ar, ok := f.articles[id]; ok {
+			res = append(res, ar)
+		}
+	}
+	return res, nil
+}
+
+type fakeCache struct {
+	domain.ArticleCache
+	history  []domain.Article
+	patchErr error
+	expired  bool // 缓存的文章是否逻辑过期
+
+	mu          sync.Mutex
+	articles    map[int64]domain.Article
+	homeDeleted int
+}
+
+func (f *fakeCache) DeleteHome(context.Context) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.homeDeleted++
+	return nil
+}
+
+func (f *fakeCache) homeDeletes() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.homeDeleted
+}
+
+func (f *fakeCache) GetArticleWithLogicalExpire(_ context.Context, id int64) (domain.Article, bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	ar, ok := f.articles[id]
+	if !ok {
+		return domain.Article{}, false, domain.ErrCacheMiss
+	}
+	return ar, f.expired, nil
+}
+
+// GetArticleVersions 不模拟版本号，Set 方法总是写入
+func (f *fakeCache) GetArticleVersions(context.Context, []int64) (map[int64]int64, error) {
+	return map[int64]int64{}, nil
+}
+
+func (f *fakeCache) SetArticleWithLogicalExpire(_ context.Context, ar *domain.Article, _ int64, _ time.Duration) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.articles[ar.ID] = *ar
+	return nil
+}
+
+func (f *fakeCache) PatchArticle(_ context.Context, id int64, fields map[string]any) error {
+	if f.patchErr != nil {
+		return f.patchErr
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	ar, ok := f.articles[id]
+	if !ok {
+		return nil
+	}
+	if title, ok := fields["Title"].(string); ok {
+		ar.Title = title
+	}
+	f.articles[id] = ar
+	return nil
+}
+
+func (f *fakeCache) DeleteArticle(_ context.Context, id int64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.articles, id)
+	return nil
+}
+
+func (f *fakeCache) PurgeArticle(ctx context.Context, id int64) error {
+	return f.DeleteArticle(ctx, id)
+}
+
+func (f *fakeCache) cached(id int64) (domain.Article, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	ar, ok := f.articles[id]
+	return ar, ok
+}
+
+func (f *fakeCache) IncrViews(context.Context, int64) (int64, error) { return 0, nil }
+
+func (f *fakeCache) GetLikeCount(_ context.Context, id int64) (int64, error) {
+	return 0, domain.ErrCacheMiss
+}
+
+func (f *fakeCache) SetLikeCount(context.Context, int64, int64) error { return nil }
+
+func (f *fakeCache) InitLikeCount(_ context.Context, _ int64, likes int64) (int64, error) {
+	return likes, nil
+}
+
+func (f *fakeCache) GetArticleByIDsWithLogicalExpire(_ context.Context, ids []int64) ([]domain.Article, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var res []domain.Article
+	for _, id := range ids {
+		if ar, ok := f.articles[id]; ok {
+			res = append(res, ar)
+		}
+	}
+	return res, nil
+}
+
+func (f *fakeCache) GetHistoryRank(_ context.Context, limit int64) ([]domain.Article, error) {
+	if int64(len(f.history)) > limit {
+		return f.history[:limit], nil
+	}
+	return f.history, nil
+}
+
+type fakeUserRepo struct {
+	domain.UserRepository
+}
+
+func (fakeUserRepo) GetByID(_ context.Context, id int64) (domain.User, error) {
+	return domain.User{ID: id}, nil
+}
+
+func (fakeUserRepo) GetByIDs(_ context.Context, ids []int64) ([]domain.User, error) {
+	res := make([]domain.User, len(ids))
+	for i, id := range ids {
+		res[i] = domain.User{ID: id}
+	}
+	return res, nil
+}
+
+func (f *fakeCache) BatchSetArticleWithLogicalExpire(_ context.Context, ars []domain.Article, _ map[int64]int64, _ time.Duration) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, ar := range ars {
+		f.articles[ar.ID] = ar
+	}
+	return nil
+}
+
+// goRunner 每个后台任务起一个 goroutine，不限制并发
+type goRunner struct{}
+
+func (goRunner) Submit(task func(ctx context.Context)) bool {
+	go task(context.Background())
+	return true
+}
+
+func (goRunner) QueueDepth() int { return 0 }
+
+func (goRunner) Dropped() int64 { return 0 }
+
+// emptySettingsRepo 没有任何配置，运行时配置全部使用默认值
+type emptySettingsRepo struct{}
+
+func (emptySettingsRepo) GetAll(context.Context) (map[string]string, error) {
+	return map[string]string{}, nil
+}
+
+func (emptySettingsRepo) SetMany(context.Context, map[string]string) error {
+	return nil
+}
+
+// newArticleRepo 使用默认运行时配置创建协调层
+func newArticleRepo(db domain.ArticleDBRepository, cache domain.ArticleCache) domain.ArticleRepository {
+	return repository.NewArticleRepository(db, cache, fakeUserRepo{}, repository.NewRuntimeSettings(emptySettingsRepo{}), false, goRunner{})
+}
+
+// rankDB 按点赞数返回预置的文章，release 不为 nil 时查询会阻塞到它被关闭
+type rankDB struct {
+	domain.ArticleDBRepository
+	articles []domain.Article
+	release  chan struct{}
+
+	mu    sync.Mutex
+	calls int // FetchArticlesByLikes 被调用的次数
+}
+
+func (f *rankDB) FetchArticlesByLikes(_ context.Context, limit int64) ([]domain.Article, error) {
+	f.mu.Lock()
+	f.calls++
+	f.mu.Unlock()
+	if f.release != nil {
+		<-f.release
+	}
+	res := append([]domain.Article(nil), f.articles...)
+	if int64(len(res)) > limit {
+		res = res[:limit]
+	}
+	return res, nil
+}
+
+func (f *rankDB) GetByIDs(_ context.Context, ids []int64) ([]domain.Article, error) {
+	var res []domain.Article
+	for _, id := range ids {
+		for _, ar := range f.articles {
+			if ar.ID == id {
+				res = append(res, ar)
+			}
+		}
+	}
+	return res, nil
+}
+
+func (f *rankDB) fetches() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls
+}
+
+// onceDeduper 每个 key 只执行一次 fn，之后的调用直接共享第一次的结果。
+// 与 singleflight 不同，它不依赖调用是否真正重叠，可以确定性地断言去重
+type onceDeduper struct {
+	mu      sync.Mutex
+	results map[string]onceResult
+	calls   map[string]int
+}
+
+type onceResult struct {
+	v   any
+	err error
+}
+
+func newOnceDeduper() *onceDeduper {
+	return &onceDeduper{results: make(map[string]onceResult), calls: make(map[string]int)}
+}
+
+func (d *onceDeduper) Do(key string, fn func() (any, error)) (any, error, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.calls[key]++
+	if res, ok := d.results[key]; ok {
+		return res.v, res.err, true
+	}
+	v, err := fn()
+	d.results[key] = onceResult{v, err}
+	return v, err, false
+}
+
+func (d *onceDeduper) callsOf(key string) int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.calls[key]
+}
+
+// missCache 读取文章时总是未命中，写入照常
+type missCache struct {
+	*fakeCache
+}
+
+func (missCache) GetArticleWithLogicalExpire(context.Context, int64) (domain.Article, bool, error) {
+	return domain.Article{}, false, domain.ErrCacheMiss
+}
@@ -2,6 +2,8 @@ package repository
 
 import (
 	"encoding/base64"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -11,24 +13,35 @@ const (
 	MinPageSize = 10
 )
 
-// DecodeCursor will decode cursor from user for mysql
-func DecodeCursor(encodedTime string) (time.Time, error) {
-	byt, err := base64.StdEncoding.DecodeString(encodedTime)
+// DecodeCursor will decode cursor from user for mysql.
+// 游标是 (created_at, id)，旧版只有时间的游标仍然可以解码，id 为 0
+func DecodeCursor(cursor string) (time.Time, int64, error) {
+	byt, err := base64.StdEncoding.DecodeString(cursor)
 	if err != nil {
-		return time.Time{}, err
+		return time.Time{}, 0, err
 	}
 
-	timeString := string(byt)
+	timeString, idString, hasID := strings.Cut(string(byt), ",")
 	t, err := time.Parse(timeFormat, timeString)
-
-	return t, err
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+	if !hasID {
+		return t, 0, nil
+	}
+	id, err := strconv.ParseInt(idString, 10, 64)
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+	return t, id, nil
 }
 
-// EncodeCursor will encode cursor from mysql to user
-func EncodeCursor(t time.Time) string {
-	timeString := t.Format(timeFormat)
+// EncodeCursor will encode cursor from mysql to user.
+// 同一秒内创建的多行靠 id 区分先后，翻页时不会重复或跳过
+func EncodeCursor(t time.Time, id int64) string {
+	s := t.Format(timeFormat) + "," + strconv.FormatInt(id, 10)
 
-	return base64.StdEncoding.EncodeToString([]byte(timeString))
+	return base64.StdEncoding.EncodeToString([]byte(s))
 }
 
 // PageVerify 分页查询 过滤器
@@ -40,3 +53,16 @@ func PageVerify(pageSize *int64) {
 		*pageSize = MinPageSize
 	}
 }
+
+// ReachedMilestone 返回计数从 before 变为 after 时跨过的最大的 step 的整数倍，没有跨过或者计数减少时返回 0。
+// 一次跨过多个里程碑时只返回最大的一个，同一个里程碑是否已经记录过由调用方保证
+func ReachedMilestone(before, after, step int64) int64 {
+	if step <= 0 || after <= before {
+		return 0
+	}
+	reached := after / step * step
+	if reached <= before {
+		return 0
+	}
+	return reached
+}
@@ -0,0 +1,62 @@
+package repository_test
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/repository"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	createdAt := time.Date(2024, 5, 1, 8, 30, 0, 0, time.UTC)
+
+	ts, id, err := repository.DecodeCursor(repository.EncodeCursor(createdAt, 42))
+	require.NoError(t, err)
+	assert.True(t, createdAt.Equal(ts))
+	assert.Equal(t, int64(42), id)
+}
+
+func TestDecodeLegacyCursor(t *testing.T) {
+	// 旧版游标只有时间，id 按 0 处理，已经发出去的游标仍然可用
+	legacy := base64.StdEncoding.EncodeToString([]byte("2024-05-01T08:30:00Z"))
+
+	ts, id, err := repository.DecodeCursor(legacy)
+	require.NoError(t, err)
+	assert.True(t, time.Date(2024, 5, 1, 8, 30, 0, 0, time.UTC).Equal(ts))
+	assert.Zero(t, id)
+}
+
+func TestDecodeCursorRejectsBadID(t *testing.T) {
+	for _, raw := range []string{"2024-05-01T08:30:00Z,abc", "not a time,1"} {
+		_, _, err := repository.DecodeCursor(base64.StdEncoding.EncodeToString([]byte(raw)))
+		assert.Error(t, err, raw)
+	}
+	_, _, err := repository.DecodeCursor("%%%")
+	assert.Error(t, err)
+}
+
+func TestReachedMilestone(t *testing.T) {
+	cases := []struct {
+		name          string
+		before, after int64
+		want          int64
+	}{
+		{"below the first milestone", 10, 49, 0},
+		{"exactly reaching it", 49, 50, 50},
+		{"crossing it", 48, 52, 50},
+		{"already past it", 50, 99, 0},
+		{"crossing several at once keeps the highest", 40, 160, 150},
+		{"decreasing", 60, 40, 0},
+		{"unchanged on a milestone", 100, 100, 0},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.want, repository.ReachedMilestone(c.before, c.after, 50), c.name)
+	}
+	// 点赞数回落后再次到达同一个里程碑仍然会返回，由唯一索引保证只记录一次
+	assert.Equal(t, int64(50), repository.ReachedMilestone(49, 50, 50))
+	assert.Zero(t, repository.ReachedMilestone(0, 10, 0))
+}
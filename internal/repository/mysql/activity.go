@@ -0,0 +1,77 @@
+package mysql
+
+import (
+	"context"
+	"strconv"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/repository"
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/repository/mysql/model"
+)
+
+type activityRepository struct {
+	DB *gorm.DB
+}
+
+var _ domain.ActivityRepository = (*activityRepository)(nil)
+
+// NewActivityRepository 创建作者动态的数据库操作层
+func NewActivityRepository(db *gorm.DB) *activityRepository {
+	return &activityRepository{db}
+}
+
+// FetchByUser 按 id 倒序分页读取作者动态，关联文章取标题，已删除、隐藏和草稿状态的文章的动态不返回
+func (m *activityRepository) FetchByUser(ctx context.Context, userID int64, cursor string, num int64) (res []domain.Activity, hasMore bool, err error) {
+	var lastID int64
+	if cursor != "" {
+		lastID, err = strconv.ParseInt(cursor, 10, 64)
+		if err != nil {
+			return nil, false, domain.ErrBadParamInput
+		}
+	}
+
+	repository.PageVerify(&num)
+	q := m.DB.WithContext(ctx).
+		Select("activity.id, activity.user_id, activity.article_id, activity.type, activity.milestone, activity.created_at, article.title AS article_title").
+		Joins("JOIN article ON article.id = activity.article_id AND article.deleted_at IS NULL AND article.hidden = ? AND article.status = ?", false, published).
+		Where("activity.user_id = ?", userID)
+	if lastID > 0 {
+		q = q.Where("activity.id < ?", lastID)
+	}
+
+	var rows []model.Activity
+	if err = q.Order("activity.id DESC").Limit(int(num) + 1).Find(&rows).Error; err != nil {
+		return nil, false, err
+	}
+
+	if int64(len(rows)) > num {
+		rows = rows[:num]
+		hasMore = true
+	}
+	for _, row := range rows {
+		res = append(res, row.ToDomain())
+	}
+	return res, hasMore, nil
+}
+
+// recordActivity 在调用方的事务中写入一条动态，同一个 (article_id, type, milestone) 已存在时什么都不做
+func recordActivity(tx *gorm.DB, userID, articleID int64, typ domain.ActivityType, milestone int64) error {
+	return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&model.Activity{
+		UserID:    userID,
+		ArticleID: articleID,
+		Type:      string(typ),
+		Milestone: milestone,
+	}).Error
+}
+
+// recordMilestone 计数从 before 变为 after 时跨过了 step 的整数倍才写入动态
+func recordMilestone(tx *gorm.DB, userID, articleID int64, typ domain.ActivityType, before, after, step int64) error {
+	reached := repository.ReachedMilestone(before, after, step)
+	if reached == 0 {
+		return nil
+	}
+	return recordActivity(tx, userID, articleID, typ, reached)
+}
@@ -0,0 +1,136 @@
+package mysql_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/repository/mysql"
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/repository/mysql/model"
+)
+
+// captureActivity 记录写入 activity 表的每一行
+func captureActivity(t *testing.T, db *gorm.DB) *[]model.Activity {
+	t.Helper()
+	rows := new([]model.Activity)
+	require.NoError(t, db.Callback().Create().After("gorm:create").Register("test:activity", func(tx *gorm.DB) {
+		if row, ok := tx.Statement.Dest.(*model.Activity); ok {
+			assert.Contains(t, tx.Statement.SQL.String(), "ON DUPLICATE KEY UPDATE", "milestones are recorded only once")
+			// created_at 由 GORM 填入当前时间，比较时忽略
+			r := *row
+			r.CreatedAt = time.Time{}
+			*rows = append(*rows, r)
+		}
+	}))
+	return rows
+}
+
+func TestFetchActivityByUser(t *testing.T) {
+	db, sqls := newDryRunDB(t)
+	var vars []any
+	require.NoError(t, db.Callback().Query().After("gorm:query").Register("test:vars", func(tx *gorm.DB) {
+		vars = tx.Statement.Vars
+	}))
+	repo := mysql.NewActivityRepository(db)
+
+	_, _, err := repo.FetchByUser(context.Background(), 7, "42", 10)
+	require.NoError(t, err)
+
+	require.Len(t, *sqls, 1)
+	sql := (*sqls)[0]
+	// 已删除、隐藏和草稿状态的文章的动态不返回
+	assert.Contains(t, sql, "JOIN article ON article.id = activity.article_id AND article.deleted_at IS NULL AND article.hidden = ? AND article.status = ?")
+	assert.Contains(t, sql, "article.title AS article_title")
+	assert.Contains(t, sql, "WHERE activity.user_id = ? AND activity.id < ?")
+	assert.Contains(t, sql, "ORDER BY activity.id DESC LIMIT ?")
+	assert.Equal(t, []any{false, "published", int64(7), int64(42), 11}, vars)
+}
+
+func TestFetchActivityRejectsBadCursor(t *testing.T) {
+	db, sqls := newDryRunDB(t)
+	repo := mysql.NewActivityRepository(db)
+
+	_, _, err := repo.FetchByUser(context.Background(), 7, "abc", 10)
+	assert.ErrorIs(t, err, domain.ErrBadParamInput)
+	assert.Empty(t, *sqls)
+}
+
+func TestApplyLikeChangesRecordsLikesMilestone(t *testing.T) {
+	cases := []struct {
+		name     string
+		stored   int64
+		count    int64
+		expected []model.Activity
+	}{
+		{"crossing 50", 49, 50, []model.Activity{{UserID: 9, ArticleID: 1, Type: "likes_milestone", Milestone: 50}}},
+		{"already past 50", 50, 51, nil},
+		{"dropping below 50", 50, 49, nil},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			db, _ := newDryRunDB(t)
+			rows := captureActivity(t, db)
+			// 文章 1 同步前存了 stored 个赞，同步后 user_likes 中有 count 行
+			require.NoError(t, db.Callback().Query().After("gorm:query").Register("test:likes", func(tx *gorm.DB) {
+				switch dest := tx.Statement.Dest.(type) {
+				case *[]model.Article:
+					*dest = []model.Article{{ID: 1, UserID: 9, Likes: tc.stored}}
+				case *int64:
+					*dest, tx.RowsAffected = tc.count, 1
+				}
+			}))
+			repo := mysql.NewArticleDBRepository(db, false)
+
+			err := repo.ApplyLikeChanges(context.Background(), domain.LikeStateChanges{
+				ToAdd: []domain.UserLike{{ArticleID: 1, UserID: 2}},
+			})
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, *rows)
+		})
+	}
+}
+
+func TestAddViewsRecordsViewsMilestone(t *testing.T) {
+	db, _ := newDryRunDB(t)
+	rows := captureActivity(t, db)
+	require.NoError(t, db.Callback().Update().After("gorm:update").Register("test:affected", func(tx *gorm.DB) {
+		tx.RowsAffected = 1
+	}))
+	// 加上 30 次浏览后是 1010，跨过了 1000
+	require.NoError(t, db.Callback().Query().After("gorm:query").Register("test:views", func(tx *gorm.DB) {
+		if ar, ok := tx.Statement.Dest.(*model.Article); ok {
+			*ar = model.Article{ID: 1, UserID: 9, Views: 1010}
+		}
+	}))
+	repo := mysql.NewArticleDBRepository(db, false)
+
+	require.NoError(t, repo.AddViews(context.Background(), 1, 30))
+	assert.Equal(t, []model.Activity{{UserID: 9, ArticleID: 1, Type: "views_milestone", Milestone: 1000}}, *rows)
+
+	// 下一批没有跨过新的里程碑
+	*rows = nil
+	require.NoError(t, repo.AddViews(context.Background(), 1, 5))
+	assert.Empty(t, *rows)
+}
+
+func TestPublishRecordsActivity(t *testing.T) {
+	db, _ := newDryRunDB(t)
+	rows := captureActivity(t, db)
+	require.NoError(t, db.Callback().Update().After("gorm:update").Register("test:affected", func(tx *gorm.DB) {
+		tx.RowsAffected = 1
+	}))
+	require.NoError(t, db.Callback().Query().After("gorm:query").Register("test:author", func(tx *gorm.DB) {
+		if ar, ok := tx.Statement.Dest.(*model.Article); ok {
+			*ar = model.Article{ID: 5, UserID: 9}
+		}
+	}))
+	repo := mysql.NewArticleDBRepository(db, false)
+
+	require.NoError(t, repo.Publish(context.Background(), 5))
+	assert.Equal(t, []model.Activity{{UserID: 9, ArticleID: 5, Type: "published"}}, *rows)
+}
@@ -2,6 +2,8 @@ package mysql
 
 import (
 	"context"
+	"errors"
+	"strconv"
 
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
@@ -12,30 +14,50 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+// articleListColumns 列表查询需要的列，不包含体积较大的 content
+const articleListColumns = "id, title, summary, summary_is_auto, user_id, updated_at, created_at, views, likes, edited, edit_count, hidden, comments_locked, language, status"
+
+// published 读者只能看到已发布且没有被隐藏的文章，草稿只通过 GetDraft、FetchDrafts 读取
+const published = string(domain.ArticleStatusPublished)
+
 type articleRepository struct {
 	DB *gorm.DB
+	// listColumns Fetch 查询的列，配置列表包含正文时额外查询 content
+	listColumns string
 }
 
 // mysql层只负责数据库操作
 var _ domain.ArticleDBRepository = (*articleRepository)(nil)
 
-// NewArticleDBRepository 创建数据库操作层
-func NewArticleDBRepository(db *gorm.DB) *articleRepository {
-	return &articleRepository{db}
+// NewArticleDBRepository 创建数据库操作层，listIncludeContent 控制文章列表是否查询正文
+func NewArticleDBRepository(db *gorm.DB, listIncludeContent bool) *articleRepository {
+	listColumns := articleListColumns
+	if listIncludeContent {
+		listColumns += ", content"
+	}
+	return &articleRepository{DB: db, listColumns: listColumns}
 }
 
-func (m *articleRepository) Fetch(ctx context.Context, cursor string, num int64) (res []domain.Article, err error) {
+// Fetch 多读一行判断后面是否还有文章，返回时去掉多读的一行
+func (m *articleRepository) Fetch(ctx context.Context, cursor string, num int64, lang, tag string) (res []domain.Article, hasMore bool, err error) {
 	var articles []model.Article
-	decodedCursor, err := repository.DecodeCursor(cursor)
+	createdAt, lastID, err := repository.DecodeCursor(cursor)
 	if err != nil && cursor != "" {
-		return nil, domain.ErrBadParamInput
+		return nil, false, domain.ErrBadParamInput
 	}
 
 	repository.PageVerify(&num)
-	err = m.DB.WithContext(ctx).Select("id, title, user_id, updated_at, created_at, views, likes").
-		Where("created_at > ?", decodedCursor).
-		Order("created_at").
-		Limit(int(num)).
+	query := m.DB.WithContext(ctx).Select(m.listColumns).
+		Where("(created_at, id) > (?, ?) AND hidden = ? AND status = ?", createdAt, lastID, false, published)
+	if lang != "" {
+		query = query.Where("language = ?", lang)
+	}
+	if tag != "" {
+		query = query.Where("id IN (?)", m.taggedWith(tag))
+	}
+	err = query.
+		Order("created_at, id").
+		Limit(int(num) + 1).
 		Find(&articles).
 		Error
 
@@ -43,21 +65,65 @@ func (m *articleRepository) Fetch(ctx context.Context, cursor string, num int64)
 		return
 	}
 
+	if int64(len(articles)) > num {
+		articles = articles[:num]
+		hasMore = true
+	}
 	for _, article := range articles {
 		res = append(res, article.ToDomain())
 	}
-
+	err = loadTags(m.DB.WithContext(ctx), res)
 	return
 }
 
+// Search 在标题和正文中按子串匹配，按 id 倒序做 keyset 分页，同样多读一行判断是否还有结果
+func (m *articleRepository) Search(ctx context.Context, query, cursor string, num int64) (res []domain.Article, hasMore bool, err error) {
+	var lastID int64
+	if cursor != "" {
+		lastID, err = strconv.ParseInt(cursor, 10, 64)
+		if err != nil {
+			return nil, false, domain.ErrBadParamInput
+		}
+	}
+
+	repository.PageVerify(&num)
+	pattern := "%" + escapeLike(query) + "%"
+	q := m.DB.WithContext(ctx).Select(m.listColumns).
+		Where("hidden = ? AND status = ?", false, published).
+		Where("title LIKE ? OR content LIKE ?", pattern, pattern)
+	if lastID > 0 {
+		q = q.Where("id < ?", lastID)
+	}
+
+	var articles []model.Article
+	if err = q.Order("id DESC").Limit(int(num) + 1).Find(&articles).Error; err != nil {
+		return nil, false, err
+	}
+
+	if int64(len(articles)) > num {
+		articles = articles[:num]
+		hasMore = true
+	}
+	for _, article := range articles {
+		res = append(res, article.ToDomain())
+	}
+	if err = loadTags(m.DB.WithContext(ctx), res); err != nil {
+		return nil, false, err
+	}
+	return res, hasMore, nil
+}
+
 func (m *articleRepository) GetByID(ctx context.Context, id int64) (res domain.Article, err error) {
 	var article model.Article
-	err = m.DB.WithContext(ctx).First(&article, "id = ?", id).Error
+	err = m.DB.WithContext(ctx).First(&article, "id = ? AND hidden = ? AND status = ?", id, false, published).Error
 	if err != nil {
 		return res, domain.ErrNotFound
 	}
-	res = article.ToDomain()
-	return
+	ars := []domain.Article{article.ToDomain()}
+	if err = loadTags(m.DB.WithContext(ctx), ars); err != nil {
+		return res, err
+	}
+	return ars[0], nil
 }
 
 func (m *articleRepository) GetByTitle(ctx context.Context, title string) (res domain.Article, err error) {
@@ -70,11 +136,37 @@ func (m *articleRepository) GetByTitle(ctx context.Context, title string) (res d
 	return
 }
 
+// GetForComment 读取评论需要的字段，不限发布状态：作者分享草稿链接后其他人同样可以评论。隐藏的文章不接受评论
+func (m *articleRepository) GetForComment(ctx context.Context, id int64) (domain.Article, error) {
+	var article model.Article
+	err := m.DB.WithContext(ctx).
+		Select("id, user_id, status, hidden, comments_locked").
+		First(&article, "id = ? AND hidden = ?", id, false).Error
+	if err != nil {
+		return domain.Article{}, domain.ErrNotFound
+	}
+	return article.ToDomain(), nil
+}
+
+// Store 创建文章并在同一事务中写入标签，created_at 和 updated_at 为零值时由 GORM 填入当前时间。
+// 直接发布的文章同时记录一条发布动态，草稿在 Publish 时才记录
 func (m *articleRepository) Store(ctx context.Context, a *domain.Article) (err error) {
-	articleModel := model.NewArticleFromDomain(a)
-	result := m.DB.WithContext(ctx).Create(&articleModel)
-	if result.Error != nil {
-		return result.Error
+	articleModel := model.NewArticleForCreate(a)
+	err = m.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(articleModel).Error; err != nil {
+			return err
+		}
+		if err := saveTags(tx, articleModel.ID, a.Tags, false); err != nil {
+			return err
+		}
+		// status 为空时数据库默认为已发布
+		if articleModel.Status == draft {
+			return nil
+		}
+		return recordActivity(tx, articleModel.UserID, articleModel.ID, domain.ActivityPublished, 0)
+	})
+	if err != nil {
+		return err
 	}
 	a.ID = articleModel.ID
 	a.CreatedAt = articleModel.CreatedAt
@@ -82,48 +174,148 @@ func (m *articleRepository) Store(ctx context.Context, a *domain.Article) (err e
 	return
 }
 
+// FindByFingerprint 按 id 顺序返回正文指纹相同的文章，隐藏的也算，只查 id 和 user_id
+func (m *articleRepository) FindByFingerprint(ctx context.Context, fp string) ([]domain.Article, error) {
+	var articles []model.Article
+	err := m.DB.WithContext(ctx).
+		Select("id, user_id").
+		Where("fingerprint = ?", fp).
+		Order("id").
+		Limit(domain.MaxFingerprintMatches).
+		Find(&articles).Error
+	if err != nil {
+		return nil, err
+	}
+	res := make([]domain.Article, len(articles))
+	for i := range articles {
+		res[i] = domain.Article{ID: articles[i].ID, User: domain.User{ID: articles[i].UserID}}
+	}
+	return res, nil
+}
+
+// Delete 软删除文章，只写入 deleted_at。标签、评论和表情回应都保留，恢复后原样可见
 func (m *articleRepository) Delete(ctx context.Context, id int64) error {
 	result := m.DB.WithContext(ctx).Delete(&model.Article{}, id)
-
 	if result.Error != nil {
 		return result.Error
 	}
-
 	if result.RowsAffected == 0 {
 		return domain.ErrNotFound
 	}
-
 	return nil
 }
 
-func (m *articleRepository) Update(ctx context.Context, ar *domain.Article) (err error) {
-	articleModel := model.NewArticleFromDomain(ar)
-	result := m.DB.WithContext(ctx).Model(&articleModel).Updates(&articleModel)
-	if result.Error != nil {
-		return result.Error
-	}
+func (m *articleRepository) Update(ctx context.Context, ar *domain.Article) (changed map[string]any, err error) {
+	err = m.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 写入前先读出旧值，用于判断是否是实质性的编辑
+		var old model.Article
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id, title, content, summary, summary_is_auto, edited, edit_count, language").
+			First(&old, "id = ?", ar.ID).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return domain.ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
 
-	if result.RowsAffected == 0 {
-		return domain.ErrNotFound
-	}
+		articleModel := model.NewArticleForUpdate(ar)
+		articleModel.MarkEditedFrom(&old)
+		articleModel.KeepSummaryFrom(&old)
+		articleModel.UpdatedAt = tx.NowFunc()
+		// 按列名显式更新，调用方传入的计数器和时间戳不会被写入
+		result := tx.Model(&model.Article{}).Where("id = ?", ar.ID).Updates(articleModel.UpdateColumns())
+		if result.Error != nil {
+			return result.Error
+		}
 
-	return
+		if result.RowsAffected == 0 {
+			return domain.ErrNotFound
+		}
+
+		// Tags 为 nil 表示不修改标签，标签变化不算作编辑
+		if ar.Tags != nil {
+			if err := saveTags(tx, ar.ID, ar.Tags, true); err != nil {
+				return err
+			}
+		}
+
+		ar.UpdatedAt = articleModel.UpdatedAt
+		ar.Edited = articleModel.Edited
+		ar.EditCount = articleModel.EditCount
+		ar.SummaryIsAuto = articleModel.SummaryIsAuto
+		if articleModel.Summary != "" {
+			ar.Summary = articleModel.Summary
+		} else {
+			ar.Summary = old.Summary
+		}
+		changed = articleModel.ChangedFieldsFrom(&old)
+		if ar.Tags != nil {
+			changed["Tags"] = ar.Tags
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return changed, nil
 }
 
+// AddViews 由同步任务调用，使用 UpdateColumn 避免刷新 updated_at。
+// 更新后的行在事务中被锁住，读回的浏览量减去 deltaViews 就是更新前的值，据此判断是否跨过了里程碑
 func (m *articleRepository) AddViews(ctx context.Context, id int64, deltaViews int64) (err error) {
-	result := m.DB.WithContext(ctx).Model(&model.Article{}).Where("id = ?", id).Update("views", gorm.Expr("views + ?", deltaViews))
+	return m.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.Article{}).Where("id = ?", id).UpdateColumn("views", gorm.Expr("views + ?", deltaViews))
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return domain.ErrNotFound
+		}
+
+		var article model.Article
+		if err := tx.Select("id, user_id, views").Where("id = ?", id).Take(&article).Error; err != nil {
+			return err
+		}
+		return recordMilestone(tx, article.UserID, id, domain.ActivityViewsMilestone,
+			article.Views-deltaViews, article.Views, domain.ViewsMilestoneStep)
+	})
+}
+
+// SetHidden 修改文章的隐藏状态，不视为编辑，不刷新 updated_at
+func (m *articleRepository) SetHidden(ctx context.Context, id int64, hidden bool) error {
+	return m.setFlag(ctx, id, "hidden", hidden)
+}
+
+// SetCommentsLocked 开启或关闭评论，同样不视为编辑
+func (m *articleRepository) SetCommentsLocked(ctx context.Context, id int64, locked bool) error {
+	return m.setFlag(ctx, id, "comments_locked", locked)
+}
+
+// setFlag 修改文章的布尔列，文章不存在时返回 domain.ErrNotFound
+func (m *articleRepository) setFlag(ctx context.Context, id int64, column string, value bool) error {
+	result := m.DB.WithContext(ctx).Model(&model.Article{}).Where("id = ?", id).UpdateColumn(column, value)
 	if result.Error != nil {
+		return result.Error
 	}
 
 	if result.RowsAffected == 0 {
-		return domain.ErrNotFound
+		// 状态未变化时 RowsAffected 也为 0，需要确认文章是否存在
+		var count int64
+		if err := m.DB.WithContext(ctx).Model(&model.Article{}).Where("id = ?", id).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			return domain.ErrNotFound
+		}
 	}
-
 	return nil
 }
 
+// AddLikes 只修改计数，同样不刷新 updated_at
 func (m *articleRepository) AddLikes(ctx context.Context, id int64, deltaLikes int64) error {
-	result := m.DB.WithContext(ctx).Model(&model.Article{}).Where("id = ?", id).Update("likes", gorm.Expr("likes + ?", deltaLikes))
+	result := m.DB.WithContext(ctx).Model(&model.Article{}).Where("id = ?", id).UpdateColumn("likes", gorm.Expr("likes + ?", deltaLikes))
 	if result.Error != nil {
 		return result.Error
 	}
@@ -192,7 +384,7 @@ func (m *articleRepository) GetLikedUsers(ctx context.Context, id int64) ([]int6
 func (m *articleRepository) GetByIDs(ctx context.Context, ids []int64) ([]domain.Article, error) {
 	var articles []model.Article
 	err := m.DB.WithContext(ctx).
-		Where("id IN ?", ids).
+		Where("id IN ? AND hidden = ? AND status = ?", ids, false, published).
 		Find(&articles).Error
 	if err != nil {
 		return nil, err
@@ -202,6 +394,9 @@ func (m *articleRepository) GetByIDs(ctx context.Context, ids []int64) ([]domain
 	for i, model := range articles {
 		res[i] = model.ToDomain()
 	}
+	if err := loadTags(m.DB.WithContext(ctx), res); err != nil {
+		return nil, err
+	}
 
 	// if len(res) < len(ids) {
 	// 	err = domain.ErrNotFound
@@ -211,82 +406,182 @@ func (m *articleRepository) GetByIDs(ctx context.Context, ids []int64) ([]domain
 
 func (m *articleRepository) ApplyLikeChanges(ctx context.Context, changes domain.LikeStateChanges) error {
 	return m.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		filteredAdd := make([]model.UserLike, 0, len(changes.ToAdd))
-		if len(changes.ToAdd) > 0 {
-			toAddIDs := make([]int64, 0, len(changes.ToAdd))
-			seen := make(map[int64]bool)
-			for _, row := range changes.ToAdd {
+		articleIDs := make([]int64, 0, len(changes.ToAdd)+len(changes.ToRemove))
+		seen := make(map[int64]bool)
+		for _, rows := range [][]domain.UserLike{changes.ToAdd, changes.ToRemove} {
+			for _, row := range rows {
 				if !seen[row.ArticleID] {
-					toAddIDs = append(toAddIDs, row.ArticleID)
+					articleIDs = append(articleIDs, row.ArticleID)
 					seen[row.ArticleID] = true
 				}
 			}
+		}
+		if len(articleIDs) == 0 {
+			return nil
+		}
 
-			var validIDs []int64
-			if err := tx.Model(&model.Article{}).
-				Where("id IN ?", toAddIDs).
-				Pluck("id", &validIDs).Error; err != nil {
-				return err
-			}
+		// 点赞和同步之间文章可能已被删除，共享锁保证同步期间文章不会再被删除。
+		// 同时读出旧的点赞数，用来判断这次同步是否跨过了点赞里程碑。
+		// 软删除的文章也要读出来，布隆过滤器里仍然有它们，删除前后的点赞都会走到这里
+		var rows []model.Article
+		if err := tx.Unscoped().Select("id, user_id, likes, deleted_at").
+			Clauses(clause.Locking{Strength: "SHARE"}).
+			Where("id IN ?", articleIDs).
+			Find(&rows).Error; err != nil {
+			return err
+		}
 
-			validMap := make(map[int64]bool)
-			for _, id := range validIDs {
-				validMap[id] = true
+		valid := make([]model.Article, 0, len(rows))
+		validMap := make(map[int64]bool)
+		existing := make(map[int64]bool)
+		var softDeletedIDs []int64
+		for _, ar := range rows {
+			existing[ar.ID] = true
+			if ar.DeletedAt.Valid {
+				softDeletedIDs = append(softDeletedIDs, ar.ID)
+				continue
 			}
+			valid = append(valid, ar)
+			validMap[ar.ID] = true
+		}
 
-			for _, row := range changes.ToAdd {
-				if validMap[row.ArticleID] {
-					filteredAdd = append(filteredAdd, model.NewUserLikeFromDomain(row))
-				} else {
-					logrus.Warnf("Dropped orphan like for article %d", row.ArticleID)
-				}
+		// 软删除的文章可以恢复，跳过这次的变化，已有的点赞和计数原样保留
+		if len(softDeletedIDs) > 0 {
+			logrus.Warnf("Skipped like changes of soft-deleted articles %v", softDeletedIDs)
+		}
+
+		// 彻底不存在的文章的点赞全部清理掉，避免残留的行影响后续对账
+		var goneIDs []int64
+		for _, id := range articleIDs {
+			if !existing[id] {
+				goneIDs = append(goneIDs, id)
 			}
 		}
-		if len(changes.ToRemove) > 0 {
-			toRemove := make([]model.UserLike, len(changes.ToRemove))
-			for _, row := range changes.ToRemove {
+		if len(goneIDs) > 0 {
+			logrus.Warnf("Dropped likes of deleted articles %v", goneIDs)
+			if err := tx.Where("article_id IN ?", goneIDs).Delete(&model.UserLike{}).Error; err != nil {
+				return err
+			}
+		}
+
+		toRemove := make([]model.UserLike, 0, len(changes.ToRemove))
+		for _, row := range changes.ToRemove {
+			if validMap[row.ArticleID] {
 				toRemove = append(toRemove, model.NewUserLikeFromDomain(row))
 			}
+		}
+		if len(toRemove) > 0 {
 			if err := tx.Delete(toRemove).Error; err != nil {
 				return err
 			}
 		}
 
+		// 同一批次中重复的点赞只写入一次
+		filteredAdd := make([]model.UserLike, 0, len(changes.ToAdd))
+		added := make(map[domain.UserLike]bool)
+		for _, row := range changes.ToAdd {
+			key := domain.UserLike{UserID: row.UserID, ArticleID: row.ArticleID}
+			if !validMap[row.ArticleID] || added[key] {
+				continue
+			}
+			added[key] = true
+			filteredAdd = append(filteredAdd, model.NewUserLikeFromDomain(row))
+		}
 		if len(filteredAdd) > 0 {
+			// 冲突依赖 (user_id, article_id) 联合主键，已存在的点赞不会产生重复行
 			if err := tx.Clauses(clause.OnConflict{
 				UpdateAll: true,
 			}).Create(&filteredAdd).Error; err != nil {
 				return err
 			}
-
 		}
 
-		uniqueArticleIDs := make(map[int64]struct{})
-		for _, row := range changes.ToRemove {
-			uniqueArticleIDs[row.ArticleID] = struct{}{}
-		}
-		for _, row := range changes.ToAdd {
-			uniqueArticleIDs[row.ArticleID] = struct{}{}
-		}
-
-		for aid := range uniqueArticleIDs {
-
+		for _, ar := range valid {
 			var realCount int64
 			if err := tx.Model(&model.UserLike{}).
-				Where("article_id = ?", aid).
+				Where("article_id = ?", ar.ID).
 				Count(&realCount).Error; err != nil {
 				return err
 			}
 
 			if err := tx.Model(&model.Article{}).
-				Where("id = ?", aid).
+				Where("id = ?", ar.ID).
 				UpdateColumn("likes", realCount).Error; err != nil {
 				return err
 			}
+
+			if err := recordMilestone(tx, ar.UserID, ar.ID, domain.ActivityLikesMilestone,
+				ar.Likes, realCount, domain.LikesMilestoneStep); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// ReconcileLikes 校准一页文章的点赞数。文章行加写锁，与 ApplyLikeChanges 的共享锁互斥，
+// 避免同步点赞时算出的计数被覆盖
+func (m *articleRepository) ReconcileLikes(ctx context.Context, afterID int64, limit int) (domain.LikesReconcileBatch, error) {
+	var batch domain.LikesReconcileBatch
+	err := m.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var articles []model.Article
+		if err := tx.Select("id, likes").
+			Where("id > ?", afterID).
+			Order("id").
+			Limit(limit).
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Find(&articles).Error; err != nil {
+			return err
+		}
+		if len(articles) == 0 {
+			return nil
+		}
+
+		ids := make([]int64, len(articles))
+		for i, ar := range articles {
+			ids[i] = ar.ID
+		}
+		var counts []struct {
+			ArticleID int64
+			Cnt       int64
+		}
+		if err := tx.Model(&model.UserLike{}).
+			Select("article_id, COUNT(*) AS cnt").
+			Where("article_id IN ?", ids).
+			Group("article_id").
+			Find(&counts).Error; err != nil {
+			return err
+		}
+		actual := make(map[int64]int64, len(counts))
+		for _, c := range counts {
+			actual[c.ArticleID] = c.Cnt
+		}
+
+		var repaired []domain.LikeDrift
+		for _, ar := range articles {
+			if ar.Likes == actual[ar.ID] {
+				continue
+			}
+			if err := tx.Model(&model.Article{}).
+				Where("id = ?", ar.ID).
+				UpdateColumn("likes", actual[ar.ID]).Error; err != nil {
+				return err
+			}
+			repaired = append(repaired, domain.LikeDrift{ArticleID: ar.ID, Stored: ar.Likes, Actual: actual[ar.ID]})
 		}
 
+		batch = domain.LikesReconcileBatch{
+			Checked:  len(articles),
+			LastID:   ids[len(ids)-1],
+			Repaired: repaired,
+		}
 		return nil
 	})
+	if err != nil {
+		return domain.LikesReconcileBatch{}, err
+	}
+	return batch, nil
 }
 
 func (m *articleRepository) FetchUserLikedArticles(ctx context.Context, uid int64, limit int64) ([]int64, error) {
@@ -302,16 +597,34 @@ func (m *articleRepository) FetchUserLikedArticles(ctx context.Context, uid int6
 	return res, err
 }
 
+// FetchArticlesByLikes 按点赞数从高到低返回可见的文章，点赞数相同时按 id 倒序，保证多次查询的顺序一致。
+// 排序走 idx_hidden_likes 索引，limit 不能超过 MaxArticlesByLikesLimit
 func (m *articleRepository) FetchArticlesByLikes(ctx context.Context, limit int64) ([]domain.Article, error) {
+	if limit < 1 || limit > domain.MaxArticlesByLikesLimit {
+		return nil, domain.ErrBadParamInput
+	}
+
 	var res []model.Article
-	err := m.DB.WithContext(ctx).Model(&model.Article{}).Order("likes desc").Limit(int(limit)).Find(&res).Error
+	err := m.DB.WithContext(ctx).
+		Model(&model.Article{}).
+		Where("hidden = ? AND status = ?", false, published).
+		Order("likes DESC, id DESC").
+		Limit(int(limit)).
+		Find(&res).Error
+	if err != nil {
+		return nil, err
+	}
 	ars := make([]domain.Article, len(res))
 	for i := range res {
 		ars[i] = res[i].ToDomain()
 	}
-	return ars, err
+	if err := loadTags(m.DB.WithContext(ctx), ars); err != nil {
+		return nil, err
+	}
+	return ars, nil
 }
 
+// FetchIDs 按 ID 顺序分页读取所有未删除文章的ID，包括草稿和隐藏的文章，用于初始化布隆过滤器
 func (m *articleRepository) FetchIDs(ctx context.Context, cursor, limit int64) (ids []int64, err error) {
 	err = m.DB.WithContext(ctx).
 		Model(&model.Article{}).
@@ -322,3 +635,22 @@ func (m *articleRepository) FetchIDs(ctx context.Context, cursor, limit int64) (
 		Find(&ids).Error
 	return
 }
+
+func (m *articleRepository) FetchTitles(ctx context.Context, cursor, limit int64) ([]domain.TitleSuggestion, error) {
+	var ars []model.Article
+	err := m.DB.WithContext(ctx).
+		Select("id, title").
+		Where("id > ? AND hidden = ? AND status = ?", cursor, false, published).
+		Order("id").
+		Limit(int(limit)).
+		Find(&ars).Error
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]domain.TitleSuggestion, len(ars))
+	for i, ar := range ars {
+		res[i] = domain.TitleSuggestion{ID: ar.ID, Title: ar.Title}
+	}
+	return res, nil
+}
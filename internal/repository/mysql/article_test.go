@@ -0,0 +1,335 @@
+package mysql_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	gormMysql "gorm.io/driver/mysql"
+	"gorm.io/gorm"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/repository"
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/repository/mysql"
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/repository/mysql/model"
+)
+
+// newDryRunDB 返回一个不连接数据库的 gorm.DB，执行过的 UPDATE/SELECT 语句会被记录到 sqls 中
+func newDryRunDB(t *testing.T) (*gorm.DB, *[]string) {
+	t.Helper()
+	db, err := gorm.Open(gormMysql.New(gormMysql.Config{
+		Conn:                      dryRunPool{},
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{
+		DryRun:                 true,
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+	})
+	require.NoError(t, err)
+
+	sqls := new([]string)
+	capture := func(tx *gorm.DB) {
+		*sqls = append(*sqls, tx.Statement.SQL.String())
+	}
+	require.NoError(t, db.Callback().Update().After("gorm:update").Register("test:capture", capture))
+	require.NoError(t, db.Callback().Query().After("gorm:query").Register("test:capture", capture))
+	return db, sqls
+}
+
+// dryRunPool 不连接数据库，只让 dry run 模式下的事务可以开始和提交
+type dryRunPool struct{}
+
+var errDryRun = errors.New("dry run pool does not execute statements")
+
+func (dryRunPool) PrepareContext(context.Context, string) (*sql.Stmt, error) {
+	return nil, errDryRun
+}
+
+func (dryRunPool) ExecContext(context.Context, string, ...any) (sql.Result, error) {
+	return nil, errDryRun
+}
+
+func (dryRunPool) QueryContext(context.Context, string, ...any) (*sql.Rows, error) {
+	return nil, errDryRun
+}
+
+func (dryRunPool) QueryRowContext(context.Context, string, ...any) *sql.Row {
+	return nil
+}
+
+func (p dryRunPool) BeginTx(context.Context, *sql.TxOptions) (gorm.ConnPool, error) {
+	return p, nil
+}
+
+func (dryRunPool) Commit() error   { return nil }
+func (dryRunPool) Rollback() error { return nil }
+
+func TestAddViewsDoesNotTouchUpdatedAt(t *testing.T) {
+	db, sqls := newDryRunDB(t)
+	repo := mysql.NewArticleDBRepository(db, false)
+
+	_ = repo.AddViews(context.Background(), 1, 10)
+
+	require.Len(t, *sqls, 1)
+	assert.Contains(t, (*sqls)[0], "`views`=views + ?")
+	assert.NotContains(t, (*sqls)[0], "updated_at")
+}
+
+func TestAddLikesDoesNotTouchUpdatedAt(t *testing.T) {
+	db, sqls := newDryRunDB(t)
+	repo := mysql.NewArticleDBRepository(db, false)
+
+	_ = repo.AddLikes(context.Background(), 1, -1)
+
+	require.Len(t, *sqls, 1)
+	assert.Contains(t, (*sqls)[0], "`likes`=likes + ?")
+	assert.NotContains(t, (*sqls)[0], "updated_at")
+}
+
+func TestFetchListProjection(t *testing.T) {
+	cases := []struct {
+		name           string
+		includeContent bool
+	}{
+		{"light", false},
+		{"full", true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			db, sqls := newDryRunDB(t)
+			repo := mysql.NewArticleDBRepository(db, c.includeContent)
+
+			_, _, err := repo.Fetch(context.Background(), "", 10, "", "")
+			require.NoError(t, err)
+
+			require.Len(t, *sqls, 1)
+			assert.Contains(t, (*sqls)[0], "SELECT id, title, summary, summary_is_auto, user_id, updated_at, created_at, views, likes")
+			if c.includeContent {
+				assert.Contains(t, (*sqls)[0], "content")
+			} else {
+				assert.NotContains(t, (*sqls)[0], "content")
+			}
+		})
+	}
+}
+
+func TestUpdateAdvancesUpdatedAtOnly(t *testing.T) {
+	db, sqls := newDryRunDB(t)
+	createdAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	oldUpdatedAt := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, db.Callback().Query().After("gorm:query").Register("test:old", func(tx *gorm.DB) {
+		if old, ok := tx.Statement.Dest.(*model.Article); ok {
+			*old = model.Article{ID: 1, Title: "old", Content: "content", UpdatedAt: oldUpdatedAt, CreatedAt: createdAt}
+		}
+	}))
+	// dry run 不执行语句，手动标记更新成功
+	require.NoError(t, db.Callback().Update().After("gorm:update").Register("test:affected", func(tx *gorm.DB) {
+		tx.RowsAffected = 1
+	}))
+	repo := mysql.NewArticleDBRepository(db, false)
+
+	// 调用方传入的时间戳都不应被采用
+	ar := &domain.Article{ID: 1, Title: "new", UpdatedAt: oldUpdatedAt, CreatedAt: time.Now().Add(time.Hour)}
+	changed, err := repo.Update(context.Background(), ar)
+	require.NoError(t, err)
+
+	// GORM 的 NowFunc 会截断精度，只比较到秒级
+	assert.True(t, ar.UpdatedAt.After(oldUpdatedAt), "UpdatedAt should advance")
+	assert.WithinDuration(t, time.Now(), ar.UpdatedAt, time.Second)
+	assert.Equal(t, ar.UpdatedAt, changed["UpdatedAt"], "cache patch should carry the stored UpdatedAt")
+
+	require.Len(t, *sqls, 2)
+	assert.Contains(t, (*sqls)[1], "`updated_at`=?")
+	assert.NotContains(t, (*sqls)[1], "created_at")
+}
+
+func TestStoreIgnoresCounters(t *testing.T) {
+	db, _ := newDryRunDB(t)
+	var inserted *model.Article
+	require.NoError(t, db.Callback().Create().After("gorm:create").Register("test:inserted", func(tx *gorm.DB) {
+		if ar, ok := tx.Statement.Dest.(*model.Article); ok {
+			inserted = ar
+		}
+	}))
+	repo := mysql.NewArticleDBRepository(db, false)
+
+	ar := &domain.Article{ID: 5, Title: "t", Content: "c", User: domain.User{ID: 7}, Views: 999999, Likes: 42, Edited: true, EditCount: 3}
+	require.NoError(t, repo.Store(context.Background(), ar))
+
+	require.NotNil(t, inserted)
+	assert.Zero(t, inserted.ID, "the ID is assigned by the database")
+	assert.Zero(t, inserted.Views)
+	assert.Zero(t, inserted.Likes)
+	assert.False(t, inserted.Edited)
+	assert.Zero(t, inserted.EditCount)
+	assert.WithinDuration(t, time.Now(), inserted.CreatedAt, time.Second)
+}
+
+func TestStoreWritesFingerprint(t *testing.T) {
+	db, _ := newDryRunDB(t)
+	var inserted *model.Article
+	require.NoError(t, db.Callback().Create().After("gorm:create").Register("test:inserted", func(tx *gorm.DB) {
+		if ar, ok := tx.Statement.Dest.(*model.Article); ok {
+			inserted = ar
+		}
+	}))
+	repo := mysql.NewArticleDBRepository(db, false)
+
+	require.NoError(t, repo.Store(context.Background(), &domain.Article{Title: "t", Content: "Hello  World"}))
+	require.NotNil(t, inserted)
+	assert.Equal(t, domain.ContentFingerprint("hello world"), inserted.Fingerprint)
+}
+
+func TestUpdateRefreshesFingerprint(t *testing.T) {
+	db, sqls := newDryRunDB(t)
+	require.NoError(t, db.Callback().Update().After("gorm:update").Register("test:affected", func(tx *gorm.DB) {
+		tx.RowsAffected = 1
+	}))
+	repo := mysql.NewArticleDBRepository(db, false)
+
+	_, err := repo.Update(context.Background(), &domain.Article{ID: 1, Content: "new content"})
+	require.NoError(t, err)
+
+	require.Len(t, *sqls, 2)
+	assert.Contains(t, (*sqls)[1], "`fingerprint`=?")
+}
+
+func TestFindByFingerprint(t *testing.T) {
+	db, sqls := newDryRunDB(t)
+	repo := mysql.NewArticleDBRepository(db, false)
+
+	_, err := repo.FindByFingerprint(context.Background(), domain.ContentFingerprint("c"))
+	require.NoError(t, err)
+
+	require.Len(t, *sqls, 1)
+	assert.Equal(t, "SELECT id, user_id FROM `article` WHERE fingerprint = ? AND `article`.`deleted_at` IS NULL ORDER BY id LIMIT ?", (*sqls)[0])
+}
+
+func TestUpdateWritesWhitelistedColumns(t *testing.T) {
+	db, sqls := newDryRunDB(t)
+	require.NoError(t, db.Callback().Update().After("gorm:update").Register("test:affected", func(tx *gorm.DB) {
+		tx.RowsAffected = 1
+	}))
+	repo := mysql.NewArticleDBRepository(db, false)
+
+	ar := &domain.Article{ID: 1, Title: "new", Views: 999999, Likes: 42, Hidden: true, User: domain.User{ID: 9}}
+	_, err := repo.Update(context.Background(), ar)
+	require.NoError(t, err)
+
+	require.Len(t, *sqls, 2)
+	update := (*sqls)[1]
+	assert.Contains(t, update, "`title`=?")
+	for _, column := range []string{"views", "likes", "hidden", "user_id", "created_at", "content", "fingerprint"} {
+		assert.NotContains(t, update, "`"+column+"`")
+	}
+}
+
+func TestFetchArticlesByLikesBreaksTiesByID(t *testing.T) {
+	db, sqls := newDryRunDB(t)
+	repo := mysql.NewArticleDBRepository(db, false)
+
+	// 点赞数相同的文章按主键排序，多次查询生成的语句完全一致，顺序不会来回变化
+	for range 3 {
+		_, err := repo.FetchArticlesByLikes(context.Background(), 10)
+		require.NoError(t, err)
+	}
+
+	require.Len(t, *sqls, 3)
+	assert.Contains(t, (*sqls)[0], "ORDER BY likes DESC, id DESC LIMIT ?")
+	assert.Equal(t, (*sqls)[0], (*sqls)[1])
+	assert.Equal(t, (*sqls)[0], (*sqls)[2])
+}
+
+func TestFetchArticlesByLikesLimit(t *testing.T) {
+	db, sqls := newDryRunDB(t)
+	repo := mysql.NewArticleDBRepository(db, false)
+
+	for _, limit := range []int64{0, domain.MaxArticlesByLikesLimit + 1} {
+		_, err := repo.FetchArticlesByLikes(context.Background(), limit)
+		require.ErrorIs(t, err, domain.ErrBadParamInput)
+	}
+	assert.Empty(t, *sqls, "rejected limits never reach the database")
+}
+
+func TestFetchHasMore(t *testing.T) {
+	cases := []struct {
+		name    string
+		rows    int
+		hasMore bool
+	}{
+		{"exactly num rows", 10, false},
+		{"one more row", 11, true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			db, _ := newDryRunDB(t)
+			var limit any
+			require.NoError(t, db.Callback().Query().After("gorm:query").Register("test:rows", func(tx *gorm.DB) {
+				if dest, ok := tx.Statement.Dest.(*[]model.Article); ok {
+					limit = tx.Statement.Vars[len(tx.Statement.Vars)-1]
+					for i := range tc.rows {
+						*dest = append(*dest, model.Article{ID: int64(i + 1)})
+					}
+				}
+			}))
+			repo := mysql.NewArticleDBRepository(db, false)
+
+			res, hasMore, err := repo.Fetch(context.Background(), "", 10, "", "")
+			require.NoError(t, err)
+			assert.Equal(t, 11, limit, "reads one extra row")
+			assert.Len(t, res, 10)
+			assert.Equal(t, tc.hasMore, hasMore)
+		})
+	}
+}
+
+func TestSearchMatchesTitleOrContent(t *testing.T) {
+	db, sqls := newDryRunDB(t)
+	var vars []any
+	require.NoError(t, db.Callback().Query().After("gorm:query").Register("test:vars", func(tx *gorm.DB) {
+		vars = tx.Statement.Vars
+	}))
+	repo := mysql.NewArticleDBRepository(db, false)
+
+	_, _, err := repo.Search(context.Background(), "100%_go", "42", 10)
+	require.NoError(t, err)
+
+	require.Len(t, *sqls, 1)
+	assert.Contains(t, (*sqls)[0], "WHERE (hidden = ? AND status = ?) AND (title LIKE ? OR content LIKE ?) AND id < ? AND `article`.`deleted_at` IS NULL ORDER BY id DESC LIMIT ?")
+	// 通配符按字面匹配，多读一行判断是否还有结果
+	assert.Equal(t, []any{false, "published", `%100\%\_go%`, `%100\%\_go%`, int64(42), 11}, vars)
+}
+
+func TestSearchRejectsBadCursor(t *testing.T) {
+	db, sqls := newDryRunDB(t)
+	repo := mysql.NewArticleDBRepository(db, false)
+
+	_, _, err := repo.Search(context.Background(), "go", "abc", 10)
+	assert.ErrorIs(t, err, domain.ErrBadParamInput)
+	assert.Empty(t, *sqls)
+}
+
+func TestFetchPagesByCreatedAtAndID(t *testing.T) {
+	db, sqls := newDryRunDB(t)
+	var vars []any
+	require.NoError(t, db.Callback().Query().After("gorm:query").Register("test:vars", func(tx *gorm.DB) {
+		if vars == nil {
+			vars = tx.Statement.Vars
+		}
+	}))
+	repo := mysql.NewArticleDBRepository(db, false)
+	createdAt := time.Date(2024, 5, 1, 8, 30, 0, 0, time.UTC)
+
+	_, _, err := repo.Fetch(context.Background(), repository.EncodeCursor(createdAt, 9), 10, "", "")
+	require.NoError(t, err)
+
+	// 同一秒内创建的文章按 id 排序，游标同时比较两列
+	require.NotEmpty(t, *sqls)
+	assert.Contains(t, (*sqls)[0], "WHERE ((created_at, id) > (?, ?) AND hidden = ? AND status = ?)")
+	assert.Contains(t, (*sqls)[0], "ORDER BY created_at, id LIMIT ?")
+	assert.Equal(t, []any{createdAt, int64(9), false, "published", 11}, vars)
+}
@@ -0,0 +1,31 @@
+package mysql
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/repository/mysql/model"
+)
+
+type auditLogRepository struct {
+	DB *gorm.DB
+}
+
+var _ domain.AuditLogRepository = (*auditLogRepository)(nil)
+
+func NewAuditLogRepository(db *gorm.DB) *auditLogRepository {
+	return &auditLogRepository{db}
+}
+
+func (m *auditLogRepository) BatchStore(ctx context.Context, logs []domain.AuditLog) error {
+	if len(logs) == 0 {
+		return nil
+	}
+	rows := make([]model.AuditLog, len(logs))
+	for i := range logs {
+		rows[i] = model.NewAuditLogFromDomain(&logs[i])
+	}
+	return m.DB.WithContext(ctx).Create(&rows).Error
+}
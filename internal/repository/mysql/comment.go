@@ -19,15 +19,30 @@ func NewCommentRepository(db *gorm.DB) *commentRepository {
 	}
 }
 
-func (c *commentRepository) Delete(ctx context.Context, aid int64, uid int64) error {
-	result := c.DB.WithContext(ctx).Model(&model.Comment{}).Where("article_id = ? AND user_id = ?", aid, uid).Delete(&model.Comment{})
+// Delete 按 id 删除一条评论，条件中带上 user_id 保证只能删除自己的评论。
+// 没有删除任何行时再查一次，区分评论不存在和不是作者
+func (c *commentRepository) Delete(ctx context.Context, id int64, uid int64) error {
+	result := c.DB.WithContext(ctx).Where("id = ? AND user_id = ?", id, uid).Delete(&model.Comment{})
 	if result.Error != nil {
 		return result.Error
 	}
-	if result.RowsAffected == 0 {
-		return domain.ErrForbidden
+	if result.RowsAffected > 0 {
+		return nil
 	}
-	return nil
+
+	var count int64
+	if err := c.DB.WithContext(ctx).Model(&model.Comment{}).Where("id = ?", id).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return domain.ErrNotFound
+	}
+	return domain.ErrForbidden
+}
+
+// DeleteByArticle 删除文章下的全部评论，回复和一级评论在同一张表中，一条语句即可
+func (c *commentRepository) DeleteByArticle(ctx context.Context, articleID int64) error {
+	return c.DB.WithContext(ctx).Where("article_id = ?", articleID).Delete(&model.Comment{}).Error
 }
 
 func (c *commentRepository) FetchReplies(ctx context.Context, rootIDs []int64) ([]*domain.Comment, error) {
@@ -49,14 +64,18 @@ func (c *commentRepository) FetchReplies(ctx context.Context, rootIDs []int64) (
 
 func (c *commentRepository) FetchRoots(ctx context.Context, articleID int64, cursor string, limit int64) ([]*domain.Comment, error) {
 	var comments []model.Comment
-	decodedCursor, err := repository.DecodeCursor(cursor)
-	if err != nil && cursor != "" {
-		return nil, domain.ErrBadParamInput
+	query := c.DB.WithContext(ctx).Where("article_id = ? AND parent_id = 0", articleID)
+	// 评论从新到旧排列，下一页是游标之前的评论
+	if cursor != "" {
+		createdAt, lastID, err := repository.DecodeCursor(cursor)
+		if err != nil {
+			return nil, domain.ErrBadParamInput
+		}
+		query = query.Where("(created_at, id) < (?, ?)", createdAt, lastID)
 	}
-	err = c.DB.WithContext(ctx).
-		Where("article_id = ? AND parent_id = 0 AND created_at > ?", articleID, decodedCursor).
+	err := query.
 		Limit(int(limit)).
-		Order("created_at DESC").
+		Order("created_at DESC, id DESC").
 		Find(&comments).Error
 	if err != nil {
 		return nil, err
@@ -70,6 +89,30 @@ func (c *commentRepository) FetchRoots(ctx context.Context, articleID int64, cur
 	return res, nil
 }
 
+func (c *commentRepository) CountByArticles(ctx context.Context, articleIDs []int64) (map[int64]int64, error) {
+	res := make(map[int64]int64, len(articleIDs))
+	if len(articleIDs) == 0 {
+		return res, nil
+	}
+
+	var rows []struct {
+		ArticleID int64
+		Count     int64
+	}
+	err := c.DB.WithContext(ctx).Model(&model.Comment{}).
+		Select("article_id, COUNT(*) AS count").
+		Where("article_id IN ?", articleIDs).
+		Group("article_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		res[row.ArticleID] = row.Count
+	}
+	return res, nil
+}
+
 func (c *commentRepository) GetByID(ctx context.Context, id int64) (*domain.Comment, error) {
 	var comment model.Comment
 	err := c.DB.WithContext(ctx).First(&comment, "id = ?", id).Error
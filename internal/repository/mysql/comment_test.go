@@ -0,0 +1,76 @@
+package mysql_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/repository"
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/repository/mysql"
+)
+
+func TestCommentDeleteOnlyMatchingRow(t *testing.T) {
+	db, sqls := newDryRunDB(t)
+	require.NoError(t, db.Callback().Delete().After("gorm:delete").Register("test:capture", func(tx *gorm.DB) {
+		*sqls = append(*sqls, tx.Statement.SQL.String())
+	}))
+	repo := mysql.NewCommentRepository(db)
+
+	// dry run 不会删除任何行，计数也为 0，视为评论不存在
+	err := repo.Delete(context.Background(), 5, 7)
+	require.ErrorIs(t, err, domain.ErrNotFound)
+
+	require.Len(t, *sqls, 2)
+	assert.Equal(t, "DELETE FROM `comment` WHERE id = ? AND user_id = ?", (*sqls)[0])
+	assert.Equal(t, "SELECT count(*) FROM `comment` WHERE id = ?", (*sqls)[1])
+}
+
+func TestCommentDeleteOthersComment(t *testing.T) {
+	db, _ := newDryRunDB(t)
+	// 评论存在但作者不是当前用户
+	require.NoError(t, db.Callback().Query().After("gorm:query").Register("test:count", func(tx *gorm.DB) {
+		if count, ok := tx.Statement.Dest.(*int64); ok {
+			*count = 1
+			tx.RowsAffected = 1
+		}
+	}))
+	repo := mysql.NewCommentRepository(db)
+
+	err := repo.Delete(context.Background(), 5, 8)
+	assert.ErrorIs(t, err, domain.ErrForbidden)
+}
+
+func TestFetchRootsPagesByCreatedAtAndID(t *testing.T) {
+	db, sqls := newDryRunDB(t)
+	var vars [][]any
+	require.NoError(t, db.Callback().Query().After("gorm:query").Register("test:vars", func(tx *gorm.DB) {
+		vars = append(vars, tx.Statement.Vars)
+	}))
+	repo := mysql.NewCommentRepository(db)
+	createdAt := time.Date(2024, 5, 1, 8, 30, 0, 0, time.UTC)
+
+	_, err := repo.FetchRoots(context.Background(), 1, "", 10)
+	require.NoError(t, err)
+	_, err = repo.FetchRoots(context.Background(), 1, repository.EncodeCursor(createdAt, 9), 10)
+	require.NoError(t, err)
+
+	// 第一页不带游标条件；从新到旧排列，同一秒内按 id 倒序，下一页取游标之前的评论
+	require.Len(t, *sqls, 2)
+	assert.Contains(t, (*sqls)[0], "WHERE article_id = ? AND parent_id = 0 ORDER BY created_at DESC, id DESC LIMIT ?")
+	assert.Contains(t, (*sqls)[1], "WHERE (article_id = ? AND parent_id = 0) AND (created_at, id) < (?, ?) ORDER BY created_at DESC, id DESC LIMIT ?")
+	assert.Equal(t, []any{int64(1), createdAt, int64(9), 10}, vars[1])
+}
+
+func TestFetchRootsRejectsBadCursor(t *testing.T) {
+	db, sqls := newDryRunDB(t)
+	repo := mysql.NewCommentRepository(db)
+
+	_, err := repo.FetchRoots(context.Background(), 1, "abc", 10)
+	assert.ErrorIs(t, err, domain.ErrBadParamInput)
+	assert.Empty(t, *sqls)
+}
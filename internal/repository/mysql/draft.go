@@ -0,0 +1,86 @@
+package mysql
+
+import (
+	"context"
+	"strconv"
+
+	"gorm.io/gorm"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/repository"
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/repository/mysql/model"
+)
+
+const draft = string(domain.ArticleStatusDraft)
+
+// GetDraft 读取 userID 的草稿，不是草稿或者作者不是 userID 时都返回 domain.ErrNotFound，不泄露文章是否存在
+func (m *articleRepository) GetDraft(ctx context.Context, id, userID int64) (domain.Article, error) {
+	var article model.Article
+	err := m.DB.WithContext(ctx).First(&article, "id = ? AND user_id = ? AND status = ?", id, userID, draft).Error
+	if err != nil {
+		return domain.Article{}, domain.ErrNotFound
+	}
+	ars := []domain.Article{article.ToDomain()}
+	if err := loadTags(m.DB.WithContext(ctx), ars); err != nil {
+		return domain.Article{}, err
+	}
+	return ars[0], nil
+}
+
+// FetchDrafts 按 id 倒序分页读取 userID 的草稿，走 idx_user_status 索引，同样多读一行判断是否还有下一页
+func (m *articleRepository) FetchDrafts(ctx context.Context, userID int64, cursor string, num int64) (res []domain.Article, hasMore bool, err error) {
+	var lastID int64
+	if cursor != "" {
+		lastID, err = strconv.ParseInt(cursor, 10, 64)
+		if err != nil {
+			return nil, false, domain.ErrBadParamInput
+		}
+	}
+
+	repository.PageVerify(&num)
+	q := m.DB.WithContext(ctx).Select(m.listColumns).
+		Where("user_id = ? AND status = ?", userID, draft)
+	if lastID > 0 {
+		q = q.Where("id < ?", lastID)
+	}
+
+	var articles []model.Article
+	if err = q.Order("id DESC").Limit(int(num) + 1).Find(&articles).Error; err != nil {
+		return nil, false, err
+	}
+
+	if int64(len(articles)) > num {
+		articles = articles[:num]
+		hasMore = true
+	}
+	for _, article := range articles {
+		res = append(res, article.ToDomain())
+	}
+	if err = loadTags(m.DB.WithContext(ctx), res); err != nil {
+		return nil, false, err
+	}
+	return res, hasMore, nil
+}
+
+// Publish 把草稿改为已发布，发布时间作为 created_at，这样文章出现在列表的最新位置。
+// 发布动态在同一事务中写入
+func (m *articleRepository) Publish(ctx context.Context, id int64) error {
+	return m.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := tx.NowFunc()
+		result := tx.Model(&model.Article{}).
+			Where("id = ? AND status = ?", id, draft).
+			Updates(map[string]any{"status": published, "created_at": now, "updated_at": now})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return domain.ErrNotFound
+		}
+
+		var article model.Article
+		if err := tx.Select("id, user_id").Where("id = ?", id).Take(&article).Error; err != nil {
+			return err
+		}
+		return recordActivity(tx, article.UserID, id, domain.ActivityPublished, 0)
+	})
+}
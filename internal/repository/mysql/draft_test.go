@@ -0,0 +1,117 @@
+package mysql_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/repository/mysql"
+)
+
+func TestPublicQueriesSkipDrafts(t *testing.T) {
+	db, sqls := newDryRunDB(t)
+	repo := mysql.NewArticleDBRepository(db, false)
+	ctx := context.Background()
+
+	_, _, err := repo.Fetch(ctx, "", 10, "", "")
+	require.NoError(t, err)
+	_, _ = repo.GetByID(ctx, 1)
+	_, err = repo.GetByIDs(ctx, []int64{1, 2})
+	require.NoError(t, err)
+
+	// 加载标签的查询之外，每条读文章表的语句都只读已发布的文章
+	var articleQueries int
+	for _, sql := range *sqls {
+		if strings.Contains(sql, "FROM `article` WHERE") {
+			articleQueries++
+			assert.Contains(t, sql, "status = ?")
+		}
+	}
+	assert.Equal(t, 3, articleQueries)
+}
+
+func TestFetchIDsIncludesDrafts(t *testing.T) {
+	db, sqls := newDryRunDB(t)
+	repo := mysql.NewArticleDBRepository(db, false)
+
+	_, err := repo.FetchIDs(context.Background(), 0, 100)
+	require.NoError(t, err)
+
+	// 布隆过滤器判断的是文章是否存在，草稿和隐藏的文章都要包括
+	require.Len(t, *sqls, 1)
+	assert.Contains(t, (*sqls)[0], "WHERE id > ? AND `article`.`deleted_at` IS NULL ORDER BY id")
+	assert.NotContains(t, (*sqls)[0], "status")
+	assert.NotContains(t, (*sqls)[0], "hidden")
+}
+
+func TestGetDraftOnlyForAuthor(t *testing.T) {
+	db, sqls := newDryRunDB(t)
+	var vars []any
+	require.NoError(t, db.Callback().Query().After("gorm:query").Register("test:vars", func(tx *gorm.DB) {
+		if vars == nil {
+			vars = tx.Statement.Vars
+		}
+	}))
+	repo := mysql.NewArticleDBRepository(db, false)
+
+	_, err := repo.GetDraft(context.Background(), 5, 7)
+	require.NoError(t, err)
+
+	require.NotEmpty(t, *sqls)
+	assert.Contains(t, (*sqls)[0], "WHERE (id = ? AND user_id = ? AND status = ?) AND `article`.`deleted_at` IS NULL")
+	assert.Equal(t, []any{int64(5), int64(7), "draft", 1}, vars)
+}
+
+func TestFetchDraftsPagesByID(t *testing.T) {
+	db, sqls := newDryRunDB(t)
+	repo := mysql.NewArticleDBRepository(db, false)
+
+	_, hasMore, err := repo.FetchDrafts(context.Background(), 7, "42", 10)
+	require.NoError(t, err)
+	assert.False(t, hasMore)
+
+	require.Len(t, *sqls, 1)
+	assert.Contains(t, (*sqls)[0], "WHERE (user_id = ? AND status = ?) AND id < ? AND `article`.`deleted_at` IS NULL ORDER BY id DESC LIMIT ?")
+	assert.NotContains(t, (*sqls)[0], "content", "drafts list does not read the body")
+}
+
+func TestFetchDraftsRejectsBadCursor(t *testing.T) {
+	db, sqls := newDryRunDB(t)
+	repo := mysql.NewArticleDBRepository(db, false)
+
+	_, _, err := repo.FetchDrafts(context.Background(), 7, "abc", 10)
+	assert.True(t, errors.Is(err, domain.ErrBadParamInput))
+	assert.Empty(t, *sqls)
+}
+
+func TestPublishOnlyDrafts(t *testing.T) {
+	db, sqls := newDryRunDB(t)
+	repo := mysql.NewArticleDBRepository(db, false)
+
+	// dry run 不更新任何行，已发布或不存在的文章都返回 ErrNotFound
+	err := repo.Publish(context.Background(), 5)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+
+	require.Len(t, *sqls, 1)
+	assert.Contains(t, (*sqls)[0], "`status`=?")
+	assert.Contains(t, (*sqls)[0], "`created_at`=?")
+	assert.Contains(t, (*sqls)[0], "WHERE (id = ? AND status = ?) AND `article`.`deleted_at` IS NULL")
+}
+
+func TestGetForCommentIncludesDrafts(t *testing.T) {
+	db, sqls := newDryRunDB(t)
+	repo := mysql.NewArticleDBRepository(db, false)
+
+	_, _ = repo.GetForComment(context.Background(), 5)
+	require.Len(t, *sqls, 1)
+	// 不按发布状态过滤，分享出去的草稿也能评论；隐藏和已删除的文章不行
+	assert.Contains(t, (*sqls)[0], "WHERE (id = ? AND hidden = ?) AND `article`.`deleted_at` IS NULL")
+	assert.NotContains(t, (*sqls)[0], "status = ?")
+	assert.NotContains(t, (*sqls)[0], "content")
+}
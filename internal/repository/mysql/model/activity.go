@@ -0,0 +1,35 @@
+package model
+
+import (
+	"time"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+)
+
+// Activity 作者动态，只追加不修改。(article_id, type, milestone) 唯一，同一个里程碑只记录一次
+type Activity struct {
+	ID        int64     `gorm:"primaryKey;autoIncrement"`
+	UserID    int64     `gorm:"column:user_id;not null;index:idx_user_id"`
+	ArticleID int64     `gorm:"column:article_id;not null;uniqueIndex:uk_article_type_milestone,priority:1"`
+	Type      string    `gorm:"type:varchar(32);not null;uniqueIndex:uk_article_type_milestone,priority:2"`
+	Milestone int64     `gorm:"not null;default:0;uniqueIndex:uk_article_type_milestone,priority:3"`
+	CreatedAt time.Time `gorm:"type:datetime;autoCreateTime"`
+	// ArticleTitle 读取时从 article 表关联得到，不写入
+	ArticleTitle string `gorm:"column:article_title;->"`
+}
+
+func (Activity) TableName() string {
+	return "activity"
+}
+
+func (m Activity) ToDomain() domain.Activity {
+	return domain.Activity{
+		ID:           m.ID,
+		UserID:       m.UserID,
+		ArticleID:    m.ArticleID,
+		ArticleTitle: m.ArticleTitle,
+		Type:         domain.ActivityType(m.Type),
+		Milestone:    m.Milestone,
+		CreatedAt:    m.CreatedAt,
+	}
+}
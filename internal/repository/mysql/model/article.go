@@ -3,18 +3,38 @@ package model
 import (
 	"time"
 
+	"gorm.io/gorm"
+
 	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
 )
 
 type Article struct {
-	ID        int64     `gorm:"primaryKey;autoIncrement"`
-	Title     string    `gorm:"type:varchar(45);not null"`
-	Content   string    `gorm:"type:longtext;not null"`
-	UserID    int64     `gorm:"column:user_id;not null"`
-	Views     int64     `gorm:"default:0"`
-	Likes     int64     `gorm:"default:0"`
-	UpdatedAt time.Time `gorm:"type:datetime"`
-	CreatedAt time.Time `gorm:"type:datetime"`
+	ID        int64  `gorm:"primaryKey;autoIncrement"`
+	Title     string `gorm:"type:varchar(45);not null"`
+	Content   string `gorm:"type:longtext;not null"`
+	UserID    int64  `gorm:"column:user_id;not null;index:idx_user_status,priority:1"`
+	Views     int64  `gorm:"default:0"`
+	Likes     int64  `gorm:"default:0"`
+	Edited    bool   `gorm:"default:false"`
+	EditCount int64  `gorm:"default:0"`
+	Hidden    bool   `gorm:"default:false"`
+	// Language 规范化后的 BCP-47 语言标签，空字符串表示未指定
+	Language string `gorm:"type:varchar(35);not null;default:''"`
+	// CommentsLocked 作者关闭评论后不再接受新评论
+	CommentsLocked bool `gorm:"not null;default:false"`
+	// Status 草稿只有作者可见，已有的文章都是已发布
+	Status string `gorm:"type:varchar(16);not null;default:'published';index:idx_user_status,priority:2"`
+	// Summary 最多 300 个字符，utf8mb4 下 varchar 按字符计长度
+	Summary       string `gorm:"type:varchar(300);not null;default:''"`
+	SummaryIsAuto bool   `gorm:"default:true"`
+	// Fingerprint 是 domain.ContentFingerprint(Content)，随正文一起写入，用于发现重复发布的内容
+	Fingerprint string `gorm:"type:char(64);not null;default:'';index:idx_fingerprint"`
+	// 指定了 type 后 GORM 不再按字段名自动维护时间，需要显式声明。
+	// 两者都只由 GORM 写入：创建时为零值则填当前时间，更新时 updated_at 总是刷新
+	UpdatedAt time.Time `gorm:"type:datetime;autoUpdateTime"`
+	CreatedAt time.Time `gorm:"type:datetime;autoCreateTime"`
+	// DeletedAt 不为空表示文章已被删除，GORM 的查询会自动跳过，Unscoped 才能读到
+	DeletedAt gorm.DeletedAt `gorm:"type:datetime;index"`
 }
 
 func (Article) TableName() string {
@@ -31,20 +51,128 @@ func (m *Article) ToDomain() domain.Article {
 		User: domain.User{
 			ID: m.UserID,
 		},
-		Views: m.Views,
-		Likes: m.Likes,
+		Views:     m.Views,
+		Likes:     m.Likes,
+		Edited:    m.Edited,
+		EditCount: m.EditCount,
+		Hidden:    m.Hidden,
+		Language:  m.Language,
+
+		CommentsLocked: m.CommentsLocked,
+		Status:         domain.ArticleStatus(m.Status),
+
+		Summary:       m.Summary,
+		SummaryIsAuto: m.SummaryIsAuto,
 	}
 }
 
-func NewArticleFromDomain(a *domain.Article) *Article {
+// NewArticleForCreate 只取创建时允许写入的字段，浏览量、点赞数和编辑次数总是从零开始。
+// 时间戳为零值时由 GORM 填入当前时间，只有导入历史文章时服务端才会指定
+func NewArticleForCreate(a *domain.Article) *Article {
 	return &Article{
-		ID:        a.ID,
 		Title:     a.Title,
 		Content:   a.Content,
 		UserID:    a.User.ID,
+		Hidden:    a.Hidden,
+		Language:  a.Language,
 		UpdatedAt: a.UpdatedAt,
 		CreatedAt: a.CreatedAt,
-		Views:     a.Views,
-		Likes:     a.Likes,
+		Status:    string(a.Status),
+
+		Summary:       a.Summary,
+		SummaryIsAuto: a.SummaryIsAuto,
+		Fingerprint:   domain.ContentFingerprint(a.Content),
+	}
+}
+
+// NewArticleForUpdate 只取更新时允许修改的字段，其余字段由 MarkEditedFrom、KeepSummaryFrom 根据旧记录计算
+func NewArticleForUpdate(a *domain.Article) *Article {
+	return &Article{
+		ID:       a.ID,
+		Title:    a.Title,
+		Content:  a.Content,
+		Language: a.Language,
+
+		Summary:       a.Summary,
+		SummaryIsAuto: a.SummaryIsAuto,
+	}
+}
+
+// MarkEditedFrom 对比更新前的记录，标题或正文确实发生变化时才记为一次编辑
+// 空字段不会被 Updates 写入，因此不参与比较
+func (m *Article) MarkEditedFrom(old *Article) {
+	m.Edited = old.Edited
+	m.EditCount = old.EditCount
+	titleChanged := m.Title != "" && m.Title != old.Title
+	contentChanged := m.Content != "" && m.Content != old.Content
+	if titleChanged || contentChanged {
+		m.Edited = true
+		m.EditCount++
+	}
+}
+
+// KeepSummaryFrom 根据更新前的记录决定摘要是否更新：
+// 作者手写过的摘要不会被自动生成的摘要覆盖，没有传摘要时保持原样
+// 需要在 ChangedFieldsFrom 之前调用
+func (m *Article) KeepSummaryFrom(old *Article) {
+	if m.Summary == "" || (m.SummaryIsAuto && !old.SummaryIsAuto) {
+		m.Summary = ""
+		m.SummaryIsAuto = old.SummaryIsAuto
+	}
+}
+
+// UpdateColumns 返回更新时写入的列，空的标题、正文、摘要和语言表示不修改。
+// 只列出允许修改的列，计数器和 created_at 不会被写入；需要在 MarkEditedFrom、KeepSummaryFrom 之后调用
+func (m *Article) UpdateColumns() map[string]any {
+	columns := map[string]any{
+		"edited":          m.Edited,
+		"edit_count":      m.EditCount,
+		"summary_is_auto": m.SummaryIsAuto,
+		"updated_at":      m.UpdatedAt,
+	}
+	if m.Title != "" {
+		columns["title"] = m.Title
+	}
+	if m.Content != "" {
+		columns["content"] = m.Content
+		columns["fingerprint"] = domain.ContentFingerprint(m.Content)
+	}
+	if m.Summary != "" {
+		columns["summary"] = m.Summary
+	}
+	if m.Language != "" {
+		columns["language"] = m.Language
+	}
+	return columns
+}
+
+// ChangedFieldsFrom 返回相对更新前记录发生变化的字段，key 为 domain.Article 的字段名，用于局部更新缓存
+// 需要在 MarkEditedFrom 之后调用
+func (m *Article) ChangedFieldsFrom(old *Article) map[string]any {
+	fields := make(map[string]any)
+	if m.Title != "" && m.Title != old.Title {
+		fields["Title"] = m.Title
+	}
+	if m.Content != "" && m.Content != old.Content {
+		fields["Content"] = m.Content
+	}
+	if m.Edited != old.Edited {
+		fields["Edited"] = m.Edited
+	}
+	if m.EditCount != old.EditCount {
+		fields["EditCount"] = m.EditCount
+	}
+	if m.Summary != "" && m.Summary != old.Summary {
+		fields["Summary"] = m.Summary
+	}
+	if m.SummaryIsAuto != old.SummaryIsAuto {
+		fields["SummaryIsAuto"] = m.SummaryIsAuto
+	}
+	if m.Language != "" && m.Language != old.Language {
+		fields["Language"] = m.Language
+	}
+	if !m.UpdatedAt.IsZero() {
+		fields["UpdatedAt"] = m.UpdatedAt
 	}
+	return fields
 }
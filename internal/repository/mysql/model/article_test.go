@@ -0,0 +1,99 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/repository/mysql/model"
+)
+
+func TestMarkEditedFrom(t *testing.T) {
+	old := &model.Article{Title: "title", Content: "content", EditCount: 2}
+
+	cases := []struct {
+		name      string
+		update    model.Article
+		edited    bool
+		editCount int64
+	}{
+		{"same title and content", model.Article{Title: "title", Content: "content"}, false, 2},
+		{"only counters", model.Article{Views: 100, Likes: 3}, false, 2},
+		{"title changed", model.Article{Title: "new title", Content: "content"}, true, 3},
+		{"content changed", model.Article{Content: "new content"}, true, 3},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			m := tc.update
+			m.MarkEditedFrom(old)
+			assert.Equal(t, tc.edited, m.Edited)
+			assert.Equal(t, tc.editCount, m.EditCount)
+		})
+	}
+}
+
+func TestMarkEditedFromKeepsEditedFlag(t *testing.T) {
+	old := &model.Article{Title: "title", Edited: true, EditCount: 1}
+	m := model.Article{Title: "title"}
+
+	m.MarkEditedFrom(old)
+
+	assert.True(t, m.Edited)
+	assert.Equal(t, int64(1), m.EditCount)
+}
+
+func TestChangedFieldsFrom(t *testing.T) {
+	old := &model.Article{Title: "title", Content: "content", EditCount: 2}
+	m := model.Article{Title: "new title", Content: "content"}
+
+	m.MarkEditedFrom(old)
+
+	assert.Equal(t, map[string]any{
+		"Title":     "new title",
+		"Edited":    true,
+		"EditCount": int64(3),
+	}, m.ChangedFieldsFrom(old))
+}
+
+func TestLanguageChangeIsNotAnEdit(t *testing.T) {
+	old := &model.Article{Title: "title", Language: "en"}
+	m := model.Article{Language: "zh-CN"}
+
+	m.MarkEditedFrom(old)
+
+	assert.False(t, m.Edited)
+	assert.Equal(t, map[string]any{"Language": "zh-CN"}, m.ChangedFieldsFrom(old))
+	assert.Equal(t, "zh-CN", m.UpdateColumns()["language"])
+
+	// 不传语言时保持原值
+	unchanged := model.Article{Title: "title"}
+	assert.NotContains(t, unchanged.UpdateColumns(), "language")
+}
+
+func TestKeepSummaryFrom(t *testing.T) {
+	cases := []struct {
+		name     string
+		old      model.Article
+		update   model.Article
+		summary  string
+		isAuto   bool
+		switched bool
+	}{
+		{"auto follows content", model.Article{Summary: "old", SummaryIsAuto: true}, model.Article{Summary: "new", SummaryIsAuto: true}, "new", true, false},
+		{"author summary kept", model.Article{Summary: "mine"}, model.Article{Summary: "generated", SummaryIsAuto: true}, "", false, false},
+		{"author overrides", model.Article{Summary: "old", SummaryIsAuto: true}, model.Article{Summary: "mine"}, "mine", false, true},
+		{"no summary given", model.Article{Summary: "old", SummaryIsAuto: true}, model.Article{Title: "title"}, "", true, false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			m := tc.update
+			m.KeepSummaryFrom(&tc.old)
+			assert.Equal(t, tc.summary, m.Summary)
+			assert.Equal(t, tc.isAuto, m.SummaryIsAuto)
+			_, changed := m.ChangedFieldsFrom(&tc.old)["SummaryIsAuto"]
+			assert.Equal(t, tc.switched, changed)
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package model
+
+import (
+	"time"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+)
+
+type AuditLog struct {
+	ID         int64     `gorm:"primaryKey;autoIncrement"`
+	ActorID    int64     `gorm:"column:actor_id;not null"`
+	Action     string    `gorm:"type:varchar(32);not null"`
+	TargetType string    `gorm:"column:target_type;type:varchar(32);not null"`
+	TargetID   int64     `gorm:"column:target_id;not null"`
+	Result     string    `gorm:"type:varchar(16);not null"`
+	Detail     string    `gorm:"type:varchar(255);not null;default:''"`
+	CreatedAt  time.Time `gorm:"type:datetime"`
+}
+
+func (AuditLog) TableName() string {
+	return "audit_logs"
+}
+
+func NewAuditLogFromDomain(l *domain.AuditLog) AuditLog {
+	return AuditLog{
+		ID:         l.ID,
+		ActorID:    l.ActorID,
+		Action:     l.Action,
+		TargetType: l.TargetType,
+		TargetID:   l.TargetID,
+		Result:     l.Result,
+		Detail:     l.Detail,
+		CreatedAt:  l.CreatedAt,
+	}
+}
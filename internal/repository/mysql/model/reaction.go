@@ -0,0 +1,27 @@
+package model
+
+import (
+	"time"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+)
+
+type Reaction struct {
+	ArticleID int64     `gorm:"column:article_id;primaryKey"`
+	UserID    int64     `gorm:"column:user_id;primaryKey"`
+	Type      string    `gorm:"column:type;type:varchar(16);primaryKey"`
+	CreatedAt time.Time `gorm:"type:datetime"`
+}
+
+func (Reaction) TableName() string {
+	return "reactions"
+}
+
+func NewReactionFromDomain(r domain.Reaction) Reaction {
+	return Reaction{
+		ArticleID: r.ArticleID,
+		UserID:    r.UserID,
+		Type:      string(r.Type),
+		CreatedAt: r.CreatedAt,
+	}
+}
@@ -0,0 +1,25 @@
+package model
+
+import "time"
+
+// Tag 标签名已经过 domain.NormalizeTag 规范化，全局唯一
+type Tag struct {
+	ID   int64  `gorm:"primaryKey;autoIncrement"`
+	Name string `gorm:"type:varchar(32);not null;uniqueIndex:uk_tag_name"`
+}
+
+func (Tag) TableName() string {
+	return "tag"
+}
+
+// ArticleTag 以 (article_id, tag_id) 为联合主键，按标签筛选文章时走 idx_tag_id。
+// CreatedAt 是标签加到文章上的时间，修改标签时保留下来的关联不会更新，热门标签按它统计
+type ArticleTag struct {
+	ArticleID int64     `gorm:"column:article_id;primaryKey;autoIncrement:false"`
+	TagID     int64     `gorm:"column:tag_id;primaryKey;autoIncrement:false;index:idx_tag_id"`
+	CreatedAt time.Time `gorm:"type:datetime;autoCreateTime;index:idx_article_tag_created_at"`
+}
+
+func (ArticleTag) TableName() string {
+	return "article_tag"
+}
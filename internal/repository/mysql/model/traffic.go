@@ -0,0 +1,22 @@
+package model
+
+import "time"
+
+// Traffic dimensions of TrafficDaily
+const (
+	TrafficDimensionReferrer = "referrer"
+	TrafficDimensionDevice   = "device"
+)
+
+// TrafficDaily 每天每项指标在一个维度上的一个取值占一行
+type TrafficDaily struct {
+	Date      time.Time `gorm:"column:date;type:date;primaryKey"`
+	Metric    string    `gorm:"type:varchar(16);primaryKey"`
+	Dimension string    `gorm:"type:varchar(16);primaryKey"`
+	Value     string    `gorm:"type:varchar(128);primaryKey"`
+	Count     int64     `gorm:"not null;default:0"`
+}
+
+func (TrafficDaily) TableName() string {
+	return "traffic_daily"
+}
@@ -9,8 +9,9 @@ import (
 type User struct {
 	ID        int64     `gorm:"primaryKey;autoIncrement"`
 	Name      string    `gorm:"type:varchar(32);not null"`
-	Username  string    `gorm:"type:varchar(32);not null"`
+	Username  string    `gorm:"type:varchar(32);not null;uniqueIndex:uk_username"`
 	Password  string    `gorm:"type:varchar(64);not null"`
+	Role      string    `gorm:"type:varchar(16);default:user"`
 	CreatedAt time.Time `gorm:"type:datetime"`
 	UpdatedAt time.Time `gorm:"type:datetime"`
 }
@@ -25,6 +26,7 @@ func (m *User) ToDomain() domain.User {
 		Name:      m.Name,
 		Username:  m.Username,
 		Password:  m.Password,
+		Role:      m.Role,
 		CreatedAt: m.CreatedAt,
 		UpdatedAt: m.UpdatedAt,
 	}
@@ -36,6 +38,7 @@ func NewUserFromDomain(a *domain.User) User {
 		Name:      a.Name,
 		Username:  a.Username,
 		Password:  a.Password,
+		Role:      a.Role,
 		CreatedAt: a.CreatedAt,
 		UpdatedAt: a.UpdatedAt,
 	}
@@ -6,10 +6,11 @@ import (
 	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
 )
 
+// UserLike 以 (user_id, article_id) 为联合主键，同一用户对同一文章只有一行
 type UserLike struct {
-	ArticleID int64     `gorm:"column:article_id;not null"`
-	UserID    int64     `gorm:"column:user_id;not null"`
-	CreatedAt time.Time `gorm:"type:datatime"`
+	UserID    int64     `gorm:"column:user_id;primaryKey;autoIncrement:false"`
+	ArticleID int64     `gorm:"column:article_id;primaryKey;autoIncrement:false;index:idx_article_id"`
+	CreatedAt time.Time `gorm:"type:datetime"`
 }
 
 func (UserLike) TableName() string {
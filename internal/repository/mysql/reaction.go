@@ -0,0 +1,85 @@
+package mysql
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/repository/mysql/model"
+)
+
+type reactionRepository struct {
+	DB *gorm.DB
+}
+
+var _ domain.ReactionRepository = (*reactionRepository)(nil)
+
+// NewReactionRepository 创建表情回应的数据库操作层
+func NewReactionRepository(db *gorm.DB) *reactionRepository {
+	return &reactionRepository{db}
+}
+
+func (m *reactionRepository) Add(ctx context.Context, r domain.Reaction) (bool, error) {
+	row := model.NewReactionFromDomain(r)
+	result := m.DB.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&row)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (m *reactionRepository) Remove(ctx context.Context, r domain.Reaction) (bool, error) {
+	result := m.DB.WithContext(ctx).
+		Where("article_id = ? AND user_id = ? AND type = ?", r.ArticleID, r.UserID, string(r.Type)).
+		Delete(&model.Reaction{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (m *reactionRepository) DeleteByArticle(ctx context.Context, aid int64) error {
+	return m.DB.WithContext(ctx).Where("article_id = ?", aid).Delete(&model.Reaction{}).Error
+}
+
+func (m *reactionRepository) CountByArticle(ctx context.Context, aid int64) (domain.ReactionCounts, error) {
+	var rows []struct {
+		Type  string
+		Count int64
+	}
+	err := m.DB.WithContext(ctx).
+		Model(&model.Reaction{}).
+		Select("type, COUNT(*) AS count").
+		Where("article_id = ?", aid).
+		Group("type").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// like 仍然存放在 user_likes 表中
+	var likes int64
+	err = m.DB.WithContext(ctx).
+		Model(&model.UserLike{}).
+		Where("article_id = ?", aid).
+		Count(&likes).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(domain.ReactionCounts, len(domain.ReactionTypes))
+	for _, t := range domain.ReactionTypes {
+		counts[t] = 0
+	}
+	for _, row := range rows {
+		if t := domain.ReactionType(row.Type); t.IsValid() && t != domain.ReactionLike {
+			counts[t] = row.Count
+		}
+	}
+	counts[domain.ReactionLike] = likes
+	return counts, nil
+}
@@ -0,0 +1,70 @@
+package mysql
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/repository/mysql/model"
+)
+
+// GetDeleted 读取已删除的文章和它的标签，文章不存在或没有被删除时返回 domain.ErrNotFound
+func (m *articleRepository) GetDeleted(ctx context.Context, id int64) (domain.Article, error) {
+	var article model.Article
+	err := m.DB.WithContext(ctx).Unscoped().
+		First(&article, "id = ? AND deleted_at IS NOT NULL", id).Error
+	if err != nil {
+		return domain.Article{}, domain.ErrNotFound
+	}
+	ars := []domain.Article{article.ToDomain()}
+	ars[0].DeletedAt = article.DeletedAt.Time
+	if err = loadTags(m.DB.WithContext(ctx), ars); err != nil {
+		return domain.Article{}, err
+	}
+	return ars[0], nil
+}
+
+// Restore 清空 deleted_at 恢复文章，文章不存在或没有被删除时返回 domain.ErrNotFound
+func (m *articleRepository) Restore(ctx context.Context, id int64) error {
+	result := m.DB.WithContext(ctx).Unscoped().Model(&model.Article{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		UpdateColumn("deleted_at", nil)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// FetchDeletedBefore 按 id 顺序读取 t 之前被软删除的文章ID，供清理任务分批处理
+func (m *articleRepository) FetchDeletedBefore(ctx context.Context, t time.Time, limit int) (ids []int64, err error) {
+	err = m.DB.WithContext(ctx).Unscoped().Model(&model.Article{}).
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", t).
+		Order("id").Limit(limit).
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+// Purge 彻底删除已软删除的文章，连同标签关联、点赞和动态。
+// 评论和表情回应由各自的仓储删除，调用方在同一个工作单元中组合，这里的事务会变成保存点
+func (m *articleRepository) Purge(ctx context.Context, id int64) error {
+	return m.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Unscoped().Where("id = ? AND deleted_at IS NOT NULL", id).Delete(&model.Article{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return domain.ErrNotFound
+		}
+		for _, row := range []any{&model.ArticleTag{}, &model.UserLike{}, &model.Activity{}} {
+			if err := tx.Where("article_id = ?", id).Delete(row).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,67 @@
+package mysql_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/repository/mysql"
+)
+
+func TestGetDeletedReadsOnlyDeletedRows(t *testing.T) {
+	db, sqls := newDryRunDB(t)
+	repo := mysql.NewArticleDBRepository(db, false)
+
+	_, err := repo.GetDeleted(context.Background(), 5)
+	require.NoError(t, err)
+
+	// Unscoped 去掉默认的 deleted_at IS NULL，只读已删除的文章；管理员审核需要完整的文章和标签
+	require.Len(t, *sqls, 2)
+	assert.Contains(t, (*sqls)[0], "SELECT * FROM `article` WHERE id = ? AND deleted_at IS NOT NULL")
+	assert.NotContains(t, (*sqls)[0], "`article`.`deleted_at` IS NULL")
+	assert.Contains(t, (*sqls)[1], "FROM `article_tag`")
+}
+
+func TestRestoreClearsDeletedAt(t *testing.T) {
+	db, sqls := newDryRunDB(t)
+	repo := mysql.NewArticleDBRepository(db, false)
+
+	// dry run 不更新任何行，没有被删除或不存在的文章都返回 ErrNotFound
+	err := repo.Restore(context.Background(), 5)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+
+	require.Len(t, *sqls, 1)
+	assert.Equal(t, "UPDATE `article` SET `deleted_at`=? WHERE id = ? AND deleted_at IS NOT NULL", (*sqls)[0])
+}
+
+func TestPurgeOnlyDeletedArticles(t *testing.T) {
+	db, _ := newDryRunDB(t)
+	var deletes []string
+	require.NoError(t, db.Callback().Delete().After("gorm:delete").Register("test:delete", func(tx *gorm.DB) {
+		deletes = append(deletes, tx.Statement.SQL.String())
+	}))
+	repo := mysql.NewArticleDBRepository(db, false)
+
+	// dry run 不删除任何行，没有被软删除的文章返回 ErrNotFound，后面的表不再清理
+	err := repo.Purge(context.Background(), 5)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+
+	require.Len(t, deletes, 1)
+	assert.Equal(t, "DELETE FROM `article` WHERE id = ? AND deleted_at IS NOT NULL", deletes[0])
+}
+
+func TestFetchDeletedBefore(t *testing.T) {
+	db, sqls := newDryRunDB(t)
+	repo := mysql.NewArticleDBRepository(db, false)
+
+	_, err := repo.FetchDeletedBefore(context.Background(), time.Now(), 100)
+	require.NoError(t, err)
+
+	require.Len(t, *sqls, 1)
+	assert.Equal(t, "SELECT `id` FROM `article` WHERE deleted_at IS NOT NULL AND deleted_at < ? ORDER BY id LIMIT ?", (*sqls)[0])
+}
@@ -0,0 +1,45 @@
+package mysql
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/repository/mysql/model"
+)
+
+type siteStatsRepository struct {
+	DB *gorm.DB
+}
+
+var _ domain.SiteStatsRepository = (*siteStatsRepository)(nil)
+
+// NewSiteStatsRepository 创建站点统计仓储，只做简单的 COUNT 查询
+func NewSiteStatsRepository(db *gorm.DB) *siteStatsRepository {
+	return &siteStatsRepository{DB: db}
+}
+
+// statsModels 每项统计对应的表
+var statsModels = map[string]any{
+	domain.StatsArticles: &model.Article{},
+	domain.StatsUsers:    &model.User{},
+	domain.StatsComments: &model.Comment{},
+}
+
+func (r *siteStatsRepository) Count(ctx context.Context, name string, since time.Time) (domain.SiteCounts, error) {
+	m, ok := statsModels[name]
+	if !ok {
+		return domain.SiteCounts{}, domain.ErrBadParamInput
+	}
+
+	var res domain.SiteCounts
+	if err := r.DB.WithContext(ctx).Model(m).Count(&res.Total).Error; err != nil {
+		return domain.SiteCounts{}, err
+	}
+	if err := r.DB.WithContext(ctx).Model(m).Where("created_at >= ?", since).Count(&res.Today).Error; err != nil {
+		return domain.SiteCounts{}, err
+	}
+	return res, nil
+}
@@ -0,0 +1,160 @@
+package mysql
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/repository/mysql/model"
+)
+
+// FetchTags 统计每个标签下可见文章的数量，按数量倒序，数量相同时按名称排序
+func (m *articleRepository) FetchTags(ctx context.Context) ([]domain.TagCount, error) {
+	var rows []struct {
+		Name     string
+		Articles int64
+	}
+	err := m.DB.WithContext(ctx).
+		Table("tag").
+		Select("tag.name, COUNT(*) AS articles").
+		Joins("JOIN article_tag ON article_tag.tag_id = tag.id").
+		Joins("JOIN article ON article.id = article_tag.article_id AND article.hidden = ? AND article.status = ? AND article.deleted_at IS NULL", false, published).
+		Group("tag.id, tag.name").
+		Order("articles DESC, tag.name").
+		Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]domain.TagCount, len(rows))
+	for i, row := range rows {
+		res[i] = domain.TagCount{Name: row.Name, Articles: row.Articles}
+	}
+	return res, nil
+}
+
+type tagRepository struct {
+	DB *gorm.DB
+}
+
+var _ domain.TagRepository = (*tagRepository)(nil)
+
+// NewTagRepository 创建按时间统计标签使用情况的仓储
+func NewTagRepository(db *gorm.DB) *tagRepository {
+	return &tagRepository{DB: db}
+}
+
+// Trending 统计 since 之后加到可见文章上的标签，按文章数倒序，数量相同时按名称排序。
+// 编辑时保留的标签不会更新 created_at，老标签不会因为文章被编辑而排到前面
+func (m *tagRepository) Trending(ctx context.Context, since time.Time, limit int64) ([]domain.TagCount, error) {
+	var rows []struct {
+		Name     string
+		Articles int64
+	}
+	err := m.DB.WithContext(ctx).
+		Table("tag").
+		Select("tag.name, COUNT(*) AS articles").
+		Joins("JOIN article_tag ON article_tag.tag_id = tag.id AND article_tag.created_at >= ?", since).
+		Joins("JOIN article ON article.id = article_tag.article_id AND article.hidden = ? AND article.status = ? AND article.deleted_at IS NULL", false, published).
+		Group("tag.id, tag.name").
+		Order("articles DESC, tag.name").
+		Limit(int(limit)).
+		Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]domain.TagCount, len(rows))
+	for i, row := range rows {
+		res[i] = domain.TagCount{Name: row.Name, Articles: row.Articles}
+	}
+	return res, nil
+}
+
+// taggedWith 返回带有 tag 的文章 ID 子查询
+func (m *articleRepository) taggedWith(tag string) *gorm.DB {
+	return m.DB.Model(&model.ArticleTag{}).
+		Select("article_tag.article_id").
+		Joins("JOIN tag ON tag.id = article_tag.tag_id").
+		Where("tag.name = ?", tag)
+}
+
+// saveTags 在 tx 中把文章的标签替换为 tags，不存在的标签先创建。
+// 替换时只删除不再使用的关联，保留下来的关联不改 created_at
+func saveTags(tx *gorm.DB, articleID int64, tags []string, replace bool) error {
+	if len(tags) == 0 {
+		if replace {
+			return tx.Where("article_id = ?", articleID).Delete(&model.ArticleTag{}).Error
+		}
+		return nil
+	}
+
+	newTags := make([]model.Tag, len(tags))
+	for i, name := range tags {
+		newTags[i] = model.Tag{Name: name}
+	}
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&newTags).Error; err != nil {
+		return err
+	}
+
+	// 已存在的标签不会回填 ID，统一按名称查一次
+	var stored []model.Tag
+	if err := tx.Where("name IN ?", tags).Find(&stored).Error; err != nil {
+		return err
+	}
+	tagIDs := make([]int64, len(stored))
+	links := make([]model.ArticleTag, len(stored))
+	for i, tag := range stored {
+		tagIDs[i] = tag.ID
+		links[i] = model.ArticleTag{ArticleID: articleID, TagID: tag.ID}
+	}
+	if !replace {
+		return tx.Create(&links).Error
+	}
+
+	if err := tx.Where("article_id = ? AND tag_id NOT IN ?", articleID, tagIDs).Delete(&model.ArticleTag{}).Error; err != nil {
+		return err
+	}
+	return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&links).Error
+}
+
+// loadTags 用一次查询填充 articles 的标签，没有标签的文章为空切片
+func loadTags(tx *gorm.DB, articles []domain.Article) error {
+	if len(articles) == 0 {
+		return nil
+	}
+	ids := make([]int64, len(articles))
+	for i := range articles {
+		ids[i] = articles[i].ID
+	}
+
+	var rows []struct {
+		ArticleID int64
+		Name      string
+	}
+	err := tx.Model(&model.ArticleTag{}).
+		Select("article_tag.article_id, tag.name").
+		Joins("JOIN tag ON tag.id = article_tag.tag_id").
+		Where("article_tag.article_id IN ?", ids).
+		Order("tag.name").
+		Find(&rows).Error
+	if err != nil {
+		return err
+	}
+
+	byArticle := make(map[int64][]string, len(articles))
+	for _, row := range rows {
+		byArticle[row.ArticleID] = append(byArticle[row.ArticleID], row.Name)
+	}
+	for i := range articles {
+		if tags, ok := byArticle[articles[i].ID]; ok {
+			articles[i].Tags = tags
+		} else {
+			articles[i].Tags = []string{}
+		}
+	}
+	return nil
+}
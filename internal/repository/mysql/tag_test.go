@@ -0,0 +1,187 @@
+package mysql_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/repository/mysql"
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/repository/mysql/model"
+)
+
+// captureWrites 额外记录 INSERT 和 DELETE 语句
+func captureWrites(t *testing.T, db *gorm.DB, sqls *[]string) {
+	t.Helper()
+	capture := func(tx *gorm.DB) {
+		*sqls = append(*sqls, tx.Statement.SQL.String())
+	}
+	require.NoError(t, db.Callback().Create().After("gorm:create").Register("test:capture", capture))
+	require.NoError(t, db.Callback().Delete().After("gorm:delete").Register("test:capture", capture))
+}
+
+func TestFetchByTag(t *testing.T) {
+	db, sqls := newDryRunDB(t)
+	repo := mysql.NewArticleDBRepository(db, false)
+
+	_, _, err := repo.Fetch(context.Background(), "", 10, "", "golang")
+	require.NoError(t, err)
+
+	// 子查询在拼接时也会经过 query 回调，最后一条才是实际执行的语句
+	require.NotEmpty(t, *sqls)
+	assert.Contains(t, (*sqls)[len(*sqls)-1], "AND id IN (SELECT article_tag.article_id FROM `article_tag` JOIN tag ON tag.id = article_tag.tag_id WHERE tag.name = ?)")
+}
+
+func TestFetchLoadsTags(t *testing.T) {
+	db, _ := newDryRunDB(t)
+	require.NoError(t, db.Callback().Query().After("gorm:query").Register("test:rows", func(tx *gorm.DB) {
+		switch tx.Statement.Table {
+		case "article":
+			if dest, ok := tx.Statement.Dest.(*[]model.Article); ok {
+				*dest = []model.Article{{ID: 1}, {ID: 2}}
+			}
+		case "article_tag":
+			// 标签行是匿名结构体，按字段名填充，已按 tag.name 排序
+			rows := tx.Statement.ReflectValue
+			for _, name := range []string{"go", "redis"} {
+				row := reflect.New(rows.Type().Elem()).Elem()
+				row.FieldByName("ArticleID").SetInt(1)
+				row.FieldByName("Name").SetString(name)
+				rows.Set(reflect.Append(rows, row))
+			}
+		}
+	}))
+	repo := mysql.NewArticleDBRepository(db, false)
+
+	res, _, err := repo.Fetch(context.Background(), "", 10, "", "")
+	require.NoError(t, err)
+	require.Len(t, res, 2)
+	assert.Equal(t, []string{"go", "redis"}, res[0].Tags)
+	assert.Equal(t, []string{}, res[1].Tags, "articles without tags get an empty slice")
+}
+
+func TestStoreWritesTagsInTransaction(t *testing.T) {
+	db, sqls := newDryRunDB(t)
+	captureWrites(t, db, sqls)
+	require.NoError(t, db.Callback().Query().After("gorm:query").Register("test:tags", func(tx *gorm.DB) {
+		if dest, ok := tx.Statement.Dest.(*[]model.Tag); ok {
+			*dest = []model.Tag{{ID: 3, Name: "go"}, {ID: 4, Name: "redis"}}
+		}
+	}))
+	repo := mysql.NewArticleDBRepository(db, false)
+
+	require.NoError(t, repo.Store(context.Background(), &domain.Article{Title: "t", Content: "c", Tags: []string{"go", "redis"}}))
+
+	require.Len(t, *sqls, 5)
+	assert.Contains(t, (*sqls)[0], "INSERT INTO `article`")
+	assert.Equal(t, "INSERT INTO `tag` (`name`) VALUES (?),(?) ON DUPLICATE KEY UPDATE `id`=`id`", (*sqls)[1])
+	assert.Equal(t, "SELECT * FROM `tag` WHERE name IN (?,?)", (*sqls)[2])
+	assert.Equal(t, "INSERT INTO `article_tag` (`article_id`,`tag_id`,`created_at`) VALUES (?,?,?),(?,?,?)", (*sqls)[3])
+	// 直接发布的文章在同一事务中记录发布动态
+	assert.Contains(t, (*sqls)[4], "INSERT INTO `activity`")
+}
+
+func TestUpdateReplacesTags(t *testing.T) {
+	cases := []struct {
+		name    string
+		tags    []string
+		replace bool
+	}{
+		{"nil keeps the tags", nil, false},
+		{"empty removes all tags", []string{}, true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			db, sqls := newDryRunDB(t)
+			captureWrites(t, db, sqls)
+			require.NoError(t, db.Callback().Update().After("gorm:update").Register("test:affected", func(tx *gorm.DB) {
+				tx.RowsAffected = 1
+			}))
+			repo := mysql.NewArticleDBRepository(db, false)
+
+			changed, err := repo.Update(context.Background(), &domain.Article{ID: 1, Title: "new", Tags: tc.tags})
+			require.NoError(t, err)
+
+			deleteTags := "DELETE FROM `article_tag` WHERE article_id = ?"
+			_, patched := changed["Tags"]
+			assert.Equal(t, tc.replace, patched)
+			if tc.replace {
+				assert.Contains(t, *sqls, deleteTags)
+			} else {
+				assert.NotContains(t, *sqls, deleteTags)
+			}
+		})
+	}
+}
+
+func TestUpdateKeepsUnchangedTagLinks(t *testing.T) {
+	db, sqls := newDryRunDB(t)
+	captureWrites(t, db, sqls)
+	require.NoError(t, db.Callback().Update().After("gorm:update").Register("test:affected", func(tx *gorm.DB) {
+		tx.RowsAffected = 1
+	}))
+	require.NoError(t, db.Callback().Query().After("gorm:query").Register("test:tags", func(tx *gorm.DB) {
+		if dest, ok := tx.Statement.Dest.(*[]model.Tag); ok {
+			*dest = []model.Tag{{ID: 3, Name: "go"}, {ID: 4, Name: "redis"}}
+		}
+	}))
+	repo := mysql.NewArticleDBRepository(db, false)
+
+	_, err := repo.Update(context.Background(), &domain.Article{ID: 1, Tags: []string{"go", "redis"}})
+	require.NoError(t, err)
+
+	// 只删除不再使用的标签，已有的关联保留原来的 created_at，热门标签不会因为编辑而重新计入
+	assert.Contains(t, *sqls, "DELETE FROM `article_tag` WHERE article_id = ? AND tag_id NOT IN (?,?)")
+	assert.Contains(t, *sqls, "INSERT INTO `article_tag` (`article_id`,`tag_id`,`created_at`) VALUES (?,?,?),(?,?,?) ON DUPLICATE KEY UPDATE `article_id`=`article_id`")
+	assert.NotContains(t, *sqls, "DELETE FROM `article_tag` WHERE article_id = ?")
+}
+
+func TestDeleteIsSoftAndKeepsTagLinks(t *testing.T) {
+	db, sqls := newDryRunDB(t)
+	captureWrites(t, db, sqls)
+	require.NoError(t, db.Callback().Delete().After("gorm:delete").Register("test:affected", func(tx *gorm.DB) {
+		tx.RowsAffected = 1
+	}))
+	repo := mysql.NewArticleDBRepository(db, false)
+
+	// 软删除只写 deleted_at，标签关联保留，恢复后文章仍然带着原来的标签
+	require.NoError(t, repo.Delete(context.Background(), 1))
+	assert.Equal(t, []string{"UPDATE `article` SET `deleted_at`=? WHERE `article`.`id` = ? AND `article`.`deleted_at` IS NULL"}, *sqls)
+}
+
+func TestFetchTags(t *testing.T) {
+	db, sqls := newDryRunDB(t)
+	repo := mysql.NewArticleDBRepository(db, false)
+
+	_, err := repo.FetchTags(context.Background())
+	require.NoError(t, err)
+
+	require.Len(t, *sqls, 1)
+	assert.Equal(t, "SELECT tag.name, COUNT(*) AS articles FROM `tag` JOIN article_tag ON article_tag.tag_id = tag.id "+
+		"JOIN article ON article.id = article_tag.article_id AND article.hidden = ? AND article.status = ? AND article.deleted_at IS NULL GROUP BY tag.id, tag.name ORDER BY articles DESC, tag.name", (*sqls)[0])
+}
+
+func TestTrendingTags(t *testing.T) {
+	db, sqls := newDryRunDB(t)
+	var vars []any
+	require.NoError(t, db.Callback().Query().After("gorm:query").Register("test:vars", func(tx *gorm.DB) {
+		vars = tx.Statement.Vars
+	}))
+	repo := mysql.NewTagRepository(db)
+	since := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	_, err := repo.Trending(context.Background(), since, 5)
+	require.NoError(t, err)
+
+	// 只统计 since 之后加上的标签，之前加上的标签不计数
+	require.Len(t, *sqls, 1)
+	assert.Equal(t, "SELECT tag.name, COUNT(*) AS articles FROM `tag` JOIN article_tag ON article_tag.tag_id = tag.id AND article_tag.created_at >= ? "+
+		"JOIN article ON article.id = article_tag.article_id AND article.hidden = ? AND article.status = ? AND article.deleted_at IS NULL "+
+		"GROUP BY tag.id, tag.name ORDER BY articles DESC, tag.name LIMIT ?", (*sqls)[0])
+	assert.Equal(t, []any{since, false, "published", 5}, vars)
+}
@@ -0,0 +1,70 @@
+package mysql
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/repository/mysql/model"
+)
+
+type trafficRepository struct {
+	DB *gorm.DB
+}
+
+var _ domain.TrafficRepository = (*trafficRepository)(nil)
+
+func NewTrafficRepository(db *gorm.DB) *trafficRepository {
+	return &trafficRepository{db}
+}
+
+// StoreTraffic 以覆盖的方式写入，同一天重复写入不会重复计数
+func (m *trafficRepository) StoreTraffic(ctx context.Context, day time.Time, metric domain.TrafficMetric, counts domain.TrafficCounts) error {
+	date := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, day.Location())
+	rows := make([]model.TrafficDaily, 0, len(counts.Referrers)+len(counts.Devices))
+	for ref, n := range counts.Referrers {
+		rows = append(rows, model.TrafficDaily{Date: date, Metric: string(metric), Dimension: model.TrafficDimensionReferrer, Value: ref, Count: n})
+	}
+	for device, n := range counts.Devices {
+		rows = append(rows, model.TrafficDaily{Date: date, Metric: string(metric), Dimension: model.TrafficDimensionDevice, Value: device, Count: n})
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	return m.DB.WithContext(ctx).
+		Clauses(clause.OnConflict{DoUpdates: clause.AssignmentColumns([]string{"count"})}).
+		Create(&rows).Error
+}
+
+func (m *trafficRepository) FetchTraffic(ctx context.Context, from, to time.Time) ([]domain.TrafficDay, error) {
+	var rows []model.TrafficDaily
+	err := m.DB.WithContext(ctx).
+		Where("date BETWEEN ? AND ?", from.Format(domain.TrafficDateLayout), to.Format(domain.TrafficDateLayout)).
+		Order("date").
+		Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var res []domain.TrafficDay
+	for _, row := range rows {
+		date := row.Date.Format(domain.TrafficDateLayout)
+		if len(res) == 0 || res[len(res)-1].Date != date {
+			res = append(res, domain.NewTrafficDay(row.Date))
+		}
+		counts := res[len(res)-1].Counts(domain.TrafficMetric(row.Metric))
+		if counts == nil {
+			continue
+		}
+		switch row.Dimension {
+		case model.TrafficDimensionReferrer:
+			counts.Referrers[row.Value] = row.Count
+		case model.TrafficDimensionDevice:
+			counts.Devices[row.Value] = row.Count
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,34 @@
+package mysql
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+)
+
+type unitOfWork struct {
+	DB                 *gorm.DB
+	listIncludeContent bool
+}
+
+var _ domain.UnitOfWork = (*unitOfWork)(nil)
+
+// NewUnitOfWork 创建工作单元，listIncludeContent 与 NewArticleDBRepository 的含义相同
+func NewUnitOfWork(db *gorm.DB, listIncludeContent bool) *unitOfWork {
+	return &unitOfWork{DB: db, listIncludeContent: listIncludeContent}
+}
+
+// Do 开启事务，用事务句柄构造一组仓储交给 fn。
+// 仓储内部自己开启的事务在这里会变成保存点，所以 Store、Delete 这类方法可以直接组合使用
+func (u *unitOfWork) Do(ctx context.Context, fn func(repos domain.Repos) error) error {
+	return u.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(domain.Repos{
+			Articles:  NewArticleDBRepository(tx, u.listIncludeContent),
+			Comments:  NewCommentRepository(tx),
+			Reactions: NewReactionRepository(tx),
+			Users:     NewUserRepository(tx),
+		})
+	})
+}
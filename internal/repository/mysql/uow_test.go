@@ -0,0 +1,108 @@
+package mysql_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	gormMysql "gorm.io/driver/mysql"
+	"gorm.io/gorm"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/repository/mysql"
+)
+
+// txPool 与 dryRunPool 一样不执行语句，另外记录事务的开始、提交和回滚。
+// 连接池本身不能实现 Commit，否则 gorm 会认为已经在事务中，只创建保存点
+type txPool struct {
+	gorm.ConnPool
+	events *[]string
+}
+
+func (p txPool) BeginTx(context.Context, *sql.TxOptions) (gorm.ConnPool, error) {
+	*p.events = append(*p.events, "begin")
+	return &txConn{p}, nil
+}
+
+type txConn struct {
+	txPool
+}
+
+func (c *txConn) Commit() error {
+	*c.events = append(*c.events, "commit")
+	return nil
+}
+
+func (c *txConn) Rollback() error {
+	*c.events = append(*c.events, "rollback")
+	return nil
+}
+
+// newTxDB 返回 dry run 的 gorm.DB，执行过的 DELETE 语句按表名记录，failTable 上的删除返回 errBoom
+func newTxDB(t *testing.T, failTable string) (*gorm.DB, *[]string, *[]string) {
+	t.Helper()
+	events := new([]string)
+	db, err := gorm.Open(gormMysql.New(gormMysql.Config{
+		Conn:                      txPool{ConnPool: dryRunPool{}, events: events},
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{
+		DryRun:                 true,
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+	})
+	require.NoError(t, err)
+
+	deleted := new([]string)
+	require.NoError(t, db.Callback().Delete().Before("gorm:delete").Register("test:fail", func(tx *gorm.DB) {
+		if tx.Statement.Table == failTable {
+			_ = tx.AddError(errBoom)
+		}
+	}))
+	// dry run 不执行语句，手动标记删除成功
+	require.NoError(t, db.Callback().Delete().After("gorm:delete").Register("test:affected", func(tx *gorm.DB) {
+		if tx.Error == nil {
+			tx.RowsAffected = 1
+			*deleted = append(*deleted, tx.Statement.Table)
+		}
+	}))
+	return db, events, deleted
+}
+
+var errBoom = errors.New("boom")
+
+// purgeArticle 与 purge-deleted 维护命令的组合方式相同
+func purgeArticle(ctx context.Context, uow domain.UnitOfWork, id int64) error {
+	return uow.Do(ctx, func(repos domain.Repos) error {
+		if err := repos.Articles.Purge(ctx, id); err != nil {
+			return err
+		}
+		if err := repos.Comments.DeleteByArticle(ctx, id); err != nil {
+			return err
+		}
+		return repos.Reactions.DeleteByArticle(ctx, id)
+	})
+}
+
+func TestUnitOfWorkCommits(t *testing.T) {
+	db, events, deleted := newTxDB(t, "")
+	uow := mysql.NewUnitOfWork(db, false)
+
+	require.NoError(t, purgeArticle(context.Background(), uow, 1))
+	assert.Equal(t, []string{"begin", "commit"}, *events)
+	assert.Equal(t, []string{"article", "article_tag", "user_likes", "activity", "comment", "reactions"}, *deleted)
+}
+
+func TestUnitOfWorkRollsBackOnSecondRepository(t *testing.T) {
+	db, events, deleted := newTxDB(t, "comment")
+	uow := mysql.NewUnitOfWork(db, false)
+
+	err := purgeArticle(context.Background(), uow, 1)
+	require.ErrorIs(t, err, errBoom)
+
+	// 文章已经在事务中删除，评论失败后整个事务回滚，后面的仓储不再执行
+	assert.Equal(t, []string{"begin", "rollback"}, *events)
+	assert.Equal(t, []string{"article", "article_tag", "user_likes", "activity"}, *deleted)
+}
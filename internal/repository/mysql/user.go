@@ -2,12 +2,24 @@ package mysql
 
 import (
 	"context"
+	"errors"
+	"strconv"
+	"strings"
 
 	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
 	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/repository/mysql/model"
+	mysqlDriver "github.com/go-sql-driver/mysql"
 	"gorm.io/gorm"
 )
 
+// errDuplicateEntry MySQL 违反唯一约束时的错误码
+const errDuplicateEntry = 1062
+
+func isDuplicateEntry(err error) bool {
+	var mysqlErr *mysqlDriver.MySQLError
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == errDuplicateEntry
+}
+
 type userRepository struct {
 	DB *gorm.DB
 }
@@ -23,7 +35,11 @@ func NewUserRepository(db *gorm.DB) *userRepository {
 
 func (m *userRepository) GetByID(ctx context.Context, id int64) (domain.User, error) {
 	var user model.User
-	if err := m.DB.WithContext(ctx).First(&user, "id = ?", id).Error; err != nil {
+	err := m.DB.WithContext(ctx).First(&user, "id = ?", id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return domain.User{}, domain.ErrNotFound
+	}
+	if err != nil {
 		return domain.User{}, err
 	}
 
@@ -35,6 +51,10 @@ func (m *userRepository) Insert(ctx context.Context, a *domain.User) error {
 
 	result := m.DB.WithContext(ctx).Create(&userModel)
 	if result.Error != nil {
+		// 并发注册同一个用户名时都能通过查重，最终由唯一索引拦下后到的一个
+		if isDuplicateEntry(result.Error) {
+			return domain.ErrUserAlreadyExists
+		}
 		return result.Error
 	}
 
@@ -52,7 +72,11 @@ func (m *userRepository) Update(ctx context.Context, a *domain.User) error {
 
 func (m *userRepository) GetByUsername(ctx context.Context, username string) (domain.User, error) {
 	var user model.User
-	if err := m.DB.WithContext(ctx).First(&user, "username = ?", username).Error; err != nil {
+	err := m.DB.WithContext(ctx).First(&user, "username = ?", domain.NormalizeUsername(username)).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return domain.User{}, domain.ErrNotFound
+	}
+	if err != nil {
 		return domain.User{}, err
 	}
 
@@ -68,3 +92,37 @@ func (m *userRepository) GetByIDs(ctx context.Context, uids []int64) ([]domain.U
 	}
 	return res, err
 }
+
+func (m *userRepository) List(ctx context.Context, cursor string, limit int64, search string) ([]domain.User, error) {
+	var lastID int64
+	if cursor != "" {
+		id, err := strconv.ParseInt(cursor, 10, 64)
+		if err != nil {
+			return nil, domain.ErrBadParamInput
+		}
+		lastID = id
+	}
+
+	query := m.DB.WithContext(ctx).Model(&model.User{}).Where("id > ?", lastID)
+	if search = strings.TrimSpace(search); search != "" {
+		prefix := escapeLike(search) + "%"
+		query = query.Where("username LIKE ? OR name LIKE ?", prefix, prefix)
+	}
+
+	var users []model.User
+	err := query.Order("id").Limit(int(limit)).Find(&users).Error
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]domain.User, len(users))
+	for i := range users {
+		res[i] = users[i].ToDomain()
+	}
+	return res, nil
+}
+
+// escapeLike 转义 LIKE 中的通配符，使用户输入按字面匹配
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
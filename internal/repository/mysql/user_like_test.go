@@ -0,0 +1,151 @@
+package mysql_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/repository/mysql"
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/repository/mysql/model"
+)
+
+func TestApplyLikeChangesUpsertsOnCompositeKey(t *testing.T) {
+	db, _ := newDryRunDB(t)
+	var insert, del string
+	var insertVars []any
+	require.NoError(t, db.Callback().Create().After("gorm:create").Register("test:insert", func(tx *gorm.DB) {
+		insert, insertVars = tx.Statement.SQL.String(), tx.Statement.Vars
+	}))
+	require.NoError(t, db.Callback().Delete().After("gorm:delete").Register("test:delete", func(tx *gorm.DB) {
+		del = tx.Statement.SQL.String()
+	}))
+	// dry run 时没有真实数据，让文章存在性检查认为文章 1 存在
+	require.NoError(t, db.Callback().Query().After("gorm:query").Register("test:pluck", func(tx *gorm.DB) {
+		if ars, ok := tx.Statement.Dest.(*[]model.Article); ok {
+			*ars = []model.Article{{ID: 1}}
+		}
+	}))
+	repo := mysql.NewArticleDBRepository(db, false)
+
+	like := domain.UserLike{ArticleID: 1, UserID: 2}
+	err := repo.ApplyLikeChanges(context.Background(), domain.LikeStateChanges{
+		ToAdd:    []domain.UserLike{like, like},
+		ToRemove: []domain.UserLike{{ArticleID: 1, UserID: 3}},
+	})
+	require.NoError(t, err)
+
+	// 重复的点赞只插入一行，已存在时依赖联合主键走 upsert
+	assert.Contains(t, insert, "ON DUPLICATE KEY UPDATE")
+	assert.Equal(t, 1, strings.Count(insert, "(?,?,?)"))
+	assert.Equal(t, []any{int64(2), int64(1)}, insertVars[:2])
+
+	// 删除只针对指定的 (user_id, article_id)
+	assert.Contains(t, del, "(`user_likes`.`user_id`,`user_likes`.`article_id`) IN ((?,?))")
+}
+
+func TestApplyLikeChangesCleansUpDeletedArticles(t *testing.T) {
+	db, sqls := newDryRunDB(t)
+	var inserts, deletes []string
+	require.NoError(t, db.Callback().Create().After("gorm:create").Register("test:insert", func(tx *gorm.DB) {
+		inserts = append(inserts, tx.Statement.SQL.String())
+	}))
+	require.NoError(t, db.Callback().Delete().After("gorm:delete").Register("test:delete", func(tx *gorm.DB) {
+		deletes = append(deletes, tx.Statement.SQL.String())
+	}))
+	// 文章 1 在点赞之后、同步之前被彻底删除，软删除的文章也查不到
+	require.NoError(t, db.Callback().Query().After("gorm:query").Register("test:pluck", func(tx *gorm.DB) {
+		if ars, ok := tx.Statement.Dest.(*[]model.Article); ok {
+			*ars = []model.Article{}
+		}
+	}))
+	repo := mysql.NewArticleDBRepository(db, false)
+
+	err := repo.ApplyLikeChanges(context.Background(), domain.LikeStateChanges{
+		ToAdd: []domain.UserLike{{ArticleID: 1, UserID: 2}},
+	})
+	require.NoError(t, err)
+
+	assert.Empty(t, inserts, "no like row for a deleted article")
+	require.Len(t, deletes, 1)
+	assert.Contains(t, deletes[0], "DELETE FROM `user_likes` WHERE article_id IN (?)")
+	for _, sql := range *sqls {
+		assert.NotContains(t, sql, "UPDATE `article`", "no count update for a deleted article")
+	}
+}
+
+func TestReconcileLikesUpdatesOnlyDrifted(t *testing.T) {
+	db, sqls := newDryRunDB(t)
+	// dry run 时 user_likes 计数为空，文章 1 存了 5 个赞因此被修正为 0，文章 2 本来就是 0
+	require.NoError(t, db.Callback().Query().After("gorm:query").Register("test:articles", func(tx *gorm.DB) {
+		if ars, ok := tx.Statement.Dest.(*[]model.Article); ok {
+			*ars = []model.Article{{ID: 1, Likes: 5}, {ID: 2}}
+		}
+	}))
+	repo := mysql.NewArticleDBRepository(db, false)
+
+	batch, err := repo.ReconcileLikes(context.Background(), 0, 2)
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, batch.Checked)
+	assert.Equal(t, int64(2), batch.LastID)
+	assert.Equal(t, []domain.LikeDrift{{ArticleID: 1, Stored: 5, Actual: 0}}, batch.Repaired)
+
+	require.Len(t, *sqls, 3)
+	assert.Contains(t, (*sqls)[0], "FOR UPDATE")
+	assert.Contains(t, (*sqls)[1], "COUNT(*) AS cnt")
+	assert.Contains(t, (*sqls)[1], "GROUP BY `article_id`")
+	assert.Contains(t, (*sqls)[2], "`likes`=?")
+}
+
+func TestApplyLikeChangesKeepsLikesOfSoftDeletedArticle(t *testing.T) {
+	db, sqls := newDryRunDB(t)
+	var inserts, deletes []string
+	require.NoError(t, db.Callback().Create().After("gorm:create").Register("test:insert", func(tx *gorm.DB) {
+		inserts = append(inserts, tx.Statement.SQL.String())
+	}))
+	require.NoError(t, db.Callback().Delete().After("gorm:delete").Register("test:delete", func(tx *gorm.DB) {
+		deletes = append(deletes, tx.Statement.SQL.String())
+		tx.RowsAffected = 1
+	}))
+	// 文章 1 已经有 3 个赞，被软删除后布隆过滤器里仍然有它，又收到一个赞
+	require.NoError(t, db.Callback().Query().After("gorm:query").Register("test:articles", func(tx *gorm.DB) {
+		if ars, ok := tx.Statement.Dest.(*[]model.Article); ok {
+			*ars = []model.Article{{ID: 1, Likes: 3, DeletedAt: gorm.DeletedAt{Time: time.Now(), Valid: true}}}
+		}
+	}))
+	require.NoError(t, db.Callback().Update().After("gorm:update").Register("test:affected", func(tx *gorm.DB) {
+		tx.RowsAffected = 1
+	}))
+	repo := mysql.NewArticleDBRepository(db, false)
+
+	require.NoError(t, repo.Delete(context.Background(), 1))
+	deletes = nil
+	err := repo.ApplyLikeChanges(context.Background(), domain.LikeStateChanges{
+		ToAdd: []domain.UserLike{{ArticleID: 1, UserID: 2}},
+	})
+	require.NoError(t, err)
+	require.NoError(t, repo.Restore(context.Background(), 1))
+
+	// 存在性检查要能看到软删除的文章
+	var check string
+	for _, sql := range *sqls {
+		if strings.Contains(sql, "LOCK IN SHARE MODE") || strings.Contains(sql, "FOR SHARE") {
+			check = sql
+		}
+	}
+	require.NotEmpty(t, check)
+	assert.NotContains(t, check, "deleted_at` IS NULL")
+
+	// 已有的点赞和计数都没有被动过，恢复后点赞数原样保留
+	assert.Empty(t, deletes, "likes of a soft-deleted article are kept")
+	assert.Empty(t, inserts)
+	for _, sql := range *sqls {
+		assert.NotContains(t, sql, "`likes`=", "the like count of a soft-deleted article is kept")
+	}
+}
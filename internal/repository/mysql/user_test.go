@@ -0,0 +1,95 @@
+package mysql_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	mysqlDriver "github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	gormMysql "gorm.io/driver/mysql"
+	"gorm.io/gorm"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/repository/mysql"
+)
+
+func TestUserListPagesByID(t *testing.T) {
+	db, sqls := newDryRunDB(t)
+	repo := mysql.NewUserRepository(db)
+
+	_, err := repo.List(context.Background(), "42", 10, "")
+	require.NoError(t, err)
+
+	require.Len(t, *sqls, 1)
+	assert.Equal(t, "SELECT * FROM `user` WHERE id > ? ORDER BY id LIMIT ?", (*sqls)[0])
+}
+
+func TestUserListSearchByPrefix(t *testing.T) {
+	db, sqls := newDryRunDB(t)
+	repo := mysql.NewUserRepository(db)
+
+	var vars []any
+	err := db.Callback().Query().After("gorm:query").Register("test:vars", func(tx *gorm.DB) {
+		vars = tx.Statement.Vars
+	})
+	require.NoError(t, err)
+
+	_, err = repo.List(context.Background(), "", 10, " ad_m%n ")
+	require.NoError(t, err)
+
+	require.Len(t, *sqls, 1)
+	assert.Equal(t, "SELECT * FROM `user` WHERE id > ? AND (username LIKE ? OR name LIKE ?) ORDER BY id LIMIT ?", (*sqls)[0])
+	assert.Equal(t, []any{int64(0), `ad\_m\%n%`, `ad\_m\%n%`, 10}, vars)
+}
+
+func TestUserListRejectsBadCursor(t *testing.T) {
+	db, _ := newDryRunDB(t)
+	repo := mysql.NewUserRepository(db)
+
+	_, err := repo.List(context.Background(), "not-a-number", 10, "")
+	assert.ErrorIs(t, err, domain.ErrBadParamInput)
+}
+
+func TestUserGetByUsernameNormalizes(t *testing.T) {
+	db, sqls := newDryRunDB(t)
+	repo := mysql.NewUserRepository(db)
+
+	var vars []any
+	err := db.Callback().Query().After("gorm:query").Register("test:vars", func(tx *gorm.DB) {
+		vars = tx.Statement.Vars
+	})
+	require.NoError(t, err)
+
+	_, _ = repo.GetByUsername(context.Background(), " Alice ")
+
+	require.Len(t, *sqls, 1)
+	assert.Equal(t, "alice", vars[0])
+}
+
+// duplicateEntryPool 像违反唯一索引的 MySQL 一样拒绝所有写入
+type duplicateEntryPool struct {
+	dryRunPool
+}
+
+func (duplicateEntryPool) ExecContext(context.Context, string, ...any) (sql.Result, error) {
+	return nil, &mysqlDriver.MySQLError{Number: 1062, Message: "Duplicate entry 'alice' for key 'user.username'"}
+}
+
+func TestUserInsertDuplicateUsername(t *testing.T) {
+	db, err := gorm.Open(gormMysql.New(gormMysql.Config{
+		Conn:                      duplicateEntryPool{},
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+	})
+	require.NoError(t, err)
+	repo := mysql.NewUserRepository(db)
+
+	err = repo.Insert(context.Background(), &domain.User{Username: "alice"})
+	require.ErrorIs(t, err, domain.ErrUserAlreadyExists)
+	// 对外是 ErrConflict
+	require.ErrorIs(t, err, domain.ErrConflict)
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"strconv"
 	"time"
+	"unicode/utf8"
 
 	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
 	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/repository/cache"
@@ -20,60 +21,137 @@ const (
 	KeyHotDailyRaw            = "article:hot:daily:raw:%s"
 	KeyHotDailyAggreGatedRank = "article:hot:daily:rank"
 	KeyHotHistoryRank         = "article:hot:history:rank"
+	KeyHotHistoryRankStale    = "article:hot:history:rank:stale"
 	KeyLikesBuffer            = "article:likes:%d"
+	KeyLikeRankDedup          = "article:likes:ranked:%s"
+	KeyUserLikeRate           = "article:user:%d:likes:%s"
 	KeyViewsBuffer            = "article:views:buffer"
 	KeyViewsProcessing        = "article:views:processing"
 	KeyHome                   = "article:home"
+	KeyArticleLock            = "article:lock:%d"
+	KeyArticleVersion         = "article:version:%d"
 )
 
+const (
+	// articleLockTTL 单篇文章读改写锁的过期时间，防止持有者崩溃后死锁
+	articleLockTTL = 2 * time.Second
+	// articleLockRetries 获取锁的重试次数，每次间隔 articleLockRetryDelay
+	articleLockRetries    = 5
+	articleLockRetryDelay = 20 * time.Millisecond
+	// patchedArticleTTL 局部更新后的逻辑过期时间，与回源重建保持一致
+	patchedArticleTTL = 10 * time.Minute
+	// articleCacheTTL 文章缓存和版本号的物理过期时间
+	articleCacheTTL = 24 * time.Hour
+	// dailyRankTTL 今日热榜聚合结果的有效期，过期后下一次请求重新聚合小时分桶
+	dailyRankTTL = 5 * time.Minute
+	// dailyRankHours 今日热榜聚合的小时分桶数
+	dailyRankHours = 24
+	// historyRankTTL 历史热榜的有效期，过期后由下一次请求触发重建
+	historyRankTTL = time.Hour
+	// staleHistoryRankTTL 历史热榜旧副本的保留时间，重建期间用它兜底
+	staleHistoryRankTTL = 7 * 24 * time.Hour
+	// maxLikesPerHour 单个用户每小时最多点赞的次数，超过后返回 ErrTooManyRequests
+	maxLikesPerHour = 60
+	// userLikedArticlesTTL 用户点赞集合的过期时间，与点赞脚本中的 EXPIRE 一致
+	userLikedArticlesTTL = 30 * time.Minute
+	// likedSetPlaceholder 点赞集合中的占位成员。Redis 不保存空集合，加载过但没有点赞的用户
+	// 也要留下这个 key；它不是数字，SISMEMBER 文章ID时不会被当成已点赞
+	likedSetPlaceholder = "loaded"
+)
+
+// DefaultMGetBatchSize 批量读取文章缓存时每条 MGET 的 key 数
+const DefaultMGetBatchSize = 100
+
+var errArticleLocked = errors.New("article cache is locked by another writer")
+
 type articleCache struct {
 	client *redis.Client
+	keyPrefix
+	maxContentSize int // 缓存中正文的最大字节数，0 表示不限制
+	// mgetBatchSize 批量读取文章时每条 MGET 最多包含的 key 数，避免热榜等大批量读取变成一条很大的命令
+	mgetBatchSize int
 }
 
 var _ domain.ArticleCache = (*articleCache)(nil)
 
-func NewArticleCache(client *redis.Client) *articleCache {
+// NewArticleCache 创建文章缓存，prefix 会加在所有 key 前面。
+// 正文超过 maxContentSize 字节的文章只缓存截断后的正文，maxContentSize 为 0 时不截断。
+// 批量读取时每条 MGET 最多 mgetBatchSize 个 key，不大于 0 时使用 DefaultMGetBatchSize
+func NewArticleCache(client *redis.Client, prefix string, maxContentSize, mgetBatchSize int) *articleCache {
+	if mgetBatchSize <= 0 {
+		mgetBatchSize = DefaultMGetBatchSize
+	}
 	return &articleCache{
 		client,
+		keyPrefix(prefix),
+		maxContentSize,
+		mgetBatchSize,
 	}
 }
 
+// truncateContent 返回适合写入缓存的文章副本，正文过长时按 UTF-8 字符边界截断并打上标记
+func (c *articleCache) truncateContent(ar domain.Article) domain.Article {
+	if c.maxContentSize <= 0 || len(ar.Content) <= c.maxContentSize {
+		return ar
+	}
+	n := c.maxContentSize
+	for n > 0 && !utf8.RuneStart(ar.Content[n]) {
+		n--
+	}
+	ar.Content = ar.Content[:n]
+	ar.ContentTruncated = true
+	return ar
+}
+
+// homePage 是首页缓存的格式，旧格式（文章数组）解析失败，按缓存未命中处理
+type homePage struct {
+	Articles []domain.Article `json:"articles"`
+	HasMore  bool             `json:"has_more"`
+}
+
 // GetHomeWithLogicalExpire 获取首页数据，支持逻辑过期检测
 // 返回: 数据、是否逻辑过期、错误
-func (c *articleCache) GetHomeWithLogicalExpire(ctx context.Context) ([]domain.Article, bool, error) {
-	key := KeyHome
+func (c *articleCache) GetHomeWithLogicalExpire(ctx context.Context) (domain.ArticlePage, bool, error) {
+	key := c.key(KeyHome)
 	data, err := c.client.Get(ctx, key).Bytes()
 	if err != nil {
-		return nil, false, err
+		return domain.ArticlePage{}, false, err
 	}
 
 	var wrapper cache.DataWithLogicalExpire
 	err = json.Unmarshal(data, &wrapper)
 	if err != nil {
-		return nil, false, err
+		return domain.ArticlePage{}, false, err
 	}
 
 	// 解析实际数据
-	articlesJSON, err := json.Marshal(wrapper.Data)
+	pageJSON, err := json.Marshal(wrapper.Data)
 	if err != nil {
-		return nil, false, err
+		return domain.ArticlePage{}, false, err
 	}
 
-	var articles []domain.Article
-	err = json.Unmarshal(articlesJSON, &articles)
+	var page homePage
+	err = json.Unmarshal(pageJSON, &page)
 	if err != nil {
-		return nil, false, err
+		return domain.ArticlePage{}, false, err
 	}
 
 	// 检查是否逻辑过期
 	isExpired := wrapper.IsLogicalExpired()
-	return articles, isExpired, nil
+	return domain.ArticlePage{Articles: page.Articles, HasMore: page.HasMore}, isExpired, nil
 }
 
 // SetHomeWithLogicalExpire 设置首页数据，使用逻辑过期
-func (c *articleCache) SetHomeWithLogicalExpire(ctx context.Context, ars []domain.Article, ttl time.Duration) error {
-	key := KeyHome
-	wrapper := cache.NewDataWithLogicalExpire(ars, ttl)
+func (c *articleCache) SetHomeWithLogicalExpire(ctx context.Context, page domain.ArticlePage, ttl time.Duration) error {
+	key := c.key(KeyHome)
+	cached := homePage{
+		Articles: make([]domain.Article, len(page.Articles)),
+		HasMore:  page.HasMore,
+	}
+	for i := range page.Articles {
+		cached.Articles[i] = c.truncateContent(page.Articles[i])
+	}
+	wrapper := cache.NewDataWithLogicalExpire(cached, ttl)
 	data, err := json.Marshal(wrapper)
 	if err != nil {
 		return err
@@ -83,9 +161,13 @@ func (c *articleCache) SetHomeWithLogicalExpire(ctx context.Context, ars []domai
 	return err
 }
 
+func (c *articleCache) DeleteHome(ctx context.Context) error {
+	return c.client.Del(ctx, c.key(KeyHome)).Err()
+}
+
 // GetArticleWithLogicalExpire 获取文章，支持逻辑过期
 func (c *articleCache) GetArticleWithLogicalExpire(ctx context.Context, id int64) (domain.Article, bool, error) {
-	key := fmt.Sprintf(KeyArticles, id)
+	key := c.key(KeyArticles, id)
 	data, err := c.client.Get(ctx, key).Bytes()
 	if errors.Is(err, redis.Nil) {
 		return domain.Article{}, false, redis.Nil
@@ -121,10 +203,10 @@ func (c *articleCache) GetArticleByIDsWithLogicalExpire(ctx context.Context, ids
 
 	keys := make([]string, len(ids))
 	for i, id := range ids {
-		keys[i] = fmt.Sprintf(KeyArticles, id)
+		keys[i] = c.key(KeyArticles, id)
 	}
 
-	jsonList, err := c.client.MGet(ctx, keys...).Result()
+	jsonList, err := c.mget(ctx, keys)
 	if err != nil {
 		return nil, err
 	}
@@ -156,44 +238,247 @@ func (c *articleCache) GetArticleByIDsWithLogicalExpire(ctx context.Context, ids
 	return articles, nil
 }
 
-// SetArticleWithLogicalExpire 设置文章缓存，使用逻辑过期
-func (c *articleCache) SetArticleWithLogicalExpire(ctx context.Context, ar *domain.Article, ttl time.Duration) error {
-	key := fmt.Sprintf(KeyArticles, ar.ID)
-	wrapper := cache.NewDataWithLogicalExpire(ar, ttl)
-	data, err := json.Marshal(wrapper)
+// mget 按 mgetBatchSize 分批 MGET，各批在同一个 pipeline 中发送，结果按 keys 的顺序合并
+func (c *articleCache) mget(ctx context.Context, keys []string) ([]any, error) {
+	size := c.mgetBatchSize
+	if len(keys) <= size {
+		return c.client.MGet(ctx, keys...).Result()
+	}
+
+	cmds := make([]*redis.SliceCmd, 0, (len(keys)+size-1)/size)
+	_, err := c.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for start := 0; start < len(keys); start += size {
+			cmds = append(cmds, pipe.MGet(ctx, keys[start:min(start+size, len(keys))]...))
+		}
+		return nil
+	})
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	res := make([]any, 0, len(keys))
+	for _, cmd := range cmds {
+		res = append(res, cmd.Val()...)
+	}
+	return res, nil
+}
+
+// GetArticleVersions 返回文章缓存的版本号，没有版本号的文章为 0
+func (c *articleCache) GetArticleVersions(ctx context.Context, ids []int64) (map[int64]int64, error) {
+	res := make(map[int64]int64, len(ids))
+	if len(ids) == 0 {
+		return res, nil
+	}
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = c.key(KeyArticleVersion, id)
+	}
+	vals, err := c.mget(ctx, keys)
+	if err != nil {
+		return nil, err
 	}
-	return c.client.Set(ctx, key, data, 24*time.Hour).Err()
+	for i, val := range vals {
+		res[ids[i]] = 0
+		if str, ok := val.(string); ok {
+			v, err := strconv.ParseInt(str, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("malformed article cache version, ID: %d", ids[i])
+			}
+			res[ids[i]] = v
+		}
+	}
+	return res, nil
+}
+
+// SetArticleWithLogicalExpire 设置文章缓存，使用逻辑过期。
+// version 是回源前读到的版本号，版本号已经变化或有人正在局部更新时放弃写入
+func (c *articleCache) SetArticleWithLogicalExpire(ctx context.Context, ar *domain.Article, version int64, ttl time.Duration) error {
+	return c.setArticles(ctx, []domain.Article{*ar}, map[int64]int64{ar.ID: version}, ttl)
+}
+
+// BatchSetArticleWithLogicalExpire 批量设置文章缓存，每篇文章与 SetArticleWithLogicalExpire 一样按版本号写入
+func (c *articleCache) BatchSetArticleWithLogicalExpire(ctx context.Context, ars []domain.Article, versions map[int64]int64, ttl time.Duration) error {
+	return c.setArticles(ctx, ars, versions, ttl)
 }
 
-// BatchSetArticleWithLogicalExpire 批量设置文章缓存
-func (c *articleCache) BatchSetArticleWithLogicalExpire(ctx context.Context, ars []domain.Article, ttl time.Duration) error {
+func (c *articleCache) setArticles(ctx context.Context, ars []domain.Article, versions map[int64]int64, ttl time.Duration) error {
 	if len(ars) == 0 {
 		return nil
 	}
 
-	iar := make([]any, 0, 2*len(ars))
+	keys := make([]string, 0, 3*len(ars))
+	args := make([]any, 0, 1+2*len(ars))
+	args = append(args, int64(articleCacheTTL/time.Second))
 	var errMarshal error = nil
 	for i := range ars {
-		wrapper := cache.NewDataWithLogicalExpire(ars[i], ttl)
+		wrapper := cache.NewDataWithLogicalExpire(c.truncateContent(ars[i]), ttl)
 		data, err := json.Marshal(wrapper)
 		if err != nil {
 			logrus.Warnf("failed to marshal article for cache, ID: %d, err: %v", ars[i].ID, err)
 			errMarshal = err
 			continue
 		}
-		key := fmt.Sprintf(KeyArticles, ars[i].ID)
-		iar = append(iar, key, data)
+		id := ars[i].ID
+		keys = append(keys, c.key(KeyArticles, id), c.key(KeyArticleLock, id), c.key(KeyArticleVersion, id))
+		args = append(args, data, versions[id])
 	}
-	if len(iar) == 0 {
+	if len(keys) == 0 {
 		return errMarshal
 	}
-	return c.client.MSet(ctx, iar...).Err()
+
+	// KEYS = {文章, 锁, 版本号} * n，ARGV = {物理过期秒数, (数据, 回源前的版本号) * n}。
+	// 在脚本中检查锁和版本号，与 PatchArticle 的读改写互斥，也不会写回局部更新之前读出的旧数据
+	script := redis.NewScript(`
+        for i = 1, #KEYS, 3 do
+            local j = (i - 1) / 3 * 2 + 2
+            local version = redis.call('GET', KEYS[i + 2]) or '0'
+            if redis.call('EXISTS', KEYS[i + 1]) == 0 and version == ARGV[j + 1] then
+                redis.call('SET', KEYS[i], ARGV[j], 'EX', ARGV[1])
+            end
+        end
+        return 0
+    `)
+	return script.Run(ctx, c.client, keys, args...).Err()
+}
+
+// PatchArticle 读出缓存的文章，改写 fields 中的字段后以新的逻辑过期时间写回。
+// 先增加版本号，缓存中没有该文章时也会增加，正在进行的回源重建不会再写回旧数据
+func (c *articleCache) PatchArticle(ctx context.Context, id int64, fields map[string]any) error {
+	key := c.key(KeyArticles, id)
+	return c.withArticleLock(ctx, id, func() error {
+		if err := c.bumpArticleVersion(ctx, c.client, id); err != nil {
+			return err
+		}
+
+		data, err := c.client.Get(ctx, key).Bytes()
+		if errors.Is(err, redis.Nil) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var wrapper struct {
+			Data map[string]any `json:"data"`
+		}
+		if err := json.Unmarshal(data, &wrapper); err != nil {
+			return err
+		}
+		if wrapper.Data == nil {
+			return fmt.Errorf("malformed article cache, ID: %d", id)
+		}
+		for field, value := range fields {
+			wrapper.Data[field] = value
+		}
+		if content, ok := fields["Content"].(string); ok {
+			truncated := c.truncateContent(domain.Article{Content: content})
+			wrapper.Data["Content"] = truncated.Content
+			wrapper.Data["ContentTruncated"] = truncated.ContentTruncated
+		}
+
+		data, err = json.Marshal(cache.NewDataWithLogicalExpire(wrapper.Data, patchedArticleTTL))
+		if err != nil {
+			return err
+		}
+		return c.client.Set(ctx, key, data, articleCacheTTL).Err()
+	})
+}
+
+// bumpArticleVersion 增加文章缓存的版本号，之前读取版本号的回源重建不会再写入
+func (c *articleCache) bumpArticleVersion(ctx context.Context, cmd redis.Cmdable, id int64) error {
+	key := c.key(KeyArticleVersion, id)
+	if err := cmd.Incr(ctx, key).Err(); err != nil {
+		return err
+	}
+	return cmd.Expire(ctx, key, articleCacheTTL).Err()
+}
+
+// withArticleLock 在单篇文章的短锁内执行 fn，拿不到锁时返回 errArticleLocked
+func (c *articleCache) withArticleLock(ctx context.Context, id int64, fn func() error) error {
+	lockKey := c.key(KeyArticleLock, id)
+	token := strconv.FormatInt(time.Now().UnixNano(), 10)
+
+	for i := 0; ; i++ {
+		ok, err := c.client.SetNX(ctx, lockKey, token, articleLockTTL).Result()
+		if err != nil {
+			return err
+		}
+		if ok {
+			break
+		}
+		if i == articleLockRetries {
+			return errArticleLocked
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(articleLockRetryDelay):
+		}
+	}
+
+	defer func() {
+		// 只释放自己持有的锁；锁已过期并被别人拿到时不删除。比较和删除在同一个脚本中完成
+		script := redis.NewScript(`
+            if redis.call('GET', KEYS[1]) == ARGV[1] then
+                return redis.call('DEL', KEYS[1])
+            end
+            return 0
+        `)
+		if err := script.Run(ctx, c.client, []string{lockKey}, token).Err(); err != nil {
+			logrus.Warnf("failed to release article cache lock, ID: %d, err: %v", id, err)
+		}
+	}()
+	return fn()
 }
 
 func (c *articleCache) IncrViews(ctx context.Context, id int64) (int64, error) {
-	return c.client.HIncrBy(ctx, KeyViewsBuffer, strconv.FormatInt(id, 10), 1).Result()
+	return c.client.HIncrBy(ctx, c.key(KeyViewsBuffer), strconv.FormatInt(id, 10), 1).Result()
+}
+
+func (c *articleCache) MGetBufferedViews(ctx context.Context, ids []int64) (map[int64]int64, error) {
+	res := make(map[int64]int64)
+	if len(ids) == 0 {
+		return res, nil
+	}
+	fields := make([]string, len(ids))
+	for i, id := range ids {
+		fields[i] = strconv.FormatInt(id, 10)
+	}
+
+	vals, err := c.client.HMGet(ctx, c.key(KeyViewsBuffer), fields...).Result()
+	if err != nil {
+		return nil, err
+	}
+	for i, val := range vals {
+		valStr, ok := val.(string)
+		if !ok {
+			continue
+		}
+		views, err := strconv.ParseInt(valStr, 10, 64)
+		if err != nil {
+			logrus.Errorf("failed to strconv.ParseInt in redis, id: %d, err: %v", ids[i], err)
+			continue
+		}
+		res[ids[i]] = views
+	}
+	return res, nil
+}
+
+func (c *articleCache) SumBufferedViews(ctx context.Context) (int64, error) {
+	vals, err := c.client.HVals(ctx, c.key(KeyViewsBuffer)).Result()
+	if err != nil {
+		return 0, err
+	}
+	var sum int64
+	for _, val := range vals {
+		views, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			logrus.Errorf("failed to strconv.ParseInt in redis, val: %s, err: %v", val, err)
+			continue
+		}
+		sum += views
+	}
+	return sum, nil
 }
 
 func (c *articleCache) FetchAndResetViews(ctx context.Context) (map[int64]int64, error) {
@@ -219,7 +504,7 @@ func (c *articleCache) FetchAndResetViews(ctx context.Context) (map[int64]int64,
 	result := make(map[int64]int64)
 
 	// KEYS[1] = KeyViewsBuffer, KEYS[2] = KeyViewsProcessing
-	val, err := script.Run(ctx, c.client, []string{KeyViewsBuffer, KeyViewsProcessing}).Result()
+	val, err := script.Run(ctx, c.client, []string{c.key(KeyViewsBuffer), c.key(KeyViewsProcessing)}).Result()
 
 	if err != nil {
 		if errors.Is(err, redis.Nil) {
@@ -245,22 +530,47 @@ func (c *articleCache) FetchAndResetViews(ctx context.Context) (map[int64]int64,
 	return result, nil
 }
 
-// TODO 应该删除缓存中的相关数据
+// DeleteArticle 只删除文章缓存，文章修改后由下一次读取回源重建
 func (c *articleCache) DeleteArticle(ctx context.Context, id int64) error {
-	key := fmt.Sprintf(KeyArticles, id)
-	err := c.client.Del(ctx, key).Err()
+	// 同时增加版本号，删除之前开始的回源重建不会把旧数据写回来
+	_, err := c.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, c.key(KeyArticles, id))
+		return c.bumpArticleVersion(ctx, pipe, id)
+	})
+	return err
+}
+
+// PurgeArticle 文章删除后清理它在缓存中的全部数据：文章缓存、点赞数、尚未落库的浏览量，
+// 以及今日热榜和历史热榜中的排名，一次往返完成。与 DeleteArticle 一样增加版本号
+func (c *articleCache) PurgeArticle(ctx context.Context, id int64) error {
+	_, err := c.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, c.key(KeyArticles, id), c.key(KeyLikesBuffer, id))
+		if err := c.bumpArticleVersion(ctx, pipe, id); err != nil {
+			return err
+		}
+		pipe.HDel(ctx, c.key(KeyViewsBuffer), strconv.FormatInt(id, 10))
+		for _, key := range c.rankKeys() {
+			pipe.ZRem(ctx, key, rankMember(id))
+		}
+		return nil
+	})
 	return err
 }
 
 func (c *articleCache) AddLikeRecord(ctx context.Context, likeRecord domain.UserLike) (bool, error) {
-	// KEYS = {该用户喜欢的文章列表, 今日热榜, 点赞数}
-	// ARGV = {本次文章ID, 点赞加分}
+	// KEYS = {该用户喜欢的文章列表, 今日热榜, 点赞数, 当天已计入热榜的点赞, 该用户本小时的点赞次数}
+	// ARGV = {本次文章ID, 点赞加分, 去重成员, 每小时点赞上限}
+	now := time.Now()
 	keys := []string{
-		fmt.Sprintf(KeyUserLikedArticles, likeRecord.UserID),
-		fmt.Sprintf(KeyHotDailyRaw, time.Now().Format("2006010215")),
-		fmt.Sprintf(KeyLikesBuffer, likeRecord.ArticleID),
+		c.key(KeyUserLikedArticles, likeRecord.UserID),
+		c.key(KeyHotDailyRaw, now.Format("2006010215")),
+		c.key(KeyLikesBuffer, likeRecord.ArticleID),
+		c.key(KeyLikeRankDedup, now.Format("20060102")),
+		c.key(KeyUserLikeRate, likeRecord.UserID, now.Format("2006010215")),
 	}
-	args := []any{likeRecord.ArticleID, 1}
+	args := []any{rankMember(likeRecord.ArticleID), 1, likeRankMember(likeRecord), maxLikesPerHour}
+	// 反复点赞/取消只有当天第一次点赞计入热榜，去重记录用 HSETNX 写入 1，
+	// 取消赞时置为 0，之后同一天再点赞也不会重新加分
 	var script = redis.NewScript(`
 		if redis.call('EXISTS', KEYS[1]) == 0 then
 			return -1 -- 未缓存, 需要加载缓存
@@ -268,20 +578,29 @@ func (c *articleCache) AddLikeRecord(ctx context.Context, likeRecord domain.User
 
 		if redis.call('SISMEMBER', KEYS[1], ARGV[1]) == 1 then
 			return 0 -- 最近已点赞
-		else 
-			redis.call('SADD', KEYS[1], ARGV[1])
-			redis.call('EXPIRE', KEYS[1], 1800)
+		end
+
+		if tonumber(redis.call('GET', KEYS[5]) or '0') >= tonumber(ARGV[4]) then
+			return -2 -- 本小时点赞次数已用完
+		end
+		redis.call('INCR', KEYS[5])
+		redis.call('EXPIRE', KEYS[5], 60*60)
 
+		redis.call('SADD', KEYS[1], ARGV[1])
+		redis.call('EXPIRE', KEYS[1], 1800)
+
+		if redis.call('HSETNX', KEYS[4], ARGV[3], 1) == 1 then
+			redis.call('EXPIRE', KEYS[4], 60*60*26) -- 26 hours
 			redis.call('ZINCRBY', KEYS[2], ARGV[2], ARGV[1])
 			redis.call('EXPIRE', KEYS[2], 60*60*26) -- 26 hours
-			
-			if redis.call('EXISTS', KEYS[3]) == 1 then
-				redis.call('INCR', KEYS[3])
-				redis.call('EXPIRE', KEYS[3], 7*24*60*60)
-			end
+		end
 
-			return 1 -- 点赞成功
+		if redis.call('EXISTS', KEYS[3]) == 1 then
+			redis.call('INCR', KEYS[3])
+			redis.call('EXPIRE', KEYS[3], 7*24*60*60)
 		end
+
+		return 1 -- 点赞成功
 	`)
 
 	res, err := script.Run(ctx, c.client, keys, args).Int()
@@ -291,6 +610,8 @@ func (c *articleCache) AddLikeRecord(ctx context.Context, likeRecord domain.User
 	switch res {
 	case -1:
 		return false, domain.ErrCacheMiss
+	case -2:
+		return false, domain.ErrTooManyRequests
 	case 0:
 		return false, nil
 	default:
@@ -299,14 +620,17 @@ func (c *articleCache) AddLikeRecord(ctx context.Context, likeRecord domain.User
 }
 
 func (c *articleCache) DecrLikeRecord(ctx context.Context, likeRecord domain.UserLike) (bool, error) {
-	// KEYS = {该用户喜欢的文章列表, 今日热榜, 点赞数}
-	// ARGV = {本次文章ID, 点赞加分}
+	// KEYS = {该用户喜欢的文章列表, 今日热榜, 点赞数, 当天已计入热榜的点赞}
+	// ARGV = {本次文章ID, 点赞加分, 去重成员}
+	now := time.Now()
 	keys := []string{
-		fmt.Sprintf(KeyUserLikedArticles, likeRecord.UserID),
-		fmt.Sprintf(KeyHotDailyRaw, time.Now().Format("2006010215")),
-		fmt.Sprintf(KeyLikesBuffer, likeRecord.ArticleID),
+		c.key(KeyUserLikedArticles, likeRecord.UserID),
+		c.key(KeyHotDailyRaw, now.Format("2006010215")),
+		c.key(KeyLikesBuffer, likeRecord.ArticleID),
+		c.key(KeyLikeRankDedup, now.Format("20060102")),
 	}
-	args := []any{likeRecord.ArticleID, -1}
+	args := []any{rankMember(likeRecord.ArticleID), -1, likeRankMember(likeRecord)}
+	// 只撤回当天计入过热榜的那一次加分，撤回后保留去重记录
 	var script = redis.NewScript(`
 		if redis.call('EXISTS', KEYS[1]) == 0 then
 			return -1 -- 未缓存, 需要加载缓存
@@ -314,20 +638,23 @@ func (c *articleCache) DecrLikeRecord(ctx context.Context, likeRecord domain.Use
 
 		if redis.call('SISMEMBER', KEYS[1], ARGV[1]) == 0 then
 			return 0 -- 最近未点赞
-		else 
-			redis.call('SREM', KEYS[1], ARGV[1])
-			redis.call('EXPIRE', KEYS[1], 1800)
+		end
+
+		redis.call('SREM', KEYS[1], ARGV[1])
+		redis.call('EXPIRE', KEYS[1], 1800)
 
+		if redis.call('HGET', KEYS[4], ARGV[3]) == '1' then
+			redis.call('HSET', KEYS[4], ARGV[3], 0)
 			redis.call('ZINCRBY', KEYS[2], ARGV[2], ARGV[1])
 			redis.call('EXPIRE', KEYS[2], 60*60*26) -- 26 hours
+		end
 
-			if redis.call('EXISTS', KEYS[3]) == 1 then
-				redis.call('DECR', KEYS[3])
-				redis.call('EXPIRE', KEYS[3], 7*24*60*60)
-			end
-
-			return 1 -- 取消赞成功
+		if redis.call('EXISTS', KEYS[3]) == 1 then
+			redis.call('DECR', KEYS[3])
+			redis.call('EXPIRE', KEYS[3], 7*24*60*60)
 		end
+
+		return 1 -- 取消赞成功
 	`)
 
 	res, err := script.Run(ctx, c.client, keys, args).Int()
@@ -344,8 +671,32 @@ func (c *articleCache) DecrLikeRecord(ctx context.Context, likeRecord domain.Use
 	}
 }
 
+// rankMember 文章在热榜 ZSET 中的成员。所有写入和删除热榜的路径都必须用它编码文章ID，
+// 否则同一篇文章可能在 ZINCRBY 时变成两个成员
+func rankMember(aid int64) string {
+	return strconv.FormatInt(aid, 10)
+}
+
+// parseRankMember 解析热榜成员，不是十进制文章ID的成员返回 false，由调用方跳过
+func parseRankMember(member any) (int64, bool) {
+	s, ok := member.(string)
+	if !ok {
+		return 0, false
+	}
+	aid, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || aid <= 0 {
+		return 0, false
+	}
+	return aid, true
+}
+
+// likeRankMember 热榜去重记录中 (用户, 文章) 对应的字段
+func likeRankMember(likeRecord domain.UserLike) string {
+	return strconv.FormatInt(likeRecord.UserID, 10) + ":" + strconv.FormatInt(likeRecord.ArticleID, 10)
+}
+
 func (c *articleCache) IsLiked(ctx context.Context, likeRecord domain.UserLike) (bool, error) {
-	return c.client.SIsMember(ctx, fmt.Sprintf(KeyUserLikedArticles, likeRecord.UserID), any(likeRecord.ArticleID)).Result()
+	return c.client.SIsMember(ctx, c.key(KeyUserLikedArticles, likeRecord.UserID), any(likeRecord.ArticleID)).Result()
 }
 
 func (c *articleCache) IsLikedBatch(ctx context.Context, uid int64, aids []int64) (map[int64]bool, error) {
@@ -370,7 +721,7 @@ func (c *articleCache) IsLikedBatch(ctx context.Context, uid int64, aids []int64
         end
         return results
     `)
-	result, err := script.Run(ctx, c.client, []string{fmt.Sprintf(KeyUserLikedArticles, uid)}, args).Slice()
+	result, err := script.Run(ctx, c.client, []string{c.key(KeyUserLikedArticles, uid)}, args).Slice()
 
 	if err == redis.Nil {
 		return nil, domain.ErrCacheMiss
@@ -387,46 +738,82 @@ func (c *articleCache) IsLikedBatch(ctx context.Context, uid int64, aids []int64
 	return resMap, nil
 }
 
-func (c *articleCache) SetUserLikedArticles(ctx context.Context, uid int64, aids []int64) error {
-	if len(aids) == 0 {
-		aids = append(aids, -1)
+// GetViewerState 用一个脚本读出点赞状态，只占一次往返。
+// 点赞集合不存在时返回 -1，表示状态未知，由调用方决定是否回源
+func (c *articleCache) GetViewerState(ctx context.Context, uid int64, aid int64) (domain.ViewerState, error) {
+	script := redis.NewScript(`
+        if redis.call('EXISTS', KEYS[1]) == 0 then
+            return -1
+        end
+        return redis.call('SISMEMBER', KEYS[1], ARGV[1])
+    `)
+	val, err := script.Run(ctx, c.client, []string{c.key(KeyUserLikedArticles, uid)}, aid).Int64()
+	if err != nil {
+		return domain.ViewerState{}, err
 	}
-	iaids := make([]any, len(aids))
-	for i, aid := range aids {
-		iaids[i] = any(aid)
+
+	var state domain.ViewerState
+	if val >= 0 {
+		liked := val == 1
+		state.Liked = &liked
 	}
-	key := fmt.Sprintf(KeyUserLikedArticles, uid)
-	return c.client.SAdd(ctx, key, iaids...).Err()
+	return state, nil
 }
 
-func (c *articleCache) GetDailyRank(ctx context.Context, limit int64) ([]domain.Article, error) {
-	if c.client.Exists(ctx, KeyHotDailyAggreGatedRank).Val() > 0 {
-		return c.fetchRankFromKey(ctx, KeyHotDailyAggreGatedRank, limit)
+// SetUserLikedArticles 用 aids 替换用户的点赞集合，DEL 和 SADD 在同一个事务中执行，
+// 不会和旧集合合并，其他请求也读不到删除后、写入前的空窗口
+func (c *articleCache) SetUserLikedArticles(ctx context.Context, uid int64, aids []int64) error {
+	members := make([]any, 0, len(aids)+1)
+	members = append(members, likedSetPlaceholder)
+	for _, aid := range aids {
+		members = append(members, strconv.FormatInt(aid, 10))
 	}
 
-	keys := make([]string, 24)
-	now := time.Now()
-	for i := range 24 {
-		keys[i] = fmt.Sprintf(KeyHotDailyRaw, now.Add(time.Duration(-i)*time.Hour).Format("2006010215"))
-	}
+	key := c.key(KeyUserLikedArticles, uid)
+	_, err := c.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, key)
+		pipe.SAdd(ctx, key, members...)
+		pipe.Expire(ctx, key, userLikedArticlesTTL)
+		return nil
+	})
+	return err
+}
 
-	err := c.client.ZUnionStore(ctx, KeyHotDailyAggreGatedRank, &redis.ZStore{
-		Keys:      keys,
-		Aggregate: "SUM",
-	}).Err()
+func (c *articleCache) GetDailyRank(ctx context.Context, offset, limit int64) ([]domain.Article, error) {
+	if c.client.Exists(ctx, c.key(KeyHotDailyAggreGatedRank)).Val() > 0 {
+		return c.fetchRankFromKey(ctx, c.key(KeyHotDailyAggreGatedRank), offset, limit)
+	}
 
-	if err != nil {
+	if err := c.AggregateDailyRank(ctx); err != nil {
 		return nil, err
 	}
+	return c.fetchRankFromKey(ctx, c.key(KeyHotDailyAggreGatedRank), offset, limit)
+}
 
-	c.client.Expire(ctx, KeyHotDailyAggreGatedRank, 5*time.Minute)
+// AggregateDailyRank 把最近 24 个小时分桶的分数相加写入聚合结果，聚合结果 dailyRankTTL 后过期。
+// 所有分桶都不存在时聚合结果为空，不是错误
+func (c *articleCache) AggregateDailyRank(ctx context.Context) error {
+	keys := make([]string, dailyRankHours)
+	now := time.Now()
+	for i := range dailyRankHours {
+		keys[i] = c.key(KeyHotDailyRaw, now.Add(time.Duration(-i)*time.Hour).Format("2006010215"))
+	}
 
-	return c.fetchRankFromKey(ctx, KeyHotDailyAggreGatedRank, limit)
+	rankKey := c.key(KeyHotDailyAggreGatedRank)
+	_, err := c.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZUnionStore(ctx, rankKey, &redis.ZStore{
+			Keys:      keys,
+			Aggregate: "SUM",
+		})
+		pipe.Expire(ctx, rankKey, dailyRankTTL)
+		return nil
+	})
+	return err
 }
 
 // GetDailyRankWithLogicalExpire 获取每日热榜，支持逻辑过期
 func (c *articleCache) GetDailyRankWithLogicalExpire(ctx context.Context, limit int64) ([]domain.Article, bool, error) {
-	data, err := c.client.Get(ctx, KeyHotDailyAggreGatedRank+"_logical").Bytes()
+	data, err := c.client.Get(ctx, c.key(KeyHotDailyAggreGatedRank+"_logical")).Bytes()
 	if err == nil {
 		var wrapper cache.DataWithLogicalExpire
 		if err := json.Unmarshal(data, &wrapper); err == nil {
@@ -448,39 +835,90 @@ func (c *articleCache) SetDailyRankWithLogicalExpire(ctx context.Context, articl
 	if err != nil {
 		return err
 	}
-	return c.client.Set(ctx, KeyHotDailyAggreGatedRank+"_logical", data, 24*time.Hour).Err()
+	return c.client.Set(ctx, c.key(KeyHotDailyAggreGatedRank+"_logical"), data, 24*time.Hour).Err()
 }
 
-func (c *articleCache) fetchRankFromKey(ctx context.Context, key string, limit int64) ([]domain.Article, error) {
-	zRes, err := c.client.ZRevRangeWithScores(ctx, key, 0, limit-1).Result()
+// fetchRankFromKey 按分数从高到低返回第 offset 名起的 limit 篇文章
+func (c *articleCache) fetchRankFromKey(ctx context.Context, key string, offset, limit int64) ([]domain.Article, error) {
+	zRes, err := c.client.ZRevRangeWithScores(ctx, key, offset, offset+limit-1).Result()
 	if err != nil {
 		return nil, err
 	}
 
 	res := make([]domain.Article, 0, len(zRes))
 	for _, z := range zRes {
-		aid, _ := strconv.ParseInt(z.Member.(string), 10, 64)
+		aid, ok := parseRankMember(z.Member)
+		if !ok {
+			logrus.Warnf("skipping malformed rank member in %s: %v", key, z.Member)
+			continue
+		}
 		res = append(res, domain.Article{
 			ID:    aid,
-			Likes: int64(z.Score),
+			Score: z.Score,
 		})
 	}
 	return res, nil
 }
 
 func (c *articleCache) IncrDailyRankScore(ctx context.Context, aid int64, scoreDelta float64) error {
-	key := fmt.Sprintf(KeyHotDailyRaw, time.Now().Format("2006010215"))
-	return c.client.ZIncrBy(ctx, key, scoreDelta, fmt.Sprintf("%d", aid)).Err()
+	key := c.key(KeyHotDailyRaw, time.Now().Format("2006010215"))
+	return c.client.ZIncrBy(ctx, key, scoreDelta, rankMember(aid)).Err()
 }
 
 func (c *articleCache) GetHistoryRank(ctx context.Context, limit int64) ([]domain.Article, error) {
-	if c.client.Exists(ctx, KeyHotHistoryRank).Val() > 0 {
-		return c.fetchRankFromKey(ctx, KeyHotHistoryRank, limit)
+	if c.client.Exists(ctx, c.key(KeyHotHistoryRank)).Val() > 0 {
+		return c.fetchRankFromKey(ctx, c.key(KeyHotHistoryRank), 0, limit)
 	}
 	return nil, domain.ErrCacheMiss
 }
 
 func (c *articleCache) SetHistoryRank(ctx context.Context, aids []int64, scores []float64) error {
+	return c.replaceRank(ctx, c.key(KeyHotHistoryRank), aids, scores, historyRankTTL)
+}
+
+func (c *articleCache) GetStaleHistoryRank(ctx context.Context, limit int64) ([]domain.Article, error) {
+	if c.client.Exists(ctx, c.key(KeyHotHistoryRankStale)).Val() > 0 {
+		return c.fetchRankFromKey(ctx, c.key(KeyHotHistoryRankStale), 0, limit)
+	}
+	return nil, domain.ErrCacheMiss
+}
+
+func (c *articleCache) SetStaleHistoryRank(ctx context.Context, aids []int64, scores []float64) error {
+	return c.replaceRank(ctx, c.key(KeyHotHistoryRankStale), aids, scores, staleHistoryRankTTL)
+}
+
+// RemoveFromRanks 从今日热榜的小时分桶、聚合结果以及历史热榜和旧副本中移除文章，一次往返完成
+func (c *articleCache) RemoveFromRanks(ctx context.Context, aids []int64) error {
+	if len(aids) == 0 {
+		return nil
+	}
+	members := make([]any, len(aids))
+	for i, aid := range aids {
+		members[i] = rankMember(aid)
+	}
+
+	_, err := c.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, key := range c.rankKeys() {
+			pipe.ZRem(ctx, key, members...)
+		}
+		return nil
+	})
+	return err
+}
+
+// rankKeys 可能包含某篇文章的所有排名 key
+func (c *articleCache) rankKeys() []string {
+	keys := []string{c.key(KeyHotDailyAggreGatedRank), c.key(KeyHotHistoryRank), c.key(KeyHotHistoryRankStale)}
+	// 小时分桶保留 26 小时，多删两个桶以免聚合时又被加回来
+	now := time.Now()
+	for i := range 26 {
+		keys = append(keys, c.key(KeyHotDailyRaw, now.Add(time.Duration(-i)*time.Hour).Format("2006010215")))
+	}
+	return keys
+}
+
+// replaceRank 用新的排名整体替换 key，避免残留已经掉出榜单的文章
+func (c *articleCache) replaceRank(ctx context.Context, key string, aids []int64, scores []float64, ttl time.Duration) error {
 	if len(aids) != len(scores) || len(aids) == 0 {
 		return domain.ErrBadParamInput
 	}
@@ -489,11 +927,17 @@ func (c *articleCache) SetHistoryRank(ctx context.Context, aids []int64, scores
 	for i := range zMem {
 		zMem[i] = redis.Z{
 			Score:  scores[i],
-			Member: any(aids[i]),
+			Member: rankMember(aids[i]),
 		}
 	}
 
-	return c.client.ZAdd(ctx, KeyHotHistoryRank, zMem...).Err()
+	_, err := c.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, key)
+		pipe.ZAdd(ctx, key, zMem...)
+		pipe.Expire(ctx, key, ttl)
+		return nil
+	})
+	return err
 }
 
 // SetHistoryRankWithLogicalExpire 设置历史热榜，使用逻辑过期
@@ -517,12 +961,12 @@ func (c *articleCache) SetHistoryRankWithLogicalExpire(ctx context.Context, aids
 		return err
 	}
 
-	return c.client.Set(ctx, KeyHotHistoryRank+"_logical", data, 24*time.Hour).Err()
+	return c.client.Set(ctx, c.key(KeyHotHistoryRank+"_logical"), data, 24*time.Hour).Err()
 }
 
 func (c *articleCache) GetLikeCount(ctx context.Context, aid int64) (int64, error) {
 	var res int64 = 0
-	resStr, err := c.client.Get(ctx, fmt.Sprintf(KeyLikesBuffer, aid)).Result()
+	resStr, err := c.client.Get(ctx, c.key(KeyLikesBuffer, aid)).Result()
 	if errors.Is(err, redis.Nil) {
 		return res, domain.ErrCacheMiss
 	}
@@ -539,13 +983,14 @@ func (c *articleCache) GetLikeCount(ctx context.Context, aid int64) (int64, erro
 	return res, nil
 }
 
+// MGetLikeCounts 批量读取点赞数，没有计数或计数无法解析的文章不在结果中，调用方据此回源
 func (c *articleCache) MGetLikeCounts(ctx context.Context, aids []int64) (map[int64]int64, error) {
 	if len(aids) == 0 {
 		return nil, nil
 	}
 	keys := make([]string, len(aids))
 	for i, aid := range aids {
-		keys[i] = fmt.Sprintf(KeyLikesBuffer, aid)
+		keys[i] = c.key(KeyLikesBuffer, aid)
 	}
 
 	result, err := c.client.MGet(ctx, keys...).Result()
@@ -556,21 +1001,18 @@ func (c *articleCache) MGetLikeCounts(ctx context.Context, aids []int64) (map[in
 	res := make(map[int64]int64)
 	for i, val := range result {
 		if val == nil {
-			res[aids[i]] = 0
 			continue
 		}
 
 		valStr, ok := val.(string)
 		if !ok {
 			logrus.Errorf("invalid type in redis for like count, id: %d, val: %v", aids[i], val)
-			res[aids[i]] = 0
 			continue
 		}
 
 		likes, err := strconv.ParseInt(valStr, 10, 64)
 		if err != nil {
 			logrus.Errorf("failed to strconv.ParseInt in redis, id: %d, err: %v", aids[i], err)
-			res[aids[i]] = 0
 			continue
 		}
 		res[aids[i]] = likes
@@ -579,15 +1021,23 @@ func (c *articleCache) MGetLikeCounts(ctx context.Context, aids []int64) (map[in
 }
 
 func (c *articleCache) IncrLikeCount(ctx context.Context, aid int64) (int64, error) {
-	key := fmt.Sprintf(KeyLikesBuffer, aid)
+	key := c.key(KeyLikesBuffer, aid)
 	return c.client.Incr(ctx, key).Result()
 }
 
 func (c *articleCache) SetLikeCount(ctx context.Context, aid, likes int64) error {
-	key := fmt.Sprintf(KeyLikesBuffer, aid)
+	key := c.key(KeyLikesBuffer, aid)
 	return c.client.Set(ctx, key, likes, 7*24*time.Hour).Err()
 }
 
+func (c *articleCache) InitLikeCount(ctx context.Context, aid, likes int64) (int64, error) {
+	key := c.key(KeyLikesBuffer, aid)
+	if err := c.client.SetNX(ctx, key, likes, 7*24*time.Hour).Err(); err != nil {
+		return 0, err
+	}
+	return c.client.Get(ctx, key).Int64()
+}
+
 func (c *articleCache) MSetLikeCount(ctx context.Context, aids, likes []int64) error {
 	if len(aids) != len(likes) {
 		return domain.ErrBadParamInput
@@ -599,7 +1049,7 @@ func (c *articleCache) MSetLikeCount(ctx context.Context, aids, likes []int64) e
 	val := make([]any, 0, 2*len(aids))
 
 	for i, aid := range aids {
-		key := fmt.Sprintf(KeyLikesBuffer, aid)
+		key := c.key(KeyLikesBuffer, aid)
 		val = append(val, key, likes[i])
 	}
 	return c.client.MSet(ctx, val...).Err()
@@ -0,0 +1,523 @@
+package redis_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+	myRedis "github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/repository/redis"
+)
+
+func TestPatchArticle(t *testing.T) {
+	_, client := newTestClient(t)
+	ctx := context.Background()
+	cache := myRedis.NewArticleCache(client, "", 0, 0)
+
+	// 已经逻辑过期的缓存，局部更新后应当重新生效
+	cached := &domain.Article{ID: 1, Title: "title", Content: "content", Summary: "摘要", SummaryIsAuto: true, User: domain.User{ID: 7, Name: "author"}}
+	require.NoError(t, cache.SetArticleWithLogicalExpire(ctx, cached, 0, -time.Minute))
+
+	updatedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	require.NoError(t, cache.PatchArticle(ctx, 1, map[string]any{
+		"Title":     "new title",
+		"Edited":    true,
+		"EditCount": int64(1),
+		"UpdatedAt": updatedAt,
+	}))
+
+	got, expired, err := cache.GetArticleWithLogicalExpire(ctx, 1)
+	require.NoError(t, err)
+	assert.False(t, expired)
+	assert.Equal(t, "new title", got.Title)
+	assert.Equal(t, "content", got.Content)
+	assert.Equal(t, "author", got.User.Name)
+	assert.Equal(t, "摘要", got.Summary)
+	assert.True(t, got.SummaryIsAuto)
+	assert.True(t, got.Edited)
+	assert.Equal(t, int64(1), got.EditCount)
+	assert.True(t, updatedAt.Equal(got.UpdatedAt))
+}
+
+func TestCachedArticlesKeepTags(t *testing.T) {
+	_, client := newTestClient(t)
+	ctx := context.Background()
+	cache := myRedis.NewArticleCache(client, "", 0, 0)
+
+	// 缓存路径返回的标签与数据库路径一致
+	ar := domain.Article{ID: 1, Title: "title", Tags: []string{"go", "redis"}}
+	require.NoError(t, cache.SetArticleWithLogicalExpire(ctx, &ar, 0, time.Minute))
+	got, _, err := cache.GetArticleWithLogicalExpire(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, ar.Tags, got.Tags)
+
+	require.NoError(t, cache.SetHomeWithLogicalExpire(ctx, domain.ArticlePage{Articles: []domain.Article{ar}}, time.Minute))
+	page, _, err := cache.GetHomeWithLogicalExpire(ctx)
+	require.NoError(t, err)
+	require.Len(t, page.Articles, 1)
+	assert.Equal(t, ar.Tags, page.Articles[0].Tags)
+
+	// 编辑时替换标签
+	require.NoError(t, cache.PatchArticle(ctx, 1, map[string]any{"Tags": []string{"mysql"}}))
+	got, _, err = cache.GetArticleWithLogicalExpire(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"mysql"}, got.Tags)
+}
+
+func TestGetArticleByIDsInBatches(t *testing.T) {
+	_, client := newTestClient(t)
+	ctx := context.Background()
+	cache := myRedis.NewArticleCache(client, "", 0, 3)
+
+	// 4 和 8 没有缓存，9 已经逻辑过期，分散在不同批次中
+	ids := []int64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	for _, id := range ids {
+		switch id {
+		case 4, 8:
+		case 9:
+			require.NoError(t, cache.SetArticleWithLogicalExpire(ctx, &domain.Article{ID: id}, 0, -time.Minute))
+		default:
+			require.NoError(t, cache.SetArticleWithLogicalExpire(ctx, &domain.Article{ID: id}, 0, time.Minute))
+		}
+	}
+
+	got, err := cache.GetArticleByIDsWithLogicalExpire(ctx, ids)
+	require.NoError(t, err)
+	var gotIDs []int64
+	for _, ar := range got {
+		gotIDs = append(gotIDs, ar.ID)
+	}
+	assert.Equal(t, []int64{1, 2, 3, 5, 6, 7, 10}, gotIDs)
+}
+
+func TestPatchArticleMissing(t *testing.T) {
+	mr, client := newTestClient(t)
+	cache := myRedis.NewArticleCache(client, "", 0, 0)
+
+	// 不创建文章缓存，只增加版本号
+	require.NoError(t, cache.PatchArticle(context.Background(), 1, map[string]any{"Title": "new title"}))
+	assert.Equal(t, []string{"article:version:1"}, mr.Keys())
+}
+
+func TestSetArticleDropsRebuildOlderThanPatch(t *testing.T) {
+	_, client := newTestClient(t)
+	ctx := context.Background()
+	cache := myRedis.NewArticleCache(client, "", 0, 0)
+	require.NoError(t, cache.SetArticleWithLogicalExpire(ctx, &domain.Article{ID: 1, Title: "old"}, 0, -time.Minute))
+
+	// 回源重建在局部更新之前读取了版本号和数据库
+	before, err := cache.GetArticleVersions(ctx, []int64{1, 2})
+	require.NoError(t, err)
+	assert.Equal(t, map[int64]int64{1: 0, 2: 0}, before)
+
+	require.NoError(t, cache.PatchArticle(ctx, 1, map[string]any{"Title": "new"}))
+
+	// 旧数据在局部更新之后才写入，单篇和批量都被丢弃
+	require.NoError(t, cache.SetArticleWithLogicalExpire(ctx, &domain.Article{ID: 1, Title: "old"}, before[1], time.Minute))
+	require.NoError(t, cache.BatchSetArticleWithLogicalExpire(ctx, []domain.Article{{ID: 1, Title: "old"}, {ID: 2, Title: "other"}}, before, time.Minute))
+	got, _, err := cache.GetArticleWithLogicalExpire(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "new", got.Title)
+	// 没有被更新的文章照常写入
+	got, _, err = cache.GetArticleWithLogicalExpire(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, "other", got.Title)
+
+	// 删除同样使之前读取的版本号失效
+	after, err := cache.GetArticleVersions(ctx, []int64{1})
+	require.NoError(t, err)
+	require.NoError(t, cache.DeleteArticle(ctx, 1))
+	require.NoError(t, cache.SetArticleWithLogicalExpire(ctx, &domain.Article{ID: 1, Title: "new"}, after[1], time.Minute))
+	_, _, err = cache.GetArticleWithLogicalExpire(ctx, 1)
+	assert.ErrorIs(t, err, redis.Nil)
+}
+
+func TestBatchSetArticleSkipsLockedArticles(t *testing.T) {
+	mr, client := newTestClient(t)
+	ctx := context.Background()
+	cache := myRedis.NewArticleCache(client, "", 0, 0)
+
+	// 文章 1 正在被局部更新
+	require.NoError(t, mr.Set("article:lock:1", "someone else"))
+	require.NoError(t, cache.BatchSetArticleWithLogicalExpire(ctx, []domain.Article{{ID: 1}, {ID: 2}}, nil, time.Minute))
+	assert.False(t, mr.Exists("article:1"))
+	assert.True(t, mr.Exists("article:2"))
+
+	// 锁不属于自己时局部更新失败，也不会删除别人的锁
+	assert.Error(t, cache.PatchArticle(ctx, 1, map[string]any{"Title": "new"}))
+	owner, err := mr.Get("article:lock:1")
+	require.NoError(t, err)
+	assert.Equal(t, "someone else", owner)
+}
+
+func TestPatchArticleLocked(t *testing.T) {
+	mr, client := newTestClient(t)
+	ctx := context.Background()
+	cache := myRedis.NewArticleCache(client, "", 0, 0)
+
+	require.NoError(t, cache.SetArticleWithLogicalExpire(ctx, &domain.Article{ID: 1, Title: "title"}, 0, time.Minute))
+	require.NoError(t, mr.Set("article:lock:1", "someone else"))
+
+	assert.Error(t, cache.PatchArticle(ctx, 1, map[string]any{"Title": "new title"}))
+
+	got, _, err := cache.GetArticleWithLogicalExpire(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "title", got.Title)
+}
+
+func TestSetArticleTruncatesLargeContent(t *testing.T) {
+	_, client := newTestClient(t)
+	ctx := context.Background()
+	cache := myRedis.NewArticleCache(client, "", 4, 0)
+
+	ar := &domain.Article{ID: 1, Content: "你好世界"}
+	require.NoError(t, cache.SetArticleWithLogicalExpire(ctx, ar, 0, time.Minute))
+	require.NoError(t, cache.BatchSetArticleWithLogicalExpire(ctx, []domain.Article{{ID: 2, Content: "abc"}}, nil, time.Minute))
+
+	// 不修改调用方的文章
+	assert.Equal(t, "你好世界", ar.Content)
+
+	got, _, err := cache.GetArticleWithLogicalExpire(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "你", got.Content, "should cut at a rune boundary")
+	assert.True(t, got.ContentTruncated)
+
+	got, _, err = cache.GetArticleWithLogicalExpire(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, "abc", got.Content)
+	assert.False(t, got.ContentTruncated)
+
+	// 局部更新后的正文同样受限制
+	require.NoError(t, cache.PatchArticle(ctx, 2, map[string]any{"Content": "abcdef"}))
+	got, _, err = cache.GetArticleWithLogicalExpire(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, "abcd", got.Content)
+	assert.True(t, got.ContentTruncated)
+}
+
+func TestMGetBufferedViews(t *testing.T) {
+	_, client := newTestClient(t)
+	ctx := context.Background()
+	cache := myRedis.NewArticleCache(client, "", 0, 0)
+
+	for i := 0; i < 3; i++ {
+		_, err := cache.IncrViews(ctx, 1)
+		require.NoError(t, err)
+	}
+
+	got, err := cache.MGetBufferedViews(ctx, []int64{1, 2})
+	require.NoError(t, err)
+	assert.Equal(t, map[int64]int64{1: 3}, got)
+}
+
+// cmdCounter 统计客户端发出的命令数和 pipeline 次数，用来确认一次读取只占一次往返
+type cmdCounter struct {
+	n         atomic.Int64
+	pipelines atomic.Int64
+}
+
+func (h *cmdCounter) DialHook(next redis.DialHook) redis.DialHook { return next }
+
+func (h *cmdCounter) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		h.n.Add(1)
+		return next(ctx, cmd)
+	}
+}
+
+func (h *cmdCounter) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		h.n.Add(int64(len(cmds)))
+		h.pipelines.Add(1)
+		return next(ctx, cmds)
+	}
+}
+
+func TestGetViewerState(t *testing.T) {
+	_, client := newTestClient(t)
+	ctx := context.Background()
+	cache := myRedis.NewArticleCache(client, "", 0, 0)
+
+	// 点赞集合不存在时状态未知
+	got, err := cache.GetViewerState(ctx, 7, 1)
+	require.NoError(t, err)
+	assert.Equal(t, domain.ViewerState{}, got)
+
+	require.NoError(t, cache.SetUserLikedArticles(ctx, 7, []int64{1}))
+
+	counter := &cmdCounter{}
+	client.AddHook(counter)
+
+	got, err = cache.GetViewerState(ctx, 7, 1)
+	require.NoError(t, err)
+	require.NotNil(t, got.Liked)
+	assert.True(t, *got.Liked)
+	assert.Equal(t, int64(1), counter.n.Load(), "should be a single round trip")
+
+	// 集合存在但不包含该文章时为 false，而不是未知
+	got, err = cache.GetViewerState(ctx, 7, 2)
+	require.NoError(t, err)
+	require.NotNil(t, got.Liked)
+	assert.False(t, *got.Liked)
+}
+
+func TestLikeRankCountsFirstLikeOfDay(t *testing.T) {
+	mr, client := newTestClient(t)
+	ctx := context.Background()
+	cache := myRedis.NewArticleCache(client, "", 0, 0)
+	require.NoError(t, cache.SetUserLikedArticles(ctx, 7, []int64{}))
+	rankKey := "article:hot:daily:raw:" + time.Now().Format("2006010215")
+	like := domain.UserLike{UserID: 7, ArticleID: 1}
+
+	// 反复点赞/取消，点赞状态照常切换，但热榜只计入当天第一次点赞
+	for i := 0; i < 3; i++ {
+		ok, err := cache.AddLikeRecord(ctx, like)
+		require.NoError(t, err)
+		assert.True(t, ok)
+		liked, err := cache.IsLiked(ctx, like)
+		require.NoError(t, err)
+		assert.True(t, liked)
+
+		ok, err = cache.DecrLikeRecord(ctx, like)
+		require.NoError(t, err)
+		assert.True(t, ok)
+	}
+	_, err := cache.AddLikeRecord(ctx, like)
+	require.NoError(t, err)
+
+	score, err := mr.ZScore(rankKey, "1")
+	require.NoError(t, err)
+	assert.Equal(t, float64(0), score)
+
+	// 其他用户的点赞不受影响
+	require.NoError(t, cache.SetUserLikedArticles(ctx, 8, []int64{}))
+	_, err = cache.AddLikeRecord(ctx, domain.UserLike{UserID: 8, ArticleID: 1})
+	require.NoError(t, err)
+	score, err = mr.ZScore(rankKey, "1")
+	require.NoError(t, err)
+	assert.Equal(t, float64(1), score)
+}
+
+func TestRankMembersShareEncoding(t *testing.T) {
+	mr, client := newTestClient(t)
+	ctx := context.Background()
+	cache := myRedis.NewArticleCache(client, "", 0, 0)
+	require.NoError(t, cache.SetUserLikedArticles(ctx, 7, []int64{}))
+	rankKey := "article:hot:daily:raw:" + time.Now().Format("2006010215")
+
+	// 点赞脚本和浏览加分写入同一个成员，不会出现两个 42
+	_, err := cache.AddLikeRecord(ctx, domain.UserLike{UserID: 7, ArticleID: 42})
+	require.NoError(t, err)
+	require.NoError(t, cache.IncrDailyRankScore(ctx, 42, 2))
+	members, err := mr.ZMembers(rankKey)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"42"}, members)
+	score, err := mr.ZScore(rankKey, "42")
+	require.NoError(t, err)
+	assert.Equal(t, float64(3), score)
+
+	// 整体写入的历史热榜也能被删除路径找到
+	require.NoError(t, cache.SetHistoryRank(ctx, []int64{42, 43}, []float64{2, 1}))
+	history, err := mr.ZMembers("article:hot:history:rank")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"42", "43"}, history)
+	require.NoError(t, cache.RemoveFromRanks(ctx, []int64{42}))
+	history, err = mr.ZMembers("article:hot:history:rank")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"43"}, history)
+	assert.False(t, mr.Exists(rankKey))
+}
+
+func TestRankSkipsMalformedMembers(t *testing.T) {
+	mr, client := newTestClient(t)
+	ctx := context.Background()
+	cache := myRedis.NewArticleCache(client, "", 0, 0)
+
+	require.NoError(t, cache.SetHistoryRank(ctx, []int64{1, 2}, []float64{3, 1}))
+	// 手工写入或旧版本留下的成员不是文章ID
+	_, err := mr.ZAdd("article:hot:history:rank", 2, "oops")
+	require.NoError(t, err)
+
+	ranks, err := cache.GetHistoryRank(ctx, 10)
+	require.NoError(t, err)
+	ids := make([]int64, len(ranks))
+	for i := range ranks {
+		ids[i] = ranks[i].ID
+	}
+	assert.Equal(t, []int64{1, 2}, ids)
+}
+
+func TestLikeHourlyCap(t *testing.T) {
+	_, client := newTestClient(t)
+	ctx := context.Background()
+	cache := myRedis.NewArticleCache(client, "", 0, 0)
+	require.NoError(t, cache.SetUserLikedArticles(ctx, 7, []int64{}))
+
+	for aid := int64(1); aid <= 60; aid++ {
+		ok, err := cache.AddLikeRecord(ctx, domain.UserLike{UserID: 7, ArticleID: aid})
+		require.NoError(t, err)
+		require.True(t, ok)
+	}
+
+	_, err := cache.AddLikeRecord(ctx, domain.UserLike{UserID: 7, ArticleID: 61})
+	assert.ErrorIs(t, err, domain.ErrTooManyRequests)
+	liked, err := cache.IsLiked(ctx, domain.UserLike{UserID: 7, ArticleID: 61})
+	require.NoError(t, err)
+	assert.False(t, liked)
+
+	// 已点赞的文章重复点赞不消耗次数，取消赞也不受限制
+	ok, err := cache.AddLikeRecord(ctx, domain.UserLike{UserID: 7, ArticleID: 1})
+	require.NoError(t, err)
+	assert.False(t, ok)
+	ok, err = cache.DecrLikeRecord(ctx, domain.UserLike{UserID: 7, ArticleID: 1})
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	// 其他用户有独立的额度
+	require.NoError(t, cache.SetUserLikedArticles(ctx, 8, []int64{}))
+	ok, err = cache.AddLikeRecord(ctx, domain.UserLike{UserID: 8, ArticleID: 61})
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestGetDailyRankPages(t *testing.T) {
+	_, client := newTestClient(t)
+	ctx := context.Background()
+	cache := myRedis.NewArticleCache(client, "", 0, 0)
+	for aid := int64(1); aid <= 7; aid++ {
+		require.NoError(t, cache.IncrDailyRankScore(ctx, aid, float64(aid*10)))
+	}
+
+	ids := func(articles []domain.Article) []int64 {
+		res := make([]int64, len(articles))
+		for i, ar := range articles {
+			res[i] = ar.ID
+		}
+		return res
+	}
+
+	first, err := cache.GetDailyRank(ctx, 0, 3)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{7, 6, 5}, ids(first))
+
+	second, err := cache.GetDailyRank(ctx, 3, 3)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{4, 3, 2}, ids(second))
+
+	// 最后一页不足 limit 篇，超出范围时为空
+	last, err := cache.GetDailyRank(ctx, 6, 3)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1}, ids(last))
+	beyond, err := cache.GetDailyRank(ctx, 9, 3)
+	require.NoError(t, err)
+	assert.Empty(t, beyond)
+}
+
+func TestAggregateDailyRank(t *testing.T) {
+	mr, client := newTestClient(t)
+	ctx := context.Background()
+	cache := myRedis.NewArticleCache(client, "", 0, 0)
+
+	// 没有小时分桶时聚合结果为空
+	require.NoError(t, cache.AggregateDailyRank(ctx))
+	empty, err := cache.GetDailyRank(ctx, 0, 10)
+	require.NoError(t, err)
+	assert.Empty(t, empty)
+
+	require.NoError(t, cache.IncrDailyRankScore(ctx, 1, 2))
+	lastHour := "article:hot:daily:raw:" + time.Now().Add(-time.Hour).Format("2006010215")
+	_, err = mr.ZAdd(lastHour, 3, "1")
+	require.NoError(t, err)
+	// 超过 24 小时的分桶不参与聚合
+	_, err = mr.ZAdd("article:hot:daily:raw:"+time.Now().Add(-24*time.Hour).Format("2006010215"), 100, "2")
+	require.NoError(t, err)
+
+	require.NoError(t, cache.AggregateDailyRank(ctx))
+	members, err := mr.ZMembers("article:hot:daily:rank")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1"}, members)
+	score, err := mr.ZScore("article:hot:daily:rank", "1")
+	require.NoError(t, err)
+	assert.Equal(t, float64(5), score)
+	assert.Equal(t, 5*time.Minute, mr.TTL("article:hot:daily:rank"))
+}
+
+func TestPurgeArticle(t *testing.T) {
+	mr, client := newTestClient(t)
+	ctx := context.Background()
+	cache := myRedis.NewArticleCache(client, "", 0, 0)
+	counter := &cmdCounter{}
+	client.AddHook(counter)
+
+	for _, id := range []int64{1, 2} {
+		require.NoError(t, cache.SetArticleWithLogicalExpire(ctx, &domain.Article{ID: id, Title: "t"}, 0, time.Minute))
+		require.NoError(t, cache.SetLikeCount(ctx, id, 5))
+		_, err := cache.IncrViews(ctx, id)
+		require.NoError(t, err)
+		require.NoError(t, cache.IncrDailyRankScore(ctx, id, 1))
+	}
+	require.NoError(t, cache.SetHistoryRank(ctx, []int64{1, 2}, []float64{20, 10}))
+	require.NoError(t, cache.AggregateDailyRank(ctx))
+
+	counter.pipelines.Store(0)
+	require.NoError(t, cache.PurgeArticle(ctx, 1))
+	assert.Equal(t, int64(1), counter.pipelines.Load(), "purge should take one round trip")
+
+	assert.False(t, mr.Exists("article:1"))
+	assert.False(t, mr.Exists("article:likes:1"))
+	views, err := cache.MGetBufferedViews(ctx, []int64{1, 2})
+	require.NoError(t, err)
+	assert.Equal(t, map[int64]int64{2: 1}, views)
+	for _, key := range []string{
+		"article:hot:daily:rank",
+		"article:hot:history:rank",
+		"article:hot:daily:raw:" + time.Now().Format("2006010215"),
+	} {
+		members, err := mr.ZMembers(key)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"2"}, members, key)
+	}
+
+	// 其他文章的缓存不受影响
+	assert.True(t, mr.Exists("article:2"))
+	assert.True(t, mr.Exists("article:likes:2"))
+}
+
+func TestSetUserLikedArticlesReplacesSet(t *testing.T) {
+	mr, client := newTestClient(t)
+	ctx := context.Background()
+	cache := myRedis.NewArticleCache(client, "", 0, 0)
+	key := "article:user:7:likedArticles"
+
+	// 重新加载替换整个集合，不和上一次加载的结果合并
+	require.NoError(t, cache.SetUserLikedArticles(ctx, 7, []int64{1, 2}))
+	require.NoError(t, cache.SetUserLikedArticles(ctx, 7, []int64{3}))
+	liked, err := cache.IsLikedBatch(ctx, 7, []int64{1, 2, 3})
+	require.NoError(t, err)
+	assert.Equal(t, map[int64]bool{1: false, 2: false, 3: true}, liked)
+	assert.Positive(t, mr.TTL(key))
+
+	// 空集合也算已加载，任何文章都不是已点赞，包括旧实现的占位 -1
+	require.NoError(t, cache.SetUserLikedArticles(ctx, 7, nil))
+	assert.True(t, mr.Exists(key))
+	liked, err = cache.IsLikedBatch(ctx, 7, []int64{-1, 0, 3})
+	require.NoError(t, err)
+	assert.Equal(t, map[int64]bool{-1: false, 0: false, 3: false}, liked)
+	state, err := cache.GetViewerState(ctx, 7, 3)
+	require.NoError(t, err)
+	require.NotNil(t, state.Liked)
+	assert.False(t, *state.Liked)
+
+	// 第一次点赞后集合中只有这一篇文章和占位成员
+	ok, err := cache.AddLikeRecord(ctx, domain.UserLike{UserID: 7, ArticleID: 5})
+	require.NoError(t, err)
+	assert.True(t, ok)
+	members, err := mr.Members(key)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"5", "loaded"}, members)
+}
@@ -17,14 +17,16 @@ const (
 type redisBloomRepo struct {
 	client       *redis.Client
 	BloomBitSize uint64
+	keyPrefix
 }
 
 var _ domain.BloomRepository = (*redisBloomRepo)(nil)
 
-func NewRedisBloomRepo(client *redis.Client, bitSize uint64) *redisBloomRepo {
+func NewRedisBloomRepo(client *redis.Client, bitSize uint64, prefix string) *redisBloomRepo {
 	return &redisBloomRepo{
 		client:       client,
 		BloomBitSize: bitSize,
+		keyPrefix:    keyPrefix(prefix),
 	}
 }
 
@@ -32,7 +34,7 @@ func (r *redisBloomRepo) Add(ctx context.Context, id int64) error {
 	offsets := r.getOffset(id)
 	pipe := r.client.Pipeline()
 	for _, offset := range offsets {
-		pipe.SetBit(ctx, KeyArticleBloom, int64(offset), 1)
+		pipe.SetBit(ctx, r.key(KeyArticleBloom), int64(offset), 1)
 	}
 	_, err := pipe.Exec(ctx)
 	return err
@@ -42,7 +44,7 @@ func (r *redisBloomRepo) Exists(ctx context.Context, id int64) (bool, error) {
 	offsets := r.getOffset(id)
 	pipe := r.client.Pipeline()
 	for _, offset := range offsets {
-		pipe.GetBit(ctx, KeyArticleBloom, int64(offset))
+		pipe.GetBit(ctx, r.key(KeyArticleBloom), int64(offset))
 	}
 	cmds, err := pipe.Exec(ctx)
 	if err != nil {
@@ -62,6 +64,36 @@ func (r *redisBloomRepo) Exists(ctx context.Context, id int64) (bool, error) {
 	return true, nil
 }
 
+// ExistsBatch 把所有 ID 的 GETBIT 放进同一个 pipeline，只占一次往返
+func (r *redisBloomRepo) ExistsBatch(ctx context.Context, ids []int64) (map[int64]bool, error) {
+	res := make(map[int64]bool, len(ids))
+	if len(ids) == 0 {
+		return res, nil
+	}
+	pipe := r.client.Pipeline()
+	cmds := make([][]*redis.IntCmd, len(ids))
+	for i, id := range ids {
+		for _, offset := range r.getOffset(id) {
+			cmds[i] = append(cmds[i], pipe.GetBit(ctx, r.key(KeyArticleBloom), int64(offset)))
+		}
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+
+	for i, id := range ids {
+		exists := true
+		for _, cmd := range cmds[i] {
+			if cmd.Val() == 0 {
+				exists = false
+				break
+			}
+		}
+		res[id] = exists
+	}
+	return res, nil
+}
+
 func (r *redisBloomRepo) getOffset(id int64) []uint64 {
 	data := fmt.Appendf(nil, "%d", id)
 	offsets := make([]uint64, 3) // 假设 k=3
@@ -88,7 +120,7 @@ func (r *redisBloomRepo) BulkAdd(ctx context.Context, ids []int64) error {
 	for _, id := range ids {
 		offsets := r.getOffset(id)
 		for _, offset := range offsets {
-			pipe.SetBit(ctx, KeyArticleBloom, int64(offset), 1)
+			pipe.SetBit(ctx, r.key(KeyArticleBloom), int64(offset), 1)
 		}
 	}
 
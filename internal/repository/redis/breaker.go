@@ -0,0 +1,178 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+)
+
+// Breaker 是挂在 Redis 客户端上的熔断器。
+// window 内连续 threshold 次连接错误后打开，打开期间所有命令不再拨号，直接返回 domain.ErrCacheUnavailable；
+// 后台每隔 probeInterval 用 PING 探测一次，成功后关闭
+type Breaker struct {
+	threshold     int
+	window        time.Duration
+	probeInterval time.Duration
+
+	mu        sync.Mutex
+	failures  int
+	firstFail time.Time
+	openedAt  time.Time // 零值表示关闭
+	trips     int64
+	rejected  int64
+}
+
+var _ redis.Hook = (*Breaker)(nil)
+
+// NewBreaker 创建熔断器，需要用 client.AddHook 挂到客户端上，并用 Run 启动后台探测
+func NewBreaker(threshold int, window, probeInterval time.Duration) *Breaker {
+	return &Breaker{
+		threshold:     threshold,
+		window:        window,
+		probeInterval: probeInterval,
+	}
+}
+
+type probeKey struct{}
+
+func (b *Breaker) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (b *Breaker) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		if !b.allow(ctx) {
+			cmd.SetErr(domain.ErrCacheUnavailable)
+			return domain.ErrCacheUnavailable
+		}
+		err := next(ctx, cmd)
+		b.record(err)
+		return err
+	}
+}
+
+func (b *Breaker) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		if !b.allow(ctx) {
+			for _, cmd := range cmds {
+				cmd.SetErr(domain.ErrCacheUnavailable)
+			}
+			return domain.ErrCacheUnavailable
+		}
+		err := next(ctx, cmds)
+		b.record(err)
+		return err
+	}
+}
+
+// Run 在熔断器打开时定期探测 Redis，直到 ctx 结束
+func (b *Breaker) Run(ctx context.Context, client *redis.Client) {
+	ticker := time.NewTicker(b.probeInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if b.State().State == domain.BreakerOpen {
+				b.Probe(ctx, client)
+			}
+		}
+	}
+}
+
+// Probe 绕过熔断发送一次 PING，成功时关闭熔断器
+func (b *Breaker) Probe(ctx context.Context, client *redis.Client) {
+	ctx, cancel := context.WithTimeout(context.WithValue(ctx, probeKey{}, true), b.probeInterval)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		logrus.Debugf("redis probe failed: %v", err)
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.openedAt.IsZero() {
+		logrus.Infof("redis is back after %s, closing circuit breaker", time.Since(b.openedAt).Round(time.Second))
+	}
+	b.openedAt = time.Time{}
+	b.failures = 0
+}
+
+// State 返回熔断器当前状态的快照
+func (b *Breaker) State() domain.BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	res := domain.BreakerState{
+		State:               domain.BreakerClosed,
+		ConsecutiveFailures: b.failures,
+		Trips:               b.trips,
+		Rejected:            b.rejected,
+	}
+	if !b.openedAt.IsZero() {
+		openedAt := b.openedAt
+		res.State = domain.BreakerOpen
+		res.OpenedAt = &openedAt
+	}
+	return res
+}
+
+// allow 判断命令是否可以发送，探测命令总是放行
+func (b *Breaker) allow(ctx context.Context) bool {
+	if probe, _ := ctx.Value(probeKey{}).(bool); probe {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.openedAt.IsZero() {
+		return true
+	}
+	b.rejected++
+	return false
+}
+
+// record 统计连接错误，Redis 返回的业务错误（包括 redis.Nil）说明连接正常
+func (b *Breaker) record(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.openedAt.IsZero() {
+		return
+	}
+	if !isConnError(err) {
+		b.failures = 0
+		return
+	}
+
+	now := time.Now()
+	if b.failures == 0 || now.Sub(b.firstFail) > b.window {
+		b.failures = 0
+		b.firstFail = now
+	}
+	b.failures++
+	if b.failures >= b.threshold {
+		b.openedAt = now
+		b.trips++
+		logrus.Warnf("redis failed %d times in a row, opening circuit breaker: %v", b.failures, err)
+	}
+}
+
+// isConnError 判断错误是否说明 Redis 不可达
+func isConnError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, redis.ErrPoolTimeout) ||
+		errors.Is(err, redis.ErrClosed)
+}
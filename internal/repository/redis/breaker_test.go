@@ -0,0 +1,59 @@
+package redis_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+	myRedis "github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/repository/redis"
+)
+
+func TestBreakerTripsAndRecovers(t *testing.T) {
+	mr, client := newTestClient(t)
+	breaker := myRedis.NewBreaker(3, time.Minute, 100*time.Millisecond)
+	client.AddHook(breaker)
+	cache := myRedis.NewArticleCache(client, "", 0, 0)
+	ctx := context.Background()
+
+	// 业务错误（key 不存在）不计入失败
+	_, _, err := cache.GetArticleWithLogicalExpire(ctx, 1)
+	require.ErrorIs(t, err, redis.Nil)
+	assert.Equal(t, 0, breaker.State().ConsecutiveFailures)
+
+	mr.Close()
+	for i := 0; i < 3; i++ {
+		_, _, err = cache.GetArticleWithLogicalExpire(ctx, 1)
+		require.Error(t, err)
+	}
+	state := breaker.State()
+	assert.Equal(t, domain.BreakerOpen, state.State)
+	assert.Equal(t, int64(1), state.Trips)
+	require.NotNil(t, state.OpenedAt)
+
+	// 打开后不再拨号，直接按缓存未命中处理
+	_, _, err = cache.GetArticleWithLogicalExpire(ctx, 1)
+	assert.True(t, errors.Is(err, domain.ErrCacheUnavailable))
+	assert.True(t, errors.Is(err, domain.ErrCacheMiss))
+	_, err = cache.AddLikeRecord(ctx, domain.UserLike{UserID: 1, ArticleID: 1})
+	assert.ErrorIs(t, err, domain.ErrCacheUnavailable)
+	assert.Equal(t, int64(2), breaker.State().Rejected)
+
+	// Redis 仍不可达时探测失败，保持打开
+	breaker.Probe(ctx, client)
+	assert.Equal(t, domain.BreakerOpen, breaker.State().State)
+
+	// 连接池在拨号失败后会退避一段时间，探测可能需要重试几次
+	require.NoError(t, mr.Restart())
+	require.Eventually(t, func() bool {
+		breaker.Probe(ctx, client)
+		return breaker.State().State == domain.BreakerClosed
+	}, 5*time.Second, 100*time.Millisecond)
+	assert.Nil(t, breaker.State().OpenedAt)
+	require.NoError(t, cache.SetLikeCount(ctx, 1, 3))
+}
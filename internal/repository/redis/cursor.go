@@ -0,0 +1,38 @@
+package redis
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+	"github.com/redis/go-redis/v9"
+)
+
+const KeyJobCursor = "job:cursor:%s"
+
+type cursorStore struct {
+	client *redis.Client
+	keyPrefix
+}
+
+var _ domain.CursorStore = (*cursorStore)(nil)
+
+// NewCursorStore 创建后台任务进度存储，进度在所有实例间共享
+func NewCursorStore(client *redis.Client, prefix string) *cursorStore {
+	return &cursorStore{
+		client,
+		keyPrefix(prefix),
+	}
+}
+
+func (s *cursorStore) GetCursor(ctx context.Context, name string) (int64, error) {
+	cursor, err := s.client.Get(ctx, s.key(KeyJobCursor, name)).Int64()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+	return cursor, err
+}
+
+func (s *cursorStore) SetCursor(ctx context.Context, name string, cursor int64) error {
+	return s.client.Set(ctx, s.key(KeyJobCursor, name), cursor, 0).Err()
+}
@@ -0,0 +1,14 @@
+package redis
+
+import "fmt"
+
+// keyPrefix 加在所有 key 前面，用于多个环境共享同一个 Redis 实例
+type keyPrefix string
+
+// key 按 format 生成 key 并加上前缀
+func (p keyPrefix) key(format string, args ...any) string {
+	if len(args) == 0 {
+		return string(p) + format
+	}
+	return string(p) + fmt.Sprintf(format, args...)
+}
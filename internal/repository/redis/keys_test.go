@@ -0,0 +1,101 @@
+package redis_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+	myRedis "github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/repository/redis"
+)
+
+func newTestClient(t *testing.T) (*miniredis.Miniredis, *redis.Client) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	return mr, client
+}
+
+func TestArticleCacheKeysUsePrefix(t *testing.T) {
+	mr, client := newTestClient(t)
+	ctx := context.Background()
+	cache := myRedis.NewArticleCache(client, "staging:", 0, 0)
+
+	require.NoError(t, cache.SetArticleWithLogicalExpire(ctx, &domain.Article{ID: 1}, 0, time.Minute))
+	require.NoError(t, cache.SetLikeCount(ctx, 1, 3))
+	_, err := cache.IncrViews(ctx, 1)
+	require.NoError(t, err)
+	require.NoError(t, cache.SetHomeWithLogicalExpire(ctx, domain.ArticlePage{Articles: []domain.Article{{ID: 1}}}, time.Minute))
+
+	assert.ElementsMatch(t, []string{
+		"staging:article:1",
+		"staging:article:likes:1",
+		"staging:article:views:buffer",
+		"staging:article:home",
+	}, mr.Keys())
+}
+
+func TestPrefixesDoNotCollide(t *testing.T) {
+	_, client := newTestClient(t)
+	ctx := context.Background()
+	dev := myRedis.NewArticleCache(client, "dev:", 0, 0)
+	prod := myRedis.NewArticleCache(client, "prod:", 0, 0)
+
+	require.NoError(t, dev.SetArticleWithLogicalExpire(ctx, &domain.Article{ID: 1, Title: "dev"}, 0, time.Minute))
+	require.NoError(t, prod.SetArticleWithLogicalExpire(ctx, &domain.Article{ID: 1, Title: "prod"}, 0, time.Minute))
+
+	ar, _, err := dev.GetArticleWithLogicalExpire(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "dev", ar.Title)
+
+	ar, _, err = prod.GetArticleWithLogicalExpire(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "prod", ar.Title)
+
+	require.NoError(t, dev.DeleteArticle(ctx, 1))
+	_, _, err = dev.GetArticleWithLogicalExpire(ctx, 1)
+	assert.Error(t, err)
+	_, _, err = prod.GetArticleWithLogicalExpire(ctx, 1)
+	assert.NoError(t, err)
+}
+
+func TestBloomPrefixesDoNotCollide(t *testing.T) {
+	mr, client := newTestClient(t)
+	ctx := context.Background()
+	dev := myRedis.NewRedisBloomRepo(client, 1024, "dev:")
+	prod := myRedis.NewRedisBloomRepo(client, 1024, "prod:")
+
+	require.NoError(t, dev.Add(ctx, 42))
+
+	exists, err := dev.Exists(ctx, 42)
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	exists, err = prod.Exists(ctx, 42)
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	assert.Equal(t, []string{"dev:bloom:article:ids"}, mr.Keys())
+}
+
+func TestBloomExistsBatchSinglePipeline(t *testing.T) {
+	_, client := newTestClient(t)
+	ctx := context.Background()
+	bloom := myRedis.NewRedisBloomRepo(client, 1<<20, "")
+	require.NoError(t, bloom.BulkAdd(ctx, []int64{1, 2, 3}))
+
+	counter := &cmdCounter{}
+	client.AddHook(counter)
+
+	got, err := bloom.ExistsBatch(ctx, []int64{1, 2, 3, 404})
+	require.NoError(t, err)
+	assert.Equal(t, map[int64]bool{1: true, 2: true, 3: true, 404: false}, got)
+	assert.Equal(t, int64(1), counter.pipelines.Load(), "should be a single pipelined call")
+	assert.Equal(t, int64(12), counter.n.Load(), "3 bits for each of the 4 ids")
+}
@@ -0,0 +1,92 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	KeyReactionCount = "article:reactions:%d:%s"
+)
+
+type reactionCache struct {
+	client *redis.Client
+	keyPrefix
+}
+
+var _ domain.ReactionCache = (*reactionCache)(nil)
+
+func NewReactionCache(client *redis.Client, prefix string) *reactionCache {
+	return &reactionCache{
+		client,
+		keyPrefix(prefix),
+	}
+}
+
+// cachedReactionTypes 除 like 以外的回应类型，like 的计数由 articleCache 维护
+func cachedReactionTypes() []domain.ReactionType {
+	types := make([]domain.ReactionType, 0, len(domain.ReactionTypes))
+	for _, t := range domain.ReactionTypes {
+		if t != domain.ReactionLike {
+			types = append(types, t)
+		}
+	}
+	return types
+}
+
+func (c *reactionCache) GetReactionCounts(ctx context.Context, aid int64) (domain.ReactionCounts, error) {
+	types := cachedReactionTypes()
+	keys := make([]string, len(types))
+	for i, t := range types {
+		keys[i] = c.key(KeyReactionCount, aid, t)
+	}
+
+	vals, err := c.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(domain.ReactionCounts, len(types))
+	for i, val := range vals {
+		str, ok := val.(string)
+		if !ok {
+			return nil, domain.ErrCacheMiss
+		}
+		count, err := strconv.ParseInt(str, 10, 64)
+		if err != nil {
+			return nil, domain.ErrCacheMiss
+		}
+		counts[types[i]] = count
+	}
+	return counts, nil
+}
+
+func (c *reactionCache) SetReactionCounts(ctx context.Context, aid int64, counts domain.ReactionCounts) error {
+	pipe := c.client.Pipeline()
+	for _, t := range cachedReactionTypes() {
+		pipe.Set(ctx, c.key(KeyReactionCount, aid, t), counts[t], 7*24*time.Hour)
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func (c *reactionCache) IncrReactionCount(ctx context.Context, aid int64, t domain.ReactionType, delta int64) error {
+	var script = redis.NewScript(`
+		if redis.call('EXISTS', KEYS[1]) == 0 then
+			return nil -- 未缓存，等待下次读取时从数据库加载
+		end
+		redis.call('INCRBY', KEYS[1], ARGV[1])
+		redis.call('EXPIRE', KEYS[1], 7*24*60*60)
+		return 1
+	`)
+	err := script.Run(ctx, c.client, []string{c.key(KeyReactionCount, aid, t)}, delta).Err()
+	if errors.Is(err, redis.Nil) {
+		return nil
+	}
+	return err
+}
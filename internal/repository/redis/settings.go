@@ -0,0 +1,36 @@
+package redis
+
+import (
+	"context"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+	"github.com/redis/go-redis/v9"
+)
+
+const KeySettings = "settings"
+
+type settingsRepository struct {
+	client *redis.Client
+	keyPrefix
+}
+
+var _ domain.SettingsRepository = (*settingsRepository)(nil)
+
+// NewSettingsRepository 创建运行时配置仓储，所有配置保存在同一个 hash 中
+func NewSettingsRepository(client *redis.Client, prefix string) *settingsRepository {
+	return &settingsRepository{
+		client,
+		keyPrefix(prefix),
+	}
+}
+
+func (r *settingsRepository) GetAll(ctx context.Context) (map[string]string, error) {
+	return r.client.HGetAll(ctx, r.key(KeySettings)).Result()
+}
+
+func (r *settingsRepository) SetMany(ctx context.Context, values map[string]string) error {
+	if len(values) == 0 {
+		return nil
+	}
+	return r.client.HSet(ctx, r.key(KeySettings), values).Err()
+}
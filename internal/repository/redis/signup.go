@@ -0,0 +1,84 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// KeyRegistration 带令牌的注册，认领时写入空值，注册成功后写入结果
+	KeyRegistration = "signup:registration:%s"
+	// KeyUsernameCheck 每个客户端在当前窗口内查询用户名的次数
+	KeyUsernameCheck = "signup:username_check:%s"
+)
+
+type signupCache struct {
+	client *redis.Client
+	keyPrefix
+}
+
+var _ domain.SignupCache = (*signupCache)(nil)
+
+// NewSignupCache 创建注册表单使用的缓存
+func NewSignupCache(client *redis.Client, prefix string) *signupCache {
+	return &signupCache{
+		client,
+		keyPrefix(prefix),
+	}
+}
+
+func (c *signupCache) ClaimRegistration(ctx context.Context, token string, ttl time.Duration) (bool, error) {
+	return c.client.SetNX(ctx, c.key(KeyRegistration, token), "", ttl).Result()
+}
+
+func (c *signupCache) SaveRegistration(ctx context.Context, token string, res domain.Registration, ttl time.Duration) error {
+	data, err := json.Marshal(res)
+	if err != nil {
+		return err
+	}
+	return c.client.Set(ctx, c.key(KeyRegistration, token), data, ttl).Err()
+}
+
+func (c *signupCache) GetRegistration(ctx context.Context, token string) (domain.Registration, error) {
+	data, err := c.client.Get(ctx, c.key(KeyRegistration, token)).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return domain.Registration{}, domain.ErrCacheMiss
+		}
+		return domain.Registration{}, err
+	}
+	// 空值表示第一次提交还在处理中
+	if len(data) == 0 {
+		return domain.Registration{}, domain.ErrCacheMiss
+	}
+
+	var res domain.Registration
+	if err := json.Unmarshal(data, &res); err != nil {
+		return domain.Registration{}, err
+	}
+	return res, nil
+}
+
+func (c *signupCache) ReleaseRegistration(ctx context.Context, token string) error {
+	return c.client.Del(ctx, c.key(KeyRegistration, token)).Err()
+}
+
+// AllowUsernameCheck 固定窗口计数，窗口从第一次查询开始计时
+func (c *signupCache) AllowUsernameCheck(ctx context.Context, client string, limit int64, window time.Duration) (bool, error) {
+	key := c.key(KeyUsernameCheck, client)
+	n, err := c.client.Incr(ctx, key).Result()
+	if err != nil {
+		return false, err
+	}
+	if n == 1 {
+		if err := c.client.Expire(ctx, key, window).Err(); err != nil {
+			return false, err
+		}
+	}
+	return n <= limit, nil
+}
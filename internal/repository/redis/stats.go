@@ -0,0 +1,52 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+	"github.com/redis/go-redis/v9"
+)
+
+const KeySiteStats = "stats:%s"
+
+type siteStatsCache struct {
+	client *redis.Client
+	keyPrefix
+}
+
+var _ domain.SiteStatsCache = (*siteStatsCache)(nil)
+
+// NewSiteStatsCache 创建站点统计缓存
+func NewSiteStatsCache(client *redis.Client, prefix string) *siteStatsCache {
+	return &siteStatsCache{
+		client,
+		keyPrefix(prefix),
+	}
+}
+
+func (c *siteStatsCache) GetCounts(ctx context.Context, key string) (domain.SiteCounts, error) {
+	data, err := c.client.Get(ctx, c.key(KeySiteStats, key)).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return domain.SiteCounts{}, domain.ErrCacheMiss
+		}
+		return domain.SiteCounts{}, err
+	}
+
+	var counts domain.SiteCounts
+	if err := json.Unmarshal(data, &counts); err != nil {
+		return domain.SiteCounts{}, err
+	}
+	return counts, nil
+}
+
+func (c *siteStatsCache) SetCounts(ctx context.Context, key string, counts domain.SiteCounts, ttl time.Duration) error {
+	data, err := json.Marshal(counts)
+	if err != nil {
+		return err
+	}
+	return c.client.Set(ctx, c.key(KeySiteStats, key), data, ttl).Err()
+}
@@ -0,0 +1,52 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+	"github.com/redis/go-redis/v9"
+)
+
+const KeyTrendingTags = "tags:trending:%s"
+
+type tagCache struct {
+	client *redis.Client
+	keyPrefix
+}
+
+var _ domain.TagCache = (*tagCache)(nil)
+
+// NewTagCache 创建热门标签缓存
+func NewTagCache(client *redis.Client, prefix string) *tagCache {
+	return &tagCache{
+		client,
+		keyPrefix(prefix),
+	}
+}
+
+func (c *tagCache) GetTrending(ctx context.Context, key string) ([]domain.TagCount, error) {
+	data, err := c.client.Get(ctx, c.key(KeyTrendingTags, key)).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, domain.ErrCacheMiss
+		}
+		return nil, err
+	}
+
+	var tags []domain.TagCount
+	if err := json.Unmarshal(data, &tags); err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+func (c *tagCache) SetTrending(ctx context.Context, key string, tags []domain.TagCount, ttl time.Duration) error {
+	data, err := json.Marshal(tags)
+	if err != nil {
+		return err
+	}
+	return c.client.Set(ctx, c.key(KeyTrendingTags, key), data, ttl).Err()
+}
@@ -0,0 +1,145 @@
+package redis
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+)
+
+const (
+	// KeyTitleIndex 所有分数为 0 的 ZSET，按字典序做前缀查询
+	KeyTitleIndex = "article:title:index"
+	// KeyTitleIndexMembers 文章 ID 到 ZSET 成员的映射，改标题和删除时据此找到旧成员
+	KeyTitleIndexMembers = "article:title:index:members"
+)
+
+// titleIndexBatchSize 重建索引时每条 ZADD/HSET 写入的成员数
+const titleIndexBatchSize = 500
+
+type titleIndex struct {
+	client *redis.Client
+	keyPrefix
+}
+
+var _ domain.TitleIndex = (*titleIndex)(nil)
+
+// NewTitleIndex 创建基于 Redis ZSET 的标题前缀索引。
+// 成员为 "小写标题\x00ID\x00原标题"，小写标题用于不区分大小写的匹配；
+// UTF-8 的字节序与前缀一致，中文等多字节前缀同样适用
+func NewTitleIndex(client *redis.Client, prefix string) *titleIndex {
+	return &titleIndex{
+		client,
+		keyPrefix(prefix),
+	}
+}
+
+func titleIndexMember(id int64, title string) string {
+	return normalizeTitlePrefix(title) + "\x00" + strconv.FormatInt(id, 10) + "\x00" + title
+}
+
+func normalizeTitlePrefix(s string) string {
+	return strings.ToLower(strings.TrimSpace(s))
+}
+
+func (t *titleIndex) Set(ctx context.Context, id int64, title string) error {
+	script := redis.NewScript(`
+		local old = redis.call('HGET', KEYS[2], ARGV[1])
+		if old then
+			redis.call('ZREM', KEYS[1], old)
+		end
+		redis.call('ZADD', KEYS[1], 0, ARGV[2])
+		redis.call('HSET', KEYS[2], ARGV[1], ARGV[2])
+		return 1
+	`)
+	keys := []string{t.key(KeyTitleIndex), t.key(KeyTitleIndexMembers)}
+	return script.Run(ctx, t.client, keys, id, titleIndexMember(id, title)).Err()
+}
+
+func (t *titleIndex) Rename(ctx context.Context, id int64, title string) error {
+	script := redis.NewScript(`
+		local old = redis.call('HGET', KEYS[2], ARGV[1])
+		if not old then
+			return 0
+		end
+		redis.call('ZREM', KEYS[1], old)
+		redis.call('ZADD', KEYS[1], 0, ARGV[2])
+		redis.call('HSET', KEYS[2], ARGV[1], ARGV[2])
+		return 1
+	`)
+	keys := []string{t.key(KeyTitleIndex), t.key(KeyTitleIndexMembers)}
+	return script.Run(ctx, t.client, keys, id, titleIndexMember(id, title)).Err()
+}
+
+func (t *titleIndex) Remove(ctx context.Context, id int64) error {
+	script := redis.NewScript(`
+		local old = redis.call('HGET', KEYS[2], ARGV[1])
+		if old then
+			redis.call('ZREM', KEYS[1], old)
+			redis.call('HDEL', KEYS[2], ARGV[1])
+		end
+		return 1
+	`)
+	keys := []string{t.key(KeyTitleIndex), t.key(KeyTitleIndexMembers)}
+	return script.Run(ctx, t.client, keys, id).Err()
+}
+
+func (t *titleIndex) Suggest(ctx context.Context, prefix string, limit int64) ([]domain.TitleSuggestion, error) {
+	prefix = normalizeTitlePrefix(prefix)
+	// 0xff 不会出现在 UTF-8 中，"[prefix" 到 "[prefix\xff" 正好覆盖所有以 prefix 开头的成员
+	members, err := t.client.ZRangeByLex(ctx, t.key(KeyTitleIndex), &redis.ZRangeBy{
+		Min:   "[" + prefix,
+		Max:   "[" + prefix + "\xff",
+		Count: limit,
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]domain.TitleSuggestion, 0, len(members))
+	for _, m := range members {
+		parts := strings.SplitN(m, "\x00", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		id, err := strconv.ParseInt(parts[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		res = append(res, domain.TitleSuggestion{ID: id, Title: parts[2]})
+	}
+	return res, nil
+}
+
+// Replace 先写入临时 key 再整体 RENAME，重建期间查询仍然使用旧索引。
+// 重建期间的 Set/Remove 会被覆盖，由下一次重建修正
+func (t *titleIndex) Replace(ctx context.Context, titles []domain.TitleSuggestion) error {
+	index, members := t.key(KeyTitleIndex), t.key(KeyTitleIndexMembers)
+	if len(titles) == 0 {
+		return t.client.Del(ctx, index, members).Err()
+	}
+
+	tmpIndex, tmpMembers := index+":tmp", members+":tmp"
+	_, err := t.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, tmpIndex, tmpMembers)
+		for start := 0; start < len(titles); start += titleIndexBatchSize {
+			batch := titles[start:min(start+titleIndexBatchSize, len(titles))]
+			zs := make([]redis.Z, len(batch))
+			hs := make(map[string]any, len(batch))
+			for i, s := range batch {
+				member := titleIndexMember(s.ID, s.Title)
+				zs[i] = redis.Z{Member: member}
+				hs[strconv.FormatInt(s.ID, 10)] = member
+			}
+			pipe.ZAdd(ctx, tmpIndex, zs...)
+			pipe.HSet(ctx, tmpMembers, hs)
+		}
+		pipe.Rename(ctx, tmpIndex, index)
+		pipe.Rename(ctx, tmpMembers, members)
+		return nil
+	})
+	return err
+}
@@ -0,0 +1,87 @@
+package redis_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+	myRedis "github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/repository/redis"
+)
+
+func TestTitleIndexCJKPrefix(t *testing.T) {
+	_, client := newTestClient(t)
+	ctx := context.Background()
+	index := myRedis.NewTitleIndex(client, "")
+
+	require.NoError(t, index.Set(ctx, 1, "数据库索引设计"))
+	require.NoError(t, index.Set(ctx, 2, "数据结构与算法"))
+	require.NoError(t, index.Set(ctx, 3, "数学之美"))
+	require.NoError(t, index.Set(ctx, 4, "Go 并发模式"))
+
+	res, err := index.Suggest(ctx, "数据", 10)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.TitleSuggestion{{ID: 1, Title: "数据库索引设计"}, {ID: 2, Title: "数据结构与算法"}}, res)
+
+	// 前缀按完整的字符匹配，只共享第一个字的标题不会混入
+	res, err = index.Suggest(ctx, "数学", 10)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.TitleSuggestion{{ID: 3, Title: "数学之美"}}, res)
+
+	// 不区分大小写，返回原始标题
+	res, err = index.Suggest(ctx, "go ", 10)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.TitleSuggestion{{ID: 4, Title: "Go 并发模式"}}, res)
+
+	res, err = index.Suggest(ctx, "数", 1)
+	require.NoError(t, err)
+	assert.Len(t, res, 1)
+}
+
+func TestTitleIndexRenameAndRemove(t *testing.T) {
+	mr, client := newTestClient(t)
+	ctx := context.Background()
+	index := myRedis.NewTitleIndex(client, "")
+
+	require.NoError(t, index.Set(ctx, 1, "旧标题"))
+	require.NoError(t, index.Rename(ctx, 1, "新标题"))
+	// 不在索引中的文章（例如已隐藏）改名后仍然不在索引中
+	require.NoError(t, index.Rename(ctx, 2, "新文章"))
+
+	res, err := index.Suggest(ctx, "新", 10)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.TitleSuggestion{{ID: 1, Title: "新标题"}}, res)
+	res, err = index.Suggest(ctx, "旧", 10)
+	require.NoError(t, err)
+	assert.Empty(t, res)
+
+	require.NoError(t, index.Remove(ctx, 1))
+	res, err = index.Suggest(ctx, "新", 10)
+	require.NoError(t, err)
+	assert.Empty(t, res)
+	assert.False(t, mr.Exists(myRedis.KeyTitleIndex))
+}
+
+func TestTitleIndexReplace(t *testing.T) {
+	_, client := newTestClient(t)
+	ctx := context.Background()
+	index := myRedis.NewTitleIndex(client, "")
+
+	require.NoError(t, index.Set(ctx, 9, "已删除的文章"))
+	require.NoError(t, index.Replace(ctx, []domain.TitleSuggestion{{ID: 1, Title: "重建后的文章"}}))
+
+	res, err := index.Suggest(ctx, "已删", 10)
+	require.NoError(t, err)
+	assert.Empty(t, res)
+	res, err = index.Suggest(ctx, "重建", 10)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.TitleSuggestion{{ID: 1, Title: "重建后的文章"}}, res)
+
+	// 重建后 Rename/Remove 仍然能找到旧成员
+	require.NoError(t, index.Rename(ctx, 1, "改名"))
+	res, err = index.Suggest(ctx, "改名", 10)
+	require.NoError(t, err)
+	assert.Len(t, res, 1)
+}
@@ -0,0 +1,74 @@
+package redis
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// KeyTrafficReferrers/KeyTrafficDevices 每天每项指标一个 hash，field 为来源域名或设备类型
+	KeyTrafficReferrers = "stats:%s:ref:%s"
+	KeyTrafficDevices   = "stats:%s:device:%s"
+)
+
+// trafficTTL 流量统计在 Redis 中的保留时间，需要覆盖夜间写入数据库的那几天
+const trafficTTL = 4 * 24 * time.Hour
+
+func trafficDate(day time.Time) string {
+	return day.Format("20060102")
+}
+
+// RecordTraffic 在一个 pipeline 中累加来源和设备两个 hash，并刷新过期时间
+func (c *articleCache) RecordTraffic(ctx context.Context, metric domain.TrafficMetric, day time.Time, info domain.ClientInfo) error {
+	referrer := info.Referrer
+	if referrer == "" {
+		referrer = domain.ReferrerDirect
+	}
+	device := info.Device
+	if device == "" {
+		device = domain.DeviceOther
+	}
+
+	date := trafficDate(day)
+	refKey := c.key(KeyTrafficReferrers, metric, date)
+	deviceKey := c.key(KeyTrafficDevices, metric, date)
+
+	pipe := c.client.Pipeline()
+	pipe.HIncrBy(ctx, refKey, referrer, 1)
+	pipe.HIncrBy(ctx, deviceKey, string(device), 1)
+	pipe.Expire(ctx, refKey, trafficTTL)
+	pipe.Expire(ctx, deviceKey, trafficTTL)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func (c *articleCache) GetTraffic(ctx context.Context, metric domain.TrafficMetric, day time.Time) (domain.TrafficCounts, error) {
+	date := trafficDate(day)
+	pipe := c.client.Pipeline()
+	refs := pipe.HGetAll(ctx, c.key(KeyTrafficReferrers, metric, date))
+	devices := pipe.HGetAll(ctx, c.key(KeyTrafficDevices, metric, date))
+	if _, err := pipe.Exec(ctx); err != nil {
+		return domain.TrafficCounts{}, err
+	}
+
+	counts := domain.NewTrafficCounts()
+	parseTrafficHash(refs, counts.Referrers)
+	parseTrafficHash(devices, counts.Devices)
+	return counts, nil
+}
+
+func parseTrafficHash(cmd *redis.MapStringStringCmd, dst map[string]int64) {
+	for field, val := range cmd.Val() {
+		n, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			logrus.Errorf("failed to parse traffic count %s=%q: %v", field, val, err)
+			continue
+		}
+		dst[field] = n
+	}
+}
@@ -0,0 +1,49 @@
+package redis_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+	myRedis "github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/repository/redis"
+)
+
+func TestRecordTraffic(t *testing.T) {
+	mr, client := newTestClient(t)
+	ctx := context.Background()
+	cache := myRedis.NewArticleCache(client, "", 0, 0)
+	day := time.Date(2026, 10, 16, 12, 0, 0, 0, time.Local)
+
+	require.NoError(t, cache.RecordTraffic(ctx, domain.TrafficViews, day, domain.ClientInfo{Referrer: "google.com", Device: domain.DeviceMobile}))
+	require.NoError(t, cache.RecordTraffic(ctx, domain.TrafficViews, day, domain.ClientInfo{Referrer: "google.com", Device: domain.DeviceDesktop}))
+	// 空的来源和设备归入 direct 和 other
+	require.NoError(t, cache.RecordTraffic(ctx, domain.TrafficViews, day, domain.ClientInfo{}))
+	require.NoError(t, cache.RecordTraffic(ctx, domain.TrafficLikes, day, domain.ClientInfo{Referrer: "internal", Device: domain.DeviceMobile}))
+
+	assert.ElementsMatch(t, []string{
+		"stats:views:ref:20261016",
+		"stats:views:device:20261016",
+		"stats:likes:ref:20261016",
+		"stats:likes:device:20261016",
+	}, mr.Keys())
+	assert.Positive(t, mr.TTL("stats:views:ref:20261016"))
+
+	views, err := cache.GetTraffic(ctx, domain.TrafficViews, day)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int64{"google.com": 2, "direct": 1}, views.Referrers)
+	assert.Equal(t, map[string]int64{"mobile": 1, "desktop": 1, "other": 1}, views.Devices)
+
+	likes, err := cache.GetTraffic(ctx, domain.TrafficLikes, day)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int64{"internal": 1}, likes.Referrers)
+
+	// 没有记录的日期返回空计数
+	empty, err := cache.GetTraffic(ctx, domain.TrafficViews, day.AddDate(0, 0, -1))
+	require.NoError(t, err)
+	assert.True(t, empty.IsEmpty())
+	assert.NotNil(t, empty.Referrers)
+}
@@ -0,0 +1,52 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+	"github.com/redis/go-redis/v9"
+)
+
+// KeyWorkerStatus 每个 worker 最近一次成功写入的记录，不设置过期时间，重启后仍然可以查看
+const KeyWorkerStatus = "worker:status:%s"
+
+type workerStatusCache struct {
+	client *redis.Client
+	keyPrefix
+}
+
+var _ domain.WorkerStatusRepository = (*workerStatusCache)(nil)
+
+// NewWorkerStatusCache 创建 worker 运行记录的存储
+func NewWorkerStatusCache(client *redis.Client, prefix string) *workerStatusCache {
+	return &workerStatusCache{
+		client,
+		keyPrefix(prefix),
+	}
+}
+
+func (c *workerStatusCache) SetWorkerStatus(ctx context.Context, status domain.WorkerStatus) error {
+	data, err := json.Marshal(status)
+	if err != nil {
+		return err
+	}
+	return c.client.Set(ctx, c.key(KeyWorkerStatus, status.Name), data, 0).Err()
+}
+
+func (c *workerStatusCache) GetWorkerStatus(ctx context.Context, name string) (domain.WorkerStatus, error) {
+	data, err := c.client.Get(ctx, c.key(KeyWorkerStatus, name)).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return domain.WorkerStatus{}, domain.ErrCacheMiss
+		}
+		return domain.WorkerStatus{}, err
+	}
+
+	var status domain.WorkerStatus
+	if err := json.Unmarshal(data, &status); err != nil {
+		return domain.WorkerStatus{}, err
+	}
+	return status, nil
+}
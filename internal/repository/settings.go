@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+	"github.com/sirupsen/logrus"
+)
+
+// 未配置或配置无法解析时使用的默认值，与引入运行时配置前的行为一致
+const (
+	defaultSettingBloomEnabled        = true
+	defaultSettingViewCountingEnabled = true
+	defaultSettingRankViewWeight      = 0
+)
+
+// runtimeSettings 在进程内缓存运行时配置，定期从仓储刷新，
+// 因此修改后最迟一个刷新周期在所有实例上生效
+type runtimeSettings struct {
+	repo   domain.SettingsRepository
+	values atomic.Pointer[map[string]string]
+}
+
+var _ domain.Settings = (*runtimeSettings)(nil)
+
+// NewRuntimeSettings 创建运行时配置，在第一次 Refresh 之前返回默认值
+func NewRuntimeSettings(repo domain.SettingsRepository) *runtimeSettings {
+	s := &runtimeSettings{repo: repo}
+	s.values.Store(&map[string]string{})
+	return s
+}
+
+// Refresh 从仓储重新加载全部配置，失败时保留上一次的值
+func (s *runtimeSettings) Refresh(ctx context.Context) error {
+	values, err := s.repo.GetAll(ctx)
+	if err != nil {
+		return err
+	}
+	s.values.Store(&values)
+	return nil
+}
+
+// Start 每隔 interval 刷新一次配置，直到 ctx 结束
+func (s *runtimeSettings) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.Refresh(ctx); err != nil {
+				logrus.Warnf("failed to refresh runtime settings: %v", err)
+			}
+		}
+	}
+}
+
+func (s *runtimeSettings) BloomEnabled() bool {
+	return s.getBool(domain.SettingBloomEnabled, defaultSettingBloomEnabled)
+}
+
+func (s *runtimeSettings) ViewCountingEnabled() bool {
+	return s.getBool(domain.SettingViewCountingEnabled, defaultSettingViewCountingEnabled)
+}
+
+func (s *runtimeSettings) RankViewWeight() float64 {
+	raw, ok := (*s.values.Load())[domain.SettingRankViewWeight]
+	if !ok {
+		return defaultSettingRankViewWeight
+	}
+	v, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return defaultSettingRankViewWeight
+	}
+	return v
+}
+
+func (s *runtimeSettings) getBool(key string, def bool) bool {
+	raw, ok := (*s.values.Load())[key]
+	if !ok {
+		return def
+	}
+	v, err := strconv.ParseBool(raw)
+	if err != nil {
+		return def
+	}
+	return v
+}
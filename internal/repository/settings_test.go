@@ -0,0 +1,107 @@
+package repository_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/repository"
+	myRedis "github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/repository/redis"
+)
+
+// missingBloom 认为所有 ID 都不存在
+type missingBloom struct {
+	domain.BloomRepository
+}
+
+func (missingBloom) Exists(context.Context, int64) (bool, error) {
+	return false, nil
+}
+
+// viewCountingCache 记录浏览量和热榜分数的增加
+type viewCountingCache struct {
+	fakeCache
+	views atomic.Int64
+	score atomic.Value
+}
+
+func (c *viewCountingCache) IncrViews(context.Context, int64) (int64, error) {
+	return c.views.Add(1), nil
+}
+
+func (c *viewCountingCache) IncrDailyRankScore(_ context.Context, _ int64, delta float64) error {
+	c.score.Store(delta)
+	return nil
+}
+
+func TestSettingsPropagateToOtherInstances(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	client := redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	// 两个实例共享同一个 Redis
+	settingsRepo := myRedis.NewSettingsRepository(client, "test:")
+	writer := repository.NewRuntimeSettings(settingsRepo)
+	reader := repository.NewRuntimeSettings(myRedis.NewSettingsRepository(client, "test:"))
+	go reader.Start(ctx, 10*time.Millisecond)
+
+	bloom := repository.NewToggleBloomRepository(missingBloom{}, reader)
+	exists, err := bloom.Exists(ctx, 1)
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	require.NoError(t, settingsRepo.SetMany(ctx, map[string]string{domain.SettingBloomEnabled: "false"}))
+	require.NoError(t, writer.Refresh(ctx))
+	assert.False(t, writer.BloomEnabled())
+
+	assert.Eventually(t, func() bool {
+		exists, err := bloom.Exists(ctx, 1)
+		return err == nil && exists
+	}, time.Second, 10*time.Millisecond, "bloom should fail open once disabled")
+}
+
+func TestSettingsDefaults(t *testing.T) {
+	settings := repository.NewRuntimeSettings(emptySettingsRepo{})
+	require.NoError(t, settings.Refresh(context.Background()))
+
+	assert.True(t, settings.BloomEnabled())
+	assert.True(t, settings.ViewCountingEnabled())
+	assert.Zero(t, settings.RankViewWeight())
+}
+
+func TestViewCountingFollowsSettings(t *testing.T) {
+	ctx := context.Background()
+	client := redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	settingsRepo := myRedis.NewSettingsRepository(client, "")
+	settings := repository.NewRuntimeSettings(settingsRepo)
+
+	cache := &viewCountingCache{fakeCache: fakeCache{articles: map[int64]domain.Article{1: {ID: 1}}}}
+	repo := repository.NewArticleRepository(&fakeDB{}, cache, fakeUserRepo{}, settings, false, goRunner{})
+
+	_, err := repo.GetByID(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), cache.views.Load())
+	assert.Nil(t, cache.score.Load(), "views do not score by default")
+
+	require.NoError(t, settingsRepo.SetMany(ctx, map[string]string{domain.SettingRankViewWeight: "0.5"}))
+	require.NoError(t, settings.Refresh(ctx))
+	_, err = repo.GetByID(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), cache.views.Load())
+	assert.Equal(t, 0.5, cache.score.Load())
+
+	require.NoError(t, settingsRepo.SetMany(ctx, map[string]string{domain.SettingViewCountingEnabled: "false"}))
+	require.NoError(t, settings.Refresh(ctx))
+	_, err = repo.GetByID(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), cache.views.Load())
+}
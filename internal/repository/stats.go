@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+)
+
+// siteStatsTTL 统计数字的缓存时间，仪表盘不需要实时数据
+const siteStatsTTL = 60 * time.Second
+
+// cachedSiteStatsRepository 把统计结果缓存在 Redis 中，避免每次打开仪表盘都执行 COUNT
+type cachedSiteStatsRepository struct {
+	db    domain.SiteStatsRepository
+	cache domain.SiteStatsCache
+}
+
+// NewCachedSiteStatsRepository 包装 db，缓存读写失败时直接查询数据库
+func NewCachedSiteStatsRepository(db domain.SiteStatsRepository, cache domain.SiteStatsCache) domain.SiteStatsRepository {
+	return &cachedSiteStatsRepository{db: db, cache: cache}
+}
+
+func (r *cachedSiteStatsRepository) Count(ctx context.Context, name string, since time.Time) (domain.SiteCounts, error) {
+	// since 不同时今日数量不同，缓存 key 带上起始时间
+	key := name + ":" + since.Format("20060102150405")
+	if counts, err := r.cache.GetCounts(ctx, key); err == nil {
+		return counts, nil
+	}
+
+	counts, err := r.db.Count(ctx, name, since)
+	if err != nil {
+		return domain.SiteCounts{}, err
+	}
+	if err := r.cache.SetCounts(ctx, key, counts, siteStatsTTL); err != nil {
+		logrus.Warnf("failed to cache site stats %s: %v", name, err)
+	}
+	return counts, nil
+}
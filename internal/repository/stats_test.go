@@ -0,0 +1,55 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/repository"
+	myRedis "github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/repository/redis"
+)
+
+// countingStats 记录 COUNT 查询的次数，每次返回递增的结果
+type countingStats struct {
+	calls int64
+}
+
+func (f *countingStats) Count(context.Context, string, time.Time) (domain.SiteCounts, error) {
+	f.calls++
+	return domain.SiteCounts{Total: f.calls * 10, Today: f.calls}, nil
+}
+
+func TestCachedSiteStats(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	db := &countingStats{}
+	stats := repository.NewCachedSiteStatsRepository(db, myRedis.NewSiteStatsCache(client, ""))
+	today := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	first, err := stats.Count(ctx, domain.StatsArticles, today)
+	require.NoError(t, err)
+	second, err := stats.Count(ctx, domain.StatsArticles, today)
+	require.NoError(t, err)
+	assert.Equal(t, first, second)
+	assert.Equal(t, int64(1), db.calls)
+
+	// 不同的统计项分别缓存
+	_, err = stats.Count(ctx, domain.StatsUsers, today)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), db.calls)
+
+	// 缓存 60 秒后过期
+	mr.FastForward(61 * time.Second)
+	third, err := stats.Count(ctx, domain.StatsArticles, today)
+	require.NoError(t, err)
+	assert.Equal(t, domain.SiteCounts{Total: 30, Today: 3}, third)
+}
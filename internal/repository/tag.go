@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+)
+
+// trendingTagsTTL 热门标签的缓存时间，排行不需要实时
+const trendingTagsTTL = 60 * time.Second
+
+// cachedTagRepository 把热门标签缓存在 Redis 中，避免每次请求都对关联表做聚合
+type cachedTagRepository struct {
+	db    domain.TagRepository
+	cache domain.TagCache
+}
+
+// NewCachedTagRepository 包装 db，缓存读写失败时直接查询数据库
+func NewCachedTagRepository(db domain.TagRepository, cache domain.TagCache) domain.TagRepository {
+	return &cachedTagRepository{db: db, cache: cache}
+}
+
+func (r *cachedTagRepository) Trending(ctx context.Context, since time.Time, limit int64) ([]domain.TagCount, error) {
+	// 起始时间和数量不同时结果不同，缓存 key 带上两者
+	key := since.Format("20060102150405") + ":" + strconv.FormatInt(limit, 10)
+	if tags, err := r.cache.GetTrending(ctx, key); err == nil {
+		return tags, nil
+	}
+
+	tags, err := r.db.Trending(ctx, since, limit)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.cache.SetTrending(ctx, key, tags, trendingTagsTTL); err != nil {
+		logrus.Warnf("failed to cache trending tags: %v", err)
+	}
+	return tags, nil
+}
@@ -0,0 +1,55 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/repository"
+	myRedis "github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/repository/redis"
+)
+
+// countingTags 记录聚合查询的次数，每次返回的数量递增
+type countingTags struct {
+	calls int64
+}
+
+func (f *countingTags) Trending(context.Context, time.Time, int64) ([]domain.TagCount, error) {
+	f.calls++
+	return []domain.TagCount{{Name: "go", Articles: f.calls}}, nil
+}
+
+func TestCachedTrendingTags(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	db := &countingTags{}
+	tags := repository.NewCachedTagRepository(db, myRedis.NewTagCache(client, ""))
+	since := time.Date(2024, 1, 2, 3, 4, 0, 0, time.UTC)
+
+	first, err := tags.Trending(ctx, since, 10)
+	require.NoError(t, err)
+	second, err := tags.Trending(ctx, since, 10)
+	require.NoError(t, err)
+	assert.Equal(t, first, second)
+	assert.Equal(t, int64(1), db.calls)
+
+	// 不同的数量分别缓存
+	_, err = tags.Trending(ctx, since, 5)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), db.calls)
+
+	// 缓存 60 秒后过期
+	mr.FastForward(61 * time.Second)
+	third, err := tags.Trending(ctx, since, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.TagCount{{Name: "go", Articles: 3}}, third)
+}
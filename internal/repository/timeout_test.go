@@ -0,0 +1,134 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/repository"
+)
+
+// readBudget 测试使用的读取超时，fallbackDeadline 是回退到数据库后整个调用允许的耗时上限
+const (
+	readBudget       = 20 * time.Millisecond
+	fallbackDeadline = 500 * time.Millisecond
+)
+
+// slowCache 的读操作一直阻塞到 ctx 结束，模拟卡住的 Redis，写入照常
+type slowCache struct {
+	*fakeCache
+}
+
+func (slowCache) GetArticleWithLogicalExpire(ctx context.Context, _ int64) (domain.Article, bool, error) {
+	<-ctx.Done()
+	return domain.Article{}, false, ctx.Err()
+}
+
+func (slowCache) GetArticleByIDsWithLogicalExpire(ctx context.Context, _ []int64) ([]domain.Article, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func (slowCache) GetHistoryRank(ctx context.Context, _ int64) ([]domain.Article, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func (slowCache) GetStaleHistoryRank(ctx context.Context, _ int64) ([]domain.Article, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func (slowCache) SetHistoryRank(context.Context, []int64, []float64) error { return nil }
+
+func (slowCache) SetStaleHistoryRank(context.Context, []int64, []float64) error { return nil }
+
+// slowDB 的 GetByID 和 Search 一直阻塞到 ctx 结束
+type slowDB struct {
+	*fakeDB
+}
+
+func (slowDB) GetByID(ctx context.Context, _ int64) (domain.Article, error) {
+	<-ctx.Done()
+	return domain.Article{}, ctx.Err()
+}
+
+func (slowDB) Search(ctx context.Context, _, _ string, _ int64) ([]domain.Article, bool, error) {
+	<-ctx.Done()
+	return nil, false, ctx.Err()
+}
+
+func newTimedRepo(db domain.ArticleDBRepository, cache domain.ArticleCache) domain.ArticleRepository {
+	repo := repository.NewArticleRepository(db, cache, fakeUserRepo{}, repository.NewRuntimeSettings(emptySettingsRepo{}), true, nil)
+	repo.Timeouts = repository.ReadTimeouts{Cache: readBudget, DB: readBudget}
+	return repo
+}
+
+func TestSlowCacheFallsBackToDB(t *testing.T) {
+	cache := slowCache{&fakeCache{articles: map[int64]domain.Article{}}}
+	db := &fakeDB{articles: map[int64]domain.Article{1: {ID: 1, Title: "from db"}}}
+	repo := newTimedRepo(db, cache)
+	ctx := context.Background()
+
+	start := time.Now()
+	ar, err := repo.GetByID(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "from db", ar.Title)
+	assert.Less(t, time.Since(start), fallbackDeadline)
+
+	start = time.Now()
+	ars, err := repo.GetByIDs(ctx, []int64{1})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1}, rankIDs(ars))
+	assert.Less(t, time.Since(start), fallbackDeadline)
+}
+
+func TestSlowCacheFallsBackToDBForRank(t *testing.T) {
+	cache := slowCache{&fakeCache{articles: map[int64]domain.Article{}}}
+	db := &rankDB{articles: []domain.Article{{ID: 2, Likes: 5}, {ID: 1, Likes: 3}}}
+	repo := newTimedRepo(db, cache)
+
+	// 热榜、旧副本和文章详情三次缓存读取都超时，仍然在预算内从数据库返回
+	start := time.Now()
+	rank, err := repo.GetHistoryRank(context.Background(), 10)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{2, 1}, rankIDs(rank))
+	assert.Less(t, time.Since(start), fallbackDeadline)
+}
+
+func TestSlowDBFailsWithinBudget(t *testing.T) {
+	cache := missCache{&fakeCache{articles: map[int64]domain.Article{}}}
+	repo := newTimedRepo(slowDB{&fakeDB{}}, cache)
+
+	start := time.Now()
+	_, err := repo.GetByID(context.Background(), 1)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), fallbackDeadline)
+}
+
+func TestSlowSearchFailsWithinBudget(t *testing.T) {
+	repo := newTimedRepo(slowDB{&fakeDB{}}, &fakeCache{articles: map[int64]domain.Article{}})
+
+	start := time.Now()
+	_, _, err := repo.Search(context.Background(), "go", "", 10)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), fallbackDeadline)
+}
+
+func TestReadBudgetDoesNotOutliveRequest(t *testing.T) {
+	cache := missCache{&fakeCache{articles: map[int64]domain.Article{}}}
+	repo := repository.NewArticleRepository(slowDB{&fakeDB{}}, cache, fakeUserRepo{}, repository.NewRuntimeSettings(emptySettingsRepo{}), true, nil)
+	repo.Timeouts = repository.ReadTimeouts{Cache: time.Minute, DB: time.Minute}
+
+	// 请求本身的超时更短时以请求为准
+	ctx, cancel := context.WithTimeout(context.Background(), readBudget)
+	defer cancel()
+	start := time.Now()
+	_, err := repo.GetByID(ctx, 1)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), fallbackDeadline)
+}
@@ -0,0 +1,47 @@
+package rest
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/rest/response"
+	"github.com/gin-gonic/gin"
+)
+
+type activityHandler struct {
+	Service domain.ActivityUsecase
+}
+
+func NewActivityHandler(svc domain.ActivityUsecase) *activityHandler {
+	return &activityHandler{
+		Service: svc,
+	}
+}
+
+// FetchByUser GET /users/:id/activity，作者的发布和里程碑动态，从新到旧排列，分页方式与草稿列表相同
+func (h *activityHandler) FetchByUser(c *gin.Context) {
+	num, ok := queryInt(c, pageNumParam)
+	if !ok {
+		return
+	}
+	idP, err := strconv.Atoi(c.Param("id"))
+	if err != nil || idP <= 0 {
+		c.JSON(http.StatusNotFound, ResponseError{Message: domain.ErrNotFound.Error()})
+		return
+	}
+
+	activities, nextCursor, err := h.Service.FetchByUser(c.Request.Context(), int64(idP), c.Query("cursor"), int64(num))
+	if err != nil {
+		c.JSON(getStatusCode(err), ResponseError{Message: err.Error()})
+		return
+	}
+
+	res := make([]response.Activity, len(activities))
+	for i := range activities {
+		res[i] = response.NewActivityFromDomain(&activities[i])
+	}
+	setPaginationHeaders(c, nextCursor, num)
+	c.Header(HeaderHasMore, strconv.FormatBool(nextCursor != ""))
+	c.JSON(http.StatusOK, res)
+}
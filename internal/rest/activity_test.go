@@ -0,0 +1,70 @@
+package rest_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/rest"
+)
+
+type fakeActivityUsecase struct {
+	domain.ActivityUsecase
+	userID int64
+	cursor string
+}
+
+func (f *fakeActivityUsecase) FetchByUser(_ context.Context, userID int64, cursor string, _ int64) ([]domain.Activity, string, error) {
+	f.userID, f.cursor = userID, cursor
+	if cursor == "bad" {
+		return nil, "", domain.ErrBadParamInput
+	}
+	at := time.Date(2024, 5, 1, 8, 30, 0, 0, time.UTC)
+	return []domain.Activity{
+		{ID: 9, UserID: userID, ArticleID: 3, ArticleTitle: "Hello", Type: domain.ActivityLikesMilestone, Milestone: 50, CreatedAt: at},
+		{ID: 7, UserID: userID, ArticleID: 3, ArticleTitle: "Hello", Type: domain.ActivityPublished, CreatedAt: at},
+	}, "7", nil
+}
+
+func TestFetchActivityByUser(t *testing.T) {
+	svc := &fakeActivityUsecase{}
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/users/:id/activity", rest.NewActivityHandler(svc).FetchByUser)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users/4/activity?cursor=12&num=5", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, int64(4), svc.userID)
+	assert.Equal(t, "12", svc.cursor)
+
+	// 发布动态不带 milestone 字段
+	assert.JSONEq(t, `[
+		{"id":9,"type":"likes_milestone","article_id":3,"article_title":"Hello","milestone":50,"created_at":"2024-05-01 08:30:00"},
+		{"id":7,"type":"published","article_id":3,"article_title":"Hello","created_at":"2024-05-01 08:30:00"}
+	]`, w.Body.String())
+	assert.Equal(t, "7", w.Header().Get("X-cursor"))
+	assert.Equal(t, "true", w.Header().Get(rest.HeaderHasMore))
+	assert.Equal(t, `</users/4/activity?cursor=7&num=5>; rel="next"`, w.Header().Get("Link"))
+}
+
+func TestFetchActivityBadInput(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/users/:id/activity", rest.NewActivityHandler(&fakeActivityUsecase{}).FetchByUser)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users/abc/activity", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users/4/activity?cursor=bad", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
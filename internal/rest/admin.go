@@ -0,0 +1,151 @@
+package rest
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/rest/request"
+)
+
+// AdminHandler represent the httphandler for admin operations
+type AdminHandler struct {
+	Service domain.AdminUsecase
+}
+
+func NewAdminHandler(svc domain.AdminUsecase) *AdminHandler {
+	return &AdminHandler{
+		Service: svc,
+	}
+}
+
+// BulkModerateArticles applies one moderation action to many articles.
+// Partial failures are reported per id in the body, the status code stays 200.
+func (h *AdminHandler) BulkModerateArticles(c *gin.Context) {
+	var req request.BulkModeration
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	results, err := h.Service.BulkModerateArticles(c.Request.Context(), userID.(int64), req.ToDomain(), req.IDs)
+	if err != nil {
+		c.JSON(getStatusCode(err), ResponseError{Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"results": results})
+}
+
+// GetSettings returns the runtime settings in effect
+func (h *AdminHandler) GetSettings(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"settings": h.Service.GetSettings(c.Request.Context())})
+}
+
+// UpdateSettings changes some runtime settings, the body is a JSON object of setting keys to new values
+func (h *AdminHandler) UpdateSettings(c *gin.Context) {
+	var values map[string]any
+	if err := c.ShouldBindJSON(&values); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	settings, err := h.Service.UpdateSettings(c.Request.Context(), userID.(int64), values)
+	if err != nil {
+		c.JSON(getStatusCode(err), ResponseError{Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"settings": settings})
+}
+
+// Overview returns a snapshot of site metrics for dashboards
+func (h *AdminHandler) Overview(c *gin.Context) {
+	c.JSON(http.StatusOK, h.Service.Overview(c.Request.Context()))
+}
+
+// ReconcileLikes repairs article likes drifted from user_likes and returns a summary.
+// If the run is interrupted, the summary of the finished part is returned with 500, calling again resumes it.
+func (h *AdminHandler) ReconcileLikes(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	summary, err := h.Service.ReconcileLikes(c.Request.Context(), userID.(int64))
+	if err != nil {
+		c.JSON(getStatusCode(err), gin.H{"error": err.Error(), "summary": summary})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"summary": summary})
+}
+
+// ImportArticles accepts NDJSON or a JSON array of articles and imports them in the background.
+// It answers 202 with the job at once, progress is polled via GetImportJob.
+func (h *AdminHandler) ImportArticles(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	// 导入在请求结束后继续执行，请求体需要先完整读出
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, domain.MaxImportBytes))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, ResponseError{Message: err.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, ResponseError{Message: err.Error()})
+		return
+	}
+
+	job, err := h.Service.ImportArticles(c.Request.Context(), userID.(int64), body)
+	if err != nil {
+		c.JSON(getStatusCode(err), ResponseError{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusAccepted, gin.H{"job": job})
+}
+
+// GetImportJob returns the progress of an article import, including every skipped or failed record
+func (h *AdminHandler) GetImportJob(c *gin.Context) {
+	job, err := h.Service.GetImportJob(c.Request.Context(), c.Param("job"))
+	if err != nil {
+		c.JSON(getStatusCode(err), ResponseError{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"job": job})
+}
+
+// Traffic returns the daily views and likes by referrer domain and device class, oldest day first
+func (h *AdminHandler) Traffic(c *gin.Context) {
+	days, ok := queryInt(c, trafficDaysParam)
+	if !ok {
+		return
+	}
+
+	res, err := h.Service.Traffic(c.Request.Context(), days)
+	if err != nil {
+		c.JSON(getStatusCode(err), ResponseError{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"days": res})
+}
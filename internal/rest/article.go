@@ -1,6 +1,8 @@
 package rest
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -16,9 +18,24 @@ type ResponseError struct {
 	Message string `json:"message"`
 }
 
+// ConflictResponse 创建文章时标题或正文冲突的响应，ExistingID 为已存在的文章
+type ConflictResponse struct {
+	Code       string `json:"code"`
+	Message    string `json:"message"`
+	ExistingID int64  `json:"existing_id"`
+}
+
 // ArticleHandler  represent the httphandler for article
 type ArticleHandler struct {
 	Service domain.ArticleUsecase
+	// ListIncludeContent 为 true 时文章列表返回正文，需要数据库层同样配置查询正文
+	ListIncludeContent bool
+	// DefaultLanguage 创建文章时没有指定语言时使用的语言标签，为空表示不指定
+	DefaultLanguage string
+	// ProviderName 是 oEmbed 响应中的站点名称，为空时省略
+	ProviderName string
+	// UnfurlBots 为 true 时爬虫请求文章详情直接返回元数据，不计浏览量也不返回正文
+	UnfurlBots bool
 }
 
 const (
@@ -29,6 +46,19 @@ const (
 	DefaultRankLimit = 10
 	RankMin          = 5
 	RankMax          = 30
+	// RankMaxOffset 今日热榜最多可以翻到的名次
+	RankMaxOffset = 500
+
+	DefaultSuggestLimit = 5
+	SuggestMaxLimit     = 10
+
+	// HeaderFeedSource 标明首页列表来自缓存(cache)、数据库(db)还是触发了重建的过期缓存(rebuild)
+	HeaderFeedSource = "X-Feed-Source"
+	// HeaderHasMore 为 true 时后面还有文章，与 X-cursor 是否为空一致
+	HeaderHasMore = "X-Has-More"
+
+	// FormatEnvelope 作为 format 参数时文章列表返回 response.ArticlePage，默认仍是裸数组
+	FormatEnvelope = "envelope"
 )
 
 func NewArticleHandler(svc domain.ArticleUsecase) *ArticleHandler {
@@ -39,6 +69,11 @@ func NewArticleHandler(svc domain.ArticleUsecase) *ArticleHandler {
 
 // GetByID will get article by given id
 func (a *ArticleHandler) GetByID(c *gin.Context) {
+	if a.UnfurlBots && isBotRequest(c) {
+		a.GetMeta(c)
+		return
+	}
+
 	idP, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusNotFound, ResponseError{Message: domain.ErrNotFound.Error()})
@@ -47,36 +82,83 @@ func (a *ArticleHandler) GetByID(c *gin.Context) {
 	id := int64(idP)
 	ctx := c.Request.Context()
 
-	art, err := a.Service.GetByID(ctx, id)
+	// 匿名请求的 viewerID 为 0，不读取用户状态
+	viewerID := c.GetInt64("user_id")
+	// 管理员带 include_deleted=true 时先查已删除的文章，其他人的这个参数被忽略
+	if c.Query("include_deleted") == "true" && c.GetString("role") == domain.RoleAdmin {
+		art, err := a.Service.GetDeleted(ctx, id)
+		if err == nil {
+			c.JSON(http.StatusOK, response.NewArticleForViewer(&art))
+			return
+		}
+		if !errors.Is(err, domain.ErrNotFound) {
+			c.JSON(getStatusCode(err), ResponseError{Message: err.Error()})
+			return
+		}
+	}
+	art, err := a.Service.GetByIDForViewer(ctx, id, viewerID)
 	if err != nil {
 		c.JSON(getStatusCode(err), ResponseError{Message: err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, response.NewArticleFromDomain(&art))
+	c.JSON(http.StatusOK, response.NewArticleForViewer(&art))
+}
+
+// GetDetail 返回文章详情、标签、点赞数、评论数，登录用户额外返回自己的点赞状态
+func (a *ArticleHandler) GetDetail(c *gin.Context) {
+	idP, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, ResponseError{Message: domain.ErrNotFound.Error()})
+		return
+	}
+
+	viewerID := c.GetInt64("user_id")
+	detail, err := a.Service.GetDetail(c.Request.Context(), int64(idP), viewerID)
+	if err != nil {
+		c.JSON(getStatusCode(err), ResponseError{Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.NewArticleDetailFromDomain(&detail))
 }
 
 // FetchArticle will fetch the articles based on given params
 func (a *ArticleHandler) FetchArticle(c *gin.Context) {
-	numS := c.Query("num")
-	num, err := strconv.Atoi(numS)
-	if err != nil || num < PageMinNum || num > PageMaxNum {
-		num = DefaultPageNum
+	num, ok := queryInt(c, pageNumParam)
+	if !ok {
+		return
 	}
 
 	cursor := c.Query("cursor")
 	ctx := c.Request.Context()
+	var source *domain.FeedSource
+	if cursor == "" {
+		ctx, source = domain.WithFeedSourceRecorder(ctx)
+	}
 
-	listAr, nextCursor, err := a.Service.Fetch(ctx, cursor, int64(num))
+	listAr, nextCursor, err := a.Service.Fetch(ctx, cursor, int64(num), c.Query("lang"), c.Query("tag"))
 	if err != nil {
 		c.JSON(getStatusCode(err), ResponseError{Message: err.Error()})
 		return
 	}
+	if source != nil && *source != "" {
+		c.Header(HeaderFeedSource, string(*source))
+	}
 	res := make([]response.Article, len(listAr))
 	for i := range listAr {
-		res[i] = response.NewArticleFromDomain(&listAr[i])
+		if a.ListIncludeContent {
+			res[i] = response.NewArticleFromDomain(&listAr[i])
+		} else {
+			res[i] = response.NewArticleSummaryFromDomain(&listAr[i])
+		}
+	}
+	setPaginationHeaders(c, nextCursor, num)
+	c.Header(HeaderHasMore, strconv.FormatBool(nextCursor != ""))
+	if c.Query("format") == FormatEnvelope {
+		c.JSON(http.StatusOK, response.NewArticlePage(res, nextCursor))
+		return
 	}
-	c.Header(`X-cursor`, nextCursor)
 	c.JSON(http.StatusOK, res)
 }
 
@@ -95,17 +177,77 @@ func (a *ArticleHandler) Store(c *gin.Context) {
 	}
 	article := req.ToDomain()
 	article.User.ID = userID.(int64)
+	if article.Language == "" {
+		article.Language = a.DefaultLanguage
+	}
 
 	ctx := c.Request.Context()
 	if err := a.Service.Store(ctx, &article); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		var conflict *domain.ConflictError
+		if errors.As(err, &conflict) && conflict.DuplicateContent {
+			c.JSON(http.StatusConflict, ConflictResponse{
+				Code:       "duplicate_content",
+				Message:    "an article with the same content already exists",
+				ExistingID: conflict.ExistingID,
+			})
+			return
+		}
+		if conflict != nil {
+			c.JSON(http.StatusConflict, ConflictResponse{
+				Code:       "conflict",
+				Message:    "an article with the same title already exists",
+				ExistingID: conflict.ExistingID,
+			})
+			return
+		}
+		// 语言标签或摘要不合法时为 400
+		c.JSON(getStatusCode(err), gin.H{"error": err.Error()})
 		return
 	}
 
 	c.JSON(http.StatusCreated, response.NewArticleFromDomain(&article))
 }
 
-// Delete will delete the article by given param
+// Update edits the article by given param, only its author may do it.
+// Fields left empty in the body keep their current value
+func (a *ArticleHandler) Update(c *gin.Context) {
+	idP, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, domain.ErrNotFound.Error())
+		return
+	}
+
+	var req request.ArticleUpdate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.IsEmpty() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "nothing to update"})
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+	article := req.ToDomain()
+	article.ID = int64(idP)
+	article.User.ID = userID.(int64)
+
+	if err := a.Service.Update(c.Request.Context(), &article); err != nil {
+		if writeTitleConflict(c, err) {
+			return
+		}
+		c.JSON(getStatusCode(err), ResponseError{err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.NewArticleFromDomain(&article))
+}
+
+// Delete will delete the article by given param, only its author may do it
 func (a *ArticleHandler) Delete(c *gin.Context) {
 	idP, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
@@ -113,8 +255,13 @@ func (a *ArticleHandler) Delete(c *gin.Context) {
 		return
 	}
 	id := int64(idP)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
 
-	if err := a.Service.Delete(c.Request.Context(), id); err != nil {
+	if err := a.Service.Delete(c.Request.Context(), id, userID.(int64)); err != nil {
 		c.JSON(getStatusCode(err), ResponseError{err.Error()})
 		return
 	}
@@ -122,6 +269,30 @@ func (a *ArticleHandler) Delete(c *gin.Context) {
 	c.Status(http.StatusNoContent)
 }
 
+// Restore 恢复自己删除的文章，其他人的文章返回 403，没有被删除的文章返回 404
+func (a *ArticleHandler) Restore(c *gin.Context) {
+	idP, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, ResponseError{Message: domain.ErrNotFound.Error()})
+		return
+	}
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	if err := a.Service.Restore(c.Request.Context(), int64(idP), userID.(int64)); err != nil {
+		if writeTitleConflict(c, err) {
+			return
+		}
+		c.JSON(getStatusCode(err), ResponseError{Message: err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
 // Like adds a like record if not exists
 func (a *ArticleHandler) Like(c *gin.Context) {
 	idP, err := strconv.Atoi(c.Param("id"))
@@ -136,7 +307,7 @@ func (a *ArticleHandler) Like(c *gin.Context) {
 		return
 	}
 	uid := UserID.(int64)
-	ok, err := a.Service.AddLikeRecord(c.Request.Context(), domain.UserLike{
+	state, err := a.Service.AddLikeRecord(c.Request.Context(), domain.UserLike{
 		ArticleID: aid,
 		UserID:    uid,
 	})
@@ -145,7 +316,7 @@ func (a *ArticleHandler) Like(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"is_changed": ok})
+	c.JSON(http.StatusOK, response.NewLikeState(state))
 }
 
 // Unlike removes a like record if exists
@@ -162,34 +333,106 @@ func (a *ArticleHandler) Unlike(c *gin.Context) {
 		return
 	}
 	uid := UserID.(int64)
-	ok, err := a.Service.RemoveLikeRecord(c.Request.Context(), domain.UserLike{
+	state, err := a.Service.RemoveLikeRecord(c.Request.Context(), domain.UserLike{
+		ArticleID: aid,
+		UserID:    uid,
+	})
+	if err != nil {
+		c.JSON(getStatusCode(err), ResponseError{err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.NewLikeState(state))
+}
+
+// LockComments closes the discussion on an article, only the author and admins may do it
+func (a *ArticleHandler) LockComments(c *gin.Context) {
+	a.setCommentsLocked(c, true)
+}
+
+// UnlockComments reopens the discussion on an article
+func (a *ArticleHandler) UnlockComments(c *gin.Context) {
+	a.setCommentsLocked(c, false)
+}
+
+func (a *ArticleHandler) setCommentsLocked(c *gin.Context, locked bool) {
+	idP, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, domain.ErrNotFound.Error())
+		return
+	}
+	aid := int64(idP)
+	UserID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+	actor := domain.User{ID: UserID.(int64), Role: c.GetString("role")}
+	if err := a.Service.SetCommentsLocked(c.Request.Context(), aid, actor, locked); err != nil {
+		c.JSON(getStatusCode(err), ResponseError{err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"comments_locked": locked})
+}
+
+// AddReaction adds a reaction of the given type if not exists
+func (a *ArticleHandler) AddReaction(c *gin.Context) {
+	a.changeReaction(c, a.Service.AddReaction)
+}
+
+// RemoveReaction removes a reaction of the given type if exists
+func (a *ArticleHandler) RemoveReaction(c *gin.Context) {
+	a.changeReaction(c, a.Service.RemoveReaction)
+}
+
+func (a *ArticleHandler) changeReaction(c *gin.Context, change func(context.Context, domain.Reaction) (bool, domain.ReactionCounts, error)) {
+	idP, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, domain.ErrNotFound.Error())
+		return
+	}
+	aid := int64(idP)
+	UserID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+	uid := UserID.(int64)
+	ok, counts, err := change(c.Request.Context(), domain.Reaction{
 		ArticleID: aid,
 		UserID:    uid,
+		Type:      domain.ReactionType(c.Param("type")),
 	})
 	if err != nil {
 		c.JSON(getStatusCode(err), ResponseError{err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"is_changed": ok})
+	c.JSON(http.StatusOK, gin.H{"is_changed": ok, "reactions": counts})
 }
 
 func (a *ArticleHandler) FetchRank(c *gin.Context) {
-	limitS := c.Query("limit")
-	limit, err := strconv.ParseInt(limitS, 10, 64)
-	if err != nil || limit < RankMin || limit > RankMax {
-		limit = DefaultRankLimit
-		logrus.Error("Invalid param 'limit'")
+	limit, ok := queryInt(c, rankLimitParam)
+	if !ok {
+		return
+	}
+	offset, ok := queryInt(c, rankOffsetParam)
+	if !ok {
+		return
 	}
 	rankType := c.DefaultQuery("type", "daily")
 
-	var listAr []domain.Article
+	var (
+		listAr []domain.Article
+		err    error
+	)
 
 	switch rankType {
 	case "daily":
-		listAr, err = a.Service.FetchDailyRank(c.Request.Context(), limit)
+		listAr, err = a.Service.FetchDailyRank(c.Request.Context(), int64(offset), int64(limit))
 	case "history":
-		listAr, err = a.Service.FetchHistoryRank(c.Request.Context(), limit)
+		listAr, err = a.Service.FetchHistoryRank(c.Request.Context(), int64(limit))
 	default:
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid rank type"})
 		return
@@ -201,11 +444,152 @@ func (a *ArticleHandler) FetchRank(c *gin.Context) {
 
 	res := make([]response.Article, len(listAr))
 	for i := range listAr {
-		res[i] = response.NewArticleFromDomain(&listAr[i])
+		res[i] = response.NewArticleSummaryFromDomain(&listAr[i])
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+// SuggestTitles 按标题前缀联想文章，q 至少 2 个字符
+func (a *ArticleHandler) SuggestTitles(c *gin.Context) {
+	limit, ok := queryInt(c, suggestLimitParam)
+	if !ok {
+		return
+	}
+
+	suggestions, err := a.Service.SuggestTitles(c.Request.Context(), c.Query("q"), int64(limit))
+	if err != nil {
+		c.JSON(getStatusCode(err), ResponseError{err.Error()})
+		return
+	}
+
+	res := make([]response.TitleSuggestion, len(suggestions))
+	for i := range suggestions {
+		res[i] = response.NewTitleSuggestionFromDomain(suggestions[i])
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+// Search 按标题和正文搜索文章，q 为 2 到 64 个字符，分页方式与 FetchArticle 相同
+func (a *ArticleHandler) Search(c *gin.Context) {
+	num, ok := queryInt(c, pageNumParam)
+	if !ok {
+		return
+	}
+
+	listAr, nextCursor, err := a.Service.Search(c.Request.Context(), c.Query("q"), c.Query("cursor"), int64(num))
+	if err != nil {
+		c.JSON(getStatusCode(err), ResponseError{Message: err.Error()})
+		return
+	}
+
+	res := make([]response.Article, len(listAr))
+	for i := range listAr {
+		if a.ListIncludeContent {
+			res[i] = response.NewArticleFromDomain(&listAr[i])
+		} else {
+			res[i] = response.NewArticleSummaryFromDomain(&listAr[i])
+		}
+	}
+	setPaginationHeaders(c, nextCursor, num)
+	c.Header(HeaderHasMore, strconv.FormatBool(nextCursor != ""))
+	c.JSON(http.StatusOK, res)
+}
+
+// FetchDrafts 返回当前用户自己的草稿，分页方式与 FetchArticle 相同
+func (a *ArticleHandler) FetchDrafts(c *gin.Context) {
+	num, ok := queryInt(c, pageNumParam)
+	if !ok {
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+	drafts, nextCursor, err := a.Service.FetchDrafts(c.Request.Context(), userID.(int64), c.Query("cursor"), int64(num))
+	if err != nil {
+		c.JSON(getStatusCode(err), ResponseError{Message: err.Error()})
+		return
+	}
+
+	res := make([]response.Article, len(drafts))
+	for i := range drafts {
+		res[i] = response.NewArticleSummaryFromDomain(&drafts[i])
+	}
+	setPaginationHeaders(c, nextCursor, num)
+	c.Header(HeaderHasMore, strconv.FormatBool(nextCursor != ""))
+	c.JSON(http.StatusOK, res)
+}
+
+// Publish 发布自己的草稿，其他人的草稿和已发布的文章都返回 404
+func (a *ArticleHandler) Publish(c *gin.Context) {
+	idP, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, ResponseError{Message: domain.ErrNotFound.Error()})
+		return
+	}
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	art, err := a.Service.Publish(c.Request.Context(), int64(idP), userID.(int64))
+	if err != nil {
+		c.JSON(getStatusCode(err), ResponseError{Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.NewArticleFromDomain(&art))
+}
+
+// ListTags 返回所有标签和带有该标签的可见文章数，按文章数从多到少排列
+func (a *ArticleHandler) ListTags(c *gin.Context) {
+	tags, err := a.Service.ListTags(c.Request.Context())
+	if err != nil {
+		c.JSON(getStatusCode(err), ResponseError{Message: err.Error()})
+		return
+	}
+
+	res := make([]response.TagCount, len(tags))
+	for i, tag := range tags {
+		res[i] = response.NewTagCountFromDomain(tag)
 	}
 	c.JSON(http.StatusOK, res)
 }
 
+// GetEngagement 批量返回文章的点赞数和评论数，不存在的文章计数为 0
+func (a *ArticleHandler) GetEngagement(c *gin.Context) {
+	var req request.Engagement
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	engagement, err := a.Service.GetEngagement(c.Request.Context(), req.IDs)
+	if err != nil {
+		c.JSON(getStatusCode(err), ResponseError{err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"engagement": engagement})
+}
+
+// writeTitleConflict 在 err 是标题冲突时返回 409 和已有文章的ID，返回值表示是否已经写出响应
+func writeTitleConflict(c *gin.Context, err error) bool {
+	var conflict *domain.ConflictError
+	if !errors.As(err, &conflict) || conflict.DuplicateContent {
+		return false
+	}
+	c.JSON(http.StatusConflict, ConflictResponse{
+		Code:       "conflict",
+		Message:    "an article with the same title already exists",
+		ExistingID: conflict.ExistingID,
+	})
+	return true
+}
+
 // getStatusCode will get the code of the error from domain.ArticleUsecase
 func getStatusCode(err error) int {
 	if err == nil {
@@ -220,6 +604,14 @@ func getStatusCode(err error) int {
 		return http.StatusNotFound
 	case domain.ErrConflict:
 		return http.StatusConflict
+	case domain.ErrBadParamInput:
+		return http.StatusBadRequest
+	case domain.ErrForbidden:
+		return http.StatusForbidden
+	case domain.ErrTooManyRequests:
+		return http.StatusTooManyRequests
+	case domain.ErrCacheUnavailable:
+		return http.StatusServiceUnavailable
 	default:
 		return http.StatusInternalServerError
 	}
@@ -0,0 +1,1077 @@
+package rest_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	gormMysql "gorm.io/driver/mysql"
+	"gorm.io/gorm"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/repository"
+	mysqlRepo "github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/repository/mysql"
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/repository/mysql/model"
+	myRedis "github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/repository/redis"
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/rest"
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/rest/response"
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/usecase/article"
+)
+
+// openDryRunDB 返回不连接数据库的 gorm.DB，所有查询都没有结果，相当于刚部署时的空库
+func openDryRunDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(gormMysql.New(gormMysql.Config{
+		DSN:                       "user:password@tcp(127.0.0.1:3306)/article",
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{
+		DryRun:                 true,
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+	})
+	require.NoError(t, err)
+	return db
+}
+
+// newDryRunDB 在 openDryRunDB 的基础上让文章查询总是返回文章 1，评论查询返回它的一条评论，用户查询返回作者 7
+func newDryRunDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db := openDryRunDB(t)
+	require.NoError(t, db.Callback().Query().After("gorm:query").Register("test:fill", func(tx *gorm.DB) {
+		now := time.Now()
+		switch dest := tx.Statement.Dest.(type) {
+		case *model.Article:
+			*dest = model.Article{ID: 1, Title: "title", UserID: 7, CreatedAt: now, UpdatedAt: now}
+		case *[]model.Comment:
+			*dest = []model.Comment{{ID: 1, ArticleID: 1, UserID: 7, Content: "hi", CreatedAt: now}}
+		case *[]model.Article:
+			*dest = []model.Article{{ID: 1, Title: "title", UserID: 7, CreatedAt: now, UpdatedAt: now}}
+		case *[]model.User:
+			*dest = []model.User{{ID: 7, Name: "author"}}
+		}
+	}))
+	return db
+}
+
+// newArticleService 创建 handler 测试用的 article usecase，其余依赖对这些测试没有影响
+func newArticleService(repo domain.ArticleRepository, cache domain.ArticleCache, ti domain.TitleIndex) domain.ArticleUsecase {
+	return article.NewService(repo, cache, nil, repository.NewNoopBloomRepository(), nil, nil, domain.ExcerptFixedLength, ti, nil, nil)
+}
+
+func TestFetchArticleFeedSource(t *testing.T) {
+	db := newDryRunDB(t)
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	cache := myRedis.NewArticleCache(client, "", 0, 0)
+	articleRepo := repository.NewArticleRepository(
+		mysqlRepo.NewArticleDBRepository(db, false),
+		cache,
+		mysqlRepo.NewUserRepository(db),
+		repository.NewRuntimeSettings(nil),
+		true,
+		nil,
+	)
+	svc := newArticleService(articleRepo, cache, nil)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/articles", rest.NewArticleHandler(svc).FetchArticle)
+
+	get := func(target string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+		require.Equal(t, http.StatusOK, w.Code)
+		return w
+	}
+
+	mr.FlushAll()
+	assert.Equal(t, "db", get("/articles").Header().Get(rest.HeaderFeedSource))
+
+	// 首页缓存是异步写入的
+	require.Eventually(t, func() bool { return mr.Exists("article:home") }, time.Second, 10*time.Millisecond)
+	assert.Equal(t, "cache", get("/articles").Header().Get(rest.HeaderFeedSource))
+
+	// 翻页请求不经过首页缓存，不返回该响应头
+	assert.Empty(t, get("/articles?cursor="+url.QueryEscape(repository.EncodeCursor(time.Now(), 1))).Header().Get(rest.HeaderFeedSource))
+}
+
+func TestFetchArticleEmptyDatabase(t *testing.T) {
+	db := openDryRunDB(t)
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	cache := myRedis.NewArticleCache(client, "", 0, 0)
+	articleRepo := repository.NewArticleRepository(
+		mysqlRepo.NewArticleDBRepository(db, false),
+		cache,
+		mysqlRepo.NewUserRepository(db),
+		repository.NewRuntimeSettings(nil),
+		true,
+		nil,
+	)
+	svc := newArticleService(articleRepo, cache, nil)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/articles", rest.NewArticleHandler(svc).FetchArticle)
+
+	// 新部署的第一个请求读空库，第二个请求读缓存中的空首页，翻页请求游标之后也没有文章
+	for _, target := range []string{"/articles", "/articles", "/articles?cursor=" + url.QueryEscape(repository.EncodeCursor(time.Now(), 1))} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+
+		require.Equal(t, http.StatusOK, w.Code, target)
+		assert.JSONEq(t, `[]`, w.Body.String(), target)
+		assert.Empty(t, w.Header().Values("X-cursor"), target)
+		assert.Equal(t, "false", w.Header().Get(rest.HeaderHasMore), target)
+	}
+	assert.True(t, mr.Exists("article:home"))
+}
+
+// pageUsecase 返回两篇文章和下一页游标
+type pageUsecase struct {
+	domain.ArticleUsecase
+}
+
+func (pageUsecase) Fetch(context.Context, string, int64, string, string) ([]domain.Article, string, error) {
+	return []domain.Article{{ID: 1, Title: "a"}, {ID: 2, Title: "b"}}, "next", nil
+}
+
+func TestFetchArticleEnvelope(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/articles", rest.NewArticleHandler(pageUsecase{}).FetchArticle)
+
+	get := func(target string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+		require.Equal(t, http.StatusOK, w.Code)
+		return w
+	}
+
+	// 不带 format 时仍然是裸数组，老客户端不受影响
+	w := get("/articles?num=2")
+	var list []response.Article
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	assert.Len(t, list, 2)
+	// is_liked 只在文章详情中返回
+	assert.NotContains(t, w.Body.String(), "is_liked")
+
+	w = get("/articles?num=2&format=envelope")
+	var page response.ArticlePage
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	assert.Len(t, page.Data, 2)
+	assert.Equal(t, "next", page.NextCursor)
+	assert.True(t, page.HasMore)
+	assert.Equal(t, 2, page.Count)
+	// 响应头照常返回
+	assert.Equal(t, "next", w.Header().Get("X-cursor"))
+	assert.Equal(t, "true", w.Header().Get(rest.HeaderHasMore))
+}
+
+// deletedUserRepo 模拟作者账号已被删除的用户表
+type deletedUserRepo struct {
+	domain.UserRepository
+}
+
+func (deletedUserRepo) GetByID(context.Context, int64) (domain.User, error) {
+	return domain.User{}, domain.ErrNotFound
+}
+
+func (deletedUserRepo) GetByIDs(context.Context, []int64) ([]domain.User, error) {
+	return nil, nil
+}
+
+func TestGetArticleByDeletedAuthor(t *testing.T) {
+	db := newDryRunDB(t)
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	cache := myRedis.NewArticleCache(client, "", 0, 0)
+	articleRepo := repository.NewArticleRepository(
+		mysqlRepo.NewArticleDBRepository(db, false),
+		cache,
+		deletedUserRepo{},
+		repository.NewRuntimeSettings(nil),
+		true,
+		nil,
+	)
+	svc := newArticleService(articleRepo, cache, nil)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/articles/:id", rest.NewArticleHandler(svc).GetByID)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/articles/1", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var body struct {
+		UserName string `json:"user_name"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, domain.DeletedUserName, body.UserName)
+}
+
+// conflictUsecase 创建文章时总是遇到标题冲突
+type conflictUsecase struct {
+	domain.ArticleUsecase
+}
+
+func (conflictUsecase) Store(context.Context, *domain.Article) error {
+	return &domain.ConflictError{ExistingID: 42}
+}
+
+func TestStoreConflictReturnsExistingID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/articles", func(c *gin.Context) {
+		c.Set("user_id", int64(7))
+	}, rest.NewArticleHandler(conflictUsecase{}).Store)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/articles", strings.NewReader(`{"title":"t","content":"c"}`))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusConflict, w.Code)
+	var body rest.ConflictResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "conflict", body.Code)
+	assert.Equal(t, int64(42), body.ExistingID)
+	assert.NotEmpty(t, body.Message)
+}
+
+// storeUsecase 记录 Store 收到的文章
+type storeUsecase struct {
+	domain.ArticleUsecase
+	stored *domain.Article
+}
+
+func (u *storeUsecase) Store(_ context.Context, ar *domain.Article) error {
+	u.stored = ar
+	return nil
+}
+
+func TestStoreIgnoresClientCounters(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := &storeUsecase{}
+	r := gin.New()
+	r.POST("/articles", func(c *gin.Context) {
+		c.Set("user_id", int64(7))
+	}, rest.NewArticleHandler(svc).Store)
+
+	w := httptest.NewRecorder()
+	body := `{"id":5,"title":"t","content":"c","views":999999,"likes":42,"edit_count":3,"created_at":"2000-01-01 00:00:00"}`
+	req := httptest.NewRequest(http.MethodPost, "/articles", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusCreated, w.Code)
+	require.NotNil(t, svc.stored)
+	assert.Equal(t, domain.Article{Title: "t", Content: "c", User: domain.User{ID: 7}}, *svc.stored)
+}
+
+// createDB 模拟一张空的文章表，Store 分配自增 ID
+type createDB struct {
+	domain.ArticleDBRepository
+}
+
+func (createDB) GetByTitle(context.Context, string) (domain.Article, error) {
+	return domain.Article{}, domain.ErrNotFound
+}
+
+func (createDB) FindByFingerprint(context.Context, string) ([]domain.Article, error) {
+	return nil, nil
+}
+
+func (createDB) Store(_ context.Context, ar *domain.Article) error {
+	ar.ID = 10
+	return nil
+}
+
+type authorRepo struct {
+	domain.UserRepository
+}
+
+func (authorRepo) GetByID(_ context.Context, id int64) (domain.User, error) {
+	return domain.User{ID: id, Name: "Alice", Username: "alice", Password: "hash"}, nil
+}
+
+func TestStoreReturnsAuthorName(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	cache := myRedis.NewArticleCache(client, "", 0, 0)
+	articleRepo := repository.NewArticleRepository(createDB{}, cache, authorRepo{}, repository.NewRuntimeSettings(nil), true, nil)
+	svc := newArticleService(articleRepo, cache, myRedis.NewTitleIndex(client, ""))
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/articles", func(c *gin.Context) {
+		c.Set("user_id", int64(7))
+	}, rest.NewArticleHandler(svc).Store)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/articles", strings.NewReader(`{"title":"t","content":"c"}`))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusCreated, w.Code)
+	var body struct {
+		ID       int64  `json:"id"`
+		UserName string `json:"user_name"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, int64(10), body.ID)
+	assert.Equal(t, "Alice", body.UserName)
+}
+
+// duplicateDB 中已有一篇作者为 owner、正文相同的文章
+type duplicateDB struct {
+	createDB
+	owner int64
+}
+
+func (d duplicateDB) FindByFingerprint(context.Context, string) ([]domain.Article, error) {
+	return []domain.Article{{ID: 3, User: domain.User{ID: d.owner}}}, nil
+}
+
+func TestStoreDuplicateContent(t *testing.T) {
+	cases := []struct {
+		name   string
+		owner  int64
+		status int
+		code   string
+	}{
+		{"other author", 8, http.StatusConflict, "duplicate_content"},
+		{"same author", 7, http.StatusCreated, "duplicate_content"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			mr := miniredis.RunT(t)
+			client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+			t.Cleanup(func() { _ = client.Close() })
+
+			cache := myRedis.NewArticleCache(client, "", 0, 0)
+			articleRepo := repository.NewArticleRepository(duplicateDB{owner: tc.owner}, cache, authorRepo{}, repository.NewRuntimeSettings(nil), true, nil)
+			svc := newArticleService(articleRepo, cache, myRedis.NewTitleIndex(client, ""))
+
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.POST("/articles", func(c *gin.Context) {
+				c.Set("user_id", int64(7))
+			}, rest.NewArticleHandler(svc).Store)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/articles", strings.NewReader(`{"title":"t","content":"c"}`))
+			req.Header.Set("Content-Type", "application/json")
+			r.ServeHTTP(w, req)
+
+			require.Equal(t, tc.status, w.Code, w.Body.String())
+			var body struct {
+				Code       string `json:"code"`
+				ExistingID int64  `json:"existing_id"`
+				Warning    *struct {
+					Code       string `json:"code"`
+					ExistingID int64  `json:"existing_id"`
+				} `json:"warning"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			if tc.status == http.StatusConflict {
+				assert.Equal(t, tc.code, body.Code)
+				assert.Equal(t, int64(3), body.ExistingID)
+				return
+			}
+			require.NotNil(t, body.Warning)
+			assert.Equal(t, tc.code, body.Warning.Code)
+			assert.Equal(t, int64(3), body.Warning.ExistingID)
+		})
+	}
+}
+
+func TestStoreArticleLanguage(t *testing.T) {
+	cases := []struct {
+		name     string
+		body     string
+		code     int
+		language string
+	}{
+		{"canonicalized", `{"title":"t","content":"c","language":"zh-cn"}`, http.StatusCreated, "zh-CN"},
+		{"site default", `{"title":"t","content":"c"}`, http.StatusCreated, "en"},
+		{"invalid", `{"title":"t","content":"c","language":"not a tag"}`, http.StatusBadRequest, ""},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			mr := miniredis.RunT(t)
+			client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+			t.Cleanup(func() { _ = client.Close() })
+
+			cache := myRedis.NewArticleCache(client, "", 0, 0)
+			articleRepo := repository.NewArticleRepository(createDB{}, cache, authorRepo{}, repository.NewRuntimeSettings(nil), true, nil)
+			svc := newArticleService(articleRepo, cache, myRedis.NewTitleIndex(client, ""))
+			handler := rest.NewArticleHandler(svc)
+			handler.DefaultLanguage = "en"
+
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.POST("/articles", func(c *gin.Context) {
+				c.Set("user_id", int64(7))
+			}, handler.Store)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/articles", strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			r.ServeHTTP(w, req)
+
+			require.Equal(t, tc.code, w.Code)
+			if tc.code != http.StatusCreated {
+				return
+			}
+			var body struct {
+				Language string `json:"language"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			assert.Equal(t, tc.language, body.Language)
+		})
+	}
+}
+
+func TestFetchArticleByLanguage(t *testing.T) {
+	db := newDryRunDB(t)
+	var sqls []string
+	var vars []any
+	require.NoError(t, db.Callback().Query().After("gorm:query").Register("test:capture", func(tx *gorm.DB) {
+		if _, ok := tx.Statement.Dest.(*[]model.Article); ok {
+			sqls = append(sqls, tx.Statement.SQL.String())
+			vars = tx.Statement.Vars
+		}
+	}))
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	cache := myRedis.NewArticleCache(client, "", 0, 0)
+	articleRepo := repository.NewArticleRepository(
+		mysqlRepo.NewArticleDBRepository(db, false),
+		cache,
+		mysqlRepo.NewUserRepository(db),
+		repository.NewRuntimeSettings(nil),
+		true,
+		nil,
+	)
+	svc := newArticleService(articleRepo, cache, nil)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/articles", rest.NewArticleHandler(svc).FetchArticle)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/articles?lang=zh-cn", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Len(t, sqls, 1)
+	assert.Contains(t, sqls[0], "language = ?")
+	assert.Contains(t, vars, "zh-CN")
+	// 按语言过滤的列表不读写首页缓存
+	assert.Empty(t, w.Header().Get(rest.HeaderFeedSource))
+	assert.False(t, mr.Exists("article:home"))
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/articles?lang=en_US!", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Len(t, sqls, 1)
+}
+
+// ownedArticleRepo 只有文章 1，作者为 7，记录收到的更新和删除。titleTaken 为 true 时文章 2 占用了文章 1 的标题
+type ownedArticleRepo struct {
+	domain.ArticleRepository
+	updated    []domain.Article
+	deleted    []int64
+	restored   []int64
+	titleTaken bool
+}
+
+func (r *ownedArticleRepo) GetByTitle(_ context.Context, title string) (domain.Article, error) {
+	if !r.titleTaken || title != "old" {
+		return domain.Article{}, domain.ErrNotFound
+	}
+	return domain.Article{ID: 2, Title: "old", User: domain.User{ID: 8}}, nil
+}
+
+func (r *ownedArticleRepo) GetByIDs(_ context.Context, ids []int64) ([]domain.Article, error) {
+	var res []domain.Article
+	for _, id := range ids {
+		if id == 1 {
+			res = append(res, domain.Article{ID: 1, Title: "old", Content: "old content", User: domain.User{ID: 7, Name: "Alice"}, Views: 12, Likes: 3})
+		}
+	}
+	return res, nil
+}
+
+func (r *ownedArticleRepo) GetDraft(context.Context, int64, int64) (domain.Article, error) {
+	return domain.Article{}, domain.ErrNotFound
+}
+
+func (r *ownedArticleRepo) Update(_ context.Context, ar *domain.Article) error {
+	r.updated = append(r.updated, *ar)
+	return nil
+}
+
+func (r *ownedArticleRepo) Delete(_ context.Context, id int64) error {
+	r.deleted = append(r.deleted, id)
+	return nil
+}
+
+// GetDeleted 把文章 1 当作已删除的文章
+func (r *ownedArticleRepo) GetDeleted(_ context.Context, id int64) (domain.Article, error) {
+	if id != 1 {
+		return domain.Article{}, domain.ErrNotFound
+	}
+	return domain.Article{ID: 1, Title: "old", User: domain.User{ID: 7}, Status: domain.ArticleStatusPublished}, nil
+}
+
+func (r *ownedArticleRepo) Restore(_ context.Context, id int64) error {
+	r.restored = append(r.restored, id)
+	return nil
+}
+
+func TestUpdateArticleOnlyByAuthor(t *testing.T) {
+	cases := []struct {
+		name   string
+		path   string
+		userID int64
+		code   int
+	}{
+		{"author", "/articles/1", 7, http.StatusOK},
+		{"not author", "/articles/1", 8, http.StatusForbidden},
+		{"missing article", "/articles/2", 7, http.StatusNotFound},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			mr := miniredis.RunT(t)
+			client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+			t.Cleanup(func() { _ = client.Close() })
+
+			repo := &ownedArticleRepo{}
+			svc := newArticleService(repo, nil, myRedis.NewTitleIndex(client, ""))
+
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.PUT("/articles/:id", func(c *gin.Context) {
+				c.Set("user_id", tc.userID)
+			}, rest.NewArticleHandler(svc).Update)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPut, tc.path, strings.NewReader(`{"title":"new","content":"c"}`))
+			req.Header.Set("Content-Type", "application/json")
+			r.ServeHTTP(w, req)
+
+			require.Equal(t, tc.code, w.Code)
+			if tc.code != http.StatusOK {
+				assert.Empty(t, repo.updated)
+				return
+			}
+			require.Len(t, repo.updated, 1)
+			var body struct {
+				ID       int64  `json:"id"`
+				Title    string `json:"title"`
+				UserName string `json:"user_name"`
+				Views    int64  `json:"views"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			assert.Equal(t, int64(1), body.ID)
+			assert.Equal(t, "new", body.Title)
+			assert.Equal(t, "Alice", body.UserName)
+			assert.Equal(t, int64(12), body.Views)
+		})
+	}
+}
+
+func TestUpdateArticleTitleTaken(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	// 文章 2 使用标题 old
+	repo := &ownedArticleRepo{titleTaken: true}
+	svc := newArticleService(repo, nil, myRedis.NewTitleIndex(client, ""))
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.PUT("/articles/:id", func(c *gin.Context) {
+		c.Set("user_id", int64(7))
+	}, rest.NewArticleHandler(svc).Update)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPut, "/articles/1", strings.NewReader(`{"title":"old"}`))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusConflict, w.Code)
+	var body rest.ConflictResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, rest.ConflictResponse{Code: "conflict", Message: "an article with the same title already exists", ExistingID: 2}, body)
+	assert.Empty(t, repo.updated)
+}
+
+func TestUpdateArticlePartial(t *testing.T) {
+	cases := []struct {
+		name    string
+		body    string
+		code    int
+		title   string
+		content string
+	}{
+		{"title only", `{"title":"new"}`, http.StatusOK, "new", "old content"},
+		{"content only", `{"content":"new content"}`, http.StatusOK, "old", "new content"},
+		{"empty", `{}`, http.StatusBadRequest, "", ""},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			mr := miniredis.RunT(t)
+			client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+			t.Cleanup(func() { _ = client.Close() })
+
+			repo := &ownedArticleRepo{}
+			svc := newArticleService(repo, nil, myRedis.NewTitleIndex(client, ""))
+
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.PUT("/articles/:id", func(c *gin.Context) {
+				c.Set("user_id", int64(7))
+			}, rest.NewArticleHandler(svc).Update)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPut, "/articles/1", strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			r.ServeHTTP(w, req)
+
+			require.Equal(t, tc.code, w.Code)
+			if tc.code != http.StatusOK {
+				return
+			}
+			// 没有提交的字段以空值交给存储层，不会被写入，原值只用于补全返回结果
+			require.Len(t, repo.updated, 1)
+			assert.NotEqual(t, "old", repo.updated[0].Title)
+			assert.NotEqual(t, "old content", repo.updated[0].Content)
+			var body struct {
+				Title   string `json:"title"`
+				Content string `json:"content"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			assert.Equal(t, tc.title, body.Title)
+			assert.Equal(t, tc.content, body.Content)
+		})
+	}
+}
+
+func TestDeleteArticleOnlyByAuthor(t *testing.T) {
+	cases := []struct {
+		name   string
+		path   string
+		userID int64
+		code   int
+	}{
+		{"author", "/articles/1", 7, http.StatusNoContent},
+		{"not author", "/articles/1", 8, http.StatusForbidden},
+		{"missing article", "/articles/2", 7, http.StatusNotFound},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			mr := miniredis.RunT(t)
+			client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+			t.Cleanup(func() { _ = client.Close() })
+
+			repo := &ownedArticleRepo{}
+			svc := newArticleService(repo, nil, myRedis.NewTitleIndex(client, ""))
+
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.DELETE("/articles/:id", func(c *gin.Context) {
+				c.Set("user_id", tc.userID)
+			}, rest.NewArticleHandler(svc).Delete)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, tc.path, nil))
+
+			require.Equal(t, tc.code, w.Code)
+			if tc.code != http.StatusNoContent {
+				assert.Empty(t, repo.deleted)
+				return
+			}
+			assert.Equal(t, []int64{1}, repo.deleted)
+		})
+	}
+}
+
+func TestRestoreArticleOnlyByAuthor(t *testing.T) {
+	cases := []struct {
+		name   string
+		path   string
+		userID int64
+		taken  bool
+		code   int
+	}{
+		{"author", "/articles/1/restore", 7, false, http.StatusNoContent},
+		{"not author", "/articles/1/restore", 8, false, http.StatusForbidden},
+		{"not deleted", "/articles/2/restore", 7, false, http.StatusNotFound},
+		{"bad id", "/articles/abc/restore", 7, false, http.StatusNotFound},
+		// 删除期间标题被另一篇文章占用
+		{"title taken", "/articles/1/restore", 7, true, http.StatusConflict},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			mr := miniredis.RunT(t)
+			client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+			t.Cleanup(func() { _ = client.Close() })
+
+			repo := &ownedArticleRepo{titleTaken: tc.taken}
+			svc := newArticleService(repo, nil, myRedis.NewTitleIndex(client, ""))
+
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.POST("/articles/:id/restore", func(c *gin.Context) {
+				c.Set("user_id", tc.userID)
+			}, rest.NewArticleHandler(svc).Restore)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, tc.path, nil))
+
+			require.Equal(t, tc.code, w.Code)
+			if tc.code == http.StatusConflict {
+				var body rest.ConflictResponse
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+				assert.Equal(t, rest.ConflictResponse{Code: "conflict", Message: "an article with the same title already exists", ExistingID: 2}, body)
+			}
+			if tc.code != http.StatusNoContent {
+				assert.Empty(t, repo.restored)
+				return
+			}
+			assert.Equal(t, []int64{1}, repo.restored)
+		})
+	}
+}
+
+type tagsUsecase struct {
+	domain.ArticleUsecase
+}
+
+func (tagsUsecase) ListTags(context.Context) ([]domain.TagCount, error) {
+	return []domain.TagCount{{Name: "go", Articles: 3}, {Name: "redis", Articles: 1}}, nil
+}
+
+func (tagsUsecase) Fetch(context.Context, string, int64, string, string) ([]domain.Article, string, error) {
+	return []domain.Article{{ID: 1, Tags: []string{"go"}}, {ID: 2}}, "", nil
+}
+
+func TestListTags(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/tags", rest.NewArticleHandler(tagsUsecase{}).ListTags)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/tags", nil))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `[{"name":"go","articles":3},{"name":"redis","articles":1}]`, w.Body.String())
+}
+
+func TestArticleTagsAlwaysArray(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/articles", rest.NewArticleHandler(tagsUsecase{}).FetchArticle)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/articles?tag=go", nil))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var body []struct {
+		Tags []string `json:"tags"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	require.Len(t, body, 2)
+	assert.Equal(t, []string{"go"}, body[0].Tags)
+	// 没有标签时返回空数组而不是 null
+	assert.NotNil(t, body[1].Tags)
+	assert.Empty(t, body[1].Tags)
+}
+
+type detailUsecase struct {
+	domain.ArticleUsecase
+	viewerID int64
+}
+
+func (u *detailUsecase) GetDetail(_ context.Context, id int64, viewerID int64) (domain.ArticleDetail, error) {
+	u.viewerID = viewerID
+	liked := true
+	return domain.ArticleDetail{
+		Article:    domain.Article{ID: id, Title: "t", Tags: []string{"go"}, Viewer: &domain.ViewerState{Liked: &liked}},
+		Engagement: domain.Engagement{Likes: 5, Comments: 3},
+	}, nil
+}
+
+// viewerUsecase 用户 7 点赞过文章 1，用户 9 没有点赞，用户 8 的点赞状态读取失败
+type viewerUsecase struct {
+	domain.ArticleUsecase
+}
+
+func (viewerUsecase) GetByIDForViewer(_ context.Context, id int64, viewerID int64) (domain.Article, error) {
+	ar := domain.Article{ID: id, Title: "t"}
+	switch viewerID {
+	case 7, 9:
+		liked := viewerID == 7
+		ar.Viewer = &domain.ViewerState{Liked: &liked}
+	}
+	return ar, nil
+}
+
+func TestGetByIDIsLiked(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/articles/:id", func(c *gin.Context) {
+		if uid := c.GetHeader("X-Test-User"); uid != "" {
+			id, _ := strconv.ParseInt(uid, 10, 64)
+			c.Set("user_id", id)
+		}
+	}, rest.NewArticleHandler(viewerUsecase{}).GetByID)
+
+	for user, want := range map[string]bool{"": false, "7": true, "9": false, "8": false} {
+		req := httptest.NewRequest(http.MethodGet, "/articles/1", nil)
+		req.Header.Set("X-Test-User", user)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code, user)
+
+		// 匿名请求和点赞状态读取失败时都返回 false，不再有 has_liked
+		var body map[string]any
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		assert.Equal(t, want, body["is_liked"], "user %q", user)
+		assert.NotContains(t, body, "has_liked", "user %q", user)
+	}
+}
+
+// deletedUsecase 文章 1 已被删除，文章 2 正常可见
+type deletedUsecase struct {
+	domain.ArticleUsecase
+}
+
+func (deletedUsecase) GetDeleted(_ context.Context, id int64) (domain.Article, error) {
+	if id != 1 {
+		return domain.Article{}, domain.ErrNotFound
+	}
+	return domain.Article{ID: 1, Title: "removed", DeletedAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)}, nil
+}
+
+func (deletedUsecase) GetByIDForViewer(_ context.Context, id int64, _ int64) (domain.Article, error) {
+	if id != 2 {
+		return domain.Article{}, domain.ErrNotFound
+	}
+	return domain.Article{ID: 2, Title: "live"}, nil
+}
+
+func TestGetByIDIncludeDeletedOnlyForAdmins(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/articles/:id", func(c *gin.Context) {
+		if role := c.GetHeader("X-Test-Role"); role != "" {
+			c.Set("user_id", int64(7))
+			c.Set("role", role)
+		}
+	}, rest.NewArticleHandler(deletedUsecase{}).GetByID)
+
+	cases := []struct {
+		name      string
+		role      string
+		path      string
+		code      int
+		deletedAt string
+	}{
+		{"admin", domain.RoleAdmin, "/articles/1?include_deleted=true", http.StatusOK, "2026-01-02 03:04:05"},
+		{"admin without include_deleted", domain.RoleAdmin, "/articles/1", http.StatusNotFound, ""},
+		// 普通用户和匿名请求带上参数也读不到已删除的文章
+		{"user", domain.RoleUser, "/articles/1?include_deleted=true", http.StatusNotFound, ""},
+		{"anonymous", "", "/articles/1?include_deleted=true", http.StatusNotFound, ""},
+		// 没有被删除的文章照常返回
+		{"admin on live article", domain.RoleAdmin, "/articles/2?include_deleted=true", http.StatusOK, ""},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			req.Header.Set("X-Test-Role", tc.role)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			require.Equal(t, tc.code, w.Code)
+			if tc.code != http.StatusOK {
+				return
+			}
+			var body response.Article
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			assert.Equal(t, tc.deletedAt, body.DeletedAt)
+		})
+	}
+}
+
+func TestGetDetailResponse(t *testing.T) {
+	uc := &detailUsecase{}
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/articles/:id/detail", func(c *gin.Context) {
+		c.Set("user_id", int64(7))
+	}, rest.NewArticleHandler(uc).GetDetail)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/articles/1/detail", nil))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, int64(7), uc.viewerID)
+	var body struct {
+		ID         int64             `json:"id"`
+		Tags       []string          `json:"tags"`
+		IsLiked    bool              `json:"is_liked"`
+		Engagement domain.Engagement `json:"engagement"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	// 文章字段和 engagement 在同一层
+	assert.Equal(t, int64(1), body.ID)
+	assert.Equal(t, []string{"go"}, body.Tags)
+	assert.True(t, body.IsLiked)
+	assert.Equal(t, domain.Engagement{Likes: 5, Comments: 3}, body.Engagement)
+}
+
+// draftRepo 只有用户 7 的草稿 3，发布后才能按 ID 读到
+type draftRepo struct {
+	domain.ArticleRepository
+	published bool
+}
+
+func (r *draftRepo) GetByID(_ context.Context, id int64) (domain.Article, error) {
+	if id != 3 || !r.published {
+		return domain.Article{}, domain.ErrNotFound
+	}
+	return domain.Article{ID: 3, Title: "draft", User: domain.User{ID: 7}, Status: domain.ArticleStatusPublished}, nil
+}
+
+func (r *draftRepo) GetByIDs(ctx context.Context, ids []int64) ([]domain.Article, error) {
+	var res []domain.Article
+	for _, id := range ids {
+		if ar, err := r.GetByID(ctx, id); err == nil {
+			res = append(res, ar)
+		}
+	}
+	return res, nil
+}
+
+func (r *draftRepo) GetDraft(_ context.Context, id, userID int64) (domain.Article, error) {
+	if id != 3 || userID != 7 || r.published {
+		return domain.Article{}, domain.ErrNotFound
+	}
+	return domain.Article{ID: 3, Title: "draft", User: domain.User{ID: 7}, Status: domain.ArticleStatusDraft}, nil
+}
+
+func (r *draftRepo) Publish(context.Context, int64) error {
+	r.published = true
+	return nil
+}
+
+func TestDraftHiddenFromOthers(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	repo := &draftRepo{}
+	svc := newArticleService(repo, nil, myRedis.NewTitleIndex(client, ""))
+	handler := rest.NewArticleHandler(svc)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/articles/:id", handler.GetByID)
+	r.POST("/articles/:id/publish", func(c *gin.Context) {
+		uid, _ := strconv.ParseInt(c.GetHeader("X-User"), 10, 64)
+		c.Set("user_id", uid)
+	}, handler.Publish)
+
+	// 匿名读取和其他人发布都是 404
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/articles/3", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/articles/3/publish", nil)
+	req.Header.Set("X-User", "8")
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.False(t, repo.published)
+
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPost, "/articles/3/publish", nil)
+	req.Header.Set("X-User", "7")
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	var body struct {
+		ID     int64  `json:"id"`
+		Status string `json:"status"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, int64(3), body.ID)
+	assert.Equal(t, "published", body.Status)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/articles/3", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+// likeUsecase 返回固定的点赞状态
+type likeUsecase struct {
+	domain.ArticleUsecase
+}
+
+func (likeUsecase) AddLikeRecord(context.Context, domain.UserLike) (domain.LikeState, error) {
+	return domain.LikeState{Changed: true, Liked: true, Likes: 11}, nil
+}
+
+func (likeUsecase) RemoveLikeRecord(context.Context, domain.UserLike) (domain.LikeState, error) {
+	return domain.LikeState{Changed: false, Liked: false, Likes: 10}, nil
+}
+
+func TestLikeReturnsStateAndCount(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	auth := func(c *gin.Context) { c.Set("user_id", int64(7)) }
+	handler := rest.NewArticleHandler(likeUsecase{})
+	r.POST("/articles/:id/like", auth, handler.Like)
+	r.DELETE("/articles/:id/like", auth, handler.Unlike)
+
+	// 保留旧版的 is_changed，前端不需要重新读取文章就能显示新的点赞数
+	for method, want := range map[string]string{
+		http.MethodPost:   `{"is_changed": true, "liked": true, "likes": 11}`,
+		http.MethodDelete: `{"is_changed": false, "liked": false, "likes": 10}`,
+	} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(method, "/articles/1/like", nil))
+		require.Equal(t, http.StatusOK, w.Code, method)
+		assert.JSONEq(t, want, w.Body.String(), method)
+	}
+}
@@ -1,11 +1,13 @@
 package rest
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
 	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
 	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/rest/request"
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/rest/response"
 	"github.com/gin-gonic/gin"
 )
 
@@ -47,22 +49,64 @@ func (h *commentHandler) CreateComment(c *gin.Context) {
 	comment := req.ToDomain()
 	comment.UserID = userID.(int64)
 
-	ctx := c.Request.Context()
-	if err := h.Service.Create(ctx, &comment); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	h.create(c, &comment)
+}
+
+// ReplyComment POST /comments/:id/replies，回复指定评论，文章和 root_id 都由父评论决定
+func (h *commentHandler) ReplyComment(c *gin.Context) {
+	var req request.CommentReply
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	idP, err := strconv.Atoi(c.Param("id"))
+	if err != nil || idP <= 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": domain.ErrNotFound.Error()})
+		return
+	}
+
+	comment := domain.Comment{
+		UserID:   userID.(int64),
+		Content:  req.Content,
+		ParentID: int64(idP),
+	}
+	h.create(c, &comment)
+}
+
+func (h *commentHandler) create(c *gin.Context, comment *domain.Comment) {
+	if err := h.Service.Create(c.Request.Context(), comment); err != nil {
+		switch {
+		case errors.Is(err, domain.ErrCommentsLocked):
+			c.JSON(http.StatusForbidden, gin.H{"code": "comments_locked", "message": "comments are locked on this article"})
+		case errors.Is(err, domain.ErrNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrBadParamInput):
+			// 正文去掉不可见字符后为空
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
 		return
 	}
 
 	c.JSON(http.StatusCreated, gin.H{"message": "Comment created successfully", "comment": comment})
 }
 
+// DeleteComment DELETE /comments/:id，只能删除自己的评论
 func (h *commentHandler) DeleteComment(c *gin.Context) {
 	idP, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusNotFound, domain.ErrNotFound.Error())
 		return
 	}
-	aid := int64(idP)
+	id := int64(idP)
 
 	userID, exists := c.Get("user_id")
 	if !exists {
@@ -72,22 +116,24 @@ func (h *commentHandler) DeleteComment(c *gin.Context) {
 	uid := userID.(int64)
 
 	ctx := c.Request.Context()
-	if err := h.Service.Delete(ctx, aid, uid); err != nil {
-		if err == domain.ErrForbidden {
+	if err := h.Service.Delete(ctx, id, uid); err != nil {
+		switch {
+		case errors.Is(err, domain.ErrForbidden):
 			c.JSON(http.StatusForbidden, gin.H{"error": "You do not have permission to delete this comment"})
-			return
+		case errors.Is(err, domain.ErrNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "Comment deleted successfully"})
 }
 
 func (h *commentHandler) FetchCommentsByArticle(c *gin.Context) {
-	numS := c.Query("num")
-	num, err := strconv.Atoi(numS)
-	if err != nil || num < PageMinNum || num > PageMaxNum {
-		num = DefaultPageNum
+	num, ok := queryInt(c, pageNumParam)
+	if !ok {
+		return
 	}
 	idP, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
@@ -105,6 +151,10 @@ func (h *commentHandler) FetchCommentsByArticle(c *gin.Context) {
 		return
 	}
 
-	c.Header("X-cursor", nextCursor)
-	c.JSON(http.StatusOK, gin.H{"comments": comments})
+	res := make([]*response.Comment, len(comments))
+	for i := range comments {
+		res[i] = response.NewCommentFromDomain(comments[i])
+	}
+	setPaginationHeaders(c, nextCursor, num)
+	c.JSON(http.StatusOK, gin.H{"comments": res})
 }
@@ -0,0 +1,171 @@
+package rest_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/repository"
+	mysqlRepo "github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/repository/mysql"
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/rest"
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/rest/response"
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/usecase/comment"
+)
+
+func TestFetchCommentsByDeletedAuthor(t *testing.T) {
+	db := newDryRunDB(t)
+	svc := comment.NewService(mysqlRepo.NewCommentRepository(db), repository.NewNoopBloomRepository(), deletedUserRepo{}, nil)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/articles/:id/comments", rest.NewCommentHandler(svc).FetchCommentsByArticle)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/articles/1/comments", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var body struct {
+		Comments []response.Comment `json:"comments"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	require.NotEmpty(t, body.Comments)
+	require.NotNil(t, body.Comments[0].User)
+	assert.Equal(t, domain.DeletedUserName, body.Comments[0].User.Name)
+	assert.True(t, body.Comments[0].User.Deleted)
+	assert.Zero(t, body.Comments[0].User.ID)
+}
+
+type lockedCommentUsecase struct {
+	domain.CommentUsecase
+}
+
+func (lockedCommentUsecase) Create(context.Context, *domain.Comment) error {
+	return domain.ErrCommentsLocked
+}
+
+func TestCreateCommentOnLockedArticle(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/articles/:id/comments", func(c *gin.Context) {
+		c.Set("user_id", int64(7))
+	}, rest.NewCommentHandler(lockedCommentUsecase{}).CreateComment)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/articles/1/comments", strings.NewReader(`{"content":"hi"}`)))
+
+	// 客户端根据 code 区分评论已关闭和其他权限错误
+	require.Equal(t, http.StatusForbidden, w.Code)
+	var body struct {
+		Code string `json:"code"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "comments_locked", body.Code)
+}
+
+// ownedCommentUsecase 只有评论 1，作者为 7
+type ownedCommentUsecase struct {
+	domain.CommentUsecase
+	deleted []int64
+}
+
+func (u *ownedCommentUsecase) Delete(_ context.Context, id int64, uid int64) error {
+	switch {
+	case id != 1:
+		return domain.ErrNotFound
+	case uid != 7:
+		return domain.ErrForbidden
+	}
+	u.deleted = append(u.deleted, id)
+	return nil
+}
+
+func TestDeleteComment(t *testing.T) {
+	cases := []struct {
+		name   string
+		path   string
+		userID int64
+		code   int
+	}{
+		{"author", "/comments/1", 7, http.StatusOK},
+		{"not author", "/comments/1", 8, http.StatusForbidden},
+		{"missing comment", "/comments/2", 7, http.StatusNotFound},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := &ownedCommentUsecase{}
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.DELETE("/comments/:id", func(c *gin.Context) {
+				c.Set("user_id", tc.userID)
+			}, rest.NewCommentHandler(svc).DeleteComment)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, tc.path, nil))
+
+			require.Equal(t, tc.code, w.Code)
+			if tc.code == http.StatusOK {
+				assert.Equal(t, []int64{1}, svc.deleted)
+			} else {
+				assert.Empty(t, svc.deleted)
+			}
+		})
+	}
+}
+
+// replyCommentUsecase 记录收到的评论，评论 1 以外的父评论都不存在
+type replyCommentUsecase struct {
+	domain.CommentUsecase
+	created []domain.Comment
+}
+
+func (u *replyCommentUsecase) Create(_ context.Context, c *domain.Comment) error {
+	if c.ParentID != 1 {
+		return domain.ErrNotFound
+	}
+	u.created = append(u.created, *c)
+	return nil
+}
+
+func TestReplyComment(t *testing.T) {
+	svc := &replyCommentUsecase{}
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/comments/:id/replies", func(c *gin.Context) {
+		c.Set("user_id", int64(7))
+	}, rest.NewCommentHandler(svc).ReplyComment)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/comments/1/replies", strings.NewReader(`{"content":"hi","root_id":99}`)))
+	require.Equal(t, http.StatusCreated, w.Code)
+	require.Len(t, svc.created, 1)
+	// 文章和 root_id 交给服务端根据父评论决定
+	assert.Equal(t, domain.Comment{UserID: 7, Content: "hi", ParentID: 1}, svc.created[0])
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/comments/2/replies", strings.NewReader(`{"content":"hi"}`)))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestCreateCommentWithOnlyInvisibleCharacters(t *testing.T) {
+	// 正文在写入前就被拒绝，不会用到任何仓储
+	svc := comment.NewService(nil, nil, nil, nil)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/articles/:id/comments", func(c *gin.Context) {
+		c.Set("user_id", int64(7))
+	}, rest.NewCommentHandler(svc).CreateComment)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/articles/1/comments", strings.NewReader(`{"content":"\u200b\u200b\ufeff"}`)))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -0,0 +1,87 @@
+package rest
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+)
+
+// CacheBreaker reports the state of the circuit breaker in front of the cache
+type CacheBreaker interface {
+	State() domain.BreakerState
+}
+
+// HealthHandler serves the health check endpoint
+type HealthHandler struct {
+	Cache CacheBreaker
+	// Workers and WorkerNames are optional, when set the last run of every named worker is reported
+	Workers     domain.WorkerStatusRepository
+	WorkerNames []string
+	// Now defaults to time.Now, tests replace it to move the clock
+	Now func() time.Time
+}
+
+// WorkerHealth is the last run of a worker, Stale is true when it hasn't flushed within
+// domain.WorkerStaleFactor intervals
+type WorkerHealth struct {
+	domain.WorkerStatus
+	Stale bool `json:"stale"`
+}
+
+func NewHealthHandler(cache CacheBreaker) *HealthHandler {
+	return &HealthHandler{Cache: cache, Now: time.Now}
+}
+
+// Healthz reports "degraded" while the cache breaker is open or a worker is stale.
+// The service still answers reads from the database then, so the status code stays 200
+func (h *HealthHandler) Healthz(c *gin.Context) {
+	cache := h.Cache.State()
+	status := "ok"
+	if cache.State == domain.BreakerOpen {
+		status = "degraded"
+	}
+	res := gin.H{"cache": cache}
+
+	if h.Workers != nil {
+		workers := h.workerHealth(c)
+		for _, w := range workers {
+			if w.Stale {
+				status = "degraded"
+			}
+		}
+		res["workers"] = workers
+	}
+
+	res["status"] = status
+	c.JSON(http.StatusOK, res)
+}
+
+// workerHealth 读取每个 worker 的运行记录，还没有记录的 worker（例如刚启动）和读取失败的不在结果中
+func (h *HealthHandler) workerHealth(c *gin.Context) []WorkerHealth {
+	now := time.Now()
+	if h.Now != nil {
+		now = h.Now()
+	}
+
+	res := make([]WorkerHealth, 0, len(h.WorkerNames))
+	for _, name := range h.WorkerNames {
+		s, err := h.Workers.GetWorkerStatus(c.Request.Context(), name)
+		if err != nil {
+			if !errors.Is(err, domain.ErrCacheMiss) {
+				logrus.Warnf("failed to get status of worker %s: %v", name, err)
+			}
+			continue
+		}
+		w := WorkerHealth{WorkerStatus: s, Stale: s.Stale(now)}
+		if w.Stale {
+			logrus.Warnf("worker %s hasn't flushed since %s", name, s.LastRun.Format(time.RFC3339))
+		}
+		res = append(res, w)
+	}
+	return res
+}
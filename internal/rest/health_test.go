@@ -0,0 +1,106 @@
+package rest_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/rest"
+)
+
+type fakeBreaker struct {
+	state domain.BreakerState
+}
+
+func (f fakeBreaker) State() domain.BreakerState { return f.state }
+
+func TestHealthzReportsCacheBreaker(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	openedAt := time.Now()
+	cases := []struct {
+		state  domain.BreakerState
+		status string
+	}{
+		{domain.BreakerState{State: domain.BreakerClosed}, "ok"},
+		{domain.BreakerState{State: domain.BreakerOpen, OpenedAt: &openedAt, Trips: 1, Rejected: 3}, "degraded"},
+	}
+	for _, c := range cases {
+		r := gin.New()
+		r.GET("/healthz", rest.NewHealthHandler(fakeBreaker{c.state}).Healthz)
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+		// 降级时仍然返回 200，数据库可以继续提供读服务
+		require.Equal(t, http.StatusOK, rec.Code)
+		var body struct {
+			Status string              `json:"status"`
+			Cache  domain.BreakerState `json:"cache"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		assert.Equal(t, c.status, body.Status)
+		assert.Equal(t, c.state.State, body.Cache.State)
+		assert.Equal(t, c.state.Rejected, body.Cache.Rejected)
+	}
+}
+
+// workerStatuses 按名字返回预置的运行记录
+type workerStatuses map[string]domain.WorkerStatus
+
+func (w workerStatuses) SetWorkerStatus(context.Context, domain.WorkerStatus) error { return nil }
+
+func (w workerStatuses) GetWorkerStatus(_ context.Context, name string) (domain.WorkerStatus, error) {
+	s, ok := w[name]
+	if !ok {
+		return domain.WorkerStatus{}, domain.ErrCacheMiss
+	}
+	return s, nil
+}
+
+func TestHealthzReportsStaleWorkers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	lastRun := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	h := rest.NewHealthHandler(fakeBreaker{domain.BreakerState{State: domain.BreakerClosed}})
+	h.Workers = workerStatuses{
+		domain.WorkerSyncViews: {Name: domain.WorkerSyncViews, LastRun: lastRun, Items: 12, IntervalMs: time.Minute.Milliseconds()},
+	}
+	// 还没有运行记录的 worker 不影响健康状态
+	h.WorkerNames = []string{domain.WorkerSyncViews, domain.WorkerSyncLikes}
+
+	cases := []struct {
+		now    time.Time
+		status string
+		stale  bool
+	}{
+		{lastRun.Add(2 * time.Minute), "ok", false},
+		{lastRun.Add(3 * time.Minute), "ok", false},
+		// 超过 3 个周期没有写入
+		{lastRun.Add(3*time.Minute + time.Second), "degraded", true},
+	}
+	for _, c := range cases {
+		h.Now = func() time.Time { return c.now }
+		r := gin.New()
+		r.GET("/healthz", h.Healthz)
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		var body struct {
+			Status  string              `json:"status"`
+			Workers []rest.WorkerHealth `json:"workers"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		assert.Equal(t, c.status, body.Status, c.now)
+		require.Len(t, body.Workers, 1)
+		assert.Equal(t, domain.WorkerSyncViews, body.Workers[0].Name)
+		assert.Equal(t, int64(12), body.Workers[0].Items)
+		assert.Equal(t, c.stale, body.Workers[0].Stale)
+	}
+}
@@ -0,0 +1,93 @@
+package rest
+
+import (
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/rest/response"
+)
+
+// MetaMaxAge 是文章元数据和 oEmbed 响应的缓存时间（秒），标题和摘要很少修改，
+// 聊天软件和中间代理可以放心缓存
+const MetaMaxAge = 3600
+
+// articleURLPath 匹配 oEmbed 请求中的文章地址，兼容 /api/v1 前缀和 /detail、/meta 后缀
+var articleURLPath = regexp.MustCompile(`^(?:/api/v1)?/articles/(\d+)(?:/detail|/meta)?/?$`)
+
+// GetMeta 返回链接预览用的文章元数据，不计浏览量，匿名可用
+func (a *ArticleHandler) GetMeta(c *gin.Context) {
+	idP, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, ResponseError{Message: domain.ErrNotFound.Error()})
+		return
+	}
+
+	meta, err := a.Service.GetMeta(c.Request.Context(), int64(idP))
+	if err != nil {
+		c.JSON(getStatusCode(err), ResponseError{Message: err.Error()})
+		return
+	}
+
+	setMetaCacheControl(c)
+	c.JSON(http.StatusOK, response.NewArticleMetaFromDomain(meta))
+}
+
+// OEmbed 按 oEmbed 规范返回文章链接的嵌入信息，只支持 json 格式。
+// url 不是文章地址时返回 404，format 不是 json 时按规范返回 501
+func (a *ArticleHandler) OEmbed(c *gin.Context) {
+	if format := c.Query("format"); format != "" && format != "json" {
+		c.JSON(http.StatusNotImplemented, ResponseError{Message: "unsupported format"})
+		return
+	}
+	rawURL := c.Query("url")
+	if rawURL == "" {
+		c.JSON(http.StatusBadRequest, ResponseError{Message: "url is required"})
+		return
+	}
+	id, ok := articleIDFromURL(rawURL)
+	if !ok {
+		c.JSON(http.StatusNotFound, ResponseError{Message: domain.ErrNotFound.Error()})
+		return
+	}
+
+	meta, err := a.Service.GetMeta(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(getStatusCode(err), ResponseError{Message: err.Error()})
+		return
+	}
+
+	setMetaCacheControl(c)
+	c.JSON(http.StatusOK, response.NewOEmbedFromDomain(meta, a.ProviderName, MetaMaxAge))
+}
+
+// articleIDFromURL 从文章地址中取出文章 ID，不检查域名，反向代理后面的域名由部署决定
+func articleIDFromURL(rawURL string) (int64, bool) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return 0, false
+	}
+	m := articleURLPath.FindStringSubmatch(u.Path)
+	if m == nil {
+		return 0, false
+	}
+	id, err := strconv.ParseInt(m[1], 10, 64)
+	if err != nil || id <= 0 {
+		return 0, false
+	}
+	return id, true
+}
+
+// isBotRequest 判断请求是否来自爬虫，依赖 ClientInfo 中间件解析的设备类型
+func isBotRequest(c *gin.Context) bool {
+	info, ok := domain.ClientInfoFrom(c.Request.Context())
+	return ok && info.Device == domain.DeviceBot
+}
+
+func setMetaCacheControl(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age="+strconv.Itoa(MetaMaxAge))
+}
@@ -0,0 +1,129 @@
+package rest_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/rest"
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/rest/middleware"
+)
+
+// metaUsecase 只有文章 1，GetByIDForViewer 在真实实现中会计一次浏览
+type metaUsecase struct {
+	domain.ArticleUsecase
+	views int
+}
+
+func (u *metaUsecase) GetMeta(_ context.Context, id int64) (domain.ArticleMeta, error) {
+	if id != 1 {
+		return domain.ArticleMeta{}, domain.ErrNotFound
+	}
+	return domain.ArticleMeta{
+		ID:          1,
+		Title:       "Hello",
+		Summary:     "first post",
+		AuthorName:  "alice",
+		PublishedAt: time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC),
+	}, nil
+}
+
+func (u *metaUsecase) GetByIDForViewer(_ context.Context, id int64, _ int64) (domain.Article, error) {
+	u.views++
+	return domain.Article{ID: id, Title: "Hello", Content: "body"}, nil
+}
+
+func newMetaRouter(uc *metaUsecase) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	h := rest.NewArticleHandler(uc)
+	h.ProviderName = "My Blog"
+	h.UnfurlBots = true
+	r := gin.New()
+	r.GET("/articles/:id", middleware.ClientInfo(), h.GetByID)
+	r.GET("/articles/:id/meta", h.GetMeta)
+	r.GET("/oembed", h.OEmbed)
+	return r
+}
+
+func TestGetMeta(t *testing.T) {
+	uc := &metaUsecase{}
+	r := newMetaRouter(uc)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/articles/1/meta", nil))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "public, max-age=3600", w.Header().Get("Cache-Control"))
+	assert.JSONEq(t, `{"id":1,"title":"Hello","summary":"first post","author_name":"alice","published_at":"2024-05-01 08:00:00"}`, w.Body.String())
+	assert.Zero(t, uc.views)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/articles/2/meta", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Empty(t, w.Header().Get("Cache-Control"))
+}
+
+func TestOEmbed(t *testing.T) {
+	uc := &metaUsecase{}
+	r := newMetaRouter(uc)
+
+	w := httptest.NewRecorder()
+	target := "/oembed?url=" + url.QueryEscape("https://blog.example.com/api/v1/articles/1?utm_source=chat")
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "public, max-age=3600", w.Header().Get("Cache-Control"))
+	assert.JSONEq(t, `{"version":"1.0","type":"link","title":"Hello","author_name":"alice","provider_name":"My Blog","cache_age":3600}`, w.Body.String())
+	assert.Zero(t, uc.views)
+}
+
+func TestOEmbedRejectsBadRequests(t *testing.T) {
+	r := newMetaRouter(&metaUsecase{})
+
+	cases := []struct {
+		query string
+		code  int
+	}{
+		{"", http.StatusBadRequest},
+		{"url=" + url.QueryEscape("https://blog.example.com/articles/1") + "&format=xml", http.StatusNotImplemented},
+		{"url=" + url.QueryEscape("https://blog.example.com/users/1"), http.StatusNotFound},
+		{"url=" + url.QueryEscape("https://blog.example.com/articles/2"), http.StatusNotFound},
+	}
+	for _, c := range cases {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/oembed?"+c.query, nil))
+		assert.Equal(t, c.code, w.Code, c.query)
+	}
+}
+
+func TestBotsGetArticleMeta(t *testing.T) {
+	uc := &metaUsecase{}
+	r := newMetaRouter(uc)
+
+	req := httptest.NewRequest(http.MethodGet, "/articles/1", nil)
+	req.Header.Set("User-Agent", "Slackbot-LinkExpanding 1.0 (+https://api.slack.com/robots)")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "body")
+	assert.Contains(t, w.Body.String(), `"author_name":"alice"`)
+	assert.Zero(t, uc.views)
+
+	// 普通浏览器仍然拿到完整文章
+	req = httptest.NewRequest(http.MethodGet, "/articles/1", nil)
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7)")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"content":"body"`)
+	assert.Equal(t, 1, uc.views)
+}
@@ -6,6 +6,9 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/sirupsen/logrus"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
 )
 
 // AuthMiddleware is a Gin middleware for JWT authentication
@@ -24,24 +27,100 @@ func AuthMiddleware(secret string) gin.HandlerFunc {
 		}
 		tokenString := parts[1]
 
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (any, error) {
+		if err := setClaims(c, tokenString, secret); err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+			return
+		}
+
+		c.Next()
+	}
+}
 
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, jwt.ErrTokenMalformed
+// OptionalAuth sets user_id, username and role like AuthMiddleware when a valid Bearer token is present,
+// otherwise the request goes on as anonymous. It is meant for public endpoints that personalize
+// their response for logged-in users, handlers read the viewer with c.GetInt64("user_id") where 0 means anonymous
+func OptionalAuth(secret string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		parts := strings.Split(c.GetHeader("Authorization"), " ")
+		if len(parts) == 2 && parts[0] == "Bearer" {
+			if err := setClaims(c, parts[1], secret); err != nil {
+				logrus.Debugf("ignoring invalid token on %s: %v", c.FullPath(), err)
 			}
+		}
 
-			return []byte(secret), nil
-		})
+		c.Next()
+	}
+}
+
+// Where StreamAuth looks for the token besides the Authorization header
+const (
+	TokenQueryParam = "access_token"
+	TokenCookie     = "token"
+)
+
+// StreamAuth works like AuthMiddleware for websocket endpoints. Browsers cannot set headers on the
+// upgrade request, so the token may also come from the access_token query param or the token cookie
+func StreamAuth(secret string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var tokenString string
+		if parts := strings.Split(c.GetHeader("Authorization"), " "); len(parts) == 2 && parts[0] == "Bearer" {
+			tokenString = parts[1]
+		} else if q := c.Query(TokenQueryParam); q != "" {
+			tokenString = q
+		} else if cookie, err := c.Cookie(TokenCookie); err == nil {
+			tokenString = cookie
+		}
+		if tokenString == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Token is required"})
+			return
+		}
 
-		if err != nil || !token.Valid {
+		if err := setClaims(c, tokenString, secret); err != nil {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
 			return
 		}
 
-		if claims, ok := token.Claims.(jwt.MapClaims); ok {
-			if userID, ok := claims["user_id"].(float64); ok {
-				c.Set("user_id", int64(userID))
-			}
+		c.Next()
+	}
+}
+
+// setClaims validates the token and copies user_id, username and role into the context
+func setClaims(c *gin.Context, tokenString, secret string) error {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (any, error) {
+
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, jwt.ErrTokenMalformed
+		}
+
+		return []byte(secret), nil
+	})
+	if err != nil {
+		return err
+	}
+	if !token.Valid {
+		return jwt.ErrTokenInvalidClaims
+	}
+
+	if claims, ok := token.Claims.(jwt.MapClaims); ok {
+		if userID, ok := claims["user_id"].(float64); ok {
+			c.Set("user_id", int64(userID))
+		}
+		if username, ok := claims["username"].(string); ok {
+			c.Set("username", username)
+		}
+		if role, ok := claims["role"].(string); ok {
+			c.Set("role", role)
+		}
+	}
+	return nil
+}
+
+// AdminOnly rejects requests from non-admin users, it must be used after AuthMiddleware
+func AdminOnly() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString("role") != domain.RoleAdmin {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Admin permission required"})
+			return
 		}
 
 		c.Next()
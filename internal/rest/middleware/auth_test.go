@@ -0,0 +1,138 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/rest/middleware"
+)
+
+const testSecret = "secret"
+
+func signToken(t *testing.T, claims jwt.MapClaims) string {
+	t.Helper()
+	claims["exp"] = time.Now().Add(time.Hour).Unix()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(testSecret))
+	require.NoError(t, err)
+	return token
+}
+
+func expiredToken(t *testing.T) string {
+	t.Helper()
+	claims := jwt.MapClaims{"user_id": 7, "exp": time.Now().Add(-time.Hour).Unix()}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(testSecret))
+	require.NoError(t, err)
+	return token
+}
+
+func TestAdminOnly(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(middleware.AuthMiddleware(testSecret), middleware.AdminOnly())
+	r.GET("/admin", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	cases := []struct {
+		name   string
+		claims jwt.MapClaims
+		code   int
+	}{
+		{"admin", jwt.MapClaims{"user_id": 1, "role": domain.RoleAdmin}, http.StatusOK},
+		{"user", jwt.MapClaims{"user_id": 2, "role": domain.RoleUser}, http.StatusForbidden},
+		{"token without role", jwt.MapClaims{"user_id": 3}, http.StatusForbidden},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/admin", nil)
+			req.Header.Set("Authorization", "Bearer "+signToken(t, tc.claims))
+			rec := httptest.NewRecorder()
+
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.code, rec.Code)
+		})
+	}
+}
+
+func TestOptionalAuth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(middleware.OptionalAuth(testSecret))
+	r.GET("/articles/1", func(c *gin.Context) {
+		c.String(http.StatusOK, "%d %s", c.GetInt64("user_id"), c.GetString("username"))
+	})
+
+	cases := []struct {
+		name   string
+		header string
+		userID string
+	}{
+		{"anonymous", "", "0 "},
+		{"valid token", "Bearer " + signToken(t, jwt.MapClaims{"user_id": 7, "username": "alice"}), "7 alice"},
+		{"invalid token", "Bearer garbage", "0 "},
+		{"expired token", "Bearer " + expiredToken(t), "0 "},
+		{"malformed header", "Token abc", "0 "},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/articles/1", nil)
+			if tc.header != "" {
+				req.Header.Set("Authorization", tc.header)
+			}
+			rec := httptest.NewRecorder()
+
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, tc.userID, rec.Body.String())
+		})
+	}
+}
+
+func TestStreamAuth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(middleware.StreamAuth(testSecret))
+	r.GET("/ws", func(c *gin.Context) {
+		c.String(http.StatusOK, "%d", c.GetInt64("user_id"))
+	})
+
+	token := signToken(t, jwt.MapClaims{"user_id": 7})
+	cases := []struct {
+		name  string
+		setup func(req *http.Request)
+		code  int
+	}{
+		{"header", func(req *http.Request) { req.Header.Set("Authorization", "Bearer "+token) }, http.StatusOK},
+		{"query", func(req *http.Request) { req.URL.RawQuery = middleware.TokenQueryParam + "=" + token }, http.StatusOK},
+		{"cookie", func(req *http.Request) { req.AddCookie(&http.Cookie{Name: middleware.TokenCookie, Value: token}) }, http.StatusOK},
+		{"missing", func(req *http.Request) {}, http.StatusUnauthorized},
+		{"expired", func(req *http.Request) { req.URL.RawQuery = middleware.TokenQueryParam + "=" + expiredToken(t) }, http.StatusUnauthorized},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/ws", nil)
+			tc.setup(req)
+			rec := httptest.NewRecorder()
+
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.code, rec.Code)
+			if tc.code == http.StatusOK {
+				assert.Equal(t, "7", rec.Body.String())
+			}
+		})
+	}
+}
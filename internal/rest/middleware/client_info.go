@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"net"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+)
+
+// maxReferrerLength 超长的来源域名不是正常流量，截断后计入统计，避免撑大统计表
+const maxReferrerLength = 64
+
+// ClientInfo 从 Referer 和 User-Agent 解析来源域名和设备类型放进请求的 context，
+// 之后的浏览和点赞据此记录流量统计。只挂在需要统计的路由上
+func ClientInfo() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		info := domain.ClientInfo{
+			Referrer: referrerDomain(c.Request.Referer(), c.Request.Host),
+			Device:   deviceClass(c.Request.UserAgent()),
+		}
+		c.Request = c.Request.WithContext(domain.WithClientInfo(c.Request.Context(), info))
+		c.Next()
+	}
+}
+
+// referrerDomain 返回 Referer 的域名（去掉端口和 www.），来自站内的记为 internal，没有或解析失败的记为 direct
+func referrerDomain(referer, host string) string {
+	if referer == "" {
+		return domain.ReferrerDirect
+	}
+	u, err := url.Parse(referer)
+	if err != nil || u.Hostname() == "" {
+		return domain.ReferrerDirect
+	}
+
+	name := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	if name == strings.TrimPrefix(strings.ToLower(host), "www.") {
+		return domain.ReferrerInternal
+	}
+	if len(name) > maxReferrerLength {
+		name = name[:maxReferrerLength]
+	}
+	return name
+}
+
+// deviceClass 按 User-Agent 中的关键字粗略判断设备类型，顺序很重要：
+// 爬虫也常带 Mobile，安卓平板不带 Mobile
+func deviceClass(ua string) domain.DeviceClass {
+	ua = strings.ToLower(ua)
+	switch {
+	case ua == "":
+		return domain.DeviceOther
+	case containsAny(ua, "bot", "crawler", "spider", "slurp", "curl/", "wget/", "python-requests", "go-http-client"):
+		return domain.DeviceBot
+	case containsAny(ua, "ipad", "tablet") || (strings.Contains(ua, "android") && !strings.Contains(ua, "mobile")):
+		return domain.DeviceTablet
+	case containsAny(ua, "mobi", "iphone", "ipod", "android"):
+		return domain.DeviceMobile
+	case containsAny(ua, "windows", "macintosh", "x11", "linux", "cros"):
+		return domain.DeviceDesktop
+	}
+	return domain.DeviceOther
+}
+
+func containsAny(s string, subs ...string) bool {
+	for _, sub := range subs {
+		if strings.Contains(s, sub) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,67 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/rest/middleware"
+)
+
+func TestClientInfo(t *testing.T) {
+	const (
+		iphone  = "Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) AppleWebKit/605.1.15 Mobile/15E148"
+		ipad    = "Mozilla/5.0 (iPad; CPU OS 17_0 like Mac OS X) AppleWebKit/605.1.15"
+		android = "Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 Chrome/120.0 Mobile Safari/537.36"
+		tablet  = "Mozilla/5.0 (Linux; Android 13; SM-X700) AppleWebKit/537.36 Chrome/120.0 Safari/537.36"
+		mac     = "Mozilla/5.0 (Macintosh; Intel Mac OS X 14_0) AppleWebKit/605.1.15 Safari/605.1.15"
+		google  = "Mozilla/5.0 (Linux; Android 6.0.1; Nexus 5X) Mobile Safari/537.36 (compatible; Googlebot/2.1)"
+	)
+
+	cases := []struct {
+		name     string
+		referer  string
+		ua       string
+		referrer string
+		device   domain.DeviceClass
+	}{
+		{"direct desktop", "", mac, domain.ReferrerDirect, domain.DeviceDesktop},
+		{"search on iphone", "https://www.Google.com/search?q=go", iphone, "google.com", domain.DeviceMobile},
+		{"android phone", "https://news.ycombinator.com/item?id=1", android, "news.ycombinator.com", domain.DeviceMobile},
+		{"android tablet", "", tablet, domain.ReferrerDirect, domain.DeviceTablet},
+		{"ipad", "", ipad, domain.ReferrerDirect, domain.DeviceTablet},
+		// 爬虫的 UA 里也带 Mobile，需要先识别为 bot
+		{"crawler", "", google, domain.ReferrerDirect, domain.DeviceBot},
+		{"curl", "", "curl/8.5.0", domain.ReferrerDirect, domain.DeviceBot},
+		// 站内跳转不算外部来源，端口不影响判断
+		{"internal", "http://blog.example.com:8080/articles", mac, domain.ReferrerInternal, domain.DeviceDesktop},
+		{"unparsable referer", "://bad", "", domain.ReferrerDirect, domain.DeviceOther},
+	}
+
+	gin.SetMode(gin.TestMode)
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var info domain.ClientInfo
+			var ok bool
+			r := gin.New()
+			r.GET("/", middleware.ClientInfo(), func(c *gin.Context) {
+				info, ok = domain.ClientInfoFrom(c.Request.Context())
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "http://blog.example.com/", nil)
+			req.Header.Set("Referer", tc.referer)
+			req.Header.Set("User-Agent", tc.ua)
+			r.ServeHTTP(httptest.NewRecorder(), req)
+
+			require.True(t, ok)
+			assert.Equal(t, tc.referrer, info.Referrer)
+			assert.Equal(t, tc.device, info.Device)
+		})
+	}
+}
@@ -6,6 +6,8 @@ import "github.com/gin-gonic/gin"
 func CORS() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
+		// 浏览器默认读不到自定义响应头，分页游标需要显式暴露
+		c.Writer.Header().Set("Access-Control-Expose-Headers", "X-cursor, X-Has-More, Link, X-Feed-Source, X-Params-Adjusted")
 
 		if c.Request.Method == "OPTIONS" {
 			c.Status(204)
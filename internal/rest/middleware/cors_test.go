@@ -27,6 +27,7 @@ func TestCORS(t *testing.T) {
 
 	assert.Equal(t, http.StatusOK, rec.Code)
 	assert.Equal(t, "*", rec.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "X-cursor, X-Has-More, Link, X-Feed-Source, X-Params-Adjusted", rec.Header().Get("Access-Control-Expose-Headers"))
 }
 
 func TestCORSOptionsPreflight(t *testing.T) {
@@ -0,0 +1,130 @@
+package middleware
+
+import (
+	"context"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const (
+	HeaderDebugDBQueries = "X-Debug-DB-Queries"
+	HeaderDebugRedisCmds = "X-Debug-Redis-Cmds"
+)
+
+// debugCounters 记录单个请求发出的数据库查询和 Redis 命令数。
+// 仓储层可能在其他 goroutine 中使用请求的 ctx，所以使用原子计数
+type debugCounters struct {
+	db    atomic.Int64
+	redis atomic.Int64
+}
+
+type debugCountersKey struct{}
+
+func countersFromContext(ctx context.Context) *debugCounters {
+	if ctx == nil {
+		return nil
+	}
+	counters, _ := ctx.Value(debugCountersKey{}).(*debugCounters)
+	return counters
+}
+
+// DebugCounters 统计每个请求的数据库查询与 Redis 命令数，通过响应头和日志输出，用于排查 N+1 问题。
+// 会在 db 上注册回调、在 client 上添加 hook，每个 db/client 只应调用一次
+func DebugCounters(db *gorm.DB, client *redis.Client) gin.HandlerFunc {
+	registerGormCounter(db)
+	client.AddHook(redisCounterHook{})
+
+	return func(c *gin.Context) {
+		counters := &debugCounters{}
+		c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), debugCountersKey{}, counters))
+		w := &debugWriter{ResponseWriter: c.Writer, counters: counters}
+		c.Writer = w
+
+		c.Next()
+		// c.Status 之类没有 body 的响应由 gin 在 handler 返回后直接写出，不经过 debugWriter
+		w.setHeaders()
+
+		logrus.WithFields(logrus.Fields{
+			"method":     c.Request.Method,
+			"path":       c.FullPath(),
+			"db_queries": counters.db.Load(),
+			"redis_cmds": counters.redis.Load(),
+		}).Info("request debug counters")
+	}
+}
+
+func registerGormCounter(db *gorm.DB) {
+	count := func(tx *gorm.DB) {
+		if counters := countersFromContext(tx.Statement.Context); counters != nil {
+			counters.db.Add(1)
+		}
+	}
+	cb := db.Callback()
+	_ = cb.Create().After("gorm:create").Register("debug:count", count)
+	_ = cb.Query().After("gorm:query").Register("debug:count", count)
+	_ = cb.Update().After("gorm:update").Register("debug:count", count)
+	_ = cb.Delete().After("gorm:delete").Register("debug:count", count)
+	_ = cb.Row().After("gorm:row").Register("debug:count", count)
+	_ = cb.Raw().After("gorm:raw").Register("debug:count", count)
+}
+
+// redisCounterHook 按命令计数，pipeline 中的每条命令都算一次
+type redisCounterHook struct{}
+
+func (redisCounterHook) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (redisCounterHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		if counters := countersFromContext(ctx); counters != nil {
+			counters.redis.Add(1)
+		}
+		return next(ctx, cmd)
+	}
+}
+
+func (redisCounterHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		if counters := countersFromContext(ctx); counters != nil {
+			counters.redis.Add(int64(len(cmds)))
+		}
+		return next(ctx, cmds)
+	}
+}
+
+var _ redis.Hook = redisCounterHook{}
+
+// debugWriter 在响应头发出前写入计数，handler 写 body 之后再设置响应头就来不及了
+type debugWriter struct {
+	gin.ResponseWriter
+	counters *debugCounters
+}
+
+func (w *debugWriter) setHeaders() {
+	if w.Written() {
+		return
+	}
+	w.Header().Set(HeaderDebugDBQueries, strconv.FormatInt(w.counters.db.Load(), 10))
+	w.Header().Set(HeaderDebugRedisCmds, strconv.FormatInt(w.counters.redis.Load(), 10))
+}
+
+func (w *debugWriter) WriteHeaderNow() {
+	w.setHeaders()
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *debugWriter) Write(data []byte) (int, error) {
+	w.setHeaders()
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *debugWriter) WriteString(s string) (int, error) {
+	w.setHeaders()
+	return w.ResponseWriter.WriteString(s)
+}
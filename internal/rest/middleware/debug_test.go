@@ -0,0 +1,109 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	gormMysql "gorm.io/driver/mysql"
+	"gorm.io/gorm"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/repository"
+	mysqlRepo "github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/repository/mysql"
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/repository/mysql/model"
+	myRedis "github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/repository/redis"
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/rest"
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/rest/middleware"
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/usecase/article"
+)
+
+// newDryRunDB 返回不连接数据库的 gorm.DB，查询总是返回文章 1 和它的作者
+func newDryRunDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(gormMysql.New(gormMysql.Config{
+		DSN:                       "user:password@tcp(127.0.0.1:3306)/article",
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{
+		DryRun:                 true,
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, db.Callback().Query().After("gorm:query").Register("test:fill", func(tx *gorm.DB) {
+		switch dest := tx.Statement.Dest.(type) {
+		case *model.Article:
+			*dest = model.Article{ID: 1, Title: "title", UserID: 7}
+		case *model.User:
+			*dest = model.User{ID: 7, Name: "author"}
+		}
+	}))
+	return db
+}
+
+func TestDebugCountersGetByID(t *testing.T) {
+	db := newDryRunDB(t)
+	client := redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	articleRepo := repository.NewArticleRepository(
+		mysqlRepo.NewArticleDBRepository(db, false),
+		myRedis.NewArticleCache(client, "", 0, 0),
+		mysqlRepo.NewUserRepository(db),
+		repository.NewRuntimeSettings(nil),
+		true,
+		nil,
+	)
+	svc := article.NewService(articleRepo, nil, nil, repository.NewNoopBloomRepository(), nil, nil, domain.ExcerptFixedLength, nil, nil, nil)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(middleware.DebugCounters(db, client))
+	r.GET("/articles/:id", rest.NewArticleHandler(svc).GetByID)
+
+	get := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/articles/1", nil))
+		require.Equal(t, http.StatusOK, w.Code)
+		return w
+	}
+
+	// 缓存未命中：查询文章、标签和作者
+	miss := get()
+	assert.Equal(t, "3", miss.Header().Get(middleware.HeaderDebugDBQueries))
+	assert.NotEqual(t, "0", miss.Header().Get(middleware.HeaderDebugRedisCmds))
+
+	// 缓存命中：GET 文章、HINCRBY 浏览量、GET 点赞数，不访问数据库
+	hit := get()
+	assert.Equal(t, "0", hit.Header().Get(middleware.HeaderDebugDBQueries))
+	assert.Equal(t, "3", hit.Header().Get(middleware.HeaderDebugRedisCmds))
+}
+
+func TestDebugCountersNoContent(t *testing.T) {
+	db := newDryRunDB(t)
+	client := redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(middleware.DebugCounters(db, client))
+	// 先建立连接，握手命令不计入请求
+	require.NoError(t, client.Ping(context.Background()).Err())
+	r.DELETE("/articles/:id", func(c *gin.Context) {
+		require.NoError(t, client.Del(c.Request.Context(), "article:1").Err())
+		c.Status(http.StatusNoContent)
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/articles/1", nil))
+	require.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "0", w.Header().Get(middleware.HeaderDebugDBQueries))
+	assert.Equal(t, "1", w.Header().Get(middleware.HeaderDebugRedisCmds))
+}
@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// redactedValue 访问日志中替换 token 的内容
+const redactedValue = "REDACTED"
+
+// Logger 与 gin.Logger 相同，只是访问日志中 access_token 查询参数的值被替换掉，
+// websocket 连接带在 URL 上的 token 不会写进日志
+func Logger() gin.HandlerFunc {
+	return gin.LoggerWithConfig(gin.LoggerConfig{Formatter: LogFormatter})
+}
+
+// LogFormatter 是去掉了 token 的 gin 默认日志格式
+func LogFormatter(param gin.LogFormatterParams) string {
+	var statusColor, methodColor, resetColor string
+	if param.IsOutputColor() {
+		statusColor = param.StatusCodeColor()
+		methodColor = param.MethodColor()
+		resetColor = param.ResetColor()
+	}
+
+	if param.Latency > time.Minute {
+		param.Latency = param.Latency.Truncate(time.Second)
+	}
+	return fmt.Sprintf("[GIN] %v |%s %3d %s| %13v | %15s |%s %-7s %s %#v\n%s",
+		param.TimeStamp.Format("2006/01/02 - 15:04:05"),
+		statusColor, param.StatusCode, resetColor,
+		param.Latency,
+		param.ClientIP,
+		methodColor, param.Method, resetColor,
+		redactToken(param.Path),
+		param.ErrorMessage,
+	)
+}
+
+// redactToken 替换 path 中 access_token 查询参数的值，其余参数保持原样和原来的顺序
+func redactToken(path string) string {
+	p, rawQuery, ok := strings.Cut(path, "?")
+	if !ok {
+		return path
+	}
+
+	parts := strings.Split(rawQuery, "&")
+	for i, part := range parts {
+		key, _, _ := strings.Cut(part, "=")
+		if k, err := url.QueryUnescape(key); err == nil && k == TokenQueryParam {
+			parts[i] = key + "=" + redactedValue
+		}
+	}
+	return p + "?" + strings.Join(parts, "&")
+}
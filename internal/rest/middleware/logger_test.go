@@ -0,0 +1,37 @@
+package middleware_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/rest/middleware"
+)
+
+func TestLoggerRedactsAccessToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var buf bytes.Buffer
+	r := gin.New()
+	r.Use(gin.LoggerWithConfig(gin.LoggerConfig{Formatter: middleware.LogFormatter, Output: &buf}))
+	r.GET("/ws/articles/:id/stats", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	cases := []struct {
+		query    string
+		expected string
+	}{
+		{"", `"/ws/articles/1/stats"`},
+		{"?access_token=secret.jwt.token", `"/ws/articles/1/stats?access_token=REDACTED"`},
+		// 其他参数保持原样，编码过的参数名也能识别
+		{"?a=1&access%5Ftoken=secret.jwt.token&b=2", `"/ws/articles/1/stats?a=1&access%5Ftoken=REDACTED&b=2"`},
+	}
+	for _, tc := range cases {
+		buf.Reset()
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/ws/articles/1/stats"+tc.query, nil))
+		assert.Contains(t, buf.String(), tc.expected)
+		assert.NotContains(t, buf.String(), "secret.jwt.token")
+	}
+}
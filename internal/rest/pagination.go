@@ -0,0 +1,29 @@
+package rest
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// setPaginationHeaders writes the next cursor into X-cursor and a RFC 8288 Link header.
+// The link is built from the request's own URL so that filters like sort and tag are kept.
+func setPaginationHeaders(c *gin.Context, nextCursor string, num int) {
+	c.Header("X-cursor", nextCursor)
+	if nextCursor == "" {
+		return
+	}
+
+	next := *c.Request.URL
+	query := next.Query()
+	query.Set("cursor", nextCursor)
+	query.Set("num", strconv.Itoa(num))
+	next.RawQuery = query.Encode()
+	next.Scheme = ""
+	next.Host = ""
+	next.User = nil
+	next.Fragment = ""
+
+	c.Header("Link", fmt.Sprintf(`<%s>; rel="next"`, next.String()))
+}
@@ -0,0 +1,122 @@
+package rest_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/rest"
+)
+
+type fakeArticleUsecase struct {
+	domain.ArticleUsecase
+	nextCursor string
+}
+
+func (f fakeArticleUsecase) FetchDailyRank(_ context.Context, _, limit int64) ([]domain.Article, error) {
+	return make([]domain.Article, limit), nil
+}
+
+func (f fakeArticleUsecase) Fetch(context.Context, string, int64, string, string) ([]domain.Article, string, error) {
+	return []domain.Article{{ID: 1, CreatedAt: time.Now(), UpdatedAt: time.Now()}}, f.nextCursor, nil
+}
+
+type fakeCommentUsecase struct {
+	domain.CommentUsecase
+	nextCursor string
+}
+
+func (f fakeCommentUsecase) FetchByArticle(context.Context, int64, string, int64) ([]*domain.Comment, string, error) {
+	return []*domain.Comment{{ID: 1}}, f.nextCursor, nil
+}
+
+// parseNextLink 解析 `<uri>; rel="next"` 形式的 Link 头
+func parseNextLink(t *testing.T, header string) *url.URL {
+	t.Helper()
+	require.True(t, strings.HasSuffix(header, `>; rel="next"`), header)
+	require.True(t, strings.HasPrefix(header, "<"), header)
+	raw := strings.TrimSuffix(strings.TrimPrefix(header, "<"), `>; rel="next"`)
+	u, err := url.Parse(raw)
+	require.NoError(t, err)
+	return u
+}
+
+func TestFetchArticleLinkHeaderKeepsFilters(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cursor := "2024-01-02T03:04:05.123+08:00"
+	r := gin.New()
+	r.GET("/articles", rest.NewArticleHandler(fakeArticleUsecase{nextCursor: cursor}).FetchArticle)
+
+	req := httptest.NewRequest(http.MethodGet, "/articles?sort=likes&tag=go+%26+rust&num=5&cursor=old", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, cursor, rec.Header().Get("X-cursor"))
+
+	next := parseNextLink(t, rec.Header().Get("Link"))
+	assert.Equal(t, "/articles", next.Path)
+	assert.Empty(t, next.Host)
+	q := next.Query()
+	assert.Equal(t, cursor, q.Get("cursor"))
+	assert.Equal(t, "5", q.Get("num"))
+	assert.Equal(t, "likes", q.Get("sort"))
+	assert.Equal(t, "go & rust", q.Get("tag"))
+	// 游标中的 '+' 必须被转义，否则会被解析成空格
+	assert.Contains(t, next.RawQuery, "%2B08%3A00")
+}
+
+func TestFetchArticleLinkHeaderUsesEffectiveNum(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/articles", rest.NewArticleHandler(fakeArticleUsecase{nextCursor: "abc"}).FetchArticle)
+
+	req := httptest.NewRequest(http.MethodGet, "/articles?num=1000", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	next := parseNextLink(t, rec.Header().Get("Link"))
+	assert.Equal(t, "abc", next.Query().Get("cursor"))
+	assert.Equal(t, "30", next.Query().Get("num"))
+}
+
+func TestFetchArticleNoLinkOnLastPage(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/articles", rest.NewArticleHandler(fakeArticleUsecase{}).FetchArticle)
+
+	req := httptest.NewRequest(http.MethodGet, "/articles?tag=go", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, rec.Header().Get("Link"))
+}
+
+func TestFetchCommentsLinkHeader(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/articles/:id/comments", rest.NewCommentHandler(fakeCommentUsecase{nextCursor: "MjAyNA=="}).FetchCommentsByArticle)
+
+	req := httptest.NewRequest(http.MethodGet, "/articles/7/comments?num=20&sort=new", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "MjAyNA==", rec.Header().Get("X-cursor"))
+
+	next := parseNextLink(t, rec.Header().Get("Link"))
+	assert.Equal(t, "/articles/7/comments", next.Path)
+	assert.Equal(t, "MjAyNA==", next.Query().Get("cursor"))
+	assert.Equal(t, "20", next.Query().Get("num"))
+	assert.Equal(t, "new", next.Query().Get("sort"))
+}
@@ -0,0 +1,81 @@
+package rest
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+)
+
+// HeaderParamsAdjusted 兼容模式下列出被修正过的查询参数，客户端据此发现自己传错了参数
+const HeaderParamsAdjusted = "X-Params-Adjusted"
+
+const strictParamsKey = "strict_params"
+
+// StrictParams 让之后的 handler 对越界或格式错误的查询参数返回 400，而不是修正后继续处理
+func StrictParams() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(strictParamsKey, true)
+		c.Next()
+	}
+}
+
+// FieldError 指出哪个请求参数不合法
+type FieldError struct {
+	Message string `json:"message"`
+	Field   string `json:"field"`
+}
+
+// intParam 描述一个有取值范围的整数查询参数
+type intParam struct {
+	name     string
+	min, max int
+	def      int
+}
+
+var (
+	pageNumParam      = intParam{name: "num", min: PageMinNum, max: PageMaxNum, def: DefaultPageNum}
+	rankLimitParam    = intParam{name: "limit", min: RankMin, max: RankMax, def: DefaultRankLimit}
+	rankOffsetParam   = intParam{name: "offset", min: 0, max: RankMaxOffset, def: 0}
+	suggestLimitParam = intParam{name: "limit", min: 1, max: SuggestMaxLimit, def: DefaultSuggestLimit}
+	trafficDaysParam  = intParam{name: "days", min: 1, max: domain.MaxTrafficDays, def: domain.DefaultTrafficDays}
+)
+
+// queryInt 解析整数查询参数，未传时使用默认值。
+// 参数不合法时，严格模式下写入 400 响应并返回 false；
+// 兼容模式下格式错误的使用默认值、越界的截断到边界，并在 X-Params-Adjusted 中列出该参数
+func queryInt(c *gin.Context, p intParam) (int, bool) {
+	raw := c.Query(p.name)
+	if raw == "" {
+		return p.def, true
+	}
+
+	v, err := strconv.Atoi(raw)
+	if err == nil && v >= p.min && v <= p.max {
+		return v, true
+	}
+
+	logrus.Debugf("invalid query param %s=%q", p.name, raw)
+	if c.GetBool(strictParamsKey) {
+		c.JSON(http.StatusBadRequest, FieldError{
+			Message: fmt.Sprintf("%s must be an integer between %d and %d", p.name, p.min, p.max),
+			Field:   p.name,
+		})
+		return 0, false
+	}
+
+	switch {
+	case err != nil:
+		v = p.def
+	case v < p.min:
+		v = p.min
+	default:
+		v = p.max
+	}
+	c.Writer.Header().Add(HeaderParamsAdjusted, p.name)
+	return v, true
+}
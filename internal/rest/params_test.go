@@ -0,0 +1,99 @@
+package rest_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/rest"
+)
+
+// newParamsRouter 在原路径注册兼容模式，在 /api/v1 下注册严格模式
+func newParamsRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	articles := rest.NewArticleHandler(fakeArticleUsecase{nextCursor: "abc"})
+	comments := rest.NewCommentHandler(fakeCommentUsecase{nextCursor: "abc"})
+
+	r.GET("/articles", articles.FetchArticle)
+	r.GET("/articles/ranks", articles.FetchRank)
+	r.GET("/articles/:id/comments", comments.FetchCommentsByArticle)
+
+	v1 := r.Group("/api/v1", rest.StrictParams())
+	v1.GET("/articles", articles.FetchArticle)
+	v1.GET("/articles/ranks", articles.FetchRank)
+	v1.GET("/articles/:id/comments", comments.FetchCommentsByArticle)
+	return r
+}
+
+func TestParamsCompatibilityMode(t *testing.T) {
+	r := newParamsRouter()
+
+	cases := []struct {
+		target   string
+		adjusted string
+		num      string // Link 中的 num，为空表示不检查
+	}{
+		{"/articles?num=3", "num", "5"},
+		{"/articles?num=1000", "num", "30"},
+		{"/articles?num=abc", "num", "10"},
+		{"/articles?num=20", "", "20"},
+		{"/articles", "", "10"},
+		{"/articles/7/comments?num=3", "num", "5"},
+		{"/articles/ranks?limit=100", "limit", ""},
+	}
+	for _, c := range cases {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, c.target, nil))
+
+		require.Equal(t, http.StatusOK, rec.Code, c.target)
+		assert.Equal(t, c.adjusted, rec.Header().Get(rest.HeaderParamsAdjusted), c.target)
+		if c.num != "" {
+			assert.Equal(t, c.num, parseNextLink(t, rec.Header().Get("Link")).Query().Get("num"), c.target)
+		}
+	}
+
+	// 热榜的 limit 被截断到上限
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/articles/ranks?limit=100", nil))
+	var rank []json.RawMessage
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &rank))
+	assert.Len(t, rank, rest.RankMax)
+}
+
+func TestParamsStrictMode(t *testing.T) {
+	r := newParamsRouter()
+
+	cases := []struct {
+		target string
+		field  string
+	}{
+		{"/api/v1/articles?num=3", "num"},
+		{"/api/v1/articles?num=abc", "num"},
+		{"/api/v1/articles/7/comments?num=31", "num"},
+		{"/api/v1/articles/ranks?limit=4", "limit"},
+	}
+	for _, c := range cases {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, c.target, nil))
+
+		require.Equal(t, http.StatusBadRequest, rec.Code, c.target)
+		var body rest.FieldError
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		assert.Equal(t, c.field, body.Field, c.target)
+		assert.NotEmpty(t, body.Message)
+	}
+
+	// 合法参数和缺省参数正常处理
+	for _, target := range []string{"/api/v1/articles?num=5", "/api/v1/articles", "/api/v1/articles/ranks?limit=30"} {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		assert.Equal(t, http.StatusOK, rec.Code, target)
+		assert.Empty(t, rec.Header().Get(rest.HeaderParamsAdjusted), target)
+	}
+}
@@ -0,0 +1,13 @@
+package request
+
+import "github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+
+// BulkModeration is the request payload for bulk article moderation
+type BulkModeration struct {
+	Action string  `json:"action" binding:"required,oneof=delete hide unhide"`
+	IDs    []int64 `json:"ids" binding:"required,min=1,max=100"`
+}
+
+func (r *BulkModeration) ToDomain() domain.ModerationAction {
+	return domain.ModerationAction(r.Action)
+}
@@ -4,18 +4,60 @@ import (
 	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
 )
 
-// Article is the request payload for creating or updating an article
+// Article is the request payload for creating or updating an article.
+// It only carries fields the author may write, counters, timestamps and the ID are decided by the server
 type Article struct {
-	ID      int64  `json:"id"`
 	Title   string `json:"title" binding:"required"`
 	Content string `json:"content" binding:"required"`
+	// Summary 可选，不填时由正文自动生成
+	Summary string `json:"summary" binding:"max=300"`
+	// Language 可选的 BCP-47 语言标签，不填时使用站点的默认语言
+	Language string `json:"language" binding:"max=35"`
+	// Tags 可选，不区分大小写，重复的标签只保留一个
+	Tags []string `json:"tags" binding:"max=10"`
+	// Status 可选，draft 保存为草稿，不填时直接发布
+	Status string `json:"status" binding:"omitempty,oneof=draft published"`
 }
 
 // ToDomain: Request -> Domain
 func (r *Article) ToDomain() domain.Article {
 	return domain.Article{
-		ID:      r.ID,
-		Title:   r.Title,
-		Content: r.Content,
+		Title:    r.Title,
+		Content:  r.Content,
+		Summary:  r.Summary,
+		Language: r.Language,
+		Tags:     r.Tags,
+		Status:   domain.ArticleStatus(r.Status),
 	}
 }
+
+// ArticleUpdate is the request payload for editing an article, empty fields are left unchanged
+type ArticleUpdate struct {
+	Title    string `json:"title"`
+	Content  string `json:"content"`
+	Summary  string `json:"summary" binding:"max=300"`
+	Language string `json:"language" binding:"max=35"`
+	// Tags 替换文章的全部标签，不传时保持原样，传空数组时清空
+	Tags []string `json:"tags" binding:"max=10"`
+}
+
+// IsEmpty reports whether the request changes nothing
+func (r *ArticleUpdate) IsEmpty() bool {
+	return r.Title == "" && r.Content == "" && r.Summary == "" && r.Language == "" && r.Tags == nil
+}
+
+// ToDomain: Request -> Domain
+func (r *ArticleUpdate) ToDomain() domain.Article {
+	return domain.Article{
+		Title:    r.Title,
+		Content:  r.Content,
+		Summary:  r.Summary,
+		Language: r.Language,
+		Tags:     r.Tags,
+	}
+}
+
+// Engagement 批量查询文章的点赞数和评论数
+type Engagement struct {
+	IDs []int64 `json:"ids" binding:"required,min=1,max=100"`
+}
@@ -8,7 +8,11 @@ type Comment struct {
 	UserID    int64  `json:"user_id"`                    // for CREATE
 	Content   string `json:"content" binding:"required"` // for CREATE
 	ParentID  int64  `json:"parent_id"`                  // for CREATE
-	RootID    int64  `json:"root_id"`                    // for CREATE
+}
+
+// CommentReply 是回复评论的请求体，文章和父评论由路径决定
+type CommentReply struct {
+	Content string `json:"content" binding:"required"`
 }
 
 // ToDomain: Request -> Domain
@@ -19,6 +23,5 @@ func (r *Comment) ToDomain() domain.Comment {
 		UserID:    r.UserID,
 		Content:   r.Content,
 		ParentID:  r.ParentID,
-		RootID:    r.RootID,
 	}
 }
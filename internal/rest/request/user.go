@@ -6,6 +6,8 @@ type User struct {
 	Name     string `json:"name"`
 	Username string `json:"username" binding:"required"`
 	Password string `json:"password" binding:"required"`
+	// RegistrationToken 由客户端为每次填写表单生成，只在注册时使用，重复提交时返回第一次的结果
+	RegistrationToken string `json:"registration_token" binding:"omitempty,max=64"`
 }
 
 func (a *User) ToDomain() domain.User {
@@ -15,3 +17,9 @@ func (a *User) ToDomain() domain.User {
 		Password: a.Password,
 	}
 }
+
+// PasswordChange 修改密码的请求体
+type PasswordChange struct {
+	OldPassword string `json:"old_password" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required,min=8"`
+}
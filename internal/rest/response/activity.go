@@ -0,0 +1,24 @@
+package response
+
+import "github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+
+// Activity 作者动态，milestone 是达到的点赞数或浏览量，发布动态没有这个字段
+type Activity struct {
+	ID           int64  `json:"id"`
+	Type         string `json:"type"`
+	ArticleID    int64  `json:"article_id"`
+	ArticleTitle string `json:"article_title"`
+	Milestone    int64  `json:"milestone,omitempty"`
+	CreatedAt    string `json:"created_at"`
+}
+
+func NewActivityFromDomain(a *domain.Activity) Activity {
+	return Activity{
+		ID:           a.ID,
+		Type:         string(a.Type),
+		ArticleID:    a.ArticleID,
+		ArticleTitle: a.ArticleTitle,
+		Milestone:    a.Milestone,
+		CreatedAt:    a.CreatedAt.Format(DateTimeFormat),
+	}
+}
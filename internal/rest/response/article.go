@@ -7,26 +7,210 @@ import (
 const DateTimeFormat = "2006-01-02 15:04:05"
 
 type Article struct {
-	ID        int64  `json:"id"`
-	Title     string `json:"title"`
-	Content   string `json:"content"`
-	UserName  string `json:"user_name"`
-	UpdatedAt string `json:"updated_at"`
-	CreatedAt string `json:"created_at"`
-	Views     int64  `json:"views"`
-	Likes     int64  `json:"likes"`
+	ID      int64  `json:"id"`
+	Title   string `json:"title"`
+	Summary string `json:"summary"`
+	// Excerpt 是去掉标记后的纯文本摘录，用于 feed 和 meta description，没有正文时省略
+	Excerpt string `json:"excerpt,omitempty"`
+	Content string `json:"content,omitempty"`
+	// ContentTruncated 为 true 时 Content 只是正文的开头，完整正文需要请求文章详情
+	ContentTruncated bool   `json:"content_truncated"`
+	UserName         string `json:"user_name"`
+	Edited           bool   `json:"edited"`
+	EditCount        int64  `json:"edit_count"`
+	UpdatedAt        string `json:"updated_at"`
+	CreatedAt        string `json:"created_at"`
+	Views            int64  `json:"views"`
+	// ViewsDisplay 是格式化后的浏览量（如 10.5k），只在列表和热榜中返回
+	ViewsDisplay string `json:"views_display,omitempty"`
+	Likes        int64  `json:"likes"`
+	// CommentsLocked 为 true 时不再接受新评论，已有评论仍然可以查看
+	CommentsLocked bool `json:"comments_locked"`
+	// Language 是文章的 BCP-47 语言标签，未指定时省略
+	Language string `json:"language,omitempty"`
+	// Tags 按名称排序，没有标签时为空数组
+	Tags []string `json:"tags"`
+	// Status 为 draft 或 published
+	Status string `json:"status"`
+	// Score 是热榜的排名分数，可能带小数，只在热榜中返回
+	Score float64 `json:"score,omitempty"`
+	// Warning 只在创建文章时返回，例如正文与作者自己已有的文章重复
+	Warning *ArticleWarning `json:"warning,omitempty"`
+	// DeletedAt 只在管理员读取已删除的文章时返回
+	DeletedAt string `json:"deleted_at,omitempty"`
+}
+
+// ArticlePage 是 format=envelope 时的文章列表，分页信息与 X-cursor、X-Has-More 响应头一致。
+// 仓储多读一行来判断 HasMore，最后一页恰好有 num 篇时也是 false
+type ArticlePage struct {
+	Data       []Article `json:"data"`
+	NextCursor string    `json:"next_cursor"`
+	HasMore    bool      `json:"has_more"`
+	Count      int       `json:"count"`
+}
+
+func NewArticlePage(data []Article, nextCursor string) ArticlePage {
+	return ArticlePage{
+		Data:       data,
+		NextCursor: nextCursor,
+		HasMore:    nextCursor != "",
+		Count:      len(data),
+	}
+}
+
+// LikeState 是点赞和取消点赞的响应，IsChanged 沿用旧版响应中的字段
+type LikeState struct {
+	IsChanged bool  `json:"is_changed"`
+	Liked     bool  `json:"liked"`
+	Likes     int64 `json:"likes"`
+}
+
+func NewLikeState(s domain.LikeState) LikeState {
+	return LikeState{IsChanged: s.Changed, Liked: s.Liked, Likes: s.Likes}
+}
+
+// ArticleWarning 创建成功但需要提醒作者的情况，ExistingID 为相关的已有文章
+type ArticleWarning struct {
+	Code       string `json:"code"`
+	Message    string `json:"message"`
+	ExistingID int64  `json:"existing_id"`
 }
 
 // FromDomain: Domain -> Response
 func NewArticleFromDomain(a *domain.Article) Article {
-	return Article{
-		ID:        a.ID,
-		Title:     a.Title,
-		Content:   a.Content,
-		UserName:  a.User.Name,
-		UpdatedAt: a.UpdatedAt.Format(DateTimeFormat),
-		CreatedAt: a.CreatedAt.Format(DateTimeFormat),
-		Views:     a.Views,
-		Likes:     a.Likes,
+	res := Article{
+		ID:               a.ID,
+		Title:            a.Title,
+		Summary:          a.Summary,
+		Excerpt:          a.Excerpt,
+		Content:          a.Content,
+		ContentTruncated: a.ContentTruncated,
+		UserName:         a.User.Name,
+		Edited:           a.Edited,
+		EditCount:        a.EditCount,
+		UpdatedAt:        a.UpdatedAt.Format(DateTimeFormat),
+		CreatedAt:        a.CreatedAt.Format(DateTimeFormat),
+		Views:            a.Views,
+		ViewsDisplay:     a.ViewsDisplay,
+		Likes:            a.Likes,
+		Score:            a.Score,
+		CommentsLocked:   a.CommentsLocked,
+		Language:         a.Language,
+		Tags:             a.Tags,
+	}
+	if res.Tags == nil {
+		res.Tags = []string{}
+	}
+	// 加入草稿之前写入的缓存没有状态，这些文章都是已发布的
+	res.Status = string(a.Status)
+	if res.Status == "" {
+		res.Status = string(domain.ArticleStatusPublished)
+	}
+	if !a.DeletedAt.IsZero() {
+		res.DeletedAt = a.DeletedAt.Format(DateTimeFormat)
+	}
+	if a.DuplicateOf != 0 {
+		res.Warning = &ArticleWarning{
+			Code:       "duplicate_content",
+			Message:    "you have already published an article with the same content",
+			ExistingID: a.DuplicateOf,
+		}
+	}
+	return res
+}
+
+// ArticleForViewer 是文章详情的响应，列表和热榜不返回 is_liked
+type ArticleForViewer struct {
+	Article
+	// IsLiked 是请求者是否点赞过这篇文章，匿名请求和点赞状态读取失败时为 false
+	IsLiked bool `json:"is_liked"`
+}
+
+func NewArticleForViewer(a *domain.Article) ArticleForViewer {
+	res := ArticleForViewer{Article: NewArticleFromDomain(a)}
+	if a.Viewer != nil && a.Viewer.Liked != nil {
+		res.IsLiked = *a.Viewer.Liked
+	}
+	return res
+}
+
+// ArticleDetail 在文章详情的基础上附带点赞数和评论数
+type ArticleDetail struct {
+	ArticleForViewer
+	Engagement domain.Engagement `json:"engagement"`
+}
+
+func NewArticleDetailFromDomain(d *domain.ArticleDetail) ArticleDetail {
+	return ArticleDetail{
+		ArticleForViewer: NewArticleForViewer(&d.Article),
+		Engagement:       d.Engagement,
+	}
+}
+
+// NewArticleSummaryFromDomain 列表和热榜只返回摘要，不返回正文
+func NewArticleSummaryFromDomain(a *domain.Article) Article {
+	res := NewArticleFromDomain(a)
+	res.Content = ""
+	res.ContentTruncated = false
+	return res
+}
+
+// TitleSuggestion 是标题联想的一条结果
+type TitleSuggestion struct {
+	ID    int64  `json:"id"`
+	Title string `json:"title"`
+}
+
+func NewTitleSuggestionFromDomain(s domain.TitleSuggestion) TitleSuggestion {
+	return TitleSuggestion{ID: s.ID, Title: s.Title}
+}
+
+// TagCount 是一个标签和带有它的可见文章数
+type TagCount struct {
+	Name     string `json:"name"`
+	Articles int64  `json:"articles"`
+}
+
+func NewTagCountFromDomain(t domain.TagCount) TagCount {
+	return TagCount{Name: t.Name, Articles: t.Articles}
+}
+
+// ArticleMeta 是链接预览用的文章元数据，不含正文
+type ArticleMeta struct {
+	ID          int64  `json:"id"`
+	Title       string `json:"title"`
+	Summary     string `json:"summary"`
+	AuthorName  string `json:"author_name"`
+	PublishedAt string `json:"published_at"`
+}
+
+func NewArticleMetaFromDomain(m domain.ArticleMeta) ArticleMeta {
+	return ArticleMeta{
+		ID:          m.ID,
+		Title:       m.Title,
+		Summary:     m.Summary,
+		AuthorName:  m.AuthorName,
+		PublishedAt: m.PublishedAt.Format(DateTimeFormat),
+	}
+}
+
+// OEmbed 是 oEmbed 1.0 的 link 类型响应，CacheAge 以秒为单位
+type OEmbed struct {
+	Version      string `json:"version"`
+	Type         string `json:"type"`
+	Title        string `json:"title"`
+	AuthorName   string `json:"author_name"`
+	ProviderName string `json:"provider_name,omitempty"`
+	CacheAge     int64  `json:"cache_age"`
+}
+
+func NewOEmbedFromDomain(m domain.ArticleMeta, provider string, cacheAge int64) OEmbed {
+	return OEmbed{
+		Version:      "1.0",
+		Type:         "link",
+		Title:        m.Title,
+		AuthorName:   m.AuthorName,
+		ProviderName: provider,
+		CacheAge:     cacheAge,
 	}
 }
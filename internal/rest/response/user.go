@@ -3,13 +3,23 @@ package response
 import "github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
 
 type User struct {
+	ID         int64  `json:"id"`
 	Name       string `json:"name"`
 	Username   string `json:"username"`
 	Created_at string `json:"created_at"`
+	// Deleted 为 true 表示作者账号已删除，Name 为占位名称
+	Deleted bool `json:"deleted,omitempty"`
 }
 
 func NewUserFromDomain(a *domain.User) *User {
+	if a == nil {
+		return nil
+	}
+	if a.IsDeleted() {
+		return &User{Name: a.Name, Deleted: true}
+	}
 	return &User{
+		ID:         a.ID,
 		Name:       a.Name,
 		Username:   a.Username,
 		Created_at: a.CreatedAt.Format(DateTimeFormat),
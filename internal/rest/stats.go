@@ -0,0 +1,302 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/net/websocket"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+)
+
+const (
+	// DefaultStatsPingInterval 向客户端发送 ping 的间隔，避免空闲连接被代理断开
+	DefaultStatsPingInterval = 30 * time.Second
+	// statsWriteWait 单次推送的写超时，客户端长时间不读时断开连接
+	statsWriteWait = 10 * time.Second
+)
+
+// errStatsHubClosed 服务关闭后不再接受新的订阅
+var errStatsHubClosed = errors.New("stats hub is closed")
+
+// StatsHub 管理作者仪表盘的实时统计连接。订阅按文章分组，每个周期对所有被订阅的文章只查询一次统计，
+// 再推送给各自的订阅者，查询只读缓存，不会因为连接数增加而放大数据库压力。
+// Run 返回时关闭所有连接，http.Server.Shutdown 不会等待已经升级的连接
+type StatsHub struct {
+	Service domain.ArticleUsecase
+
+	Interval        time.Duration
+	PingInterval    time.Duration
+	MaxConnsPerUser int
+	// AllowedOrigins 允许跨域连接的来源，如 "https://dashboard.example.com"。
+	// token 可以放在 cookie 中，不在列表里且与服务不同源的浏览器连接会被拒绝
+	AllowedOrigins []string
+
+	mu      sync.Mutex
+	subs    map[int64]map[*statsSubscriber]struct{}
+	perUser map[int64]int
+	// last 每篇文章最近一次推送的统计，浏览量落库后缓存中的文章可能还是旧值，推送时取较大的浏览量避免数字回退
+	last   map[int64]domain.ArticleStats
+	closed bool
+}
+
+// statsSubscriber 是一个连接，updates 只保留最新的一次统计，客户端读得慢时丢弃旧值
+type statsSubscriber struct {
+	userID    int64
+	articleID int64
+	updates   chan domain.ArticleStats
+	done      chan struct{}
+}
+
+func NewStatsHub(svc domain.ArticleUsecase, interval time.Duration, maxConnsPerUser int) *StatsHub {
+	return &StatsHub{
+		Service:         svc,
+		Interval:        interval,
+		PingInterval:    DefaultStatsPingInterval,
+		MaxConnsPerUser: maxConnsPerUser,
+		subs:            make(map[int64]map[*statsSubscriber]struct{}),
+		perUser:         make(map[int64]int),
+		last:            make(map[int64]domain.ArticleStats),
+	}
+}
+
+// Run 每隔 Interval 推送一次统计，直到 ctx 结束
+func (h *StatsHub) Run(ctx context.Context) {
+	ticker := time.NewTicker(h.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			h.shutdown()
+			logrus.Info("StatsHub stopped")
+			return
+		case <-ticker.C:
+			h.broadcast(ctx)
+		}
+	}
+}
+
+// Stream GET /ws/articles/:id/stats，只有作者和管理员可以订阅，升级为 websocket 后推送 {views, likes}
+func (h *StatsHub) Stream(c *gin.Context) {
+	idP, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, domain.ErrNotFound.Error())
+		return
+	}
+	aid := int64(idP)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+	actor := domain.User{ID: userID.(int64), Role: c.GetString("role")}
+
+	ctx := c.Request.Context()
+	if err := h.Service.AuthorizeStats(ctx, aid, actor); err != nil {
+		c.JSON(getStatusCode(err), ResponseError{Message: err.Error()})
+		return
+	}
+
+	sub, err := h.subscribe(actor.ID, aid)
+	if errors.Is(err, errStatsHubClosed) {
+		c.JSON(http.StatusServiceUnavailable, ResponseError{Message: err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(getStatusCode(err), ResponseError{Message: err.Error()})
+		return
+	}
+	defer h.unsubscribe(sub)
+
+	// 连上后立即推送一次，不必等到下一个周期
+	if stats, err := h.Service.LiveStats(ctx, []int64{aid}); err == nil {
+		h.publish(stats)
+	} else {
+		logrus.Warnf("failed to get stats of article %d: %v", aid, err)
+	}
+
+	server := websocket.Server{
+		Handshake: h.checkOrigin,
+		Handler: func(ws *websocket.Conn) {
+			h.serve(ws, sub)
+		},
+	}
+	server.ServeHTTP(c.Writer, c.Request)
+}
+
+// checkOrigin 没有 Origin 的非浏览器客户端直接放行，浏览器只允许同源或 AllowedOrigins 中的来源
+func (h *StatsHub) checkOrigin(config *websocket.Config, req *http.Request) error {
+	origin := req.Header.Get("Origin")
+	if origin == "" {
+		return nil
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return err
+	}
+	if u.Host == req.Host {
+		return nil
+	}
+	for _, allowed := range h.AllowedOrigins {
+		if origin == allowed {
+			return nil
+		}
+	}
+	return domain.ErrForbidden
+}
+
+// serve 是连接的写循环，所有写操作都在这里完成。客户端发来的消息直接丢弃，读到错误说明连接已断开
+func (h *StatsHub) serve(ws *websocket.Conn, sub *statsSubscriber) {
+	defer ws.Close()
+
+	disconnected := make(chan struct{})
+	go func() {
+		defer close(disconnected)
+		var msg []byte
+		for {
+			if err := websocket.Message.Receive(ws, &msg); err != nil {
+				return
+			}
+		}
+	}()
+
+	ping := time.NewTicker(h.PingInterval)
+	defer ping.Stop()
+
+	for {
+		select {
+		case <-sub.done:
+			return
+		case <-disconnected:
+			return
+		case stats := <-sub.updates:
+			_ = ws.SetWriteDeadline(time.Now().Add(statsWriteWait))
+			if err := websocket.JSON.Send(ws, stats); err != nil {
+				return
+			}
+		case <-ping.C:
+			// 客户端收到 ping 会自动回复 pong，写超时说明连接已经不可用
+			_ = ws.SetWriteDeadline(time.Now().Add(statsWriteWait))
+			ws.PayloadType = websocket.PingFrame
+			_, err := ws.Write(nil)
+			ws.PayloadType = websocket.TextFrame
+			if err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (h *StatsHub) subscribe(userID, articleID int64) (*statsSubscriber, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		return nil, errStatsHubClosed
+	}
+	if h.MaxConnsPerUser > 0 && h.perUser[userID] >= h.MaxConnsPerUser {
+		return nil, domain.ErrTooManyRequests
+	}
+
+	sub := &statsSubscriber{
+		userID:    userID,
+		articleID: articleID,
+		updates:   make(chan domain.ArticleStats, 1),
+		done:      make(chan struct{}),
+	}
+	if h.subs[articleID] == nil {
+		h.subs[articleID] = make(map[*statsSubscriber]struct{})
+	}
+	h.subs[articleID][sub] = struct{}{}
+	h.perUser[userID]++
+	return sub, nil
+}
+
+func (h *StatsHub) unsubscribe(sub *statsSubscriber) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	subs, ok := h.subs[sub.articleID]
+	if !ok {
+		return
+	}
+	if _, ok := subs[sub]; !ok {
+		return
+	}
+	delete(subs, sub)
+	if len(subs) == 0 {
+		delete(h.subs, sub.articleID)
+		delete(h.last, sub.articleID)
+	}
+	if h.perUser[sub.userID]--; h.perUser[sub.userID] <= 0 {
+		delete(h.perUser, sub.userID)
+	}
+}
+
+// broadcast 查询所有被订阅文章的统计并推送，查询失败时跳过这个周期
+func (h *StatsHub) broadcast(ctx context.Context) {
+	h.mu.Lock()
+	ids := make([]int64, 0, len(h.subs))
+	for id := range h.subs {
+		ids = append(ids, id)
+	}
+	h.mu.Unlock()
+	if len(ids) == 0 {
+		return
+	}
+
+	stats, err := h.Service.LiveStats(ctx, ids)
+	if err != nil {
+		logrus.Warnf("failed to get live stats: %v", err)
+		return
+	}
+	h.publish(stats)
+}
+
+func (h *StatsHub) publish(stats map[int64]domain.ArticleStats) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for id, s := range stats {
+		subs, ok := h.subs[id]
+		if !ok {
+			continue
+		}
+		if prev, ok := h.last[id]; ok && prev.Views > s.Views {
+			s.Views = prev.Views
+		}
+		h.last[id] = s
+		for sub := range subs {
+			// 只有持锁时会写入 updates，清空后一定有空位
+			select {
+			case <-sub.updates:
+			default:
+			}
+			sub.updates <- s
+		}
+	}
+}
+
+// shutdown 通知所有连接关闭并拒绝新的订阅
+func (h *StatsHub) shutdown() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.closed = true
+	for _, subs := range h.subs {
+		for sub := range subs {
+			close(sub.done)
+		}
+	}
+	h.subs = make(map[int64]map[*statsSubscriber]struct{})
+	h.perUser = make(map[int64]int)
+	h.last = make(map[int64]domain.ArticleStats)
+}
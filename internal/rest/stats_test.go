@@ -0,0 +1,195 @@
+package rest_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/websocket"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/rest"
+)
+
+// statsUsecase 文章 1 的作者是用户 1，每次查询按 views 依次返回浏览量，用完后停在最后一个值
+type statsUsecase struct {
+	domain.ArticleUsecase
+
+	mu    sync.Mutex
+	views []int64
+	calls [][]int64
+}
+
+func (u *statsUsecase) AuthorizeStats(_ context.Context, id int64, actor domain.User) error {
+	if id != 1 {
+		return domain.ErrNotFound
+	}
+	if actor.ID != 1 {
+		return domain.ErrForbidden
+	}
+	return nil
+}
+
+func (u *statsUsecase) LiveStats(_ context.Context, ids []int64) (map[int64]domain.ArticleStats, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.calls = append(u.calls, ids)
+	views := u.views[0]
+	if len(u.views) > 1 {
+		u.views = u.views[1:]
+	}
+	res := make(map[int64]domain.ArticleStats)
+	for _, id := range ids {
+		res[id] = domain.ArticleStats{Views: views, Likes: 2}
+	}
+	return res, nil
+}
+
+// newStatsServer 用 user 查询参数代替 token 认证
+func newStatsServer(t *testing.T, hub *rest.StatsHub) *httptest.Server {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/ws/articles/:id/stats", func(c *gin.Context) {
+		if user := c.Query("user"); user != "" {
+			c.Set("user_id", map[string]int64{"author": 1, "other": 2}[user])
+		}
+		c.Next()
+	}, hub.Stream)
+	srv := httptest.NewServer(r)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func dialStats(t *testing.T, srv *httptest.Server, path string) *websocket.Conn {
+	t.Helper()
+	ws, err := websocket.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+path, "", srv.URL)
+	require.NoError(t, err)
+	t.Cleanup(func() { ws.Close() })
+	return ws
+}
+
+func receiveStats(t *testing.T, ws *websocket.Conn) domain.ArticleStats {
+	t.Helper()
+	var stats domain.ArticleStats
+	require.NoError(t, ws.SetReadDeadline(time.Now().Add(time.Second)))
+	require.NoError(t, websocket.JSON.Receive(ws, &stats))
+	return stats
+}
+
+func TestStatsStreamPushesSnapshots(t *testing.T) {
+	// 第三次查询时浏览量已经落库，缓存中的文章还是旧值，推送的浏览量不应回退
+	svc := &statsUsecase{views: []int64{10, 12, 11}}
+	hub := rest.NewStatsHub(svc, 20*time.Millisecond, 2)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go hub.Run(ctx)
+	srv := newStatsServer(t, hub)
+
+	ws := dialStats(t, srv, "/ws/articles/1/stats?user=author")
+	assert.Equal(t, domain.ArticleStats{Views: 10, Likes: 2}, receiveStats(t, ws))
+	assert.Equal(t, domain.ArticleStats{Views: 12, Likes: 2}, receiveStats(t, ws))
+	assert.Equal(t, domain.ArticleStats{Views: 12, Likes: 2}, receiveStats(t, ws))
+}
+
+func TestStatsStreamQueriesOncePerTick(t *testing.T) {
+	svc := &statsUsecase{views: []int64{1}}
+	hub := rest.NewStatsHub(svc, 20*time.Millisecond, 2)
+	srv := newStatsServer(t, hub)
+
+	first := dialStats(t, srv, "/ws/articles/1/stats?user=author")
+	second := dialStats(t, srv, "/ws/articles/1/stats?user=author")
+	receiveStats(t, first)
+	receiveStats(t, second)
+
+	// 两个订阅者共享一次查询
+	svc.mu.Lock()
+	svc.calls = nil
+	svc.mu.Unlock()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go hub.Run(ctx)
+	receiveStats(t, first)
+	receiveStats(t, second)
+
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+	require.NotEmpty(t, svc.calls)
+	assert.Equal(t, []int64{1}, svc.calls[0])
+}
+
+func TestStatsStreamRejects(t *testing.T) {
+	hub := rest.NewStatsHub(&statsUsecase{views: []int64{1}}, time.Hour, 1)
+	srv := newStatsServer(t, hub)
+	dialStats(t, srv, "/ws/articles/1/stats?user=author")
+
+	cases := []struct {
+		name string
+		path string
+		code int
+	}{
+		{"anonymous", "/ws/articles/1/stats", http.StatusUnauthorized},
+		{"not author", "/ws/articles/1/stats?user=other", http.StatusForbidden},
+		{"missing article", "/ws/articles/2/stats?user=author", http.StatusNotFound},
+		{"too many connections", "/ws/articles/1/stats?user=author", http.StatusTooManyRequests},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp, err := http.Get(srv.URL + tc.path)
+			require.NoError(t, err)
+			resp.Body.Close()
+			assert.Equal(t, tc.code, resp.StatusCode)
+		})
+	}
+}
+
+func TestStatsStreamRejectsCrossOrigin(t *testing.T) {
+	hub := rest.NewStatsHub(&statsUsecase{views: []int64{1}}, time.Hour, 1)
+	srv := newStatsServer(t, hub)
+
+	_, err := websocket.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/ws/articles/1/stats?user=author", "", "https://evil.example.com")
+	require.Error(t, err)
+
+	// 被拒绝的连接不占用名额
+	require.Eventually(t, func() bool {
+		ws, err := websocket.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/ws/articles/1/stats?user=author", "", srv.URL)
+		if err != nil {
+			return false
+		}
+		ws.Close()
+		return true
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestStatsStreamClosedOnShutdown(t *testing.T) {
+	hub := rest.NewStatsHub(&statsUsecase{views: []int64{1}}, time.Hour, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		hub.Run(ctx)
+		close(done)
+	}()
+	srv := newStatsServer(t, hub)
+
+	ws := dialStats(t, srv, "/ws/articles/1/stats?user=author")
+	receiveStats(t, ws)
+
+	cancel()
+	<-done
+	var stats domain.ArticleStats
+	require.NoError(t, ws.SetReadDeadline(time.Now().Add(time.Second)))
+	assert.Error(t, websocket.JSON.Receive(ws, &stats))
+
+	// 关闭后不再接受新的连接
+	resp, err := http.Get(srv.URL + "/ws/articles/1/stats?user=author")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+}
@@ -0,0 +1,75 @@
+package rest
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/rest/response"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	DefaultTrendingTagsLimit = 10
+	TrendingTagsMaxLimit     = 50
+)
+
+var trendingLimitParam = intParam{name: "limit", min: 1, max: TrendingTagsMaxLimit, def: DefaultTrendingTagsLimit}
+
+type tagHandler struct {
+	Service domain.TagUsecase
+}
+
+func NewTagHandler(svc domain.TagUsecase) *tagHandler {
+	return &tagHandler{
+		Service: svc,
+	}
+}
+
+// Trending GET /tags/trending?window=7d，最近一段时间内加到可见文章上最多的标签。
+// window 为天数或小时数（如 7d、24h），默认 7 天，最长 30 天
+func (h *tagHandler) Trending(c *gin.Context) {
+	limit, ok := queryInt(c, trendingLimitParam)
+	if !ok {
+		return
+	}
+	window := domain.DefaultTrendingTagsWindow
+	if raw := c.Query("window"); raw != "" {
+		if window, ok = parseWindow(raw); !ok {
+			c.JSON(http.StatusBadRequest, FieldError{Message: "window must look like 7d or 24h", Field: "window"})
+			return
+		}
+	}
+
+	tags, err := h.Service.Trending(c.Request.Context(), window, int64(limit))
+	if err != nil {
+		c.JSON(getStatusCode(err), ResponseError{Message: err.Error()})
+		return
+	}
+
+	res := make([]response.TagCount, len(tags))
+	for i, tag := range tags {
+		res[i] = response.NewTagCountFromDomain(tag)
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+// parseWindow 解析 7d、24h 这样的时间窗口，只支持天和小时
+func parseWindow(raw string) (time.Duration, bool) {
+	if len(raw) < 2 {
+		return 0, false
+	}
+	n, err := strconv.Atoi(raw[:len(raw)-1])
+	// 数值再大也超过了最长的窗口，提前拒绝以免溢出
+	if err != nil || n <= 0 || n > int(domain.MaxTrendingTagsWindow/time.Hour) {
+		return 0, false
+	}
+	switch raw[len(raw)-1] {
+	case 'd':
+		return time.Duration(n) * 24 * time.Hour, true
+	case 'h':
+		return time.Duration(n) * time.Hour, true
+	}
+	return 0, false
+}
@@ -0,0 +1,65 @@
+package rest_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/rest"
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/usecase/tag"
+)
+
+// windowTagRepo 记录统计的起点和数量
+type windowTagRepo struct {
+	since time.Time
+	limit int64
+}
+
+func (f *windowTagRepo) Trending(_ context.Context, since time.Time, limit int64) ([]domain.TagCount, error) {
+	f.since, f.limit = since, limit
+	return []domain.TagCount{{Name: "rust", Articles: 2}, {Name: "go", Articles: 1}}, nil
+}
+
+func TestTrendingTags(t *testing.T) {
+	cases := []struct {
+		query  string
+		code   int
+		window time.Duration
+		limit  int64
+	}{
+		{"", http.StatusOK, 7 * 24 * time.Hour, 10},
+		{"?window=24h&limit=3", http.StatusOK, 24 * time.Hour, 3},
+		{"?window=30d", http.StatusOK, 30 * 24 * time.Hour, 10},
+		{"?window=31d", http.StatusBadRequest, 0, 0},
+		{"?window=0d", http.StatusBadRequest, 0, 0},
+		{"?window=1w", http.StatusBadRequest, 0, 0},
+		{"?window=abc", http.StatusBadRequest, 0, 0},
+	}
+	for _, tc := range cases {
+		t.Run(tc.query, func(t *testing.T) {
+			repo := &windowTagRepo{}
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.GET("/tags/trending", rest.NewTagHandler(tag.NewService(repo)).Trending)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/tags/trending"+tc.query, nil))
+
+			require.Equal(t, tc.code, w.Code)
+			if tc.code != http.StatusOK {
+				assert.Zero(t, repo.limit, "repository is not queried")
+				return
+			}
+			assert.JSONEq(t, `[{"name":"rust","articles":2},{"name":"go","articles":1}]`, w.Body.String())
+			assert.WithinDuration(t, time.Now().Add(-tc.window), repo.since, time.Minute)
+			assert.Equal(t, tc.limit, repo.limit)
+		})
+	}
+}
@@ -2,17 +2,23 @@ package rest
 
 import (
 	"context"
+	"errors"
 	"net/http"
+	"strconv"
 
 	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
 	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/rest/request"
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/rest/response"
 	"github.com/gin-gonic/gin"
 )
 
 type UserService interface {
 	Register(ctx context.Context, name, username, password string) error
+	RegisterWithToken(ctx context.Context, token, name, username, password string) error
+	UsernameAvailable(ctx context.Context, client, username string) (bool, error)
 	Login(ctx context.Context, username, password string) (string, error)
 	EditPassword(ctx context.Context, id int64, oldPassword, newPassword string) error
+	List(ctx context.Context, cursor string, limit int64, search string) ([]domain.User, string, error)
 }
 
 type UserHandler struct {
@@ -33,15 +39,41 @@ func (h *UserHandler) Register(c *gin.Context) {
 		return
 	}
 
-	err := h.Service.Register(c.Request.Context(), req.Name, req.Username, req.Password)
+	err := h.Service.RegisterWithToken(c.Request.Context(), req.RegistrationToken, req.Name, req.Username, req.Password)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		if errors.Is(err, domain.ErrConflict) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		// 令牌用于其他用户名等客户端错误返回 400
+		c.JSON(getStatusCode(err), gin.H{"error": err.Error()})
 		return
 	}
 
 	c.JSON(http.StatusCreated, gin.H{"message": "User created successfully"})
 }
 
+// UsernameAvailable GET /auth/username-available?username=x，注册表单即时检查用户名是否已被占用。
+// 按客户端 IP 限流，超过次数返回 429 和 Retry-After。ClientIP 只采用受信任代理的 X-Forwarded-For，见 main 中的 newRouter
+func (h *UserHandler) UsernameAvailable(c *gin.Context) {
+	username := domain.NormalizeUsername(c.Query("username"))
+	if username == "" {
+		c.JSON(http.StatusBadRequest, ResponseError{Message: "username is required"})
+		return
+	}
+
+	available, err := h.Service.UsernameAvailable(c.Request.Context(), c.ClientIP(), username)
+	if err != nil {
+		if errors.Is(err, domain.ErrTooManyRequests) {
+			c.Header("Retry-After", strconv.Itoa(int(domain.UsernameCheckWindow.Seconds())))
+		}
+		c.JSON(getStatusCode(err), ResponseError{Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"username": username, "available": available})
+}
+
 // Login handles user login and returns a JWT token upon successful authentication
 func (h *UserHandler) Login(c *gin.Context) {
 	var req request.User
@@ -63,3 +95,51 @@ func (h *UserHandler) Login(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"token": token})
 }
+
+// EditPassword PUT /users/password，登录用户凭旧密码修改自己的密码，新密码至少 8 位
+func (h *UserHandler) EditPassword(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var req request.PasswordChange
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err := h.Service.EditPassword(c.Request.Context(), userID.(int64), req.OldPassword, req.NewPassword)
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(getStatusCode(err), ResponseError{Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password updated successfully"})
+}
+
+// List returns a page of users for admins, filtered by the optional search prefix
+func (h *UserHandler) List(c *gin.Context) {
+	num, err := strconv.Atoi(c.Query("num"))
+	if err != nil || num < PageMinNum || num > PageMaxNum {
+		num = DefaultPageNum
+	}
+
+	users, nextCursor, err := h.Service.List(c.Request.Context(), c.Query("cursor"), int64(num), c.Query("search"))
+	if err != nil {
+		c.JSON(getStatusCode(err), ResponseError{Message: err.Error()})
+		return
+	}
+
+	res := make([]*response.User, len(users))
+	for i := range users {
+		res[i] = response.NewUserFromDomain(&users[i])
+	}
+	setPaginationHeaders(c, nextCursor, num)
+	c.JSON(http.StatusOK, res)
+}
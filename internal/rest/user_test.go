@@ -0,0 +1,318 @@
+package rest_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+	myRedis "github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/repository/redis"
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/rest"
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/usecase/user"
+)
+
+// racingUserRepo 让并发的注册请求都先通过查重再插入，插入时像唯一索引一样只放行第一个
+type racingUserRepo struct {
+	domain.UserRepository
+	checked sync.WaitGroup
+
+	mu    sync.Mutex
+	users map[string]domain.User
+}
+
+func (r *racingUserRepo) GetByUsername(_ context.Context, username string) (domain.User, error) {
+	r.checked.Done()
+	r.checked.Wait()
+	return domain.User{}, domain.ErrNotFound
+}
+
+func (r *racingUserRepo) Insert(_ context.Context, u *domain.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.users[u.Username]; ok {
+		return domain.ErrUserAlreadyExists
+	}
+	u.ID = int64(len(r.users) + 1)
+	r.users[u.Username] = *u
+	return nil
+}
+
+func TestConcurrentRegisterSameUsername(t *testing.T) {
+	const n = 2
+	repo := &racingUserRepo{users: map[string]domain.User{}}
+	repo.checked.Add(n)
+	svc := user.NewService(repo, []byte("secret"), time.Hour, nil)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/register", rest.NewUserHandler(svc).Register)
+
+	codes := make([]int, n)
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/register", strings.NewReader(`{"name":"Alice","username":"alice","password":"pw123456"}`))
+			req.Header.Set("Content-Type", "application/json")
+			r.ServeHTTP(w, req)
+			codes[i] = w.Code
+		}()
+	}
+	wg.Wait()
+
+	assert.ElementsMatch(t, []int{http.StatusCreated, http.StatusConflict}, codes)
+	assert.Len(t, repo.users, 1)
+}
+
+func TestConcurrentRegisterCaseVariants(t *testing.T) {
+	usernames := []string{"Alice", "alice"}
+	repo := &racingUserRepo{users: map[string]domain.User{}}
+	repo.checked.Add(len(usernames))
+	svc := user.NewService(repo, []byte("secret"), time.Hour, nil)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/register", rest.NewUserHandler(svc).Register)
+
+	codes := make([]int, len(usernames))
+	var wg sync.WaitGroup
+	for i, username := range usernames {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/register", strings.NewReader(`{"username":"`+username+`","password":"pw123456"}`))
+			req.Header.Set("Content-Type", "application/json")
+			r.ServeHTTP(w, req)
+			codes[i] = w.Code
+		}()
+	}
+	wg.Wait()
+
+	// 两个请求都通过了查重，由唯一索引拦下后到的一个
+	assert.ElementsMatch(t, []int{http.StatusCreated, http.StatusConflict}, codes)
+	require.Len(t, repo.users, 1)
+	u := repo.users["alice"]
+	assert.Equal(t, "alice", u.Username)
+	// 显示名保留注册时输入的大小写
+	assert.Contains(t, usernames, u.Name)
+}
+
+// passwordUserRepo 只有用户 1，记录更新后的密码哈希
+type passwordUserRepo struct {
+	domain.UserRepository
+	user domain.User
+}
+
+func (r *passwordUserRepo) GetByID(_ context.Context, id int64) (domain.User, error) {
+	if id != r.user.ID {
+		return domain.User{}, domain.ErrNotFound
+	}
+	return r.user, nil
+}
+
+func (r *passwordUserRepo) Update(_ context.Context, u *domain.User) error {
+	r.user = *u
+	return nil
+}
+
+func TestEditPassword(t *testing.T) {
+	cases := []struct {
+		name    string
+		userID  int64
+		body    string
+		code    int
+		changed bool
+	}{
+		{"success", 1, `{"old_password":"pw123456","new_password":"newpass99"}`, http.StatusOK, true},
+		{"wrong old password", 1, `{"old_password":"wrong","new_password":"newpass99"}`, http.StatusBadRequest, false},
+		{"new password too short", 1, `{"old_password":"pw123456","new_password":"short"}`, http.StatusBadRequest, false},
+		{"missing user", 2, `{"old_password":"pw123456","new_password":"newpass99"}`, http.StatusNotFound, false},
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("pw123456"), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := &passwordUserRepo{user: domain.User{ID: 1, Username: "alice", Password: string(hash)}}
+			svc := user.NewService(repo, []byte("secret"), time.Hour, nil)
+
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.PUT("/users/password", func(c *gin.Context) {
+				c.Set("user_id", tc.userID)
+			}, rest.NewUserHandler(svc).EditPassword)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPut, "/users/password", strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			r.ServeHTTP(w, req)
+
+			require.Equal(t, tc.code, w.Code)
+			newErr := bcrypt.CompareHashAndPassword([]byte(repo.user.Password), []byte("newpass99"))
+			assert.Equal(t, tc.changed, newErr == nil)
+		})
+	}
+}
+
+// memoryUserRepo 用 map 保存用户，插入时像唯一索引一样拒绝重复的用户名
+type memoryUserRepo struct {
+	domain.UserRepository
+	mu    sync.Mutex
+	users map[string]domain.User
+}
+
+func (r *memoryUserRepo) GetByUsername(_ context.Context, username string) (domain.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	u, ok := r.users[username]
+	if !ok {
+		return domain.User{}, domain.ErrNotFound
+	}
+	return u, nil
+}
+
+func (r *memoryUserRepo) Insert(_ context.Context, u *domain.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.users[u.Username]; ok {
+		return domain.ErrUserAlreadyExists
+	}
+	u.ID = int64(len(r.users) + 1)
+	r.users[u.Username] = *u
+	return nil
+}
+
+func newSignupCache(t *testing.T) domain.SignupCache {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	return myRedis.NewSignupCache(client, "")
+}
+
+func TestRegisterDoubleSubmitWithToken(t *testing.T) {
+	const n = 3
+	repo := &memoryUserRepo{users: map[string]domain.User{}}
+	svc := user.NewService(repo, []byte("secret"), time.Hour, newSignupCache(t))
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/register", rest.NewUserHandler(svc).Register)
+
+	// 同一次填写的表单连点三次，都返回创建成功，只注册一个用户
+	codes := make([]int, n)
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/register", strings.NewReader(`{"username":"alice","password":"pw123456","registration_token":"form-1"}`))
+			req.Header.Set("Content-Type", "application/json")
+			r.ServeHTTP(w, req)
+			codes[i] = w.Code
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, []int{http.StatusCreated, http.StatusCreated, http.StatusCreated}, codes)
+	assert.Len(t, repo.users, 1)
+
+	// 换一个令牌就是新的注册，用户名已被占用
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/register", strings.NewReader(`{"username":"alice","password":"pw123456","registration_token":"form-2"}`))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestRegisterTokenReusedForOtherUsername(t *testing.T) {
+	repo := &memoryUserRepo{users: map[string]domain.User{}}
+	svc := user.NewService(repo, []byte("secret"), time.Hour, newSignupCache(t))
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/register", rest.NewUserHandler(svc).Register)
+
+	register := func(body string) int {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/register", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	require.Equal(t, http.StatusCreated, register(`{"username":"alice","password":"pw123456","registration_token":"form-1"}`))
+	// 同一个令牌换了用户名是客户端的错误，不是 500
+	assert.Equal(t, http.StatusBadRequest, register(`{"username":"bob","password":"pw123456","registration_token":"form-1"}`))
+	assert.Len(t, repo.users, 1)
+}
+
+func TestRegisterWithOnlyInvisibleCharacters(t *testing.T) {
+	repo := &memoryUserRepo{users: map[string]domain.User{}}
+	svc := user.NewService(repo, []byte("secret"), time.Hour, newSignupCache(t))
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/register", rest.NewUserHandler(svc).Register)
+
+	// 用户名去掉不可见字符后为空，带不带令牌都是 400
+	for _, body := range []string{
+		`{"username":"\u200b","password":"pw123456"}`,
+		`{"username":"\u200b\ufeff","password":"pw123456","registration_token":"form-1"}`,
+	} {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/register", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code, body)
+	}
+	assert.Empty(t, repo.users)
+}
+
+func TestUsernameAvailable(t *testing.T) {
+	repo := &memoryUserRepo{users: map[string]domain.User{"alice": {ID: 1, Username: "alice"}}}
+	svc := user.NewService(repo, []byte("secret"), time.Hour, newSignupCache(t))
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/auth/username-available", rest.NewUserHandler(svc).UsernameAvailable)
+
+	get := func(query string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/auth/username-available"+query, nil))
+		return w
+	}
+
+	w := get("?username=Alice")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"username":"alice","available":false}`, w.Body.String())
+
+	w = get("?username=bob")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"username":"bob","available":true}`, w.Body.String())
+
+	assert.Equal(t, http.StatusBadRequest, get("?username=%20").Code)
+
+	for range domain.UsernameCheckLimit - 2 {
+		require.Equal(t, http.StatusOK, get("?username=bob").Code)
+	}
+	w = get("?username=bob")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "60", w.Header().Get("Retry-After"))
+}
@@ -0,0 +1,75 @@
+// Package textutil 清理用户输入的文本，去掉不可见的控制字符和格式字符。
+// 零宽字符和 RTL override 会打乱热榜等列表的显示，也可以拼出与 "admin" 看起来相同的用户名
+package textutil
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// keepFormat 中的格式字符(Cf)不会被删除：ZWNJ 和 ZWJ 是波斯语、天城文等文字
+// 以及组合 emoji 的一部分，删掉会改变文字本身
+var keepFormat = map[rune]bool{
+	'\u200c': true, // ZERO WIDTH NON-JOINER
+	'\u200d': true, // ZERO WIDTH JOINER
+}
+
+// CleanLine 清理标题、用户名这类单行文本：删除控制字符和格式字符，
+// 连续的空白合并为一个空格并去掉首尾空白，最后做 NFC 规范化。
+// 结果可能是空字符串，由调用方决定是否拒绝
+func CleanLine(s string) string {
+	return cleanLine(s, keepFormat)
+}
+
+// CleanIdentifier 与 CleanLine 相同，但连 ZWNJ 和 ZWJ 也删除。
+// 用户名这类用来区分身份的文本中它们只会造成看起来相同的两个名字
+func CleanIdentifier(s string) string {
+	return cleanLine(s, nil)
+}
+
+func cleanLine(s string, keep map[rune]bool) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	space := false
+	for _, r := range s {
+		switch {
+		case unicode.IsSpace(r):
+			space = b.Len() > 0
+		case invisible(r, keep):
+		default:
+			if space {
+				b.WriteByte(' ')
+				space = false
+			}
+			b.WriteRune(r)
+		}
+	}
+	return norm.NFC.String(b.String())
+}
+
+// CleanText 清理评论这类多行文本：与 CleanLine 相同地删除控制字符和格式字符，
+// 但保留换行、制表符和原有的空白，\r\n 换行变为 \n
+func CleanText(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	for _, r := range s {
+		if r == '\n' || r == '\t' || unicode.IsSpace(r) && !unicode.Is(unicode.Cc, r) {
+			b.WriteRune(r)
+			continue
+		}
+		if !invisible(r, keepFormat) {
+			b.WriteRune(r)
+		}
+	}
+	return norm.NFC.String(b.String())
+}
+
+// invisible 报告 r 是否是需要删除的控制字符(Cc)或不在 keep 中的格式字符(Cf)
+func invisible(r rune, keep map[rune]bool) bool {
+	if unicode.Is(unicode.Cc, r) {
+		return true
+	}
+	return unicode.Is(unicode.Cf, r) && !keep[r]
+}
@@ -0,0 +1,91 @@
+package textutil_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/textutil"
+)
+
+func TestCleanLine(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"plain", "Go 并发编程", "Go 并发编程"},
+		{"trim", "  标题\t", "标题"},
+		{"collapse whitespace", "a  b\t\tc\n\nd", "a b c d"},
+		{"nbsp and ideographic space", "a\u00a0\u00a0b\u3000c", "a b c"},
+		{"zero width space", "ad\u200bmin", "admin"},
+		{"zero width no-break space", "\ufeffadmin", "admin"},
+		{"soft hyphen", "ad\u00admin", "admin"},
+		{"word joiner", "ad\u2060min", "admin"},
+		{"rtl override", "\u202eevil.exe", "evil.exe"},
+		{"bidi isolates and marks", "\u2067abc\u2069\u200f", "abc"},
+		{"control characters", "a\x00b\x1bc\x7f", "abc"},
+		{"c1 control", "a\u0085b", "a b"},
+		{"invisible between spaces", "a \u200b b", "a b"},
+		{"only invisible", "\u200b\u200e\u202e", ""},
+		{"only whitespace", " \t\n\u3000", ""},
+		{"nfc composes", "cafe\u0301", "café"},
+		{"nfc after strip", "e\u200b\u0301", "é"},
+		{"hangul jamo", "\u1112\u1161\u11ab", "한"},
+		{"keeps zwj emoji", "👩\u200d💻", "👩\u200d💻"},
+		{"keeps zwnj", "می\u200cخواهم", "می\u200cخواهم"},
+		{"keeps variation selector", "❤\ufe0f", "❤\ufe0f"},
+		{"arabic text", "مرحبا بالعالم", "مرحبا بالعالم"},
+		{"tag characters", "a\U000e0041\U000e0042b", "ab"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, textutil.CleanLine(tt.in))
+		})
+	}
+}
+
+func TestCleanIdentifier(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"plain", "alice", "alice"},
+		{"zero width space", "ali\u200bce", "alice"},
+		{"zwj", "e\u200dve", "eve"},
+		{"zwnj", "\u200ceve", "eve"},
+		{"rtl override", "\u202eecila", "ecila"},
+		{"collapse whitespace", " al  ice ", "al ice"},
+		{"nfc composes", "jose\u0301", "josé"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, textutil.CleanIdentifier(tt.in))
+		})
+	}
+}
+
+func TestCleanText(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"plain", "写得很好", "写得很好"},
+		{"keeps newlines and indent", "第一行\n\n  第二行\n\tcode", "第一行\n\n  第二行\n\tcode"},
+		{"keeps surrounding spaces", "  a  b  ", "  a  b  "},
+		{"crlf", "a\r\nb", "a\nb"},
+		{"zero width space", "ad\u200bmin", "admin"},
+		{"rtl override", "look \u202egnp.exe", "look gnp.exe"},
+		{"control characters", "a\x00b\x1b[31mc", "ab[31mc"},
+		{"nfc composes", "cafe\u0301", "café"},
+		{"keeps zwj emoji", "👨\u200d👩\u200d👧", "👨\u200d👩\u200d👧"},
+		{"only invisible", "\u200b\ufeff", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, textutil.CleanText(tt.in))
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package activity
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+)
+
+type service struct {
+	activityRepo domain.ActivityRepository
+}
+
+// FetchByUser 获取作者动态，按ID倒序，cursor 为上一页最后一条动态的ID
+func (s *service) FetchByUser(ctx context.Context, userID int64, cursor string, num int64) ([]domain.Activity, string, error) {
+	activities, hasMore, err := s.activityRepo.FetchByUser(ctx, userID, cursor, num)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(activities) == 0 {
+		return activities, "", nil
+	}
+
+	var nextCursor string
+	if hasMore {
+		nextCursor = strconv.FormatInt(activities[len(activities)-1].ID, 10)
+	}
+	return activities, nextCursor, nil
+}
+
+var _ domain.ActivityUsecase = (*service)(nil)
+
+// NewService 创建作者动态服务，动态由文章的数据库操作层在发布和同步计数时写入
+func NewService(activityRepo domain.ActivityRepository) *service {
+	return &service{
+		activityRepo: activityRepo,
+	}
+}
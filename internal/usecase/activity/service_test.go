@@ -0,0 +1,89 @@
+package activity_test
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/usecase/activity"
+)
+
+// fakeActivityRepo 按 id 倒序保存动态，分页方式与 mysql 实现一致
+type fakeActivityRepo struct {
+	domain.ActivityRepository
+	rows []domain.Activity
+	err  error
+}
+
+func (f *fakeActivityRepo) FetchByUser(_ context.Context, userID int64, cursor string, num int64) ([]domain.Activity, bool, error) {
+	if f.err != nil {
+		return nil, false, f.err
+	}
+	var lastID int64
+	if cursor != "" {
+		var err error
+		if lastID, err = strconv.ParseInt(cursor, 10, 64); err != nil {
+			return nil, false, domain.ErrBadParamInput
+		}
+	}
+	var res []domain.Activity
+	for _, a := range f.rows {
+		if a.UserID != userID || (lastID > 0 && a.ID >= lastID) {
+			continue
+		}
+		if int64(len(res)) == num {
+			return res, true, nil
+		}
+		res = append(res, a)
+	}
+	return res, false, nil
+}
+
+func TestFetchByUserPagesWithCursor(t *testing.T) {
+	repo := &fakeActivityRepo{rows: []domain.Activity{
+		{ID: 5, UserID: 1, Type: domain.ActivityLikesMilestone, Milestone: 50},
+		{ID: 4, UserID: 2, Type: domain.ActivityPublished},
+		{ID: 3, UserID: 1, Type: domain.ActivityViewsMilestone, Milestone: 1000},
+		{ID: 1, UserID: 1, Type: domain.ActivityPublished},
+	}}
+	svc := activity.NewService(repo)
+
+	page, next, err := svc.FetchByUser(context.Background(), 1, "", 2)
+	require.NoError(t, err)
+	require.Len(t, page, 2)
+	assert.Equal(t, int64(5), page[0].ID)
+	assert.Equal(t, int64(3), page[1].ID)
+	assert.Equal(t, "3", next)
+
+	// 最后一页没有下一页的游标
+	page, next, err = svc.FetchByUser(context.Background(), 1, next, 2)
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	assert.Equal(t, int64(1), page[0].ID)
+	assert.Empty(t, next)
+}
+
+func TestFetchByUserEmpty(t *testing.T) {
+	svc := activity.NewService(&fakeActivityRepo{})
+
+	page, next, err := svc.FetchByUser(context.Background(), 1, "", 10)
+	require.NoError(t, err)
+	assert.Empty(t, page)
+	assert.Empty(t, next)
+}
+
+func TestFetchByUserPassesErrors(t *testing.T) {
+	svc := activity.NewService(&fakeActivityRepo{})
+	_, _, err := svc.FetchByUser(context.Background(), 1, "abc", 10)
+	assert.ErrorIs(t, err, domain.ErrBadParamInput)
+
+	dbErr := errors.New("db is down")
+	svc = activity.NewService(&fakeActivityRepo{err: dbErr})
+	_, _, err = svc.FetchByUser(context.Background(), 1, "", 10)
+	assert.ErrorIs(t, err, dbErr)
+}
@@ -0,0 +1,39 @@
+package admin
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+)
+
+const auditActionImportArticles = "import_articles"
+
+// ImportArticles 在后台导入文章并记录审计日志，返回的任务用于查询进度
+func (s *service) ImportArticles(ctx context.Context, actorID int64, body []byte) (domain.ImportJob, error) {
+	job, err := s.articleImporter.Start(ctx, body)
+	if err != nil {
+		return domain.ImportJob{}, err
+	}
+
+	log := domain.AuditLog{
+		ActorID:    actorID,
+		Action:     auditActionImportArticles,
+		TargetType: auditTargetArticle,
+		Result:     domain.BulkResultOK,
+		Detail:     "job=" + job.ID,
+		CreatedAt:  time.Now(),
+	}
+	if err := s.auditRepo.BatchStore(context.WithoutCancel(ctx), []domain.AuditLog{log}); err != nil {
+		logrus.Errorf("failed to store audit logs of admin %d: %v", actorID, err)
+	}
+
+	return job, nil
+}
+
+// GetImportJob 返回导入任务的当前进度
+func (s *service) GetImportJob(ctx context.Context, id string) (domain.ImportJob, error) {
+	return s.articleImporter.Get(ctx, id)
+}
@@ -0,0 +1,81 @@
+package admin
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+)
+
+// overviewTrendingLimit 概览中返回的热门文章数
+const overviewTrendingLimit = 5
+
+// Overview 并发收集各项站点指标，某一项失败时该项为 null，错误记录在 Errors 中，不影响其他项
+func (s *service) Overview(ctx context.Context) domain.SiteOverview {
+	now := time.Now()
+	y, m, d := now.Date()
+	today := time.Date(y, m, d, 0, 0, 0, 0, now.Location())
+
+	res := domain.SiteOverview{GeneratedAt: now, Errors: []string{}}
+	var mu sync.Mutex
+	fail := func(section string, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		res.Errors = append(res.Errors, fmt.Sprintf("%s: %v", section, err))
+	}
+
+	// 每一项都自行处理错误，不返回给 errgroup，避免一项失败取消其他查询
+	var g errgroup.Group
+	counts := map[string]**domain.SiteCounts{
+		domain.StatsArticles: &res.Articles,
+		domain.StatsUsers:    &res.Users,
+		domain.StatsComments: &res.Comments,
+	}
+	for name, dst := range counts {
+		g.Go(func() error {
+			c, err := s.stats.Count(ctx, name, today)
+			if err != nil {
+				fail(name, err)
+				return nil
+			}
+			*dst = &c
+			return nil
+		})
+	}
+	g.Go(func() error {
+		rank, err := s.articleCache.GetDailyRank(ctx, 0, overviewTrendingLimit)
+		if err != nil {
+			fail("trending_article_ids", err)
+			return nil
+		}
+		ids := make([]int64, len(rank))
+		for i, ar := range rank {
+			ids[i] = ar.ID
+		}
+		res.TrendingArticleIDs = ids
+		return nil
+	})
+	g.Go(func() error {
+		views, err := s.articleCache.SumBufferedViews(ctx)
+		if err != nil {
+			fail("buffered_views", err)
+			return nil
+		}
+		res.BufferedViews = &views
+		return nil
+	})
+	_ = g.Wait()
+	sort.Strings(res.Errors)
+
+	depth := int64(s.likesWorker.QueueDepth())
+	res.LikeQueueDepth = &depth
+	backgroundDepth, dropped := int64(s.background.QueueDepth()), s.background.Dropped()
+	res.BackgroundQueueDepth = &backgroundDepth
+	res.BackgroundTasksDropped = &dropped
+	return res
+}
@@ -0,0 +1,112 @@
+package admin_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/usecase/admin"
+)
+
+// fakeStats 返回预置的统计数字，failing 中的统计项返回错误
+type fakeStats struct {
+	counts  map[string]domain.SiteCounts
+	failing map[string]bool
+
+	mu    sync.Mutex
+	since time.Time
+}
+
+func (f *fakeStats) Count(_ context.Context, name string, since time.Time) (domain.SiteCounts, error) {
+	if f.failing[name] {
+		return domain.SiteCounts{}, errBoom
+	}
+	f.mu.Lock()
+	f.since = since
+	f.mu.Unlock()
+	return f.counts[name], nil
+}
+
+type fakeOverviewCache struct {
+	domain.ArticleCache
+	rankErr error
+}
+
+func (f fakeOverviewCache) GetDailyRank(_ context.Context, _, limit int64) ([]domain.Article, error) {
+	if f.rankErr != nil {
+		return nil, f.rankErr
+	}
+	res := make([]domain.Article, limit)
+	for i := range res {
+		res[i].ID = int64(i + 1)
+	}
+	return res, nil
+}
+
+func (fakeOverviewCache) SumBufferedViews(context.Context) (int64, error) { return 42, nil }
+
+type fakeLikesWorker struct {
+	domain.SyncLikesWorker
+}
+
+func (fakeLikesWorker) QueueDepth() int { return 3 }
+
+type fakeBackground struct {
+	domain.BackgroundRunner
+}
+
+func (fakeBackground) QueueDepth() int { return 5 }
+
+func (fakeBackground) Dropped() int64 { return 2 }
+
+func TestOverview(t *testing.T) {
+	stats := &fakeStats{counts: map[string]domain.SiteCounts{
+		domain.StatsArticles: {Total: 100, Today: 2},
+		domain.StatsUsers:    {Total: 50, Today: 1},
+		domain.StatsComments: {Total: 300, Today: 10},
+	}}
+	svc := admin.NewService(nil, nil, nil, nil, stats, fakeOverviewCache{}, fakeLikesWorker{}, nil, nil, fakeBackground{}, nil)
+
+	res := svc.Overview(context.Background())
+
+	assert.Equal(t, &domain.SiteCounts{Total: 100, Today: 2}, res.Articles)
+	assert.Equal(t, &domain.SiteCounts{Total: 50, Today: 1}, res.Users)
+	assert.Equal(t, &domain.SiteCounts{Total: 300, Today: 10}, res.Comments)
+	assert.Equal(t, []int64{1, 2, 3, 4, 5}, res.TrendingArticleIDs)
+	require.NotNil(t, res.LikeQueueDepth)
+	assert.Equal(t, int64(3), *res.LikeQueueDepth)
+	require.NotNil(t, res.BackgroundQueueDepth)
+	assert.Equal(t, int64(5), *res.BackgroundQueueDepth)
+	require.NotNil(t, res.BackgroundTasksDropped)
+	assert.Equal(t, int64(2), *res.BackgroundTasksDropped)
+	require.NotNil(t, res.BufferedViews)
+	assert.Equal(t, int64(42), *res.BufferedViews)
+	assert.Empty(t, res.Errors)
+
+	// 今日从本地时间零点开始计算
+	assert.Equal(t, 0, stats.since.Hour())
+	assert.Equal(t, time.Now().Day(), stats.since.Day())
+}
+
+func TestOverviewDegradesFailedSections(t *testing.T) {
+	stats := &fakeStats{
+		counts:  map[string]domain.SiteCounts{domain.StatsArticles: {Total: 100, Today: 2}},
+		failing: map[string]bool{domain.StatsUsers: true, domain.StatsComments: true},
+	}
+	svc := admin.NewService(nil, nil, nil, nil, stats, fakeOverviewCache{rankErr: errBoom}, fakeLikesWorker{}, nil, nil, fakeBackground{}, nil)
+
+	res := svc.Overview(context.Background())
+
+	// 失败的项为 null，其他项不受影响
+	assert.Equal(t, &domain.SiteCounts{Total: 100, Today: 2}, res.Articles)
+	assert.Nil(t, res.Users)
+	assert.Nil(t, res.Comments)
+	assert.Nil(t, res.TrendingArticleIDs)
+	assert.NotNil(t, res.BufferedViews)
+	assert.Equal(t, []string{"comments: boom", "trending_article_ids: boom", "users: boom"}, res.Errors)
+}
@@ -0,0 +1,40 @@
+package admin
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+)
+
+const auditActionReconcileLikes = "reconcile_likes"
+
+// ReconcileLikes 校准文章点赞数并记录审计日志。
+// 中途失败时已修正的部分仍然有效，返回的汇总包含已完成的部分
+func (s *service) ReconcileLikes(ctx context.Context, actorID int64) (domain.LikesReconcileSummary, error) {
+	summary, runErr := s.likesReconciler.Run(ctx)
+
+	result := domain.BulkResultOK
+	if runErr != nil {
+		logrus.Errorf("failed to reconcile likes: %v", runErr)
+		result = domain.BulkResultError
+	}
+	log := domain.AuditLog{
+		ActorID:    actorID,
+		Action:     auditActionReconcileLikes,
+		TargetType: auditTargetArticle,
+		Result:     result,
+		Detail: fmt.Sprintf("checked=%d repaired=%d largest_drift=%d done=%t",
+			summary.Checked, summary.Repaired, summary.LargestDrift, summary.Done),
+		CreatedAt: time.Now(),
+	}
+	// 请求可能已经超时，审计日志使用独立的 context 写入
+	if err := s.auditRepo.BatchStore(context.WithoutCancel(ctx), []domain.AuditLog{log}); err != nil {
+		logrus.Errorf("failed to store audit logs of admin %d: %v", actorID, err)
+	}
+
+	return summary, runErr
+}
@@ -0,0 +1,120 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+)
+
+const auditTargetArticle = "article"
+
+type service struct {
+	articleSvc   domain.ArticleUsecase
+	auditRepo    domain.AuditLogRepository
+	settings     domain.Settings
+	settingsRepo domain.SettingsRepository
+	stats        domain.SiteStatsRepository
+	articleCache domain.ArticleCache
+	likesWorker  domain.SyncLikesWorker
+
+	likesReconciler domain.LikesReconciler
+	articleImporter domain.ArticleImporter
+	background      domain.BackgroundRunner
+	trafficRepo     domain.TrafficRepository
+}
+
+var _ domain.AdminUsecase = (*service)(nil)
+
+// NewService 创建admin usecase服务
+// 所有操作都经由 article usecase 执行，保证布隆过滤器、缓存清理等逻辑一致
+// 运行时配置从 settings 读取，修改写入 settingsRepo，由各实例定期刷新
+// 站点概览从 stats、articleCache、likesWorker 和 background 收集，点赞数校准由 likesReconciler 执行，
+// 文章批量导入由 articleImporter 在后台执行，流量统计最近几天从 articleCache 读取，更早的从 trafficRepo 读取
+func NewService(
+	articleSvc domain.ArticleUsecase,
+	auditRepo domain.AuditLogRepository,
+	settings domain.Settings,
+	settingsRepo domain.SettingsRepository,
+	stats domain.SiteStatsRepository,
+	articleCache domain.ArticleCache,
+	likesWorker domain.SyncLikesWorker,
+	likesReconciler domain.LikesReconciler,
+	articleImporter domain.ArticleImporter,
+	background domain.BackgroundRunner,
+	trafficRepo domain.TrafficRepository,
+) *service {
+	return &service{
+		articleSvc:   articleSvc,
+		auditRepo:    auditRepo,
+		settings:     settings,
+		settingsRepo: settingsRepo,
+		stats:        stats,
+		articleCache: articleCache,
+		likesWorker:  likesWorker,
+
+		likesReconciler: likesReconciler,
+		articleImporter: articleImporter,
+		background:      background,
+		trafficRepo:     trafficRepo,
+	}
+}
+
+// BulkModerateArticles 逐个处理文章，单个失败不会中断整个批次
+func (s *service) BulkModerateArticles(ctx context.Context, actorID int64, action domain.ModerationAction, ids []int64) ([]domain.BulkResult, error) {
+	if len(ids) == 0 || len(ids) > domain.MaxBulkModerationSize {
+		return nil, domain.ErrBadParamInput
+	}
+
+	var apply func(ctx context.Context, id int64) error
+	switch action {
+	case domain.ModerationDelete:
+		apply = func(ctx context.Context, id int64) error {
+			return s.articleSvc.Delete(ctx, id, 0)
+		}
+	case domain.ModerationHide:
+		apply = func(ctx context.Context, id int64) error {
+			return s.articleSvc.SetHidden(ctx, id, true)
+		}
+	case domain.ModerationUnhide:
+		apply = func(ctx context.Context, id int64) error {
+			return s.articleSvc.SetHidden(ctx, id, false)
+		}
+	default:
+		return nil, domain.ErrBadParamInput
+	}
+
+	results := make([]domain.BulkResult, 0, len(ids))
+	logs := make([]domain.AuditLog, 0, len(ids))
+	for _, id := range ids {
+		res := domain.BulkResult{ID: id, Status: domain.BulkResultOK}
+		if err := apply(ctx, id); err != nil {
+			if errors.Is(err, domain.ErrNotFound) {
+				res.Status = domain.BulkResultNotFound
+			} else {
+				logrus.Errorf("failed to %s article %d: %v", action, id, err)
+				res.Status = domain.BulkResultError
+				res.Error = err.Error()
+			}
+		}
+		results = append(results, res)
+		logs = append(logs, domain.AuditLog{
+			ActorID:    actorID,
+			Action:     string(action),
+			TargetType: auditTargetArticle,
+			TargetID:   id,
+			Result:     res.Status,
+			CreatedAt:  time.Now(),
+		})
+	}
+
+	// 审计日志写入失败不影响已完成的操作
+	if err := s.auditRepo.BatchStore(ctx, logs); err != nil {
+		logrus.Errorf("failed to store audit logs of admin %d: %v", actorID, err)
+	}
+
+	return results, nil
+}
@@ -0,0 +1,192 @@
+package admin_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/usecase/admin"
+)
+
+var errBoom = errors.New("boom")
+
+// fakeArticleUsecase 根据ID决定结果：1xx 成功，4xx 不存在，5xx 内部错误
+type fakeArticleUsecase struct {
+	domain.ArticleUsecase
+	deleted []int64
+	hidden  map[int64]bool
+}
+
+func (f *fakeArticleUsecase) result(id int64) error {
+	switch {
+	case id >= 500:
+		return errBoom
+	case id >= 400:
+		return domain.ErrNotFound
+	default:
+		return nil
+	}
+}
+
+func (f *fakeArticleUsecase) Delete(_ context.Context, id int64, _ int64) error {
+	if err := f.result(id); err != nil {
+		return err
+	}
+	f.deleted = append(f.deleted, id)
+	return nil
+}
+
+func (f *fakeArticleUsecase) SetHidden(_ context.Context, id int64, hidden bool) error {
+	if err := f.result(id); err != nil {
+		return err
+	}
+	f.hidden[id] = hidden
+	return nil
+}
+
+type fakeAuditRepo struct {
+	logs []domain.AuditLog
+	err  error
+}
+
+func (f *fakeAuditRepo) BatchStore(_ context.Context, logs []domain.AuditLog) error {
+	f.logs = append(f.logs, logs...)
+	return f.err
+}
+
+func TestBulkDeleteMixedResults(t *testing.T) {
+	articles := &fakeArticleUsecase{hidden: map[int64]bool{}}
+	audit := &fakeAuditRepo{}
+	svc := admin.NewService(articles, audit, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+
+	results, err := svc.BulkModerateArticles(context.Background(), 9, domain.ModerationDelete, []int64{101, 404, 500, 102})
+	require.NoError(t, err)
+
+	require.Len(t, results, 4)
+	assert.Equal(t, domain.BulkResult{ID: 101, Status: domain.BulkResultOK}, results[0])
+	assert.Equal(t, domain.BulkResult{ID: 404, Status: domain.BulkResultNotFound}, results[1])
+	assert.Equal(t, domain.BulkResult{ID: 500, Status: domain.BulkResultError, Error: errBoom.Error()}, results[2])
+	assert.Equal(t, domain.BulkResult{ID: 102, Status: domain.BulkResultOK}, results[3])
+	assert.Equal(t, []int64{101, 102}, articles.deleted)
+
+	require.Len(t, audit.logs, 4)
+	for i, l := range audit.logs {
+		assert.Equal(t, int64(9), l.ActorID)
+		assert.Equal(t, "delete", l.Action)
+		assert.Equal(t, "article", l.TargetType)
+		assert.Equal(t, results[i].ID, l.TargetID)
+		assert.Equal(t, results[i].Status, l.Result)
+	}
+}
+
+func TestBulkHideAndUnhide(t *testing.T) {
+	articles := &fakeArticleUsecase{hidden: map[int64]bool{}}
+	svc := admin.NewService(articles, &fakeAuditRepo{}, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	ctx := context.Background()
+
+	results, err := svc.BulkModerateArticles(ctx, 1, domain.ModerationHide, []int64{1, 2, 401})
+	require.NoError(t, err)
+	assert.Equal(t, domain.BulkResultNotFound, results[2].Status)
+	assert.Equal(t, map[int64]bool{1: true, 2: true}, articles.hidden)
+
+	_, err = svc.BulkModerateArticles(ctx, 1, domain.ModerationUnhide, []int64{2})
+	require.NoError(t, err)
+	assert.Equal(t, map[int64]bool{1: true, 2: false}, articles.hidden)
+}
+
+func TestBulkModerationAuditFailureDoesNotFailBatch(t *testing.T) {
+	svc := admin.NewService(&fakeArticleUsecase{hidden: map[int64]bool{}}, &fakeAuditRepo{err: errBoom}, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+
+	results, err := svc.BulkModerateArticles(context.Background(), 1, domain.ModerationDelete, []int64{1})
+	require.NoError(t, err)
+	assert.Equal(t, domain.BulkResultOK, results[0].Status)
+}
+
+func TestBulkModerationRejectsBadInput(t *testing.T) {
+	svc := admin.NewService(&fakeArticleUsecase{}, &fakeAuditRepo{}, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	ctx := context.Background()
+
+	_, err := svc.BulkModerateArticles(ctx, 1, "publish", []int64{1})
+	assert.ErrorIs(t, err, domain.ErrBadParamInput)
+
+	_, err = svc.BulkModerateArticles(ctx, 1, domain.ModerationDelete, nil)
+	assert.ErrorIs(t, err, domain.ErrBadParamInput)
+
+	_, err = svc.BulkModerateArticles(ctx, 1, domain.ModerationDelete, make([]int64, domain.MaxBulkModerationSize+1))
+	assert.ErrorIs(t, err, domain.ErrBadParamInput)
+}
+
+type fakeSettings struct{}
+
+func (fakeSettings) BloomEnabled() bool        { return true }
+func (fakeSettings) ViewCountingEnabled() bool { return true }
+func (fakeSettings) RankViewWeight() float64   { return 0 }
+
+type fakeSettingsRepo struct {
+	domain.SettingsRepository
+	values map[string]string
+}
+
+func (f *fakeSettingsRepo) SetMany(_ context.Context, values map[string]string) error {
+	for k, v := range values {
+		f.values[k] = v
+	}
+	return nil
+}
+
+func TestUpdateSettings(t *testing.T) {
+	audit := &fakeAuditRepo{}
+	repo := &fakeSettingsRepo{values: map[string]string{}}
+	svc := admin.NewService(&fakeArticleUsecase{}, audit, fakeSettings{}, repo, nil, nil, nil, nil, nil, nil, nil)
+
+	res, err := svc.UpdateSettings(context.Background(), 9, map[string]any{
+		domain.SettingBloomEnabled:   false,
+		domain.SettingRankViewWeight: 1.5,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]string{"bloom_enabled": "false", "rank_view_weight": "1.5"}, repo.values)
+	assert.Equal(t, map[string]any{
+		"bloom_enabled":         false,
+		"view_counting_enabled": true,
+		"rank_view_weight":      1.5,
+	}, res)
+
+	require.Len(t, audit.logs, 2)
+	details := []string{audit.logs[0].Detail, audit.logs[1].Detail}
+	assert.ElementsMatch(t, []string{"bloom_enabled=false", "rank_view_weight=1.5"}, details)
+	for _, l := range audit.logs {
+		assert.Equal(t, int64(9), l.ActorID)
+		assert.Equal(t, "setting", l.TargetType)
+	}
+}
+
+func TestUpdateSettingsRejectsInvalidValues(t *testing.T) {
+	cases := []struct {
+		name   string
+		values map[string]any
+	}{
+		{"empty", map[string]any{}},
+		{"unknown key", map[string]any{"rate_limit": 10.0}},
+		{"wrong type", map[string]any{domain.SettingBloomEnabled: "false"}},
+		{"out of range", map[string]any{domain.SettingRankViewWeight: -1.0}},
+		{"one invalid among valid", map[string]any{domain.SettingViewCountingEnabled: false, domain.SettingRankViewWeight: 1000.0}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			audit := &fakeAuditRepo{}
+			repo := &fakeSettingsRepo{values: map[string]string{}}
+			svc := admin.NewService(&fakeArticleUsecase{}, audit, fakeSettings{}, repo, nil, nil, nil, nil, nil, nil, nil)
+
+			_, err := svc.UpdateSettings(context.Background(), 9, tc.values)
+			assert.ErrorIs(t, err, domain.ErrBadParamInput)
+			assert.Empty(t, repo.values)
+			assert.Empty(t, audit.logs)
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package admin
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+)
+
+const (
+	auditTargetSetting  = "setting"
+	auditActionSettings = "update_setting"
+
+	maxRankViewWeight = 100
+)
+
+// settingValidators 校验各个配置项的类型和范围，并编码为仓储中保存的字符串
+var settingValidators = map[string]func(v any) (string, bool){
+	domain.SettingBloomEnabled:        validateBool,
+	domain.SettingViewCountingEnabled: validateBool,
+	domain.SettingRankViewWeight:      validateFloat(0, maxRankViewWeight),
+}
+
+func validateBool(v any) (string, bool) {
+	b, ok := v.(bool)
+	if !ok {
+		return "", false
+	}
+	return strconv.FormatBool(b), true
+}
+
+func validateFloat(min, max float64) func(v any) (string, bool) {
+	return func(v any) (string, bool) {
+		f, ok := v.(float64)
+		if !ok || f < min || f > max {
+			return "", false
+		}
+		return strconv.FormatFloat(f, 'f', -1, 64), true
+	}
+}
+
+// GetSettings 返回本实例当前生效的配置
+func (s *service) GetSettings(_ context.Context) map[string]any {
+	return map[string]any{
+		domain.SettingBloomEnabled:        s.settings.BloomEnabled(),
+		domain.SettingViewCountingEnabled: s.settings.ViewCountingEnabled(),
+		domain.SettingRankViewWeight:      s.settings.RankViewWeight(),
+	}
+}
+
+// UpdateSettings 整体校验通过后才写入，每个修改的配置项记录一条审计日志
+func (s *service) UpdateSettings(ctx context.Context, actorID int64, values map[string]any) (map[string]any, error) {
+	if len(values) == 0 {
+		return nil, domain.ErrBadParamInput
+	}
+
+	encoded := make(map[string]string, len(values))
+	for key, v := range values {
+		validate, ok := settingValidators[key]
+		if !ok {
+			return nil, domain.ErrBadParamInput
+		}
+		raw, ok := validate(v)
+		if !ok {
+			return nil, domain.ErrBadParamInput
+		}
+		encoded[key] = raw
+	}
+
+	if err := s.settingsRepo.SetMany(ctx, encoded); err != nil {
+		return nil, err
+	}
+
+	logs := make([]domain.AuditLog, 0, len(encoded))
+	for key, raw := range encoded {
+		logs = append(logs, domain.AuditLog{
+			ActorID:    actorID,
+			Action:     auditActionSettings,
+			TargetType: auditTargetSetting,
+			Result:     domain.BulkResultOK,
+			Detail:     key + "=" + raw,
+			CreatedAt:  time.Now(),
+		})
+	}
+	if err := s.auditRepo.BatchStore(ctx, logs); err != nil {
+		logrus.Errorf("failed to store audit logs of admin %d: %v", actorID, err)
+	}
+
+	// 本实例的配置要等下一次刷新才生效，这里直接返回写入后的值
+	res := s.GetSettings(ctx)
+	for key, v := range values {
+		res[key] = v
+	}
+	return res, nil
+}
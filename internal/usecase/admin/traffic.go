@@ -0,0 +1,56 @@
+package admin
+
+import (
+	"context"
+	"time"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+)
+
+// Traffic 最近 TrafficCacheDays 天还没有写入数据库，从缓存读取，更早的日期从数据库读取。
+// 没有任何记录的日期也会返回，计数为空
+func (s *service) Traffic(ctx context.Context, days int) ([]domain.TrafficDay, error) {
+	if days < 1 || days > domain.MaxTrafficDays {
+		return nil, domain.ErrBadParamInput
+	}
+
+	now := time.Now()
+	y, m, d := now.Date()
+	today := time.Date(y, m, d, 0, 0, 0, 0, now.Location())
+	from := today.AddDate(0, 0, 1-days)
+	cacheFrom := today.AddDate(0, 0, 1-domain.TrafficCacheDays)
+
+	stored := make(map[string]domain.TrafficDay)
+	if from.Before(cacheFrom) {
+		list, err := s.trafficRepo.FetchTraffic(ctx, from, cacheFrom.AddDate(0, 0, -1))
+		if err != nil {
+			return nil, err
+		}
+		for _, day := range list {
+			stored[day.Date] = day
+		}
+	}
+
+	res := make([]domain.TrafficDay, 0, days)
+	for day := from; !day.After(today); day = day.AddDate(0, 0, 1) {
+		if day.Before(cacheFrom) {
+			td, ok := stored[day.Format(domain.TrafficDateLayout)]
+			if !ok {
+				td = domain.NewTrafficDay(day)
+			}
+			res = append(res, td)
+			continue
+		}
+
+		td := domain.NewTrafficDay(day)
+		for _, metric := range domain.TrafficMetrics {
+			counts, err := s.articleCache.GetTraffic(ctx, metric, day)
+			if err != nil {
+				return nil, err
+			}
+			*td.Counts(metric) = counts
+		}
+		res = append(res, td)
+	}
+	return res, nil
+}
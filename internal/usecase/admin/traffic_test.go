@@ -0,0 +1,92 @@
+package admin_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/usecase/admin"
+)
+
+// fakeTrafficCache 按日期返回预置的浏览来源计数，记录被查询的日期
+type fakeTrafficCache struct {
+	domain.ArticleCache
+	views   map[string]int64
+	queried []string
+}
+
+func (f *fakeTrafficCache) GetTraffic(_ context.Context, metric domain.TrafficMetric, day time.Time) (domain.TrafficCounts, error) {
+	date := day.Format(domain.TrafficDateLayout)
+	f.queried = append(f.queried, date)
+	counts := domain.NewTrafficCounts()
+	if n, ok := f.views[date]; ok && metric == domain.TrafficViews {
+		counts.Referrers["google.com"] = n
+	}
+	return counts, nil
+}
+
+type fakeTrafficRepo struct {
+	domain.TrafficRepository
+	days     []domain.TrafficDay
+	from, to string
+}
+
+func (f *fakeTrafficRepo) FetchTraffic(_ context.Context, from, to time.Time) ([]domain.TrafficDay, error) {
+	f.from = from.Format(domain.TrafficDateLayout)
+	f.to = to.Format(domain.TrafficDateLayout)
+	return f.days, nil
+}
+
+func TestTraffic(t *testing.T) {
+	now := time.Now()
+	date := func(offset int) string {
+		return now.AddDate(0, 0, offset).Format(domain.TrafficDateLayout)
+	}
+
+	stored := domain.NewTrafficDay(now.AddDate(0, 0, -3))
+	stored.Views.Devices["mobile"] = 7
+	repo := &fakeTrafficRepo{days: []domain.TrafficDay{stored}}
+	cache := &fakeTrafficCache{views: map[string]int64{date(0): 3, date(-1): 5}}
+	svc := admin.NewService(nil, nil, nil, nil, nil, cache, nil, nil, nil, nil, repo)
+
+	days, err := svc.Traffic(context.Background(), 5)
+	require.NoError(t, err)
+	require.Len(t, days, 5)
+
+	// 从旧到新排列，没有记录的日期计数为空
+	for i, day := range days {
+		assert.Equal(t, date(i-4), day.Date)
+	}
+	assert.True(t, days[0].Views.IsEmpty())
+	assert.Equal(t, map[string]int64{"mobile": 7}, days[1].Views.Devices)
+	assert.True(t, days[2].Views.IsEmpty())
+	assert.Equal(t, int64(5), days[3].Views.Referrers["google.com"])
+	assert.Equal(t, int64(3), days[4].Views.Referrers["google.com"])
+
+	// 今天和昨天读缓存，更早的读数据库
+	assert.Equal(t, date(-4), repo.from)
+	assert.Equal(t, date(-2), repo.to)
+	assert.ElementsMatch(t, []string{date(-1), date(-1), date(0), date(0)}, cache.queried)
+}
+
+func TestTrafficRecentDaysSkipDB(t *testing.T) {
+	repo := &fakeTrafficRepo{}
+	svc := admin.NewService(nil, nil, nil, nil, nil, &fakeTrafficCache{}, nil, nil, nil, nil, repo)
+
+	days, err := svc.Traffic(context.Background(), 1)
+	require.NoError(t, err)
+	require.Len(t, days, 1)
+	assert.Empty(t, repo.from)
+}
+
+func TestTrafficDaysOutOfRange(t *testing.T) {
+	svc := admin.NewService(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	for _, days := range []int{0, domain.MaxTrafficDays + 1} {
+		_, err := svc.Traffic(context.Background(), days)
+		require.ErrorIs(t, err, domain.ErrBadParamInput)
+	}
+}
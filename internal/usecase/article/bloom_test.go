@@ -0,0 +1,79 @@
+package article_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/repository"
+)
+
+func TestGetByIDWithBloomDisabledPassesThrough(t *testing.T) {
+	repo := &fakeArticleRepo{articles: map[int64]domain.Article{7: {ID: 7, Title: "t"}}}
+	svc := newService(serviceDeps{repo: repo, bloom: repository.NewNoopBloomRepository()})
+
+	ar, err := svc.GetByID(context.Background(), 7)
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), ar.ID)
+
+	// 关闭布隆过滤器后，不存在的文章由下层返回 ErrNotFound
+	_, err = svc.GetByID(context.Background(), 8)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
+
+func TestGetByIDRejectedByBloom(t *testing.T) {
+	repo := &fakeArticleRepo{articles: map[int64]domain.Article{7: {ID: 7}}}
+	svc := newService(serviceDeps{repo: repo, bloom: missingBloom{}})
+
+	_, err := svc.GetByID(context.Background(), 7)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
+
+// endlessIDsRepo 模拟一张很大的表，FetchIDs 永远返回下一批
+type endlessIDsRepo struct {
+	domain.ArticleRepository
+	batches atomic.Int64
+}
+
+func (r *endlessIDsRepo) FetchIDs(_ context.Context, cursor, limit int64) ([]int64, error) {
+	r.batches.Add(1)
+	ids := make([]int64, limit)
+	for i := range ids {
+		ids[i] = cursor + int64(i) + 1
+	}
+	return ids, nil
+}
+
+// slowBloom 每批写入都需要一点时间，且不理会 ctx
+type slowBloom struct {
+	domain.BloomRepository
+}
+
+func (slowBloom) BulkAdd(context.Context, []int64) error {
+	time.Sleep(5 * time.Millisecond)
+	return nil
+}
+
+func TestInitBloomFilterStopsOnCancel(t *testing.T) {
+	repo := &endlessIDsRepo{}
+	svc := newService(serviceDeps{repo: repo, bloom: slowBloom{}})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- svc.InitBloomFilter(ctx) }()
+
+	require.Eventually(t, func() bool { return repo.batches.Load() > 10 }, time.Second, time.Millisecond)
+	cancel()
+
+	select {
+	case err := <-done:
+		assert.ErrorIs(t, err, context.Canceled)
+	case <-time.After(500 * time.Millisecond):
+		t.Fatal("InitBloomFilter did not return after the context was canceled")
+	}
+}
@@ -0,0 +1,31 @@
+package article_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+)
+
+func TestSetCommentsLockedOnlyAuthorOrAdmin(t *testing.T) {
+	ctx := context.Background()
+	repo := &fakeArticleRepo{articles: map[int64]domain.Article{
+		1: {ID: 1, User: domain.User{ID: 7}},
+	}}
+	svc := newService(serviceDeps{repo: repo, titleIndex: newFakeTitleIndex()})
+
+	err := svc.SetCommentsLocked(ctx, 1, domain.User{ID: 8, Role: domain.RoleUser}, true)
+	require.ErrorIs(t, err, domain.ErrForbidden)
+	assert.False(t, repo.articles[1].CommentsLocked)
+
+	require.NoError(t, svc.SetCommentsLocked(ctx, 1, domain.User{ID: 7, Role: domain.RoleUser}, true))
+	assert.True(t, repo.articles[1].CommentsLocked)
+
+	require.NoError(t, svc.SetCommentsLocked(ctx, 1, domain.User{ID: 9, Role: domain.RoleAdmin}, false))
+	assert.False(t, repo.articles[1].CommentsLocked)
+
+	require.ErrorIs(t, svc.SetCommentsLocked(ctx, 2, domain.User{ID: 7}, true), domain.ErrNotFound)
+}
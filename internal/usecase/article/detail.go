@@ -0,0 +1,40 @@
+package article
+
+import (
+	"context"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+)
+
+// GetDetail 一次返回详情页需要的全部数据。文章（含标签、点赞数和用户状态）与评论数并发读取，
+// 点赞数直接取文章上的实时计数，不再单独查询
+func (a *service) GetDetail(ctx context.Context, id int64, viewerID int64) (domain.ArticleDetail, error) {
+	var (
+		art      domain.Article
+		comments map[int64]int64
+	)
+	g, gctx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		var err error
+		art, err = a.GetByIDForViewer(gctx, id, viewerID)
+		return err
+	})
+	g.Go(func() error {
+		var err error
+		comments, err = a.commentRepo.CountByArticles(gctx, []int64{id})
+		return err
+	})
+	if err := g.Wait(); err != nil {
+		return domain.ArticleDetail{}, err
+	}
+
+	if art.Tags == nil {
+		art.Tags = []string{}
+	}
+	return domain.ArticleDetail{
+		Article:    art,
+		Engagement: domain.Engagement{Likes: art.Likes, Comments: comments[id]},
+	}, nil
+}
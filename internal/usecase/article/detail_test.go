@@ -0,0 +1,51 @@
+package article_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+)
+
+func newDetailService() (domain.ArticleUsecase, *commentCountRepo) {
+	repo, cache := newViewerFixture()
+	repo.articles[1] = domain.Article{ID: 1, Title: "t", Likes: 5, Tags: []string{"go", "redis"}}
+	repo.articles[2] = domain.Article{ID: 2, Title: "untagged"}
+	comments := &commentCountRepo{counts: map[int64]int64{1: 3}}
+	return newService(serviceDeps{repo: repo, cache: cache, comments: comments}), comments
+}
+
+func TestGetDetail(t *testing.T) {
+	svc, comments := newDetailService()
+
+	detail, err := svc.GetDetail(context.Background(), 1, 7)
+	require.NoError(t, err)
+	assert.Equal(t, "t", detail.Article.Title)
+	assert.Equal(t, []string{"go", "redis"}, detail.Article.Tags)
+	assert.Equal(t, domain.Engagement{Likes: 5, Comments: 3}, detail.Engagement)
+	require.NotNil(t, detail.Article.Viewer)
+	require.NotNil(t, detail.Article.Viewer.Liked)
+	assert.True(t, *detail.Article.Viewer.Liked)
+	// 评论数只查询一次
+	assert.Equal(t, []int64{1}, comments.queried)
+}
+
+func TestGetDetailAnonymous(t *testing.T) {
+	svc, _ := newDetailService()
+
+	detail, err := svc.GetDetail(context.Background(), 2, 0)
+	require.NoError(t, err)
+	assert.Nil(t, detail.Article.Viewer)
+	assert.Equal(t, []string{}, detail.Article.Tags)
+	assert.Equal(t, domain.Engagement{}, detail.Engagement)
+}
+
+func TestGetDetailNotFound(t *testing.T) {
+	svc, _ := newDetailService()
+
+	_, err := svc.GetDetail(context.Background(), 404, 7)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
@@ -0,0 +1,63 @@
+package article
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+)
+
+// FetchDrafts 获取用户自己的草稿，按ID倒序，cursor 为上一页最后一篇草稿的ID
+func (a *service) FetchDrafts(ctx context.Context, userID int64, cursor string, num int64) ([]domain.Article, string, error) {
+	drafts, hasMore, err := a.articleRepo.FetchDrafts(ctx, userID, cursor, num)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(drafts) == 0 {
+		return drafts, "", nil
+	}
+
+	var nextCursor string
+	if hasMore {
+		nextCursor = strconv.FormatInt(drafts[len(drafts)-1].ID, 10)
+	}
+	return drafts, nextCursor, nil
+}
+
+// Publish 发布草稿，只有作者本人可以操作，其他人和已发布的文章都返回 ErrNotFound。
+// 草稿创建时已经在布隆过滤器中，发布后才加入标题索引，返回值是发布后的文章。
+// 发布后用 GetByIDs 重新读取，GetByID 会把作者的发布操作计为一次浏览
+func (a *service) Publish(ctx context.Context, id int64, userID int64) (domain.Article, error) {
+	if _, err := a.articleRepo.GetDraft(ctx, id, userID); err != nil {
+		return domain.Article{}, err
+	}
+	if err := a.articleRepo.Publish(ctx, id); err != nil {
+		return domain.Article{}, err
+	}
+
+	ars, err := a.articleRepo.GetByIDs(ctx, []int64{id})
+	if err != nil {
+		return domain.Article{}, err
+	}
+	if len(ars) == 0 {
+		return domain.Article{}, domain.ErrNotFound
+	}
+	ar := ars[0]
+	if err := a.titleIndex.Set(ctx, id, ar.Title); err != nil {
+		logrus.Warnf("failed to add article %d to title index: %v", id, err)
+	}
+	ar.Excerpt = generateExcerpt(ar.Content, a.excerpt)
+	return ar, nil
+}
+
+// getDraft 读取作者本人的草稿，格式和 GetByID 的返回一致
+func (a *service) getDraft(ctx context.Context, id int64, userID int64) (domain.Article, error) {
+	ar, err := a.articleRepo.GetDraft(ctx, id, userID)
+	if err != nil {
+		return domain.Article{}, err
+	}
+	ar.Excerpt = generateExcerpt(ar.Content, a.excerpt)
+	return ar, nil
+}
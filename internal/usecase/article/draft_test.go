@@ -0,0 +1,188 @@
+package article_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/repository"
+	myRedis "github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/repository/redis"
+)
+
+// setBloom 用集合模拟布隆过滤器，记录加入过的ID
+type setBloom struct {
+	domain.BloomRepository
+	ids map[int64]bool
+}
+
+func (b *setBloom) Exists(_ context.Context, id int64) (bool, error) { return b.ids[id], nil }
+
+func (b *setBloom) Add(_ context.Context, id int64) error {
+	b.ids[id] = true
+	return nil
+}
+
+func (b *setBloom) BulkAdd(_ context.Context, ids []int64) error {
+	for _, id := range ids {
+		b.ids[id] = true
+	}
+	return nil
+}
+
+func TestDraftVisibleOnlyAfterPublish(t *testing.T) {
+	ctx := context.Background()
+	repo := &fakeArticleRepo{articles: map[int64]domain.Article{}}
+	bloom := &setBloom{ids: map[int64]bool{}}
+	index := newFakeTitleIndex()
+	svc := newService(serviceDeps{repo: repo, bloom: bloom, titleIndex: index})
+
+	ar := &domain.Article{ID: 1, Title: "草稿标题", Content: "正文", User: domain.User{ID: 7}, Status: domain.ArticleStatusDraft}
+	require.NoError(t, svc.Store(ctx, ar))
+	repo.articles[1] = *ar
+	// 草稿不进入标题联想
+	assert.Empty(t, index.titles)
+
+	_, err := svc.GetByID(ctx, 1)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+
+	// 其他人发布、编辑或删除都和文章不存在一样
+	_, err = svc.Publish(ctx, 1, 8)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+	assert.ErrorIs(t, svc.Update(ctx, &domain.Article{ID: 1, Title: "改", User: domain.User{ID: 8}}), domain.ErrNotFound)
+	assert.ErrorIs(t, svc.Delete(ctx, 1, 8), domain.ErrNotFound)
+
+	// 作者可以继续编辑草稿
+	edit := &domain.Article{ID: 1, Content: "新正文", User: domain.User{ID: 7}}
+	require.NoError(t, svc.Update(ctx, edit))
+	assert.Equal(t, domain.ArticleStatusDraft, edit.Status)
+	assert.Empty(t, index.titles)
+
+	published, err := svc.Publish(ctx, 1, 7)
+	require.NoError(t, err)
+	assert.Equal(t, domain.ArticleStatusPublished, published.Status)
+	assert.Equal(t, map[int64]string{1: "草稿标题"}, index.titles)
+
+	_, err = svc.GetByID(ctx, 1)
+	require.NoError(t, err)
+
+	// 已发布的文章不能再次发布
+	_, err = svc.Publish(ctx, 1, 7)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
+
+func TestDraftPassesBloomExistenceCheck(t *testing.T) {
+	ctx := context.Background()
+	repo := &fakeArticleRepo{articles: map[int64]domain.Article{}}
+	bloom := &setBloom{ids: map[int64]bool{}}
+	svc := newService(serviceDeps{repo: repo, bloom: bloom, titleIndex: newFakeTitleIndex()})
+
+	ar := &domain.Article{ID: 1, Title: "草稿", Content: "正文", User: domain.User{ID: 7}, Status: domain.ArticleStatusDraft}
+	require.NoError(t, svc.Store(ctx, ar))
+	repo.articles[1] = *ar
+
+	exists, err := bloom.Exists(ctx, 1)
+	require.NoError(t, err)
+	assert.True(t, exists, "drafts exist even though they are not published")
+
+	// 通过了布隆过滤器，作者编辑草稿时能找到文章，而不是被当作不存在
+	require.NoError(t, svc.Update(ctx, &domain.Article{ID: 1, Title: "新标题", User: domain.User{ID: 7}}))
+
+	// 布隆过滤器为空时，已有的草稿同样会被重新加入
+	bloom.ids = map[int64]bool{}
+	require.NoError(t, svc.InitBloomFilter(ctx))
+	assert.True(t, bloom.ids[1])
+}
+
+func TestStoreRejectsUnknownStatus(t *testing.T) {
+	repo := &fakeArticleRepo{articles: map[int64]domain.Article{}}
+	svc := newService(serviceDeps{repo: repo, titleIndex: newFakeTitleIndex()})
+
+	err := svc.Store(context.Background(), &domain.Article{Title: "t", Content: "c", Status: "archived"})
+	assert.ErrorIs(t, err, domain.ErrBadParamInput)
+	assert.Empty(t, repo.stored)
+
+	// 不指定状态时直接发布
+	ar := &domain.Article{Title: "t", Content: "c"}
+	require.NoError(t, svc.Store(context.Background(), ar))
+	assert.Equal(t, domain.ArticleStatusPublished, ar.Status)
+}
+
+// draftDB 只有用户 7 的草稿 1
+type draftDB struct {
+	domain.ArticleDBRepository
+	ar domain.Article
+}
+
+func (d *draftDB) GetDraft(_ context.Context, id, userID int64) (domain.Article, error) {
+	if id != d.ar.ID || userID != d.ar.User.ID || d.ar.Status != domain.ArticleStatusDraft {
+		return domain.Article{}, domain.ErrNotFound
+	}
+	return d.ar, nil
+}
+
+func (d *draftDB) Publish(context.Context, int64) error {
+	d.ar.Status = domain.ArticleStatusPublished
+	return nil
+}
+
+func (d *draftDB) GetByIDs(_ context.Context, ids []int64) ([]domain.Article, error) {
+	if len(ids) != 1 || ids[0] != d.ar.ID || d.ar.Status != domain.ArticleStatusPublished {
+		return nil, nil
+	}
+	return []domain.Article{d.ar}, nil
+}
+
+type authorRepo struct {
+	domain.UserRepository
+}
+
+func (authorRepo) GetByID(_ context.Context, id int64) (domain.User, error) {
+	return domain.User{ID: id, Name: "author"}, nil
+}
+
+func (authorRepo) GetByIDs(_ context.Context, ids []int64) ([]domain.User, error) {
+	res := make([]domain.User, len(ids))
+	for i, id := range ids {
+		res[i] = domain.User{ID: id, Name: "author"}
+	}
+	return res, nil
+}
+
+type weightSettingsRepo struct {
+	domain.SettingsRepository
+}
+
+func (weightSettingsRepo) GetAll(context.Context) (map[string]string, error) {
+	return map[string]string{domain.SettingRankViewWeight: "1"}, nil
+}
+
+func TestPublishDoesNotCountView(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	// 浏览会增加热榜分数，发布时如果计了浏览就能在 Redis 中看到
+	settings := repository.NewRuntimeSettings(weightSettingsRepo{})
+	require.NoError(t, settings.Refresh(ctx))
+	cache := myRedis.NewArticleCache(client, "", 0, 0)
+	db := &draftDB{ar: domain.Article{ID: 1, Title: "草稿", Content: "正文", User: domain.User{ID: 7}, Status: domain.ArticleStatusDraft}}
+	repo := repository.NewArticleRepository(db, cache, authorRepo{}, settings, true, nil)
+	svc := newService(serviceDeps{repo: repo, cache: cache, titleIndex: newFakeTitleIndex()})
+
+	ar, err := svc.Publish(ctx, 1, 7)
+	require.NoError(t, err)
+	assert.Equal(t, domain.ArticleStatusPublished, ar.Status)
+	assert.Equal(t, "author", ar.User.Name)
+	assert.Zero(t, ar.Views)
+
+	assert.False(t, mr.Exists(myRedis.KeyViewsBuffer), "publishing must not record a view")
+	rank, err := cache.GetDailyRank(ctx, 0, 10)
+	require.NoError(t, err)
+	assert.Empty(t, rank, "publishing must not add rank score")
+}
@@ -0,0 +1,96 @@
+package article_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+)
+
+const spamContent = "Buy cheap watches at example.com\nBest prices   on the web!"
+
+func newDuplicateService(existing ...domain.Article) (domain.ArticleUsecase, *fakeArticleRepo) {
+	repo := &fakeArticleRepo{articles: make(map[int64]domain.Article)}
+	for _, ar := range existing {
+		repo.articles[ar.ID] = ar
+	}
+	svc := newService(serviceDeps{repo: repo, titleIndex: newFakeTitleIndex()})
+	return svc, repo
+}
+
+func TestContentFingerprint(t *testing.T) {
+	fp := domain.ContentFingerprint(spamContent)
+	require.Len(t, fp, 64)
+
+	// 只有大小写和空白不同的内容指纹相同
+	for _, near := range []string{
+		"buy cheap watches at example.com best prices on the web!",
+		"  BUY CHEAP WATCHES AT EXAMPLE.COM\r\n\r\nBEST PRICES ON THE WEB!\t",
+	} {
+		assert.Equal(t, fp, domain.ContentFingerprint(near), near)
+	}
+
+	// 文字不同、空白位置不同或词序不同都是不同的内容
+	for _, other := range []string{
+		"Buy cheap watches at example.org\nBest prices on the web!",
+		"Buy cheap watches at example.com\nBest prices on the web",
+		"Best prices on the web! Buy cheap watches at example.com",
+		"Buy cheap watches at exam ple.com\nBest prices on the web!",
+	} {
+		assert.NotEqual(t, fp, domain.ContentFingerprint(other), other)
+	}
+
+	assert.Equal(t, domain.ContentFingerprint("中文 内容"), domain.ContentFingerprint("中文\n\n内容"))
+	assert.Empty(t, domain.ContentFingerprint(" \n\t"))
+}
+
+func TestStoreRejectsContentOfAnotherAuthor(t *testing.T) {
+	svc, repo := newDuplicateService(domain.Article{ID: 3, Title: "original", Content: spamContent, User: domain.User{ID: 1}})
+
+	ar := &domain.Article{Title: "different title", Content: strings.ToUpper(spamContent), User: domain.User{ID: 2}}
+	err := svc.Store(context.Background(), ar)
+
+	require.ErrorIs(t, err, domain.ErrConflict)
+	var conflict *domain.ConflictError
+	require.True(t, errors.As(err, &conflict))
+	assert.True(t, conflict.DuplicateContent)
+	assert.Equal(t, int64(3), conflict.ExistingID)
+	assert.Empty(t, repo.stored)
+}
+
+func TestStoreWarnsOnOwnDuplicate(t *testing.T) {
+	svc, repo := newDuplicateService(
+		domain.Article{ID: 5, Title: "repost", Content: spamContent, User: domain.User{ID: 1}},
+		domain.Article{ID: 3, Title: "original", Content: spamContent, User: domain.User{ID: 1}},
+	)
+
+	ar := &domain.Article{Title: "third time", Content: spamContent, User: domain.User{ID: 1}}
+	require.NoError(t, svc.Store(context.Background(), ar))
+
+	require.Len(t, repo.stored, 1)
+	// 指向作者最早的一篇
+	assert.Equal(t, int64(3), ar.DuplicateOf)
+}
+
+func TestStoreAcceptsDifferentContent(t *testing.T) {
+	svc, repo := newDuplicateService(domain.Article{ID: 3, Title: "original", Content: spamContent, User: domain.User{ID: 1}})
+
+	ar := &domain.Article{Title: "reply", Content: spamContent + " Not really, it is a scam.", User: domain.User{ID: 2}}
+	require.NoError(t, svc.Store(context.Background(), ar))
+
+	require.Len(t, repo.stored, 1)
+	assert.Zero(t, ar.DuplicateOf)
+}
+
+func BenchmarkContentFingerprint(b *testing.B) {
+	content := strings.Repeat("Lorem ipsum dolor sit amet, 中文内容  混排。\n", 2500)[:100<<10]
+	b.SetBytes(int64(len(content)))
+	for b.Loop() {
+		domain.ContentFingerprint(content)
+	}
+}
@@ -0,0 +1,64 @@
+package article
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+)
+
+// GetEngagement 点赞数优先读 Redis，Redis 不可用或没有计数的文章从文章详情（缓存未命中时读数据库）补齐；
+// 评论数按文章分组统计，一次查询完成
+func (a *service) GetEngagement(ctx context.Context, ids []int64) (map[int64]domain.Engagement, error) {
+	if len(ids) > domain.MaxEngagementBatch {
+		return nil, domain.ErrBadParamInput
+	}
+
+	res := make(map[int64]domain.Engagement, len(ids))
+	uniq := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		if _, ok := res[id]; ok {
+			continue
+		}
+		res[id] = domain.Engagement{}
+		uniq = append(uniq, id)
+	}
+	if len(uniq) == 0 {
+		return res, nil
+	}
+
+	likes, err := a.articleCache.MGetLikeCounts(ctx, uniq)
+	if err != nil {
+		logrus.Warnf("failed to get like counts from cache, falling back to articles: %v", err)
+	}
+	// 没有点赞计数的文章从文章详情补齐
+	var missing []int64
+	for _, id := range uniq {
+		if _, ok := likes[id]; !ok {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) > 0 {
+		articles, err := a.articleRepo.GetByIDs(ctx, missing)
+		if err != nil {
+			return nil, err
+		}
+		if likes == nil {
+			likes = make(map[int64]int64, len(articles))
+		}
+		for _, ar := range articles {
+			likes[ar.ID] = ar.Likes
+		}
+	}
+
+	comments, err := a.commentRepo.CountByArticles(ctx, uniq)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, id := range uniq {
+		res[id] = domain.Engagement{Likes: likes[id], Comments: comments[id]}
+	}
+	return res, nil
+}
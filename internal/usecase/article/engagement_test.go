@@ -0,0 +1,88 @@
+package article_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+)
+
+// likeCountsCache 返回预置的点赞数，没有预置的文章和 Redis 一样不在结果中；err 不为空时模拟 Redis 不可用
+type likeCountsCache struct {
+	domain.ArticleCache
+	likes map[int64]int64
+	err   error
+}
+
+func (c likeCountsCache) MGetLikeCounts(_ context.Context, ids []int64) (map[int64]int64, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	res := make(map[int64]int64)
+	for _, id := range ids {
+		if n, ok := c.likes[id]; ok {
+			res[id] = n
+		}
+	}
+	return res, nil
+}
+
+type commentCountRepo struct {
+	domain.CommentRepository
+	counts  map[int64]int64
+	queried []int64
+}
+
+func (r *commentCountRepo) CountByArticles(_ context.Context, ids []int64) (map[int64]int64, error) {
+	r.queried = append(r.queried, ids...)
+	res := make(map[int64]int64)
+	for _, id := range ids {
+		if n, ok := r.counts[id]; ok {
+			res[id] = n
+		}
+	}
+	return res, nil
+}
+
+func TestGetEngagement(t *testing.T) {
+	repo := &fakeArticleRepo{articles: map[int64]domain.Article{
+		1: {ID: 1, Likes: 1},
+		2: {ID: 2, Likes: 6},
+		3: {ID: 3},
+	}}
+	comments := &commentCountRepo{counts: map[int64]int64{1: 4, 3: 2}}
+	// 文章 1 的点赞数在 Redis 中，文章 2 没有缓存计数，从文章补齐
+	cache := likeCountsCache{likes: map[int64]int64{1: 9}}
+	svc := newService(serviceDeps{repo: repo, cache: cache, comments: comments})
+
+	res, err := svc.GetEngagement(context.Background(), []int64{1, 2, 3, 4, 1})
+	require.NoError(t, err)
+	assert.Equal(t, map[int64]domain.Engagement{
+		1: {Likes: 9, Comments: 4},
+		2: {Likes: 6, Comments: 0},
+		3: {Likes: 0, Comments: 2},
+		4: {}, // 不存在的文章计数为 0
+	}, res)
+	assert.Equal(t, []int64{1, 2, 3, 4}, comments.queried)
+}
+
+func TestGetEngagementCacheUnavailable(t *testing.T) {
+	repo := &fakeArticleRepo{articles: map[int64]domain.Article{1: {ID: 1, Likes: 5}}}
+	cache := likeCountsCache{err: errors.New("redis down")}
+	svc := newService(serviceDeps{repo: repo, cache: cache, comments: &commentCountRepo{}})
+
+	res, err := svc.GetEngagement(context.Background(), []int64{1})
+	require.NoError(t, err)
+	assert.Equal(t, domain.Engagement{Likes: 5}, res[1])
+}
+
+func TestGetEngagementTooManyIDs(t *testing.T) {
+	svc := newService(serviceDeps{repo: &fakeArticleRepo{}})
+
+	_, err := svc.GetEngagement(context.Background(), make([]int64, domain.MaxEngagementBatch+1))
+	require.ErrorIs(t, err, domain.ErrBadParamInput)
+}
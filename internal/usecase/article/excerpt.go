@@ -0,0 +1,70 @@
+package article
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+)
+
+var (
+	htmlScript   = regexp.MustCompile(`(?is)<(script|style)\b[^>]*>.*?</(script|style)>`)
+	htmlBlockEnd = regexp.MustCompile(`(?i)</(p|div|h[1-6]|li|ul|ol|blockquote|pre|table)>|<br\s*/?>`)
+	htmlTag      = regexp.MustCompile(`<[^>]*>`)
+)
+
+// generateExcerpt 按 strategy 从正文生成纯文本摘录，最多 domain.MaxExcerptRunes 个字符。
+// 正文可以是 markdown 或 HTML，先去掉标记再截取；未知的 strategy 按固定长度处理
+func generateExcerpt(content string, strategy domain.ExcerptStrategy) string {
+	paragraphs := plainParagraphs(content)
+	if len(paragraphs) == 0 {
+		return ""
+	}
+
+	switch strategy {
+	case domain.ExcerptFirstParagraph:
+		return ellipsize(paragraphs[0], domain.MaxExcerptRunes)
+	case domain.ExcerptFirstSentence:
+		return ellipsize(firstSentence(strings.Join(paragraphs, " ")), domain.MaxExcerptRunes)
+	default:
+		return ellipsize(strings.Join(paragraphs, " "), domain.MaxExcerptRunes)
+	}
+}
+
+// plainParagraphs 去掉 HTML 和 markdown 标记，返回有文字的段落。
+// HTML 的块级元素结束处视为段落分隔
+func plainParagraphs(content string) []string {
+	content = htmlScript.ReplaceAllString(content, "")
+	content = htmlBlockEnd.ReplaceAllString(content, "\n\n")
+	content = htmlTag.ReplaceAllString(content, "")
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	content = mdCodeFence.ReplaceAllString(content, "\n\n")
+
+	var res []string
+	for _, paragraph := range strings.Split(content, "\n\n") {
+		if text := stripMarkdown(paragraph); text != "" {
+			// 实体在去掉标记之后再解码，避免 &lt;b&gt; 这样的文字被当成标签去掉
+			res = append(res, html.UnescapeString(text))
+		}
+	}
+	return res
+}
+
+// firstSentence 返回第一个句子（含句末标点）。
+// 中文句号等全角标点直接断句，英文标点后面需要跟空白，避免在小数点处断开
+func firstSentence(text string) string {
+	runes := []rune(text)
+	for i, r := range runes {
+		switch r {
+		case '。', '！', '？':
+			return string(runes[:i+1])
+		case '.', '!', '?':
+			if i+1 == len(runes) || unicode.IsSpace(runes[i+1]) {
+				return string(runes[:i+1])
+			}
+		}
+	}
+	return text
+}
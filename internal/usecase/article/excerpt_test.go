@@ -0,0 +1,50 @@
+package article_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+)
+
+func excerptOf(t *testing.T, content string, strategy domain.ExcerptStrategy) string {
+	t.Helper()
+	repo := &fakeArticleRepo{articles: map[int64]domain.Article{1: {ID: 1, Content: content}}}
+	svc := newService(serviceDeps{repo: repo, excerpt: strategy})
+	ar, err := svc.GetByID(context.Background(), 1)
+	require.NoError(t, err)
+	return ar.Excerpt
+}
+
+func TestExcerptStrategies(t *testing.T) {
+	markdown := "# Title\n\nGo is **fun**. It has v1.22 and [tools](https://go.dev)!\n\nSecond paragraph."
+	htmlContent := "<h1>Title</h1><p>第一句。第二句&amp;更多</p><script>alert(1)</script><p>第二段</p>"
+	long := strings.Repeat("word ", 100)
+
+	cases := []struct {
+		name     string
+		content  string
+		strategy domain.ExcerptStrategy
+		excerpt  string
+	}{
+		{"fixed markdown", markdown, domain.ExcerptFixedLength, "Go is fun. It has v1.22 and tools! Second paragraph."},
+		{"paragraph markdown", markdown, domain.ExcerptFirstParagraph, "Go is fun. It has v1.22 and tools!"},
+		{"sentence markdown", markdown, domain.ExcerptFirstSentence, "Go is fun."},
+		{"fixed html", htmlContent, domain.ExcerptFixedLength, "Title 第一句。第二句&更多 第二段"},
+		{"paragraph html", htmlContent, domain.ExcerptFirstParagraph, "Title"},
+		{"sentence html", htmlContent, domain.ExcerptFirstSentence, "Title 第一句。"},
+		{"fixed truncated", long, domain.ExcerptFixedLength, strings.TrimSpace(long[:len("word ")*31+len("word")]) + "…"},
+		{"sentence without end", "no ending here", domain.ExcerptFirstSentence, "no ending here"},
+		{"empty", "<p></p>", domain.ExcerptFirstSentence, ""},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.excerpt, excerptOf(t, tc.content, tc.strategy))
+		})
+	}
+}
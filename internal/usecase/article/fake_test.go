@@ -0,0 +1,414 @@
+package article_test
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/usecase/article"
+)
+
+// serviceDeps 是 article.NewService 的依赖，测试只填用到的字段。
+// bloom 默认总是命中，excerpt 默认按长度截取，其余依赖默认为 nil
+type serviceDeps struct {
+	repo          domain.ArticleRepository
+	cache         domain.ArticleCache
+	likes         domain.SyncLikesWorker
+	bloom         domain.BloomRepository
+	reactions     domain.ReactionRepository
+	reactionCache domain.ReactionCache
+	excerpt       domain.ExcerptStrategy
+	titleIndex    domain.TitleIndex
+	comments      domain.CommentRepository
+	summarizer    domain.Summarizer
+}
+
+// newService 按 deps 创建 article usecase，所有测试都通过它创建服务
+func newService(deps serviceDeps) domain.ArticleUsecase {
+	if deps.bloom == nil {
+		deps.bloom = fakeBloom{}
+	}
+	if deps.excerpt == "" {
+		deps.excerpt = domain.ExcerptFixedLength
+	}
+	return article.NewService(deps.repo, deps.cache, deps.likes, deps.bloom, deps.reactions, deps.reactionCache,
+		deps.excerpt, deps.titleIndex, deps.comments, deps.summarizer)
+}
+
+// 测试用的内存实现，只实现用到的方法，其余方法由内嵌接口兜底（调用即 panic）
+
+type fakeBloom struct {
+	domain.BloomRepository
+}
+
+func (fakeBloom) Exists(context.Context, int64) (bool, error) { return true, nil }
+
+func (fakeBloom) Add(context.Context, int64) error { return nil }
+
+type fakeArticleCache struct {
+	domain.ArticleCache
+	mu    sync.Mutex
+	liked map[domain.UserLike]bool
+	likes map[int64]int64
+}
+
+func newFakeArticleCache() *fakeArticleCache {
+	return &fakeArticleCache{
+		liked: make(map[domain.UserLike]bool),
+		likes: make(map[int64]int64),
+	}
+}
+
+func (c *fakeArticleCache) AddLikeRecord(_ context.Context, ul domain.UserLike) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.liked[ul] {
+		return false, nil
+	}
+	c.liked[ul] = true
+	c.likes[ul.ArticleID]++
+	return true, nil
+}
+
+func (c *fakeArticleCache) DecrLikeRecord(_ context.Context, ul domain.UserLike) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.liked[ul] {
+		return false, nil
+	}
+	delete(c.liked, ul)
+	c.likes[ul.ArticleID]--
+	return true, nil
+}
+
+func (c *fakeArticleCache) GetLikeCount(_ context.Context, aid int64) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.likes[aid], nil
+}
+
+type fakeLikesWorker struct {
+	sent []domain.LikeAction
+}
+
+func (w *fakeLikesWorker) Start(context.Context) {}
+
+func (w *fakeLikesWorker) QueueDepth() int { return len(w.sent) }
+
+func (w *fakeLikesWorker) Send(_ domain.UserLike, action domain.LikeAction) {
+	w.sent = append(w.sent, action)
+}
+
+type fakeReactionRepo struct {
+	rows map[domain.Reaction]bool
+}
+
+func newFakeReactionRepo() *fakeReactionRepo {
+	return &fakeReactionRepo{rows: make(map[domain.Reaction]bool)}
+}
+
+func (r *fakeReactionRepo) Add(_ context.Context, re domain.Reaction) (bool, error) {
+	if r.rows[re] {
+		return false, nil
+	}
+	r.rows[re] = true
+	return true, nil
+}
+
+func (r *fakeReactionRepo) Remove(_ context.Context, re domain.Reaction) (bool, error) {
+	if !r.rows[re] {
+		return false, nil
+	}
+	delete(r.rows, re)
+	return true, nil
+}
+
+func (r *fakeReactionRepo) DeleteByArticle(_ context.Context, aid int64) error {
+	for re := range r.rows {
+		if re.ArticleID == aid {
+			delete(r.rows, re)
+		}
+	}
+	return nil
+}
+
+func (r *fakeReactionRepo) CountByArticle(_ context.Context, aid int64) (domain.ReactionCounts, error) {
+	counts := make(domain.ReactionCounts)
+	for _, t := range domain.ReactionTypes {
+		counts[t] = 0
+	}
+	for re := range r.rows {
+		if re.ArticleID == aid {
+			counts[re.Type]++
+		}
+	}
+	return counts, nil
+}
+
+type fakeReactionCache struct {
+	counts map[int64]domain.ReactionCounts
+}
+
+func newFakeReactionCache() *fakeReactionCache {
+	return &fakeReactionCache{counts: make(map[int64]domain.ReactionCounts)}
+}
+
+func (c *fakeReactionCache) GetReactionCounts(_ context.Context, aid int64) (domain.ReactionCounts, error) {
+	counts, ok := c.counts[aid]
+	if !ok {
+		return nil, domain.ErrCacheMiss
+	}
+	res := make(domain.ReactionCounts, len(counts))
+	for t, n := range counts {
+		res[t] = n
+	}
+	return res, nil
+}
+
+func (c *fakeReactionCache) SetReactionCounts(_ context.Context, aid int64, counts domain.ReactionCounts) error {
+	c.counts[aid] = make(domain.ReactionCounts)
+	for t, n := range counts {
+		if t != domain.ReactionLike {
+			c.counts[aid][t] = n
+		}
+	}
+	return nil
+}
+
+func (c *fakeReactionCache) IncrReactionCount(_ context.Context, aid int64, t domain.ReactionType, delta int64) error {
+	if counts, ok := c.counts[aid]; ok {
+		counts[t] += delta
+	}
+	return nil
+}
+
+type fakeArticleRepo struct {
+	domain.ArticleRepository
+	articles map[int64]domain.Article
+	stored   []domain.Article
+	updated  []domain.Article
+	deleted  map[int64]domain.Article
+}
+
+func (r *fakeArticleRepo) GetByTitle(_ context.Context, title string) (domain.Article, error) {
+	for _, ar := range r.articles {
+		if ar.Title == title {
+			return ar, nil
+		}
+	}
+	return domain.Article{}, domain.ErrNotFound
+}
+
+func (r *fakeArticleRepo) FindByFingerprint(_ context.Context, fp string) ([]domain.Article, error) {
+	var res []domain.Article
+	for _, ar := range r.articles {
+		if domain.ContentFingerprint(ar.Content) == fp {
+			res = append(res, ar)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].ID < res[j].ID })
+	return res, nil
+}
+
+func (r *fakeArticleRepo) Store(_ context.Context, ar *domain.Article) error {
+	r.stored = append(r.stored, *ar)
+	return nil
+}
+
+func (r *fakeArticleRepo) Update(_ context.Context, ar *domain.Article) error {
+	r.updated = append(r.updated, *ar)
+	return nil
+}
+
+func (r *fakeArticleRepo) GetByID(_ context.Context, id int64) (domain.Article, error) {
+	ar, ok := r.articles[id]
+	if !ok || ar.Status == domain.ArticleStatusDraft {
+		return domain.Article{}, domain.ErrNotFound
+	}
+	return ar, nil
+}
+
+func (r *fakeArticleRepo) GetDraft(_ context.Context, id int64, userID int64) (domain.Article, error) {
+	ar, ok := r.articles[id]
+	if !ok || ar.Status != domain.ArticleStatusDraft || ar.User.ID != userID {
+		return domain.Article{}, domain.ErrNotFound
+	}
+	return ar, nil
+}
+
+func (r *fakeArticleRepo) Publish(_ context.Context, id int64) error {
+	ar, ok := r.articles[id]
+	if !ok || ar.Status != domain.ArticleStatusDraft {
+		return domain.ErrNotFound
+	}
+	ar.Status = domain.ArticleStatusPublished
+	r.articles[id] = ar
+	return nil
+}
+
+// missingBloom 模拟一个认为所有ID都不存在的布隆过滤器
+type missingBloom struct {
+	domain.BloomRepository
+}
+
+func (missingBloom) Exists(context.Context, int64) (bool, error) { return false, nil }
+
+func (r *fakeArticleRepo) GetDailyRank(context.Context, int64, int64) ([]domain.Article, error) {
+	return r.stored, nil
+}
+
+// viewsCache 记录 MGetBufferedViews 被查询的ID，并返回预置的浏览量增量
+type viewsCache struct {
+	domain.ArticleCache
+	buffered map[int64]int64
+	queried  []int64
+}
+
+// MGetLikeCounts 和没有任何点赞计数的 Redis 一样返回空结果
+func (c *viewsCache) MGetLikeCounts(context.Context, []int64) (map[int64]int64, error) {
+	return map[int64]int64{}, nil
+}
+
+func (c *viewsCache) MGetBufferedViews(_ context.Context, ids []int64) (map[int64]int64, error) {
+	c.queried = append(c.queried, ids...)
+	res := make(map[int64]int64)
+	for _, id := range ids {
+		if v, ok := c.buffered[id]; ok {
+			res[id] = v
+		}
+	}
+	return res, nil
+}
+
+func (r *fakeArticleRepo) GetByIDs(_ context.Context, ids []int64) ([]domain.Article, error) {
+	var res []domain.Article
+	for _, id := range ids {
+		if ar, ok := r.articles[id]; ok && !ar.Hidden && ar.Status != domain.ArticleStatusDraft {
+			res = append(res, ar)
+		}
+	}
+	return res, nil
+}
+
+func (r *fakeArticleRepo) SetHidden(_ context.Context, id int64, hidden bool) error {
+	ar := r.articles[id]
+	ar.Hidden = hidden
+	r.articles[id] = ar
+	return nil
+}
+
+// Delete 和软删除一样把文章移到 deleted 中，Restore 时原样放回
+func (r *fakeArticleRepo) Delete(_ context.Context, id int64) error {
+	if ar, ok := r.articles[id]; ok {
+		if r.deleted == nil {
+			r.deleted = make(map[int64]domain.Article)
+		}
+		r.deleted[id] = ar
+	}
+	delete(r.articles, id)
+	return nil
+}
+
+func (r *fakeArticleRepo) GetDeleted(_ context.Context, id int64) (domain.Article, error) {
+	ar, ok := r.deleted[id]
+	if !ok {
+		return domain.Article{}, domain.ErrNotFound
+	}
+	return ar, nil
+}
+
+func (r *fakeArticleRepo) Restore(_ context.Context, id int64) error {
+	ar, ok := r.deleted[id]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	delete(r.deleted, id)
+	r.articles[id] = ar
+	return nil
+}
+
+// FetchIDs 按 ID 顺序分页返回所有文章的ID，包括草稿和隐藏的文章
+func (r *fakeArticleRepo) FetchIDs(_ context.Context, cursor, limit int64) ([]int64, error) {
+	var ids []int64
+	for id := range r.articles {
+		if id > cursor {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	if int64(len(ids)) > limit {
+		ids = ids[:limit]
+	}
+	return ids, nil
+}
+
+// FetchTitles 按 ID 顺序分页返回未隐藏的文章
+func (r *fakeArticleRepo) FetchTitles(_ context.Context, cursor, limit int64) ([]domain.TitleSuggestion, error) {
+	var res []domain.TitleSuggestion
+	for _, ar := range r.articles {
+		if ar.ID > cursor && !ar.Hidden {
+			res = append(res, domain.TitleSuggestion{ID: ar.ID, Title: ar.Title})
+		}
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].ID < res[j].ID })
+	if int64(len(res)) > limit {
+		res = res[:limit]
+	}
+	return res, nil
+}
+
+// fakeTitleIndex 在内存中保存文章ID到标题的映射
+type fakeTitleIndex struct {
+	titles map[int64]string
+}
+
+func newFakeTitleIndex() *fakeTitleIndex {
+	return &fakeTitleIndex{titles: map[int64]string{}}
+}
+
+func (f *fakeTitleIndex) Set(_ context.Context, id int64, title string) error {
+	f.titles[id] = title
+	return nil
+}
+
+func (f *fakeTitleIndex) Rename(_ context.Context, id int64, title string) error {
+	if _, ok := f.titles[id]; ok {
+		f.titles[id] = title
+	}
+	return nil
+}
+
+func (f *fakeTitleIndex) Remove(_ context.Context, id int64) error {
+	delete(f.titles, id)
+	return nil
+}
+
+func (f *fakeTitleIndex) Suggest(_ context.Context, prefix string, limit int64) ([]domain.TitleSuggestion, error) {
+	var res []domain.TitleSuggestion
+	for id, title := range f.titles {
+		if strings.HasPrefix(title, prefix) {
+			res = append(res, domain.TitleSuggestion{ID: id, Title: title})
+		}
+	}
+	if int64(len(res)) > limit {
+		res = res[:limit]
+	}
+	return res, nil
+}
+
+func (f *fakeTitleIndex) Replace(_ context.Context, titles []domain.TitleSuggestion) error {
+	f.titles = make(map[int64]string, len(titles))
+	for _, s := range titles {
+		f.titles[s.ID] = s.Title
+	}
+	return nil
+}
+
+func (r *fakeArticleRepo) SetCommentsLocked(_ context.Context, id int64, locked bool) error {
+	ar := r.articles[id]
+	ar.CommentsLocked = locked
+	r.articles[id] = ar
+	return nil
+}
@@ -0,0 +1,179 @@
+package article_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/repository"
+	myRedis "github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/repository/redis"
+)
+
+// pageRepo 返回预置的一页文章和是否还有下一页
+type pageRepo struct {
+	domain.ArticleRepository
+	page domain.ArticlePage
+}
+
+func (r pageRepo) Fetch(context.Context, string, int64, string, string) ([]domain.Article, bool, error) {
+	return r.page.Articles, r.page.HasMore, nil
+}
+
+func TestFetchCursorOnlyWhenMoreArticles(t *testing.T) {
+	full := make([]domain.Article, 5)
+	for i := range full {
+		full[i] = domain.Article{ID: int64(i + 1), CreatedAt: time.Now()}
+	}
+
+	// 最后一页恰好有 num 篇，不返回游标，客户端不会再请求一次空页
+	svc := newService(serviceDeps{repo: pageRepo{page: domain.ArticlePage{Articles: full}}, cache: &viewsCache{}})
+	res, cursor, err := svc.Fetch(context.Background(), "", 5, "", "")
+	require.NoError(t, err)
+	assert.Len(t, res, 5)
+	assert.Empty(t, cursor)
+
+	svc = newService(serviceDeps{repo: pageRepo{page: domain.ArticlePage{Articles: full, HasMore: true}}, cache: &viewsCache{}})
+	_, cursor, err = svc.Fetch(context.Background(), "", 5, "", "")
+	require.NoError(t, err)
+	assert.NotEmpty(t, cursor)
+}
+
+// keysetRepo 按 (created_at, id) 顺序分页，游标的解析方式和 mysql 实现相同
+type keysetRepo struct {
+	domain.ArticleRepository
+	articles []domain.Article
+}
+
+func (r keysetRepo) Fetch(_ context.Context, cursor string, num int64, _, _ string) ([]domain.Article, bool, error) {
+	createdAt, lastID, err := repository.DecodeCursor(cursor)
+	if err != nil && cursor != "" {
+		return nil, false, domain.ErrBadParamInput
+	}
+	var page []domain.Article
+	for _, ar := range r.articles {
+		if ar.CreatedAt.After(createdAt) || (ar.CreatedAt.Equal(createdAt) && ar.ID > lastID) {
+			page = append(page, ar)
+		}
+	}
+	if int64(len(page)) > num {
+		return page[:num], true, nil
+	}
+	return page, false, nil
+}
+
+func TestFetchPagesThroughSameSecond(t *testing.T) {
+	// 批量导入的文章创建时间相同，只按时间翻页会跳过或重复同一秒的文章
+	createdAt := time.Date(2024, 5, 1, 8, 30, 0, 0, time.UTC)
+	all := make([]domain.Article, 7)
+	for i := range all {
+		all[i] = domain.Article{ID: int64(i + 1), CreatedAt: createdAt}
+	}
+	svc := newService(serviceDeps{repo: keysetRepo{articles: all}, cache: &viewsCache{}})
+
+	var ids []int64
+	cursor := ""
+	for range len(all) {
+		res, next, err := svc.Fetch(context.Background(), cursor, 3, "", "")
+		require.NoError(t, err)
+		for _, ar := range res {
+			ids = append(ids, ar.ID)
+		}
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+	assert.Equal(t, []int64{1, 2, 3, 4, 5, 6, 7}, ids)
+}
+
+// unreachableDB 被调用说明没有命中首页缓存
+type unreachableDB struct {
+	domain.ArticleDBRepository
+	t *testing.T
+}
+
+func (d unreachableDB) Fetch(context.Context, string, int64, string, string) ([]domain.Article, bool, error) {
+	d.t.Fatal("home page should be served from the cache")
+	return nil, false, nil
+}
+
+func TestFetchEmptyHomeCache(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	// 首页缓存为空数组，即使 HasMore 为 true 也不生成游标
+	cache := myRedis.NewArticleCache(client, "", 0, 0)
+	require.NoError(t, cache.SetHomeWithLogicalExpire(context.Background(), domain.ArticlePage{Articles: []domain.Article{}, HasMore: true}, time.Minute))
+	repo := repository.NewArticleRepository(unreachableDB{t: t}, cache, nil, repository.NewRuntimeSettings(nil), true, nil)
+	svc := newService(serviceDeps{repo: repo, cache: cache})
+
+	res, cursor, err := svc.Fetch(context.Background(), "", 5, "", "")
+	require.NoError(t, err)
+	assert.NotNil(t, res)
+	assert.Empty(t, res)
+	assert.Empty(t, cursor)
+}
+
+func TestFetchEmptyPageFromDB(t *testing.T) {
+	svc := newService(serviceDeps{repo: pageRepo{page: domain.ArticlePage{HasMore: true}}, cache: &viewsCache{}})
+	res, cursor, err := svc.Fetch(context.Background(), "", 5, "", "")
+	require.NoError(t, err)
+	assert.NotNil(t, res)
+	assert.Empty(t, res)
+	assert.Empty(t, cursor)
+}
+
+// likedPageRepo 在 pageRepo 的基础上返回空的用户点赞列表，点赞时加载缓存用
+type likedPageRepo struct {
+	pageRepo
+}
+
+func (likedPageRepo) FetchUserLikedArticles(context.Context, int64, int64) ([]int64, error) {
+	return nil, nil
+}
+
+func TestFetchMergesBufferedLikesAndViews(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	cache := myRedis.NewArticleCache(client, "", 0, 0)
+
+	// 数据库中的点赞数和浏览量还没有同步
+	page := domain.ArticlePage{Articles: []domain.Article{
+		{ID: 1, Likes: 3, Views: 10, CreatedAt: time.Now()},
+		{ID: 2, Likes: 4, Views: 20, CreatedAt: time.Now()},
+	}}
+	worker := &fakeLikesWorker{}
+	svc := newService(serviceDeps{repo: likedPageRepo{pageRepo{page: page}}, cache: cache, likes: worker})
+
+	// 文章 1 的点赞数已经在 Redis 中，文章 2 没有计数
+	require.NoError(t, cache.SetLikeCount(ctx, 1, 3))
+	for _, uid := range []int64{7, 8} {
+		state, err := svc.AddLikeRecord(ctx, domain.UserLike{UserID: uid, ArticleID: 1})
+		require.NoError(t, err)
+		require.True(t, state.Changed)
+	}
+	_, err := cache.IncrViews(ctx, 1)
+	require.NoError(t, err)
+
+	res, _, err := svc.Fetch(ctx, "", 5, "", "")
+	require.NoError(t, err)
+	require.Len(t, res, 2)
+	assert.Equal(t, int64(5), res[0].Likes)
+	assert.Equal(t, int64(11), res[0].Views)
+	// 没有点赞计数时保留数据库中的值，而不是变成 0
+	assert.Equal(t, int64(4), res[1].Likes)
+	assert.Equal(t, int64(20), res[1].Views)
+	// 点赞还在等待同步，列表不依赖落库
+	assert.Len(t, worker.sent, 2)
+	// 不修改仓储返回的切片
+	assert.Equal(t, int64(3), page.Articles[0].Likes)
+}
@@ -0,0 +1,56 @@
+package article_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+	myRedis "github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/repository/redis"
+)
+
+// likeRepo 只有一篇已落库 10 个赞的文章，用户没有点赞过任何文章
+type likeRepo struct {
+	domain.ArticleRepository
+}
+
+func (likeRepo) GetByIDs(_ context.Context, ids []int64) ([]domain.Article, error) {
+	return []domain.Article{{ID: ids[0], Likes: 10}}, nil
+}
+
+func (likeRepo) FetchUserLikedArticles(context.Context, int64, int64) ([]int64, error) {
+	return nil, nil
+}
+
+func TestLikeReturnsStateAndCount(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	cache := myRedis.NewArticleCache(client, "", 0, 0)
+	worker := &fakeLikesWorker{}
+	svc := newService(serviceDeps{repo: likeRepo{}, cache: cache, likes: worker})
+	like := domain.UserLike{UserID: 7, ArticleID: 1}
+
+	// Redis 中还没有点赞数，这次点赞也要计入，而不是返回尚未同步的数据库中的 10
+	state, err := svc.AddLikeRecord(ctx, like)
+	require.NoError(t, err)
+	assert.Equal(t, domain.LikeState{Changed: true, Liked: true, Likes: 11}, state)
+
+	state, err = svc.AddLikeRecord(ctx, like)
+	require.NoError(t, err)
+	assert.Equal(t, domain.LikeState{Changed: false, Liked: true, Likes: 11}, state)
+
+	state, err = svc.RemoveLikeRecord(ctx, like)
+	require.NoError(t, err)
+	assert.Equal(t, domain.LikeState{Changed: true, Liked: false, Likes: 10}, state)
+
+	state, err = svc.RemoveLikeRecord(ctx, like)
+	require.NoError(t, err)
+	assert.Equal(t, domain.LikeState{Changed: false, Liked: false, Likes: 10}, state)
+	assert.Len(t, worker.sent, 2)
+}
@@ -0,0 +1,32 @@
+package article
+
+import (
+	"context"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+)
+
+// GetMeta 返回链接预览需要的元数据。与 GetByID 不同，这里走批量读取的缓存路径，
+// 不计浏览量也不计入热榜，聊天软件反复抓取同一个链接不会刷高数据
+func (a *service) GetMeta(ctx context.Context, id int64) (domain.ArticleMeta, error) {
+	ars, err := a.getPublished(ctx, id)
+	if err != nil {
+		return domain.ArticleMeta{}, err
+	}
+	if len(ars) == 0 {
+		return domain.ArticleMeta{}, domain.ErrNotFound
+	}
+
+	ar := ars[0]
+	summary := ar.Summary
+	if summary == "" {
+		summary = generateExcerpt(ar.Content, a.excerpt)
+	}
+	return domain.ArticleMeta{
+		ID:          ar.ID,
+		Title:       ar.Title,
+		Summary:     summary,
+		AuthorName:  ar.User.Name,
+		PublishedAt: ar.CreatedAt,
+	}, nil
+}
@@ -0,0 +1,67 @@
+package article_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+)
+
+// viewCountingRepo 的 GetByID 在真实实现中会计一次浏览，元数据接口不应该调用它
+type viewCountingRepo struct {
+	*fakeArticleRepo
+	views int
+}
+
+func (r *viewCountingRepo) GetByID(ctx context.Context, id int64) (domain.Article, error) {
+	r.views++
+	return r.fakeArticleRepo.GetByID(ctx, id)
+}
+
+func newMetaService() (domain.ArticleUsecase, *viewCountingRepo) {
+	created := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	repo := &viewCountingRepo{fakeArticleRepo: &fakeArticleRepo{articles: map[int64]domain.Article{
+		1: {ID: 1, Title: "t", Summary: "s", Content: "body", User: domain.User{Name: "alice"}, CreatedAt: created},
+		2: {ID: 2, Title: "no summary", Content: "first paragraph\n\nsecond paragraph", CreatedAt: created},
+		3: {ID: 3, Title: "draft", Status: domain.ArticleStatusDraft},
+		4: {ID: 4, Title: "hidden", Hidden: true},
+	}}}
+	return newService(serviceDeps{repo: repo}), repo
+}
+
+func TestGetMeta(t *testing.T) {
+	svc, repo := newMetaService()
+
+	meta, err := svc.GetMeta(context.Background(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, domain.ArticleMeta{
+		ID:          1,
+		Title:       "t",
+		Summary:     "s",
+		AuthorName:  "alice",
+		PublishedAt: time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC),
+	}, meta)
+	// 不走 GetByID，不会计入浏览量
+	assert.Zero(t, repo.views)
+}
+
+func TestGetMetaFallsBackToExcerpt(t *testing.T) {
+	svc, _ := newMetaService()
+
+	meta, err := svc.GetMeta(context.Background(), 2)
+	require.NoError(t, err)
+	assert.Contains(t, meta.Summary, "first paragraph")
+}
+
+func TestGetMetaHidesInvisibleArticles(t *testing.T) {
+	svc, _ := newMetaService()
+
+	for _, id := range []int64{3, 4, 404} {
+		_, err := svc.GetMeta(context.Background(), id)
+		assert.ErrorIs(t, err, domain.ErrNotFound, "article %d", id)
+	}
+}
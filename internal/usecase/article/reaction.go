@@ -0,0 +1,119 @@
+package article
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+)
+
+// AddReaction 添加表情回应，like 类型沿用原有的点赞流程
+func (a *service) AddReaction(ctx context.Context, r domain.Reaction) (bool, domain.ReactionCounts, error) {
+	if !r.Type.IsValid() {
+		return false, nil, domain.ErrBadParamInput
+	}
+
+	var (
+		ok  bool
+		err error
+	)
+	if r.Type == domain.ReactionLike {
+		ok, err = a.addLikeRecord(ctx, domain.UserLike{ArticleID: r.ArticleID, UserID: r.UserID})
+	} else {
+		ok, err = a.changeReaction(ctx, r, domain.Like)
+	}
+	if err != nil {
+		return false, nil, err
+	}
+
+	counts, err := a.GetReactionCounts(ctx, r.ArticleID)
+	if err != nil {
+		return false, nil, err
+	}
+	return ok, counts, nil
+}
+
+// RemoveReaction 移除表情回应，like 类型沿用原有的取消点赞流程
+func (a *service) RemoveReaction(ctx context.Context, r domain.Reaction) (bool, domain.ReactionCounts, error) {
+	if !r.Type.IsValid() {
+		return false, nil, domain.ErrBadParamInput
+	}
+
+	var (
+		ok  bool
+		err error
+	)
+	if r.Type == domain.ReactionLike {
+		ok, err = a.removeLikeRecord(ctx, domain.UserLike{ArticleID: r.ArticleID, UserID: r.UserID})
+	} else {
+		ok, err = a.changeReaction(ctx, r, domain.Unlike)
+	}
+	if err != nil {
+		return false, nil, err
+	}
+
+	counts, err := a.GetReactionCounts(ctx, r.ArticleID)
+	if err != nil {
+		return false, nil, err
+	}
+	return ok, counts, nil
+}
+
+// GetReactionCounts 获取文章各类回应的数量
+func (a *service) GetReactionCounts(ctx context.Context, aid int64) (domain.ReactionCounts, error) {
+	if err := a.mustExists(ctx, aid); err != nil {
+		return nil, err
+	}
+
+	// like 的计数以点赞缓存为准，它比尚未同步的 user_likes 更新
+	likes, likeErr := a.articleCache.GetLikeCount(ctx, aid)
+
+	counts, err := a.reactionCache.GetReactionCounts(ctx, aid)
+	if err == nil && likeErr == nil {
+		counts[domain.ReactionLike] = likes
+		return counts, nil
+	}
+
+	// 缓存未命中，从数据库统计
+	counts, err = a.reactionRepo.CountByArticle(ctx, aid)
+	if err != nil {
+		logrus.Errorf("failed to CountByArticle: %v", err)
+		return nil, err
+	}
+	if err := a.reactionCache.SetReactionCounts(ctx, aid, counts); err != nil {
+		logrus.Warnf("failed to SetReactionCounts: %v", err)
+	}
+	if likeErr == nil {
+		counts[domain.ReactionLike] = likes
+	}
+	return counts, nil
+}
+
+// changeReaction 写入或删除一条非 like 的回应，并同步缓存中的计数
+func (a *service) changeReaction(ctx context.Context, r domain.Reaction, action domain.LikeAction) (bool, error) {
+	if err := a.mustExists(ctx, r.ArticleID); err != nil {
+		return false, err
+	}
+
+	var (
+		ok  bool
+		err error
+	)
+	if action == domain.Like {
+		ok, err = a.reactionRepo.Add(ctx, r)
+	} else {
+		ok, err = a.reactionRepo.Remove(ctx, r)
+	}
+	if err != nil {
+		logrus.Errorf("failed to %s reaction: %v", action, err)
+		return false, err
+	}
+
+	if ok {
+		if err := a.reactionCache.IncrReactionCount(ctx, r.ArticleID, r.Type, int64(action)); err != nil {
+			logrus.Warnf("failed to IncrReactionCount: %v", err)
+		}
+	}
+	return ok, nil
+}
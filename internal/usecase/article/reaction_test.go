@@ -0,0 +1,95 @@
+package article_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+)
+
+func newReactionService() (domain.ArticleUsecase, *fakeLikesWorker) {
+	worker := &fakeLikesWorker{}
+	svc := newService(serviceDeps{cache: newFakeArticleCache(), likes: worker, reactions: newFakeReactionRepo(), reactionCache: newFakeReactionCache()})
+	return svc, worker
+}
+
+func TestAddReactionCountsPerType(t *testing.T) {
+	svc, _ := newReactionService()
+	ctx := context.Background()
+
+	ok, counts, err := svc.AddReaction(ctx, domain.Reaction{ArticleID: 1, UserID: 1, Type: domain.ReactionLove})
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, int64(1), counts[domain.ReactionLove])
+	assert.Equal(t, int64(0), counts[domain.ReactionWow])
+
+	ok, counts, err = svc.AddReaction(ctx, domain.Reaction{ArticleID: 1, UserID: 2, Type: domain.ReactionLove})
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, int64(2), counts[domain.ReactionLove])
+
+	ok, counts, err = svc.AddReaction(ctx, domain.Reaction{ArticleID: 1, UserID: 1, Type: domain.ReactionWow})
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, int64(2), counts[domain.ReactionLove])
+	assert.Equal(t, int64(1), counts[domain.ReactionWow])
+
+	// 重复回应不改变计数
+	ok, counts, err = svc.AddReaction(ctx, domain.Reaction{ArticleID: 1, UserID: 1, Type: domain.ReactionWow})
+	require.NoError(t, err)
+	assert.False(t, ok)
+	assert.Equal(t, int64(1), counts[domain.ReactionWow])
+
+	// 其他文章不受影响
+	counts, err = svc.GetReactionCounts(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), counts[domain.ReactionLove])
+	assert.Equal(t, int64(0), counts[domain.ReactionWow])
+}
+
+func TestRemoveReaction(t *testing.T) {
+	svc, _ := newReactionService()
+	ctx := context.Background()
+
+	_, _, err := svc.AddReaction(ctx, domain.Reaction{ArticleID: 1, UserID: 1, Type: domain.ReactionLove})
+	require.NoError(t, err)
+	_, _, err = svc.AddReaction(ctx, domain.Reaction{ArticleID: 1, UserID: 1, Type: domain.ReactionWow})
+	require.NoError(t, err)
+
+	ok, counts, err := svc.RemoveReaction(ctx, domain.Reaction{ArticleID: 1, UserID: 1, Type: domain.ReactionLove})
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, int64(0), counts[domain.ReactionLove])
+	assert.Equal(t, int64(1), counts[domain.ReactionWow])
+
+	ok, _, err = svc.RemoveReaction(ctx, domain.Reaction{ArticleID: 1, UserID: 1, Type: domain.ReactionLove})
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestLikeReactionUsesLikeFlow(t *testing.T) {
+	svc, worker := newReactionService()
+	ctx := context.Background()
+
+	ok, counts, err := svc.AddReaction(ctx, domain.Reaction{ArticleID: 1, UserID: 1, Type: domain.ReactionLike})
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, int64(1), counts[domain.ReactionLike])
+
+	ok, counts, err = svc.RemoveReaction(ctx, domain.Reaction{ArticleID: 1, UserID: 1, Type: domain.ReactionLike})
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, int64(0), counts[domain.ReactionLike])
+
+	assert.Equal(t, []domain.LikeAction{domain.Like, domain.Unlike}, worker.sent)
+}
+
+func TestReactionRejectsUnknownType(t *testing.T) {
+	svc, _ := newReactionService()
+
+	_, _, err := svc.AddReaction(context.Background(), domain.Reaction{ArticleID: 1, UserID: 1, Type: "angry"})
+	assert.ErrorIs(t, err, domain.ErrBadParamInput)
+}
@@ -0,0 +1,76 @@
+package article_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+)
+
+func TestRestoreOnlyByAuthor(t *testing.T) {
+	ctx := context.Background()
+	repo := &fakeArticleRepo{articles: map[int64]domain.Article{
+		1: {ID: 1, Title: "并发编程入门", User: domain.User{ID: 7}, Status: domain.ArticleStatusPublished},
+	}}
+	bloom := &setBloom{ids: map[int64]bool{1: true}}
+	index := newFakeTitleIndex()
+	index.titles[1] = "并发编程入门"
+	svc := newService(serviceDeps{repo: repo, bloom: bloom, titleIndex: index})
+
+	require.NoError(t, svc.Delete(ctx, 1, 7))
+	assert.Empty(t, index.titles)
+	// 模拟删除之后重建过的布隆过滤器
+	delete(bloom.ids, 1)
+
+	// 其他人不能恢复，没有被删除的文章返回 ErrNotFound
+	assert.ErrorIs(t, svc.Restore(ctx, 1, 8), domain.ErrForbidden)
+	assert.ErrorIs(t, svc.Restore(ctx, 2, 7), domain.ErrNotFound)
+
+	require.NoError(t, svc.Restore(ctx, 1, 7))
+	assert.Contains(t, repo.articles, int64(1))
+	assert.True(t, bloom.ids[1])
+	assert.Equal(t, map[int64]string{1: "并发编程入门"}, index.titles)
+
+	assert.ErrorIs(t, svc.Restore(ctx, 1, 7), domain.ErrNotFound)
+}
+
+func TestRestoreDraftSkipsTitleIndex(t *testing.T) {
+	ctx := context.Background()
+	repo := &fakeArticleRepo{
+		articles: map[int64]domain.Article{},
+		deleted: map[int64]domain.Article{
+			1: {ID: 1, Title: "草稿", User: domain.User{ID: 7}, Status: domain.ArticleStatusDraft},
+		},
+	}
+	index := newFakeTitleIndex()
+	svc := newService(serviceDeps{repo: repo, bloom: &setBloom{ids: map[int64]bool{}}, titleIndex: index})
+
+	// userID 为 0 时不检查作者，供管理员使用
+	require.NoError(t, svc.Restore(ctx, 1, 0))
+	assert.Empty(t, index.titles)
+}
+
+func TestRestoreRejectsTakenTitle(t *testing.T) {
+	ctx := context.Background()
+	repo := &fakeArticleRepo{articles: map[int64]domain.Article{
+		1: {ID: 1, Title: "并发编程入门", User: domain.User{ID: 7}, Status: domain.ArticleStatusPublished},
+	}}
+	index := newFakeTitleIndex()
+	svc := newService(serviceDeps{repo: repo, bloom: &setBloom{ids: map[int64]bool{1: true}}, titleIndex: index})
+
+	require.NoError(t, svc.Delete(ctx, 1, 7))
+	// 删除期间另一篇文章用了同一个标题
+	repo.articles[2] = domain.Article{ID: 2, Title: "并发编程入门", User: domain.User{ID: 8}, Status: domain.ArticleStatusPublished}
+
+	err := svc.Restore(ctx, 1, 7)
+	var conflict *domain.ConflictError
+	require.ErrorAs(t, err, &conflict)
+	assert.ErrorIs(t, err, domain.ErrConflict)
+	assert.Equal(t, int64(2), conflict.ExistingID)
+	assert.False(t, conflict.DuplicateContent)
+	assert.NotContains(t, repo.articles, int64(1))
+	assert.Contains(t, repo.deleted, int64(1))
+}
@@ -0,0 +1,32 @@
+package article
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+)
+
+// Search 按标题和正文搜索文章，查询为 MinSearchQueryRunes 到 MaxSearchQueryRunes 个字符，cursor 为上一页最后一篇文章的ID
+func (a *service) Search(ctx context.Context, query, cursor string, num int64) ([]domain.Article, string, error) {
+	query = strings.TrimSpace(query)
+	if n := utf8.RuneCountInString(query); n < domain.MinSearchQueryRunes || n > domain.MaxSearchQueryRunes {
+		return nil, "", domain.ErrBadParamInput
+	}
+
+	articles, hasMore, err := a.articleRepo.Search(ctx, query, cursor, num)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(articles) == 0 {
+		return articles, "", nil
+	}
+
+	var nextCursor string
+	if hasMore {
+		nextCursor = strconv.FormatInt(articles[len(articles)-1].ID, 10)
+	}
+	return a.withViewsDisplay(ctx, articles), nextCursor, nil
+}
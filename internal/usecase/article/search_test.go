@@ -0,0 +1,61 @@
+package article_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+)
+
+// searchRepo 返回预置的一页结果，记录收到的查询
+type searchRepo struct {
+	domain.ArticleRepository
+	page    domain.ArticlePage
+	queries []string
+}
+
+func (r *searchRepo) Search(_ context.Context, query, _ string, _ int64) ([]domain.Article, bool, error) {
+	r.queries = append(r.queries, query)
+	return r.page.Articles, r.page.HasMore, nil
+}
+
+func TestSearchCursorIsLastID(t *testing.T) {
+	repo := &searchRepo{page: domain.ArticlePage{
+		Articles: []domain.Article{{ID: 9}, {ID: 7}},
+		HasMore:  true,
+	}}
+	svc := newService(serviceDeps{repo: repo, cache: &viewsCache{}})
+
+	res, cursor, err := svc.Search(context.Background(), " 并发 ", "", 2)
+	require.NoError(t, err)
+	assert.Len(t, res, 2)
+	assert.Equal(t, "7", cursor)
+	assert.Equal(t, []string{"并发"}, repo.queries)
+
+	// 没有更多结果时不返回游标
+	repo.page.HasMore = false
+	_, cursor, err = svc.Search(context.Background(), "并发", "7", 2)
+	require.NoError(t, err)
+	assert.Empty(t, cursor)
+}
+
+func TestSearchRejectsShortOrLongQuery(t *testing.T) {
+	repo := &searchRepo{}
+	svc := newService(serviceDeps{repo: repo, cache: &viewsCache{}})
+
+	long := strings.Repeat("并", domain.MaxSearchQueryRunes+1)
+	for _, q := range []string{"", "   ", "a", " 中 ", long} {
+		_, _, err := svc.Search(context.Background(), q, "", 10)
+		assert.ErrorIs(t, err, domain.ErrBadParamInput, "query %q", q)
+	}
+	assert.Empty(t, repo.queries)
+
+	// 按字符而不是字节计数，首尾空白不计入
+	_, _, err := svc.Search(context.Background(), " "+long[len("并"):]+" ", "", 10)
+	require.NoError(t, err)
+	assert.Len(t, repo.queries, 1)
+}
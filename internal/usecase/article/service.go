@@ -3,12 +3,16 @@ package article
 import (
 	"context"
 	"errors"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/sirupsen/logrus"
 	"golang.org/x/sync/errgroup"
 
 	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/repository"
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/textutil"
 )
 
 type service struct {
@@ -16,35 +20,83 @@ type service struct {
 	articleCache    domain.ArticleCache
 	syncLikesWorker domain.SyncLikesWorker
 	bloomRepo       domain.BloomRepository
+	reactionRepo    domain.ReactionRepository
+	reactionCache   domain.ReactionCache
+	excerpt         domain.ExcerptStrategy
+	titleIndex      domain.TitleIndex
+	commentRepo     domain.CommentRepository
+	summarizer      domain.Summarizer
 }
 
 var _ domain.ArticleUsecase = (*service)(nil)
 
 // NewService 创建article usecase服务
 // 注意：articleCache仅用于点赞等特殊缓存操作，一般的缓存逻辑由repository层处理
-func NewService(a domain.ArticleRepository, ac domain.ArticleCache, s domain.SyncLikesWorker, b domain.BloomRepository) *service {
+// 读取文章时按 excerpt 策略从正文生成 Excerpt，写文章时同步维护标题联想索引 ti
+// cr 只用于批量统计评论数，summarizer 为 nil 时用正文的第一段作为自动摘要
+func NewService(
+	a domain.ArticleRepository,
+	ac domain.ArticleCache,
+	s domain.SyncLikesWorker,
+	b domain.BloomRepository,
+	rr domain.ReactionRepository,
+	rc domain.ReactionCache,
+	excerpt domain.ExcerptStrategy,
+	ti domain.TitleIndex,
+	cr domain.CommentRepository,
+	summarizer domain.Summarizer,
+) *service {
+	if summarizer == nil {
+		summarizer = FirstParagraphSummarizer{}
+	}
 	return &service{
 		articleRepo:     a,
 		articleCache:    ac,
 		syncLikesWorker: s,
 		bloomRepo:       b,
+		reactionRepo:    rr,
+		reactionCache:   rc,
+		excerpt:         excerpt,
+		titleIndex:      ti,
+		commentRepo:     cr,
+		summarizer:      summarizer,
 	}
 }
 
 // Fetch 获取文章列表
-func (a *service) Fetch(ctx context.Context, cursor string, num int64) ([]domain.Article, string, error) {
-	articles, err := a.articleRepo.Fetch(ctx, cursor, num)
+func (a *service) Fetch(ctx context.Context, cursor string, num int64, lang, tag string) ([]domain.Article, string, error) {
+	if lang != "" {
+		canonical, ok := domain.NormalizeLanguage(lang)
+		if !ok {
+			return nil, "", domain.ErrBadParamInput
+		}
+		lang = canonical
+	}
+
+	articles, hasMore, err := a.articleRepo.Fetch(ctx, cursor, num, lang, domain.NormalizeTag(tag))
 	if err != nil {
 		return nil, "", err
 	}
 
+	// 空表或翻到最后一页之后，首页缓存中也可能是空数组，返回空切片而不是 nil
 	if len(articles) == 0 {
-		return articles, "", nil
+		return []domain.Article{}, "", nil
+	}
+
+	// 后面还有文章时才生成下一个cursor，客户端不必再请求一次空页
+	var nextCursor string
+	if hasMore {
+		last := articles[len(articles)-1]
+		nextCursor = repository.EncodeCursor(last.CreatedAt, last.ID)
 	}
+	res := a.withViewsDisplay(ctx, articles)
+	a.mergeBufferedLikes(ctx, res)
+	return res, nextCursor, nil
+}
 
-	// 生成下一个cursor
-	nextCursor := encodeCursor(articles[len(articles)-1].CreatedAt)
-	return articles, nextCursor, nil
+// ListTags 获取所有标签及其可见文章数
+func (a *service) ListTags(ctx context.Context) ([]domain.TagCount, error) {
+	return a.articleRepo.FetchTags(ctx)
 }
 
 // GetByID 根据ID获取文章（所有缓存逻辑由repository层处理）
@@ -53,55 +105,313 @@ func (a *service) GetByID(ctx context.Context, id int64) (domain.Article, error)
 		return domain.Article{}, err
 	}
 
-	return a.articleRepo.GetByID(ctx, id)
+	ar, err := a.articleRepo.GetByID(ctx, id)
+	if err != nil {
+		return domain.Article{}, err
+	}
+	ar.Excerpt = generateExcerpt(ar.Content, a.excerpt)
+	return ar, nil
 }
 
-// Update 更新文章
+// Update 更新文章，空字段保持原值。ar.User.ID 不为 0 时是作者发起的编辑：只有作者本人可以修改，
+// 成功后用原文章补全 ar 中没有修改的字段，调用方可以直接用 ar 作为更新结果返回
 func (a *service) Update(ctx context.Context, ar *domain.Article) error {
-	if err := a.mustExists(ctx, ar.ID); err != nil {
+	var existing *domain.Article
+	if ar.User.ID != 0 {
+		// 只按 ID 比较，管理员也不能以作者身份编辑他人的文章
+		owned, err := a.getOwned(ctx, ar.ID, domain.User{ID: ar.User.ID})
+		if err != nil {
+			return err
+		}
+		existing = &owned
+	} else if err := a.mustExists(ctx, ar.ID); err != nil {
+		return err
+	}
+	if ar.Title != "" {
+		if ar.Title = textutil.CleanLine(ar.Title); ar.Title == "" {
+			return domain.ErrBadParamInput
+		}
+		// 与 Store 一样检查标题，改回文章自己的标题不算冲突
+		if existedArticle, _ := a.articleRepo.GetByTitle(ctx, ar.Title); existedArticle.ID != 0 && existedArticle.ID != ar.ID {
+			return &domain.ConflictError{ExistingID: existedArticle.ID}
+		}
+	}
+	if err := normalizeLanguage(ar); err != nil {
 		return err
 	}
-	ar.UpdatedAt = time.Now()
-	return a.articleRepo.Update(ctx, ar)
+	tags, err := domain.NormalizeTags(ar.Tags)
+	if err != nil {
+		return err
+	}
+	ar.Tags = tags
+	// 正文变化时生成新的自动摘要，是否覆盖由存储层根据原摘要是否为作者手写决定
+	if ar.Summary == "" && ar.Content != "" {
+		ar.Summary = a.summarize(ctx, ar.Content)
+		ar.SummaryIsAuto = true
+	}
+	if err := a.articleRepo.Update(ctx, ar); err != nil {
+		return err
+	}
+
+	// 隐藏的文章不在索引中，只改已有条目的标题
+	if ar.Title != "" {
+		if err := a.titleIndex.Rename(ctx, ar.ID, ar.Title); err != nil {
+			logrus.Warnf("failed to rename article %d in title index: %v", ar.ID, err)
+		}
+	}
+
+	// 原文章来自缓存，正文可能被截断过，只能在写入之后用来补全返回值
+	if existing != nil {
+		fillUnchanged(ar, existing)
+	}
+	return nil
 }
 
-// Store 创建文章
+// fillUnchanged 用原文章补全 ar 中没有修改的字段和不可编辑的字段
+func fillUnchanged(ar, existing *domain.Article) {
+	if ar.Title == "" {
+		ar.Title = existing.Title
+	}
+	if ar.Content == "" {
+		ar.Content = existing.Content
+		ar.ContentTruncated = existing.ContentTruncated
+	}
+	if ar.Language == "" {
+		ar.Language = existing.Language
+	}
+	if ar.Tags == nil {
+		ar.Tags = existing.Tags
+	}
+	ar.User = existing.User
+	ar.CreatedAt = existing.CreatedAt
+	ar.Views = existing.Views
+	ar.Likes = existing.Likes
+	ar.Hidden = existing.Hidden
+	ar.CommentsLocked = existing.CommentsLocked
+	ar.Status = existing.Status
+}
+
+// Store 创建文章。正文与其他用户的文章重复时返回 DuplicateContent 的 ConflictError，
+// 与作者自己的文章重复时照常创建，并在 m.DuplicateOf 中给出已有的文章
 func (a *service) Store(ctx context.Context, m *domain.Article) error {
+	duplicateOf, err := a.checkDuplicateContent(ctx, m)
+	if err != nil {
+		return err
+	}
+	if err := a.Import(ctx, m); err != nil {
+		return err
+	}
+	m.DuplicateOf = duplicateOf
+
+	// 添加到布隆过滤器，草稿也要添加：布隆过滤器只判断文章是否存在，可见性由数据库查询决定
+	a.bloomRepo.Add(ctx, m.ID)
+
+	return nil
+}
+
+// checkDuplicateContent 按正文指纹查找已有的文章，返回作者本人最早的一篇重复文章
+func (a *service) checkDuplicateContent(ctx context.Context, m *domain.Article) (int64, error) {
+	fp := domain.ContentFingerprint(m.Content)
+	if fp == "" {
+		return 0, nil
+	}
+	matches, err := a.articleRepo.FindByFingerprint(ctx, fp)
+	if err != nil {
+		return 0, err
+	}
+
+	var duplicateOf int64
+	for _, ar := range matches {
+		if ar.User.ID != m.User.ID {
+			return 0, &domain.ConflictError{ExistingID: ar.ID, DuplicateContent: true}
+		}
+		if duplicateOf == 0 {
+			duplicateOf = ar.ID
+		}
+	}
+	return duplicateOf, nil
+}
+
+// Import 与 Store 相同但不加入布隆过滤器，由批量导入的调用方成批添加。
+// m.CreatedAt 非零时保留原始发布时间
+func (a *service) Import(ctx context.Context, m *domain.Article) error {
+	// 去掉零宽字符、RTL override 等不可见字符，只剩这些字符的标题视为空标题
+	if m.Title = textutil.CleanLine(m.Title); m.Title == "" {
+		return domain.ErrBadParamInput
+	}
+	if err := normalizeLanguage(m); err != nil {
+		return err
+	}
+	tags, err := domain.NormalizeTags(m.Tags)
+	if err != nil {
+		return err
+	}
+	m.Tags = tags
+	if m.Status == "" {
+		m.Status = domain.ArticleStatusPublished
+	} else if !m.Status.IsValid() {
+		return domain.ErrBadParamInput
+	}
+
 	// 检查标题是否已存在
 	existedArticle, _ := a.articleRepo.GetByTitle(ctx, m.Title)
 	if existedArticle.ID != 0 {
-		return domain.ErrConflict
+		return &domain.ConflictError{ExistingID: existedArticle.ID}
+	}
+
+	// 作者没有提供摘要时从正文生成
+	m.Summary = strings.TrimSpace(m.Summary)
+	m.SummaryIsAuto = m.Summary == ""
+	if m.SummaryIsAuto {
+		m.Summary = a.summarize(ctx, m.Content)
+	} else if utf8.RuneCountInString(m.Summary) > domain.MaxSummaryRunes {
+		return domain.ErrBadParamInput
 	}
 
-	err := a.articleRepo.Store(ctx, m)
+	if err := a.articleRepo.Store(ctx, m); err != nil {
+		return err
+	}
+
+	// 草稿不参与标题联想，发布时再加入索引
+	if m.Status == domain.ArticleStatusPublished {
+		if err := a.titleIndex.Set(ctx, m.ID, m.Title); err != nil {
+			logrus.Warnf("failed to add article %d to title index: %v", m.ID, err)
+		}
+	}
+	return nil
+}
+
+// Delete 删除文章，userID 非零时只有作者本人可以删除
+func (a *service) Delete(ctx context.Context, id int64, userID int64) error {
+	if userID != 0 {
+		if _, err := a.getOwned(ctx, id, domain.User{ID: userID}); err != nil {
+			return err
+		}
+	} else if err := a.mustExists(ctx, id); err != nil {
+		return err
+	}
+
+	if err := a.articleRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	a.removeTitle(ctx, id)
+	return nil
+}
+
+// GetDeleted 读取已删除的文章供管理员审核，不计浏览量
+func (a *service) GetDeleted(ctx context.Context, id int64) (domain.Article, error) {
+	ar, err := a.articleRepo.GetDeleted(ctx, id)
+	if err != nil {
+		return domain.Article{}, err
+	}
+	ar.Excerpt = generateExcerpt(ar.Content, a.excerpt)
+	return ar, nil
+}
+
+// Restore 恢复已删除的文章，userID 非零时只有作者本人可以恢复。
+// 删除期间可能有人用了同一个标题，这时与 Store 一样返回 ConflictError。
+// 布隆过滤器不支持删除，这里仍然重新加入一次，过滤器重建期间删除的文章也能恢复可见
+func (a *service) Restore(ctx context.Context, id int64, userID int64) error {
+	ar, err := a.articleRepo.GetDeleted(ctx, id)
 	if err != nil {
 		return err
 	}
+	if userID != 0 && ar.User.ID != userID {
+		return domain.ErrForbidden
+	}
+	// GetByTitle 只查未删除的文章
+	if existedArticle, _ := a.articleRepo.GetByTitle(ctx, ar.Title); existedArticle.ID != 0 && existedArticle.ID != id {
+		return &domain.ConflictError{ExistingID: existedArticle.ID}
+	}
 
-	// 添加到布隆过滤器
-	a.bloomRepo.Add(ctx, m.ID)
+	if err := a.articleRepo.Restore(ctx, id); err != nil {
+		return err
+	}
 
+	if err := a.bloomRepo.Add(ctx, id); err != nil {
+		logrus.Warnf("failed to add restored article %d to bloom filter: %v", id, err)
+	}
+	if ar.Status == domain.ArticleStatusPublished {
+		a.restoreTitle(ctx, id)
+	}
 	return nil
 }
 
-// Delete 删除文章
-func (a *service) Delete(ctx context.Context, id int64) error {
+// SetHidden 隐藏或取消隐藏文章
+func (a *service) SetHidden(ctx context.Context, id int64, hidden bool) error {
 	if err := a.mustExists(ctx, id); err != nil {
 		return err
 	}
 
-	return a.articleRepo.Delete(ctx, id)
+	if err := a.articleRepo.SetHidden(ctx, id, hidden); err != nil {
+		return err
+	}
+
+	if hidden {
+		a.removeTitle(ctx, id)
+	} else {
+		a.restoreTitle(ctx, id)
+	}
+	return nil
+}
+
+// SetCommentsLocked 开启或关闭评论，只有作者和管理员可以操作
+func (a *service) SetCommentsLocked(ctx context.Context, id int64, actor domain.User, locked bool) error {
+	if _, err := a.getOwned(ctx, id, actor); err != nil {
+		return err
+	}
+
+	return a.articleRepo.SetCommentsLocked(ctx, id, locked)
+}
+
+// getOwned 返回 actor 有权管理的文章，文章不存在时返回 ErrNotFound，
+// actor 既不是作者也不是管理员时返回 ErrForbidden
+func (a *service) getOwned(ctx context.Context, id int64, actor domain.User) (domain.Article, error) {
+	ars, err := a.getPublished(ctx, id)
+	if err != nil {
+		return domain.Article{}, err
+	}
+	if len(ars) == 0 {
+		// 草稿只有作者本人能找到，对其他人和管理员都和不存在一样
+		return a.articleRepo.GetDraft(ctx, id, actor.ID)
+	}
+	if actor.Role != domain.RoleAdmin && ars[0].User.ID != actor.ID {
+		return domain.Article{}, domain.ErrForbidden
+	}
+	return ars[0], nil
+}
+
+// getPublished 读取已发布的文章，不存在时返回空切片而不是错误，方便调用方再查草稿
+func (a *service) getPublished(ctx context.Context, id int64) ([]domain.Article, error) {
+	if err := a.mustExists(ctx, id); err != nil {
+		return nil, nil
+	}
+	return a.articleRepo.GetByIDs(ctx, []int64{id})
+}
+
+// AddLikeRecord 添加点赞记录，返回点赞之后的状态和点赞数
+func (a *service) AddLikeRecord(ctx context.Context, likeRecord domain.UserLike) (domain.LikeState, error) {
+	ok, err := a.addLikeRecord(ctx, likeRecord)
+	if err != nil {
+		return domain.LikeState{}, err
+	}
+	return a.likeState(ctx, likeRecord.ArticleID, ok, true)
 }
 
-// AddLikeRecord 添加点赞记录
-func (a *service) AddLikeRecord(ctx context.Context, likeRecord domain.UserLike) (bool, error) {
+func (a *service) addLikeRecord(ctx context.Context, likeRecord domain.UserLike) (bool, error) {
 	if err := a.mustExists(ctx, likeRecord.ArticleID); err != nil {
 		return false, err
 	}
+	a.initLikeCount(ctx, likeRecord.ArticleID)
 
 	// 尝试从缓存添加点赞
 	ok, err := a.articleCache.AddLikeRecord(ctx, likeRecord)
 	if err != nil {
+		// Redis 不可用时点赞无法去重，直接失败而不是回源数据库
+		if errors.Is(err, domain.ErrCacheUnavailable) {
+			return false, domain.ErrCacheUnavailable
+		}
 		if errors.Is(err, domain.ErrCacheMiss) {
 			// 缓存未命中，从数据库加载用户点赞列表
 			likedArticles, err := a.articleRepo.FetchUserLikedArticles(ctx, likeRecord.UserID, domain.LikeRecordLimit)
@@ -132,20 +442,38 @@ func (a *service) AddLikeRecord(ctx context.Context, likeRecord domain.UserLike)
 	// 发送到worker异步同步到数据库
 	if ok {
 		a.syncLikesWorker.Send(likeRecord, domain.Like)
+		if info, hasInfo := domain.ClientInfoFrom(ctx); hasInfo {
+			if err := a.articleCache.RecordTraffic(ctx, domain.TrafficLikes, time.Now(), info); err != nil {
+				logrus.Debugf("failed to record like traffic of article %d: %v", likeRecord.ArticleID, err)
+			}
+		}
 	}
 
 	return ok, nil
 }
 
-// RemoveLikeRecord 移除点赞记录
-func (a *service) RemoveLikeRecord(ctx context.Context, likeRecord domain.UserLike) (bool, error) {
+// RemoveLikeRecord 移除点赞记录，返回取消之后的状态和点赞数
+func (a *service) RemoveLikeRecord(ctx context.Context, likeRecord domain.UserLike) (domain.LikeState, error) {
+	ok, err := a.removeLikeRecord(ctx, likeRecord)
+	if err != nil {
+		return domain.LikeState{}, err
+	}
+	return a.likeState(ctx, likeRecord.ArticleID, ok, false)
+}
+
+func (a *service) removeLikeRecord(ctx context.Context, likeRecord domain.UserLike) (bool, error) {
 	if err := a.mustExists(ctx, likeRecord.ArticleID); err != nil {
 		return false, err
 	}
+	a.initLikeCount(ctx, likeRecord.ArticleID)
 
 	// 尝试从缓存移除点赞
 	ok, err := a.articleCache.DecrLikeRecord(ctx, likeRecord)
 	if err != nil {
+		// Redis 不可用时点赞无法去重，直接失败而不是回源数据库
+		if errors.Is(err, domain.ErrCacheUnavailable) {
+			return false, domain.ErrCacheUnavailable
+		}
 		if errors.Is(err, domain.ErrCacheMiss) {
 			// 缓存未命中
 			likedArticles, err := a.articleRepo.FetchUserLikedArticles(ctx, likeRecord.UserID, domain.LikeRecordLimit)
@@ -181,14 +509,63 @@ func (a *service) RemoveLikeRecord(ctx context.Context, likeRecord domain.UserLi
 	return ok, nil
 }
 
+// initLikeCount 点赞脚本只在 Redis 中已有计数时增减点赞数，没有计数时先用文章中的点赞数初始化，
+// 否则这次点赞要等同步任务落库后才能体现在点赞数中
+func (a *service) initLikeCount(ctx context.Context, aid int64) {
+	if _, err := a.articleCache.GetLikeCount(ctx, aid); !errors.Is(err, domain.ErrCacheMiss) {
+		return
+	}
+	if _, err := a.loadLikeCount(ctx, aid); err != nil {
+		logrus.Warnf("failed to init like count of article %d: %v", aid, err)
+	}
+}
+
+// loadLikeCount 用文章中的点赞数初始化 Redis 中的计数，返回初始化后的计数。
+// 计数已经存在时不覆盖，Redis 不可用时返回文章中的点赞数
+func (a *service) loadLikeCount(ctx context.Context, aid int64) (int64, error) {
+	ars, err := a.articleRepo.GetByIDs(ctx, []int64{aid})
+	if err != nil {
+		return 0, err
+	}
+	if len(ars) == 0 {
+		return 0, domain.ErrNotFound
+	}
+	likes, err := a.articleCache.InitLikeCount(ctx, aid, ars[0].Likes)
+	if err != nil {
+		logrus.Warnf("failed to init like count of article %d: %v", aid, err)
+		return ars[0].Likes, nil
+	}
+	return likes, nil
+}
+
+// likeState 读取点赞或取消之后的点赞数。同一个请求中的点赞脚本已经更新了 Redis 中的计数，
+// 计数不存在时才用文章中尚未包含这次操作的点赞数
+func (a *service) likeState(ctx context.Context, aid int64, changed, liked bool) (domain.LikeState, error) {
+	likes, err := a.articleCache.GetLikeCount(ctx, aid)
+	if err != nil {
+		if likes, err = a.loadLikeCount(ctx, aid); err != nil {
+			return domain.LikeState{}, err
+		}
+	}
+	return domain.LikeState{Changed: changed, Liked: liked, Likes: likes}, nil
+}
+
 // FetchDailyRank 获取每日热榜
-func (a *service) FetchDailyRank(ctx context.Context, limit int64) ([]domain.Article, error) {
-	return a.articleRepo.GetDailyRank(ctx, limit)
+func (a *service) FetchDailyRank(ctx context.Context, offset, limit int64) ([]domain.Article, error) {
+	articles, err := a.articleRepo.GetDailyRank(ctx, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	return a.withViewsDisplay(ctx, articles), nil
 }
 
 // FetchHistoryRank 获取历史热榜
 func (a *service) FetchHistoryRank(ctx context.Context, limit int64) ([]domain.Article, error) {
-	return a.articleRepo.GetHistoryRank(ctx, limit)
+	articles, err := a.articleRepo.GetHistoryRank(ctx, limit)
+	if err != nil {
+		return nil, err
+	}
+	return a.withViewsDisplay(ctx, articles), nil
 }
 
 // InitBloomFilter 初始化布隆过滤器
@@ -201,23 +578,33 @@ func (a *service) InitBloomFilter(ctx context.Context) error {
 	idBatchChan := make(chan []int64, WorkerCount*2)
 	g, ctx := errgroup.WithContext(ctx)
 
-	// 启动消费者（Redis Writers）
+	// 启动消费者（Redis Writers），每批之间检查 ctx，关闭时不必等整个扫描结束
 	for range WorkerCount {
 		g.Go(func() error {
-			for ids := range idBatchChan {
-				if err := a.bloomRepo.BulkAdd(ctx, ids); err != nil {
-					return err
+			for {
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case ids, ok := <-idBatchChan:
+					if !ok {
+						return nil
+					}
+					if err := a.bloomRepo.BulkAdd(ctx, ids); err != nil {
+						return err
+					}
 				}
 			}
-			return nil
 		})
 	}
 
-	// 启动生产者
+	// 启动生产者，按 ID 分批扫描，单次查询最多 BatchSize 行
 	g.Go(func() error {
 		defer close(idBatchChan)
 		var cursor int64 = 0
 		for {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
 			ids, err := a.articleRepo.FetchIDs(ctx, cursor, BatchSize)
 			if err != nil {
 				return err
@@ -253,7 +640,15 @@ func (a *service) mustExists(ctx context.Context, id int64) error {
 	return nil
 }
 
-// encodeCursor 编码cursor
-func encodeCursor(t time.Time) string {
-	return t.Format(time.RFC3339Nano)
+// normalizeLanguage 把文章的语言标签转为规范形式，不合法时返回 ErrBadParamInput，空标签表示未指定
+func normalizeLanguage(ar *domain.Article) error {
+	if ar.Language == "" {
+		return nil
+	}
+	lang, ok := domain.NormalizeLanguage(ar.Language)
+	if !ok {
+		return domain.ErrBadParamInput
+	}
+	ar.Language = lang
+	return nil
 }
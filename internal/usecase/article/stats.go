@@ -0,0 +1,50 @@
+package article
+
+import (
+	"context"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+)
+
+// AuthorizeStats 只有作者和管理员可以查看文章的实时统计
+func (a *service) AuthorizeStats(ctx context.Context, id int64, actor domain.User) error {
+	_, err := a.getOwned(ctx, id, actor)
+	return err
+}
+
+// LiveStats 浏览量为缓存中的文章浏览量加上尚未落库的增量，点赞数优先读点赞计数缓存。
+// 文章详情、浏览增量和点赞数都按 ID 批量读取，每次推送的 Redis 往返次数与文章数无关，
+// 只有被淘汰的文章会回源数据库
+func (a *service) LiveStats(ctx context.Context, ids []int64) (map[int64]domain.ArticleStats, error) {
+	if len(ids) == 0 {
+		return map[int64]domain.ArticleStats{}, nil
+	}
+
+	articles, err := a.articleRepo.GetByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	buffered, err := a.articleCache.MGetBufferedViews(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	likes, err := a.articleCache.MGetLikeCounts(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make(map[int64]domain.ArticleStats, len(articles))
+	for _, ar := range articles {
+		// 没有点赞计数时使用文章中的点赞数
+		arLikes, ok := likes[ar.ID]
+		if !ok {
+			arLikes = ar.Likes
+		}
+		res[ar.ID] = domain.ArticleStats{
+			Views: ar.Views + buffered[ar.ID],
+			Likes: arLikes,
+		}
+	}
+	return res, nil
+}
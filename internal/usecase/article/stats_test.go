@@ -0,0 +1,63 @@
+package article_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+)
+
+// statsCache 在 viewsCache 的基础上返回预置的点赞数，没有预置的文章视为没有计数
+type statsCache struct {
+	viewsCache
+	likes     map[int64]int64
+	likeMGets int
+}
+
+func (c *statsCache) MGetLikeCounts(_ context.Context, ids []int64) (map[int64]int64, error) {
+	c.likeMGets++
+	res := make(map[int64]int64)
+	for _, id := range ids {
+		if likes, ok := c.likes[id]; ok {
+			res[id] = likes
+		}
+	}
+	return res, nil
+}
+
+func TestLiveStats(t *testing.T) {
+	repo := &fakeArticleRepo{articles: map[int64]domain.Article{
+		1: {ID: 1, Views: 100, Likes: 3},
+		2: {ID: 2, Views: 50, Likes: 4},
+	}}
+	cache := &statsCache{
+		viewsCache: viewsCache{buffered: map[int64]int64{1: 7}},
+		likes:      map[int64]int64{1: 5},
+	}
+	svc := newService(serviceDeps{repo: repo, cache: cache, titleIndex: newFakeTitleIndex()})
+
+	stats, err := svc.LiveStats(context.Background(), []int64{1, 2, 3})
+	require.NoError(t, err)
+	assert.Equal(t, map[int64]domain.ArticleStats{
+		1: {Views: 107, Likes: 5},
+		2: {Views: 50, Likes: 4}, // 点赞数缓存未命中时使用文章中的点赞数
+	}, stats)
+	// 点赞数一次批量读取，不按文章逐个读
+	assert.Equal(t, 1, cache.likeMGets)
+}
+
+func TestAuthorizeStatsOnlyAuthorOrAdmin(t *testing.T) {
+	ctx := context.Background()
+	repo := &fakeArticleRepo{articles: map[int64]domain.Article{
+		1: {ID: 1, User: domain.User{ID: 7}},
+	}}
+	svc := newService(serviceDeps{repo: repo, titleIndex: newFakeTitleIndex()})
+
+	require.NoError(t, svc.AuthorizeStats(ctx, 1, domain.User{ID: 7, Role: domain.RoleUser}))
+	require.NoError(t, svc.AuthorizeStats(ctx, 1, domain.User{ID: 9, Role: domain.RoleAdmin}))
+	require.ErrorIs(t, svc.AuthorizeStats(ctx, 1, domain.User{ID: 8, Role: domain.RoleUser}), domain.ErrForbidden)
+	require.ErrorIs(t, svc.AuthorizeStats(ctx, 2, domain.User{ID: 7}), domain.ErrNotFound)
+}
@@ -0,0 +1,65 @@
+package article
+
+import (
+	"context"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+)
+
+// titleIndexBatchSize 重建标题索引时每次从数据库读取的文章数
+const titleIndexBatchSize = 1000
+
+// SuggestTitles 按标题前缀联想文章，查询至少 MinSuggestQueryRunes 个字符
+func (a *service) SuggestTitles(ctx context.Context, query string, limit int64) ([]domain.TitleSuggestion, error) {
+	query = strings.TrimSpace(query)
+	if utf8.RuneCountInString(query) < domain.MinSuggestQueryRunes {
+		return nil, domain.ErrBadParamInput
+	}
+	return a.titleIndex.Suggest(ctx, query, limit)
+}
+
+// RebuildTitleIndex 从数据库重建标题索引，用于冷启动或索引与数据库不一致时
+func (a *service) RebuildTitleIndex(ctx context.Context) error {
+	var (
+		titles []domain.TitleSuggestion
+		cursor int64
+	)
+	for {
+		batch, err := a.articleRepo.FetchTitles(ctx, cursor, titleIndexBatchSize)
+		if err != nil {
+			return err
+		}
+		if len(batch) == 0 {
+			break
+		}
+		titles = append(titles, batch...)
+		cursor = batch[len(batch)-1].ID
+	}
+	return a.titleIndex.Replace(ctx, titles)
+}
+
+// removeTitle 把文章移出标题索引，失败只记录日志，下次重建时修正
+func (a *service) removeTitle(ctx context.Context, id int64) {
+	if err := a.titleIndex.Remove(ctx, id); err != nil {
+		logrus.Warnf("failed to remove article %d from title index: %v", id, err)
+	}
+}
+
+// restoreTitle 取消隐藏或恢复删除后把文章重新加入标题索引
+func (a *service) restoreTitle(ctx context.Context, id int64) {
+	ars, err := a.articleRepo.GetByIDs(ctx, []int64{id})
+	if err != nil {
+		logrus.Warnf("failed to restore article %d to title index: %v", id, err)
+		return
+	}
+	if len(ars) == 0 {
+		return
+	}
+	if err := a.titleIndex.Set(ctx, id, ars[0].Title); err != nil {
+		logrus.Warnf("failed to restore article %d to title index: %v", id, err)
+	}
+}
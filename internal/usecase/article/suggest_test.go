@@ -0,0 +1,69 @@
+package article_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+)
+
+func TestTitleIndexFollowsArticleWrites(t *testing.T) {
+	ctx := context.Background()
+	repo := &fakeArticleRepo{articles: map[int64]domain.Article{}}
+	index := newFakeTitleIndex()
+	svc := newService(serviceDeps{repo: repo, titleIndex: index})
+
+	ar := &domain.Article{ID: 1, Title: "并发编程入门", Content: "正文"}
+	require.NoError(t, svc.Store(ctx, ar))
+	repo.articles[1] = *ar
+	assert.Equal(t, map[int64]string{1: "并发编程入门"}, index.titles)
+
+	require.NoError(t, svc.Update(ctx, &domain.Article{ID: 1, Title: "并发编程进阶"}))
+	assert.Equal(t, "并发编程进阶", index.titles[1])
+	repo.articles[1] = domain.Article{ID: 1, Title: "并发编程进阶"}
+
+	// 隐藏期间改标题不会重新出现在联想中，取消隐藏后恢复
+	require.NoError(t, svc.SetHidden(ctx, 1, true))
+	assert.Empty(t, index.titles)
+	require.NoError(t, svc.Update(ctx, &domain.Article{ID: 1, Title: "并发编程进阶"}))
+	assert.Empty(t, index.titles)
+	require.NoError(t, svc.SetHidden(ctx, 1, false))
+	assert.Equal(t, map[int64]string{1: "并发编程进阶"}, index.titles)
+
+	require.NoError(t, svc.Delete(ctx, 1, 0))
+	assert.Empty(t, index.titles)
+}
+
+func TestSuggestTitlesRejectsShortQuery(t *testing.T) {
+	index := newFakeTitleIndex()
+	index.titles[1] = "中文标题"
+	svc := newService(serviceDeps{repo: &fakeArticleRepo{}, titleIndex: index})
+
+	for _, q := range []string{"", "g", " 中 "} {
+		_, err := svc.SuggestTitles(context.Background(), q, 5)
+		assert.ErrorIs(t, err, domain.ErrBadParamInput, q)
+	}
+
+	// 长度按字符而不是字节计算
+	res, err := svc.SuggestTitles(context.Background(), "中文", 5)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.TitleSuggestion{{ID: 1, Title: "中文标题"}}, res)
+}
+
+func TestRebuildTitleIndexSkipsHidden(t *testing.T) {
+	repo := &fakeArticleRepo{articles: map[int64]domain.Article{}}
+	for id := int64(1); id <= 2500; id++ {
+		repo.articles[id] = domain.Article{ID: id, Title: "title", Hidden: id == 7}
+	}
+	index := newFakeTitleIndex()
+	index.titles[9999] = "deleted"
+	svc := newService(serviceDeps{repo: repo, titleIndex: index})
+
+	require.NoError(t, svc.RebuildTitleIndex(context.Background()))
+	assert.Len(t, index.titles, 2499)
+	assert.NotContains(t, index.titles, int64(7))
+	assert.NotContains(t, index.titles, int64(9999))
+}
@@ -0,0 +1,104 @@
+package article
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+)
+
+const ellipsis = "…"
+
+var (
+	mdCodeFence  = regexp.MustCompile("(?s)```.*?(```|$)")
+	mdLinePrefix = regexp.MustCompile(`^\s*(>\s*)*([-*+]\s+|\d+\.\s+)?`)
+	mdHeading    = regexp.MustCompile(`^\s*#{1,6}\s`)
+	mdRule       = regexp.MustCompile(`^\s*([-*_]\s*){3,}$`)
+	mdImage      = regexp.MustCompile(`!\[[^\]]*\]\([^)]*\)`)
+	mdLink       = regexp.MustCompile(`\[([^\]]*)\]\([^)]*\)`)
+	mdInlineCode = regexp.MustCompile("`([^`]*)`")
+	mdEmphasis   = regexp.MustCompile(`\*+|__|~~`)
+	whitespace   = regexp.MustCompile(`\s+`)
+)
+
+// FirstParagraphSummarizer 是默认的摘要生成方式，取正文的第一个段落
+type FirstParagraphSummarizer struct{}
+
+func (FirstParagraphSummarizer) Summarize(_ context.Context, content string) (string, error) {
+	return generateSummary(content), nil
+}
+
+// summarize 用配置的 summarizer 生成自动摘要，出错时退回第一段，结果过长时截断
+func (a *service) summarize(ctx context.Context, content string) string {
+	summary, err := a.summarizer.Summarize(ctx, content)
+	if err != nil {
+		logrus.Warnf("failed to summarize article, using the first paragraph: %v", err)
+		return generateSummary(content)
+	}
+	return ellipsize(strings.TrimSpace(summary), domain.MaxSummaryRunes)
+}
+
+// generateSummary 从 markdown 正文生成摘要：去掉标记，取第一个有文字的段落，
+// 超过 domain.MaxSummaryRunes 个字符时截断并加省略号
+func generateSummary(content string) string {
+	content = mdCodeFence.ReplaceAllString(content, "\n\n")
+	for _, paragraph := range strings.Split(content, "\n\n") {
+		if text := stripMarkdown(paragraph); text != "" {
+			return ellipsize(text, domain.MaxSummaryRunes)
+		}
+	}
+	return ""
+}
+
+// stripMarkdown 把一个段落转成纯文本，标题和分隔线不算正文
+func stripMarkdown(paragraph string) string {
+	var lines []string
+	for _, line := range strings.Split(paragraph, "\n") {
+		if mdHeading.MatchString(line) || mdRule.MatchString(line) {
+			continue
+		}
+		line = mdLinePrefix.ReplaceAllString(line, "")
+		line = mdImage.ReplaceAllString(line, "")
+		line = mdLink.ReplaceAllString(line, "$1")
+		line = mdInlineCode.ReplaceAllString(line, "$1")
+		line = mdEmphasis.ReplaceAllString(line, "")
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return whitespace.ReplaceAllString(strings.Join(lines, " "), " ")
+}
+
+// ellipsize 按字符（rune）截断到最多 max 个字符（含省略号）。
+// 中日韩文字可以在任意字符处截断；拉丁文字尽量退回到上一个空格，避免截断单词
+func ellipsize(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+
+	cut := runes[:max-1]
+	if isWordRune(runes[max-2]) && isWordRune(runes[max-1]) {
+		for i := len(cut) - 1; i > len(cut)/2; i-- {
+			if unicode.IsSpace(cut[i]) {
+				cut = cut[:i]
+				break
+			}
+		}
+	}
+	return strings.TrimRightFunc(string(cut), func(r rune) bool {
+		return unicode.IsSpace(r) || unicode.IsPunct(r)
+	}) + ellipsis
+}
+
+// isWordRune 判断字符是否属于以空格分词的文字
+func isWordRune(r rune) bool {
+	if unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul) {
+		return false
+	}
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
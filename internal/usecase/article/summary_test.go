@@ -0,0 +1,148 @@
+package article_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+)
+
+func storeArticle(t *testing.T, ar domain.Article) domain.Article {
+	t.Helper()
+	repo := &fakeArticleRepo{}
+	svc := newService(serviceDeps{repo: repo, titleIndex: newFakeTitleIndex()})
+	require.NoError(t, svc.Store(context.Background(), &ar))
+	require.Len(t, repo.stored, 1)
+	return repo.stored[0]
+}
+
+func TestStoreGeneratesSummary(t *testing.T) {
+	longCJK := strings.Repeat("中文摘要测试", 60)
+	longLatin := strings.Repeat("lorem ipsum dolor ", 30)
+
+	cases := []struct {
+		name    string
+		content string
+		summary string
+	}{
+		{"plain", "Hello world.\n\nSecond paragraph.", "Hello world."},
+		{
+			"markdown stripped",
+			"# Title\n\n> **Go** is [fun](https://go.dev) with `gofmt` ![logo](a.png)\n> really\n\nmore",
+			"Go is fun with gofmt really",
+		},
+		{"code block skipped", "```go\nfmt.Println()\n```\n\n- first item\n- second item", "first item second item"},
+		{"cjk cut at rune", longCJK, string([]rune(longCJK)[:domain.MaxSummaryRunes-1]) + "…"},
+		{"latin keeps words", longLatin, strings.TrimSpace(longLatin[:len("lorem ipsum dolor ")*16+len("lorem ipsum")]) + "…"},
+		{"empty", "# Only a heading", ""},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			stored := storeArticle(t, domain.Article{Title: "t", Content: tc.content})
+			assert.Equal(t, tc.summary, stored.Summary)
+			assert.True(t, stored.SummaryIsAuto)
+			assert.LessOrEqual(t, utf8.RuneCountInString(stored.Summary), domain.MaxSummaryRunes)
+		})
+	}
+}
+
+func TestStoreKeepsAuthorSummary(t *testing.T) {
+	stored := storeArticle(t, domain.Article{Title: "t", Content: "content", Summary: " 作者写的摘要 "})
+	assert.Equal(t, "作者写的摘要", stored.Summary)
+	assert.False(t, stored.SummaryIsAuto)
+}
+
+func TestStoreRejectsLongSummary(t *testing.T) {
+	svc := newService(serviceDeps{repo: &fakeArticleRepo{}, titleIndex: newFakeTitleIndex()})
+	ar := domain.Article{Title: "t", Content: "c", Summary: strings.Repeat("长", domain.MaxSummaryRunes+1)}
+	assert.ErrorIs(t, svc.Store(context.Background(), &ar), domain.ErrBadParamInput)
+}
+
+func TestStoreConflictCarriesExistingID(t *testing.T) {
+	repo := &fakeArticleRepo{articles: map[int64]domain.Article{42: {ID: 42, Title: "t"}}}
+	svc := newService(serviceDeps{repo: repo, titleIndex: newFakeTitleIndex()})
+
+	err := svc.Store(context.Background(), &domain.Article{Title: "t", Content: "c"})
+	require.ErrorIs(t, err, domain.ErrConflict)
+	var conflict *domain.ConflictError
+	require.ErrorAs(t, err, &conflict)
+	assert.Equal(t, int64(42), conflict.ExistingID)
+	assert.Empty(t, repo.stored)
+}
+
+func TestUpdateRegeneratesAutoSummary(t *testing.T) {
+	repo := &fakeArticleRepo{}
+	svc := newService(serviceDeps{repo: repo, titleIndex: newFakeTitleIndex()})
+
+	require.NoError(t, svc.Update(context.Background(), &domain.Article{ID: 1, Content: "新的正文。"}))
+	require.NoError(t, svc.Update(context.Background(), &domain.Article{ID: 1, Title: "only title"}))
+
+	require.Len(t, repo.updated, 2)
+	assert.Equal(t, "新的正文。", repo.updated[0].Summary)
+	assert.True(t, repo.updated[0].SummaryIsAuto)
+	assert.Empty(t, repo.updated[1].Summary, "summary is left alone when content does not change")
+}
+
+// fakeSummarizer 返回预置的摘要，记录被调用的次数；err 不为空时模拟外部服务失败
+type fakeSummarizer struct {
+	summary string
+	err     error
+	calls   int
+}
+
+func (s *fakeSummarizer) Summarize(context.Context, string) (string, error) {
+	s.calls++
+	return s.summary, s.err
+}
+
+func TestStoreUsesSummarizer(t *testing.T) {
+	long := strings.Repeat("word ", domain.MaxSummaryRunes)
+	cases := []struct {
+		name       string
+		summarizer *fakeSummarizer
+		summary    string
+	}{
+		{"generated", &fakeSummarizer{summary: " 外部生成的摘要 "}, "外部生成的摘要"},
+		// 外部服务失败时不影响创建文章，退回第一段
+		{"error falls back", &fakeSummarizer{err: errors.New("timeout")}, "第一段。"},
+		{"truncated", &fakeSummarizer{summary: long}, ""},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := &fakeArticleRepo{}
+			svc := newService(serviceDeps{repo: repo, titleIndex: newFakeTitleIndex(), summarizer: tc.summarizer})
+
+			ar := domain.Article{Title: "t", Content: "第一段。\n\n第二段。"}
+			require.NoError(t, svc.Store(context.Background(), &ar))
+			require.Len(t, repo.stored, 1)
+			stored := repo.stored[0]
+			assert.True(t, stored.SummaryIsAuto)
+			assert.Equal(t, 1, tc.summarizer.calls)
+			if tc.summary != "" {
+				assert.Equal(t, tc.summary, stored.Summary)
+			} else {
+				assert.LessOrEqual(t, utf8.RuneCountInString(stored.Summary), domain.MaxSummaryRunes)
+			}
+		})
+	}
+}
+
+func TestStoreSkipsSummarizerForAuthorSummary(t *testing.T) {
+	summarizer := &fakeSummarizer{summary: "generated"}
+	repo := &fakeArticleRepo{}
+	svc := newService(serviceDeps{repo: repo, titleIndex: newFakeTitleIndex(), summarizer: summarizer})
+
+	require.NoError(t, svc.Store(context.Background(), &domain.Article{Title: "t", Content: "c", Summary: "作者写的摘要"}))
+	require.Len(t, repo.stored, 1)
+	assert.Equal(t, "作者写的摘要", repo.stored[0].Summary)
+	assert.False(t, repo.stored[0].SummaryIsAuto)
+	assert.Zero(t, summarizer.calls)
+}
@@ -0,0 +1,86 @@
+package article_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+)
+
+func TestNormalizeTags(t *testing.T) {
+	tooMany := make([]string, domain.MaxTagsPerArticle+1)
+	for i := range tooMany {
+		tooMany[i] = fmt.Sprintf("tag%d", i)
+	}
+
+	cases := []struct {
+		name string
+		in   []string
+		want []string
+		err  error
+	}{
+		{"nil keeps tags", nil, nil, nil},
+		{"empty removes tags", []string{}, []string{}, nil},
+		{"normalized and sorted", []string{" Redis ", "go", "GO", ""}, []string{"go", "redis"}, nil},
+		{"duplicates do not count", append(tooMany[:domain.MaxTagsPerArticle:domain.MaxTagsPerArticle], "TAG0"), tooMany[:domain.MaxTagsPerArticle], nil},
+		{"too many", tooMany, nil, domain.ErrBadParamInput},
+		{"too long", []string{strings.Repeat("长", domain.MaxTagRunes+1)}, nil, domain.ErrBadParamInput},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := domain.NormalizeTags(tc.in)
+			require.ErrorIs(t, err, tc.err)
+			if tc.err == nil {
+				assert.ElementsMatch(t, tc.want, got)
+				assert.Equal(t, tc.want == nil, got == nil)
+			}
+		})
+	}
+}
+
+func TestStoreNormalizesTags(t *testing.T) {
+	stored := storeArticle(t, domain.Article{Title: "t", Content: "c", Tags: []string{"Redis", "go", "redis"}})
+	assert.Equal(t, []string{"go", "redis"}, stored.Tags)
+}
+
+func TestUpdateKeepsTagsWhenNil(t *testing.T) {
+	repo := &fakeArticleRepo{articles: map[int64]domain.Article{
+		1: {ID: 1, Title: "t", Content: "c", User: domain.User{ID: 7}, Tags: []string{"go"}},
+	}}
+	svc := newService(serviceDeps{repo: repo, titleIndex: newFakeTitleIndex()})
+
+	ar := &domain.Article{ID: 1, Title: "new", User: domain.User{ID: 7}}
+	require.NoError(t, svc.Update(context.Background(), ar))
+	require.Len(t, repo.updated, 1)
+	assert.Nil(t, repo.updated[0].Tags, "the repository leaves the tags alone")
+	assert.Equal(t, []string{"go"}, ar.Tags)
+
+	ar = &domain.Article{ID: 1, User: domain.User{ID: 7}, Tags: []string{" Rust "}}
+	require.NoError(t, svc.Update(context.Background(), ar))
+	assert.Equal(t, []string{"rust"}, repo.updated[1].Tags)
+}
+
+// tagFilterRepo 记录 Fetch 收到的标签
+type tagFilterRepo struct {
+	pageRepo
+	tag *string
+}
+
+func (r tagFilterRepo) Fetch(_ context.Context, _ string, _ int64, _, tag string) ([]domain.Article, bool, error) {
+	*r.tag = tag
+	return nil, false, nil
+}
+
+func TestFetchNormalizesTag(t *testing.T) {
+	var tag string
+	svc := newService(serviceDeps{repo: tagFilterRepo{tag: &tag}, cache: &viewsCache{}})
+
+	_, _, err := svc.Fetch(context.Background(), "", 5, "", " GoLang ")
+	require.NoError(t, err)
+	assert.Equal(t, "golang", tag)
+}
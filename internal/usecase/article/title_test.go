@@ -0,0 +1,60 @@
+package article_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+)
+
+func TestStoreCleansTitle(t *testing.T) {
+	svc, repo := newDuplicateService(domain.Article{ID: 3, Title: "admin notice", User: domain.User{ID: 1}})
+
+	ar := &domain.Article{Title: "  Go\u200b  并发\u202e ", Content: "c", User: domain.User{ID: 1}}
+	require.NoError(t, svc.Store(context.Background(), ar))
+	require.Len(t, repo.stored, 1)
+	assert.Equal(t, "Go 并发", repo.stored[0].Title)
+
+	// 夹带零宽字符的标题与已有标题冲突，不能用来仿冒
+	err := svc.Store(context.Background(), &domain.Article{Title: "admin\u200b notice", Content: "d", User: domain.User{ID: 2}})
+	assert.ErrorIs(t, err, domain.ErrConflict)
+
+	// 只有不可见字符的标题视为空标题
+	err = svc.Store(context.Background(), &domain.Article{Title: "\u200b\u202e\ufeff", Content: "e", User: domain.User{ID: 1}})
+	assert.ErrorIs(t, err, domain.ErrBadParamInput)
+	assert.Len(t, repo.stored, 1)
+}
+
+func TestUpdateCleansTitle(t *testing.T) {
+	svc, repo := newDuplicateService(domain.Article{ID: 3, Title: "old", User: domain.User{ID: 1}})
+
+	require.NoError(t, svc.Update(context.Background(), &domain.Article{ID: 3, Title: "new\u2066 title"}))
+	require.Len(t, repo.updated, 1)
+	assert.Equal(t, "new title", repo.updated[0].Title)
+
+	err := svc.Update(context.Background(), &domain.Article{ID: 3, Title: "\u200e"})
+	assert.ErrorIs(t, err, domain.ErrBadParamInput)
+	assert.Len(t, repo.updated, 1)
+}
+
+func TestUpdateRejectsTakenTitle(t *testing.T) {
+	svc, repo := newDuplicateService(
+		domain.Article{ID: 3, Title: "old", User: domain.User{ID: 1}},
+		domain.Article{ID: 4, Title: "Go 并发", User: domain.User{ID: 2}},
+	)
+
+	// 清理后与其他文章的标题相同同样算冲突
+	err := svc.Update(context.Background(), &domain.Article{ID: 3, Title: "Go\u200b 并发"})
+	var conflict *domain.ConflictError
+	require.ErrorAs(t, err, &conflict)
+	assert.ErrorIs(t, err, domain.ErrConflict)
+	assert.Equal(t, int64(4), conflict.ExistingID)
+	assert.Empty(t, repo.updated)
+
+	// 保留自己原来的标题不算冲突
+	require.NoError(t, svc.Update(context.Background(), &domain.Article{ID: 3, Title: "old", Content: "c"}))
+	assert.Len(t, repo.updated, 1)
+}
@@ -0,0 +1,73 @@
+package article
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+)
+
+// GetByIDForViewer 在获取文章的同时读取登录用户的点赞状态。
+// 两者并发执行，登录用户的详情请求不会比匿名请求多出串行的往返；
+// 读取用户状态失败不影响文章本身的返回，只是不带 Viewer。
+// 已发布的文章中找不到时再查 viewer 自己的草稿，其他人看到的仍是 ErrNotFound
+func (a *service) GetByIDForViewer(ctx context.Context, id int64, viewerID int64) (domain.Article, error) {
+	if viewerID <= 0 {
+		return a.GetByID(ctx, id)
+	}
+
+	var (
+		art   domain.Article
+		state domain.ViewerState
+		vErr  error
+	)
+	g, gctx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		var err error
+		art, err = a.GetByID(gctx, id)
+		if errors.Is(err, domain.ErrNotFound) {
+			art, err = a.getDraft(gctx, id, viewerID)
+		}
+		return err
+	})
+	g.Go(func() error {
+		state, vErr = a.getViewerState(gctx, viewerID, id)
+		return nil
+	})
+	if err := g.Wait(); err != nil {
+		return domain.Article{}, err
+	}
+
+	if vErr != nil {
+		logrus.Warnf("failed to get viewer state of user %d on article %d: %v", viewerID, id, vErr)
+		return art, nil
+	}
+	art.Viewer = &state
+	return art, nil
+}
+
+// getViewerState 读取缓存中的用户状态。点赞集合不存在时与 AddLikeRecord 一样从数据库加载并回填缓存
+func (a *service) getViewerState(ctx context.Context, uid int64, aid int64) (domain.ViewerState, error) {
+	state, err := a.articleCache.GetViewerState(ctx, uid, aid)
+	if err != nil {
+		return domain.ViewerState{}, err
+	}
+	if state.Liked != nil {
+		return state, nil
+	}
+
+	likedArticles, err := a.articleRepo.FetchUserLikedArticles(ctx, uid, domain.LikeRecordLimit)
+	if err != nil {
+		return domain.ViewerState{}, err
+	}
+	if err := a.articleCache.SetUserLikedArticles(ctx, uid, likedArticles); err != nil {
+		logrus.Warnf("failed to SetUserLikedArticles: %v", err)
+	}
+	liked := slices.Contains(likedArticles, aid)
+	state.Liked = &liked
+	return state, nil
+}
@@ -0,0 +1,104 @@
+package article_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+)
+
+// viewerCache 模拟用户状态缓存，liked 中没有该用户时表示点赞集合不存在
+type viewerCache struct {
+	domain.ArticleCache
+	mu    sync.Mutex
+	liked map[int64][]int64
+	calls int
+	err   error
+}
+
+func (c *viewerCache) GetViewerState(_ context.Context, uid, aid int64) (domain.ViewerState, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls++
+	if c.err != nil {
+		return domain.ViewerState{}, c.err
+	}
+	var state domain.ViewerState
+	if ids, ok := c.liked[uid]; ok {
+		liked := slices.Contains(ids, aid)
+		state.Liked = &liked
+	}
+	return state, nil
+}
+
+func (c *viewerCache) SetUserLikedArticles(_ context.Context, uid int64, aids []int64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.liked[uid] = aids
+	return nil
+}
+
+// likedRepo 在 fakeArticleRepo 基础上提供数据库中的点赞记录
+type likedRepo struct {
+	*fakeArticleRepo
+	liked   map[int64][]int64
+	fetched int
+}
+
+func (r *likedRepo) FetchUserLikedArticles(_ context.Context, uid int64, _ int64) ([]int64, error) {
+	r.fetched++
+	return r.liked[uid], nil
+}
+
+func newViewerFixture() (*likedRepo, *viewerCache) {
+	repo := &likedRepo{
+		fakeArticleRepo: &fakeArticleRepo{articles: map[int64]domain.Article{1: {ID: 1, Title: "t"}}},
+		liked:           map[int64][]int64{7: {1}},
+	}
+	return repo, &viewerCache{liked: make(map[int64][]int64)}
+}
+
+func TestGetByIDForViewerAnonymousSkipsViewerState(t *testing.T) {
+	repo, cache := newViewerFixture()
+	svc := newService(serviceDeps{repo: repo, cache: cache})
+
+	got, err := svc.GetByIDForViewer(context.Background(), 1, 0)
+	require.NoError(t, err)
+	assert.Nil(t, got.Viewer)
+	assert.Zero(t, cache.calls)
+}
+
+func TestGetByIDForViewerFallsBackToDBForLikes(t *testing.T) {
+	repo, cache := newViewerFixture()
+	svc := newService(serviceDeps{repo: repo, cache: cache})
+
+	// 点赞集合不存在，从数据库加载并回填缓存
+	got, err := svc.GetByIDForViewer(context.Background(), 1, 7)
+	require.NoError(t, err)
+	require.NotNil(t, got.Viewer)
+	require.NotNil(t, got.Viewer.Liked)
+	assert.True(t, *got.Viewer.Liked)
+	assert.Equal(t, []int64{1}, cache.liked[7])
+
+	// 回填后直接命中缓存
+	_, err = svc.GetByIDForViewer(context.Background(), 1, 7)
+	require.NoError(t, err)
+	assert.Equal(t, 1, repo.fetched)
+}
+
+func TestGetByIDForViewerIgnoresViewerStateError(t *testing.T) {
+	repo, cache := newViewerFixture()
+	cache.err = errors.New("redis down")
+	svc := newService(serviceDeps{repo: repo, cache: cache})
+
+	got, err := svc.GetByIDForViewer(context.Background(), 1, 7)
+	require.NoError(t, err)
+	assert.Equal(t, "t", got.Title)
+	assert.Nil(t, got.Viewer)
+}
@@ -0,0 +1,87 @@
+package article
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+)
+
+// exactViewsThreshold 浏览量超过该值的文章在列表中只展示约数，不再合并缓冲区中的增量
+const exactViewsThreshold = 10_000
+
+// humanizeViews 把浏览量格式化为便于展示的形式：999, 1k, 10.5k, 1M。
+// 小数部分向下截断，避免展示的数字比实际大
+func humanizeViews(n int64) string {
+	switch {
+	case n < 1_000:
+		return strconv.FormatInt(n, 10)
+	case n < 1_000_000:
+		return formatUnit(n, 1_000, "k")
+	default:
+		return formatUnit(n, 1_000_000, "M")
+	}
+}
+
+// formatUnit 保留一位小数，小数为 0 时省略
+func formatUnit(n, unit int64, suffix string) string {
+	whole, tenth := n/unit, n%unit*10/unit
+	if tenth == 0 {
+		return strconv.FormatInt(whole, 10) + suffix
+	}
+	return strconv.FormatInt(whole, 10) + "." + strconv.FormatInt(tenth, 10) + suffix
+}
+
+// withViewsDisplay 返回填好 ViewsDisplay 的副本，带正文的文章同时生成 Excerpt。
+// 只有浏览量不超过 exactViewsThreshold 的文章才合并缓冲区里尚未落库的增量，
+// 热门文章差几次浏览看不出来，没必要为它们多查一次 Redis。
+// 入参可能与缓存回写共享底层数组，因此不在原切片上修改
+func (a *service) withViewsDisplay(ctx context.Context, articles []domain.Article) []domain.Article {
+	res := make([]domain.Article, len(articles))
+	copy(res, articles)
+
+	var ids []int64
+	for i := range res {
+		if res[i].Views <= exactViewsThreshold {
+			ids = append(ids, res[i].ID)
+		}
+	}
+	if len(ids) > 0 {
+		buffered, err := a.articleCache.MGetBufferedViews(ctx, ids)
+		if err != nil {
+			logrus.Warnf("failed to get buffered views: %v", err)
+		}
+		for i := range res {
+			res[i].Views += buffered[res[i].ID]
+		}
+	}
+
+	for i := range res {
+		res[i].ViewsDisplay = humanizeViews(res[i].Views)
+		if res[i].Content != "" {
+			res[i].Excerpt = generateExcerpt(res[i].Content, a.excerpt)
+		}
+	}
+	return res
+}
+
+// mergeBufferedLikes 用 Redis 中的实时点赞数覆盖列表中的点赞数，与文章详情保持一致。
+// 首页缓存和数据库中的点赞数要等同步任务落库后才更新；没有计数或 Redis 不可用时保留原值
+func (a *service) mergeBufferedLikes(ctx context.Context, articles []domain.Article) {
+	ids := make([]int64, len(articles))
+	for i := range articles {
+		ids[i] = articles[i].ID
+	}
+	likes, err := a.articleCache.MGetLikeCounts(ctx, ids)
+	if err != nil {
+		logrus.Warnf("failed to get buffered likes: %v", err)
+		return
+	}
+	for i := range articles {
+		if n, ok := likes[articles[i].ID]; ok {
+			articles[i].Likes = n
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package article_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+)
+
+func TestViewsDisplay(t *testing.T) {
+	cases := []struct {
+		views   int64
+		display string
+	}{
+		{0, "0"},
+		{999, "999"},
+		{1000, "1k"},
+		{1099, "1k"},
+		{1999, "1.9k"},
+		{10500, "10.5k"},
+		{999_999, "999.9k"},
+		{1_000_000, "1M"},
+		{1_250_000, "1.2M"},
+	}
+
+	repo := &fakeArticleRepo{}
+	for i, c := range cases {
+		// 缓冲区合并由下一个测试覆盖，这里缓存中不放增量
+		repo.stored = append(repo.stored, domain.Article{ID: int64(i + 1), Views: c.views})
+	}
+	svc := newService(serviceDeps{repo: repo, cache: &viewsCache{}})
+
+	got, err := svc.FetchDailyRank(context.Background(), 0, int64(len(cases)))
+	require.NoError(t, err)
+	require.Len(t, got, len(cases))
+	for i, c := range cases {
+		assert.Equal(t, c.display, got[i].ViewsDisplay, "views=%d", c.views)
+	}
+}
+
+func TestViewsMergeSkipsPopularArticles(t *testing.T) {
+	repo := &fakeArticleRepo{stored: []domain.Article{
+		{ID: 1, Views: 999},
+		{ID: 2, Views: 10_000},
+		{ID: 3, Views: 10_500},
+	}}
+	cache := &viewsCache{buffered: map[int64]int64{1: 1, 2: 600, 3: 100}}
+	svc := newService(serviceDeps{repo: repo, cache: cache})
+
+	got, err := svc.FetchDailyRank(context.Background(), 0, 3)
+	require.NoError(t, err)
+
+	// 超过阈值的文章不查询缓冲区，直接展示库中的浏览量
+	assert.ElementsMatch(t, []int64{1, 2}, cache.queried)
+	assert.Equal(t, int64(1000), got[0].Views)
+	assert.Equal(t, "1k", got[0].ViewsDisplay)
+	assert.Equal(t, int64(10_600), got[1].Views)
+	assert.Equal(t, "10.6k", got[1].ViewsDisplay)
+	assert.Equal(t, int64(10_500), got[2].Views)
+	assert.Equal(t, "10.5k", got[2].ViewsDisplay)
+
+	// 返回的是副本，仓储返回的切片不会被修改
+	assert.Equal(t, int64(999), repo.stored[0].Views)
+	assert.Empty(t, repo.stored[0].ViewsDisplay)
+}
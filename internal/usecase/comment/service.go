@@ -2,20 +2,31 @@ package comment
 
 import (
 	"context"
+	"strings"
 
 	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
 	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/repository"
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/textutil"
 	"github.com/sirupsen/logrus"
 )
 
 type service struct {
 	commentRepo domain.CommentRepository
 	bloomRepo   domain.BloomRepository
+	userRepo    domain.UserRepository
+	articleRepo domain.ArticleRepository
 }
 
+// mustExists 通过布隆过滤器检查文章是否存在。
+// 布隆过滤器本身出错时采用 fail-open 策略：记录日志后视为存在，由数据库兜底，
+// 避免 Redis 故障导致评论功能整体不可用
 func (s *service) mustExists(ctx context.Context, id int64) error {
 	exists, err := s.bloomRepo.Exists(ctx, id)
-	if err == nil && !exists {
+	if err != nil {
+		logrus.Warnf("failed to check article %d in bloom filter, assuming it exists: %v", id, err)
+		return nil
+	}
+	if !exists {
 		logrus.Warnf("bloom filter says article %d does not exist", id)
 		return domain.ErrNotFound
 	}
@@ -23,24 +34,63 @@ func (s *service) mustExists(ctx context.Context, id int64) error {
 	return nil
 }
 
+// Create 发表评论。回复时 root_id 由服务端根据父评论计算，不信任客户端传入的值。
+// 正文去掉不可见字符后保留原有的换行和空白，只剩空白时返回 ErrBadParamInput
 func (s *service) Create(ctx context.Context, c *domain.Comment) error {
+	if c.Content = textutil.CleanText(c.Content); strings.TrimSpace(c.Content) == "" {
+		return domain.ErrBadParamInput
+	}
+	if err := s.resolveThread(ctx, c); err != nil {
+		return err
+	}
 	if err := s.mustExists(ctx, c.ArticleID); err != nil {
-		if err == domain.ErrNotFound {
-			return domain.ErrNotFound
-		}
+		return err
+	}
+
+	// 锁定状态随文章一起缓存，通常不需要额外查询数据库；草稿也可以评论
+	ar, err := s.articleRepo.GetForComment(ctx, c.ArticleID)
+	if err != nil {
+		return err
+	}
+	if ar.CommentsLocked {
+		return domain.ErrCommentsLocked
 	}
 	return s.commentRepo.Store(ctx, c)
 }
 
-func (s *service) Delete(ctx context.Context, aid int64, uid int64) error {
-	return s.commentRepo.Delete(ctx, aid, uid)
+// resolveThread 设置评论的 root_id：一级评论为 0，回复一级评论时为父评论的 ID，
+// 回复其他回复时沿用父评论的 root_id。没有指定文章时使用父评论所在的文章，
+// 父评论不存在或不在同一篇文章下时返回 ErrNotFound
+func (s *service) resolveThread(ctx context.Context, c *domain.Comment) error {
+	if c.ParentID == 0 {
+		c.RootID = 0
+		return nil
+	}
+
+	parent, err := s.commentRepo.GetByID(ctx, c.ParentID)
+	if err != nil {
+		return err
+	}
+	if c.ArticleID == 0 {
+		c.ArticleID = parent.ArticleID
+	} else if parent.ArticleID != c.ArticleID {
+		return domain.ErrNotFound
+	}
+
+	c.RootID = parent.RootID
+	if c.RootID == 0 {
+		c.RootID = parent.ID
+	}
+	return nil
+}
+
+func (s *service) Delete(ctx context.Context, id int64, uid int64) error {
+	return s.commentRepo.Delete(ctx, id, uid)
 }
 
 func (s *service) FetchByArticle(ctx context.Context, articleID int64, cursor string, limit int64) ([]*domain.Comment, string, error) {
 	if err := s.mustExists(ctx, articleID); err != nil {
-		if err == domain.ErrNotFound {
-			return nil, "", domain.ErrNotFound
-		}
+		return nil, "", err
 	}
 	res, err := s.commentRepo.FetchRoots(ctx, articleID, cursor, limit)
 	if err != nil {
@@ -57,9 +107,16 @@ func (s *service) FetchByArticle(ctx context.Context, articleID int64, cursor st
 
 	replies, err := s.commentRepo.FetchReplies(ctx, rootIDs)
 	if err != nil {
+		if err := s.fillUsers(ctx, res); err != nil {
+			return nil, "", err
+		}
 		return res, "", nil
 	}
 
+	if err := s.fillUsers(ctx, res, replies); err != nil {
+		return nil, "", err
+	}
+
 	replyMap := make(map[int64][]*domain.Comment)
 	for _, r := range replies {
 		replyMap[r.RootID] = append(replyMap[r.RootID], r)
@@ -73,14 +130,51 @@ func (s *service) FetchByArticle(ctx context.Context, articleID int64, cursor st
 		}
 	}
 
-	return res, repository.EncodeCursor(res[len(res)-1].CreatedAt), nil
+	return res, repository.EncodeCursor(res[len(res)-1].CreatedAt, res[len(res)-1].ID), nil
+}
+
+// fillUsers 批量填充评论作者，账号已删除的作者使用占位用户
+func (s *service) fillUsers(ctx context.Context, groups ...[]*domain.Comment) error {
+	var userIDs []int64
+	seen := make(map[int64]bool)
+	for _, comments := range groups {
+		for _, c := range comments {
+			if !seen[c.UserID] {
+				seen[c.UserID] = true
+				userIDs = append(userIDs, c.UserID)
+			}
+		}
+	}
+
+	users, err := s.userRepo.GetByIDs(ctx, userIDs)
+	if err != nil {
+		return err
+	}
+	userMap := make(map[int64]domain.User, len(users))
+	for _, u := range users {
+		userMap[u.ID] = u
+	}
+
+	for _, comments := range groups {
+		for _, c := range comments {
+			u, ok := userMap[c.UserID]
+			if !ok {
+				u = domain.DeletedUser()
+			}
+			c.User = &u
+		}
+	}
+	return nil
 }
 
 var _ domain.CommentUsecase = (*service)(nil)
 
-func NewService(commentRepo domain.CommentRepository, bloomRepo domain.BloomRepository) *service {
+// NewService 创建评论服务，articleRepo 用于在发表评论前检查文章是否关闭了评论
+func NewService(commentRepo domain.CommentRepository, bloomRepo domain.BloomRepository, userRepo domain.UserRepository, articleRepo domain.ArticleRepository) *service {
 	return &service{
 		commentRepo: commentRepo,
 		bloomRepo:   bloomRepo,
+		userRepo:    userRepo,
+		articleRepo: articleRepo,
 	}
 }
@@ -0,0 +1,274 @@
+package comment_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/usecase/comment"
+)
+
+var errRedis = errors.New("redis is down")
+
+type fakeBloom struct {
+	domain.BloomRepository
+	exists bool
+	err    error
+}
+
+func (f fakeBloom) Exists(context.Context, int64) (bool, error) {
+	return f.exists, f.err
+}
+
+type fakeCommentRepo struct {
+	domain.CommentRepository
+	stored  []*domain.Comment
+	roots   []*domain.Comment
+	replies []*domain.Comment
+	calls   int
+	// existing 是 GetByID 能找到的评论
+	existing map[int64]*domain.Comment
+}
+
+func (f *fakeCommentRepo) GetByID(_ context.Context, id int64) (*domain.Comment, error) {
+	c, ok := f.existing[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return c, nil
+}
+
+func (f *fakeCommentRepo) FetchRoots(context.Context, int64, string, int64) ([]*domain.Comment, error) {
+	f.calls++
+	return f.roots, nil
+}
+
+func (f *fakeCommentRepo) FetchReplies(_ context.Context, rootIDs []int64) ([]*domain.Comment, error) {
+	f.calls++
+	wanted := make(map[int64]bool, len(rootIDs))
+	for _, id := range rootIDs {
+		wanted[id] = true
+	}
+	var res []*domain.Comment
+	for _, r := range f.replies {
+		if wanted[r.RootID] {
+			res = append(res, r)
+		}
+	}
+	return res, nil
+}
+
+// fakeUserRepo 记录 GetByIDs 的调用次数，用于确认作者是批量加载的
+type fakeUserRepo struct {
+	domain.UserRepository
+	users map[int64]domain.User
+	calls int
+}
+
+func (f *fakeUserRepo) GetByIDs(_ context.Context, ids []int64) ([]domain.User, error) {
+	f.calls++
+	var res []domain.User
+	for _, id := range ids {
+		if u, ok := f.users[id]; ok {
+			res = append(res, u)
+		}
+	}
+	return res, nil
+}
+
+func (f *fakeCommentRepo) Store(_ context.Context, c *domain.Comment) error {
+	f.stored = append(f.stored, c)
+	return nil
+}
+
+// fakeArticleRepo 保存文章，GetForComment 与真实实现一样不限发布状态，隐藏或不存在的文章返回 ErrNotFound
+type fakeArticleRepo struct {
+	domain.ArticleRepository
+	articles map[int64]domain.Article
+}
+
+func (f fakeArticleRepo) Store(_ context.Context, ar *domain.Article) error {
+	ar.ID = int64(len(f.articles) + 1)
+	f.articles[ar.ID] = *ar
+	return nil
+}
+
+func (f fakeArticleRepo) GetForComment(_ context.Context, id int64) (domain.Article, error) {
+	ar, ok := f.articles[id]
+	if !ok || ar.Hidden {
+		return domain.Article{}, domain.ErrNotFound
+	}
+	return ar, nil
+}
+
+func TestCreate(t *testing.T) {
+	cases := []struct {
+		name    string
+		bloom   fakeBloom
+		wantErr error
+		stored  bool
+	}{
+		{"exists", fakeBloom{exists: true}, nil, true},
+		{"not found", fakeBloom{exists: false}, domain.ErrNotFound, false},
+		// 布隆过滤器出错时 fail-open，仍然写入评论
+		{"bloom error", fakeBloom{err: errRedis}, nil, true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := &fakeCommentRepo{}
+			articles := fakeArticleRepo{articles: map[int64]domain.Article{1: {ID: 1}}}
+			svc := comment.NewService(repo, tc.bloom, nil, articles)
+
+			err := svc.Create(context.Background(), &domain.Comment{ArticleID: 1, Content: "hi"})
+			if tc.wantErr != nil {
+				require.ErrorIs(t, err, tc.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tc.stored, len(repo.stored) == 1)
+		})
+	}
+}
+
+func TestCreateCleansContent(t *testing.T) {
+	repo := &fakeCommentRepo{}
+	articles := fakeArticleRepo{articles: map[int64]domain.Article{1: {ID: 1}}}
+	svc := comment.NewService(repo, fakeBloom{exists: true}, nil, articles)
+
+	// 去掉不可见字符，换行和缩进保持原样
+	c := &domain.Comment{ArticleID: 1, Content: "写得\u200b好\r\n\n  \u202e谢谢"}
+	require.NoError(t, svc.Create(context.Background(), c))
+	require.Len(t, repo.stored, 1)
+	assert.Equal(t, "写得好\n\n  谢谢", repo.stored[0].Content)
+
+	err := svc.Create(context.Background(), &domain.Comment{ArticleID: 1, Content: " \u200b\n\ufeff "})
+	assert.ErrorIs(t, err, domain.ErrBadParamInput)
+	assert.Len(t, repo.stored, 1)
+}
+
+func TestCreateRejectsLockedArticle(t *testing.T) {
+	repo := &fakeCommentRepo{}
+	articles := fakeArticleRepo{articles: map[int64]domain.Article{
+		1: {ID: 1, CommentsLocked: true},
+		2: {ID: 2},
+	}}
+	svc := comment.NewService(repo, fakeBloom{exists: true}, nil, articles)
+	ctx := context.Background()
+
+	err := svc.Create(ctx, &domain.Comment{ArticleID: 1, Content: "hi"})
+	require.ErrorIs(t, err, domain.ErrCommentsLocked)
+	// 对外仍然是 ErrForbidden
+	require.ErrorIs(t, err, domain.ErrForbidden)
+
+	// 布隆过滤器误判存在、但文章已经不存在时返回 404
+	require.ErrorIs(t, svc.Create(ctx, &domain.Comment{ArticleID: 3, Content: "hi"}), domain.ErrNotFound)
+
+	require.NoError(t, svc.Create(ctx, &domain.Comment{ArticleID: 2, Content: "hi"}))
+	require.Len(t, repo.stored, 1)
+	assert.Equal(t, int64(2), repo.stored[0].ArticleID)
+}
+
+func TestCreateOnSharedDraft(t *testing.T) {
+	ctx := context.Background()
+	repo := &fakeCommentRepo{}
+	articles := fakeArticleRepo{articles: map[int64]domain.Article{}}
+	draft := &domain.Article{Title: "draft", User: domain.User{ID: 7}, Status: domain.ArticleStatusDraft}
+	require.NoError(t, articles.Store(ctx, draft))
+	svc := comment.NewService(repo, fakeBloom{exists: true}, nil, articles)
+
+	// 作者分享草稿链接后，其他人也可以评论
+	require.NoError(t, svc.Create(ctx, &domain.Comment{ArticleID: draft.ID, UserID: 8, Content: "hi"}))
+	require.Len(t, repo.stored, 1)
+	assert.Equal(t, draft.ID, repo.stored[0].ArticleID)
+}
+
+func TestCreateComputesRootID(t *testing.T) {
+	cases := []struct {
+		name     string
+		comment  domain.Comment
+		wantRoot int64
+		wantErr  error
+	}{
+		// 客户端伪造的 root_id 一律被覆盖
+		{"top level", domain.Comment{ArticleID: 1, RootID: 99}, 0, nil},
+		{"reply to root", domain.Comment{ArticleID: 1, ParentID: 10, RootID: 99}, 10, nil},
+		{"reply to reply", domain.Comment{ArticleID: 1, ParentID: 11, RootID: 99}, 10, nil},
+		{"article from parent", domain.Comment{ParentID: 11}, 10, nil},
+		{"missing parent", domain.Comment{ArticleID: 1, ParentID: 404}, 0, domain.ErrNotFound},
+		{"parent in other article", domain.Comment{ArticleID: 2, ParentID: 10}, 0, domain.ErrNotFound},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := &fakeCommentRepo{existing: map[int64]*domain.Comment{
+				10: {ID: 10, ArticleID: 1},
+				11: {ID: 11, ArticleID: 1, ParentID: 10, RootID: 10},
+			}}
+			articles := fakeArticleRepo{articles: map[int64]domain.Article{1: {ID: 1}, 2: {ID: 2}}}
+			svc := comment.NewService(repo, fakeBloom{exists: true}, nil, articles)
+
+			c := tc.comment
+			c.Content = "hi"
+			err := svc.Create(context.Background(), &c)
+			if tc.wantErr != nil {
+				require.ErrorIs(t, err, tc.wantErr)
+				assert.Empty(t, repo.stored)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, repo.stored, 1)
+			assert.Equal(t, tc.wantRoot, repo.stored[0].RootID)
+			assert.Equal(t, int64(1), repo.stored[0].ArticleID)
+		})
+	}
+}
+
+func TestFetchByArticleLoadsReplyAuthorsInBatch(t *testing.T) {
+	now := time.Now()
+	repo := &fakeCommentRepo{}
+	for i := int64(1); i <= 3; i++ {
+		repo.roots = append(repo.roots, &domain.Comment{ID: i, ArticleID: 1, UserID: i, CreatedAt: now})
+	}
+	// 回复数量远多于根评论，作者查询次数仍然固定
+	for i := int64(0); i < 30; i++ {
+		repo.replies = append(repo.replies, &domain.Comment{
+			ID: 100 + i, ArticleID: 1, UserID: 1 + i%4, RootID: 1 + i%3, ParentID: 1 + i%3,
+		})
+	}
+	users := &fakeUserRepo{users: map[int64]domain.User{
+		1: {ID: 1, Username: "alice"},
+		2: {ID: 2, Username: "bob"},
+		3: {ID: 3, Username: "carol"},
+		// 4 号用户已注销
+	}}
+	svc := comment.NewService(repo, fakeBloom{exists: true}, users, fakeArticleRepo{})
+
+	res, _, err := svc.FetchByArticle(context.Background(), 1, "", 10)
+	require.NoError(t, err)
+	require.Len(t, res, 3)
+
+	total := 0
+	for _, root := range res {
+		require.NotNil(t, root.User)
+		for _, r := range root.Replies {
+			require.NotNil(t, r.User)
+			assert.Equal(t, root.ID, r.RootID)
+			if r.UserID == 4 {
+				assert.Equal(t, domain.DeletedUser(), *r.User)
+			} else {
+				assert.Equal(t, r.UserID, r.User.ID)
+			}
+			total++
+		}
+	}
+	assert.Equal(t, 30, total)
+
+	assert.Equal(t, 2, repo.calls, "roots and replies should take one query each")
+	assert.Equal(t, 1, users.calls, "authors should be loaded in a single batch")
+}
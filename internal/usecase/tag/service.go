@@ -0,0 +1,33 @@
+package tag
+
+import (
+	"context"
+	"time"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+)
+
+// trendingResolution 统计起点取整到分钟，同一分钟内的请求命中同一份缓存
+const trendingResolution = time.Minute
+
+type service struct {
+	tagRepo domain.TagRepository
+}
+
+// Trending 统计最近 window 内加到可见文章上最多的标签
+func (s *service) Trending(ctx context.Context, window time.Duration, limit int64) ([]domain.TagCount, error) {
+	if window <= 0 || window > domain.MaxTrendingTagsWindow {
+		return nil, domain.ErrBadParamInput
+	}
+	since := time.Now().Add(-window).Truncate(trendingResolution)
+	return s.tagRepo.Trending(ctx, since, limit)
+}
+
+var _ domain.TagUsecase = (*service)(nil)
+
+// NewService 创建标签服务，标签的使用时间由文章的数据库操作层在保存标签时写入
+func NewService(tagRepo domain.TagRepository) *service {
+	return &service{
+		tagRepo: tagRepo,
+	}
+}
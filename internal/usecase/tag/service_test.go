@@ -0,0 +1,87 @@
+package tag_test
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/usecase/tag"
+)
+
+// tagUse 是一次把标签加到文章上
+type tagUse struct {
+	name string
+	at   time.Time
+}
+
+// fakeTagRepo 按加上标签的时间统计，排序方式与 mysql 实现一致
+type fakeTagRepo struct {
+	uses  []tagUse
+	since time.Time
+}
+
+func (f *fakeTagRepo) Trending(_ context.Context, since time.Time, limit int64) ([]domain.TagCount, error) {
+	f.since = since
+	counts := map[string]int64{}
+	var order []string
+	for _, u := range f.uses {
+		if u.at.Before(since) {
+			continue
+		}
+		if counts[u.name] == 0 {
+			order = append(order, u.name)
+		}
+		counts[u.name]++
+	}
+	res := make([]domain.TagCount, 0, len(order))
+	for _, name := range order {
+		res = append(res, domain.TagCount{Name: name, Articles: counts[name]})
+	}
+	slices.SortFunc(res, func(a, b domain.TagCount) int {
+		if c := cmp.Compare(b.Articles, a.Articles); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Name, b.Name)
+	})
+	if int64(len(res)) > limit {
+		res = res[:limit]
+	}
+	return res, nil
+}
+
+func TestTrendingRanksRecentTagsFirst(t *testing.T) {
+	now := time.Now()
+	repo := &fakeTagRepo{uses: []tagUse{
+		// go 总共用得最多，但都是一个月前加上的
+		{"go", now.Add(-40 * 24 * time.Hour)},
+		{"go", now.Add(-35 * 24 * time.Hour)},
+		{"go", now.Add(-31 * 24 * time.Hour)},
+		{"go", now.Add(-2 * 24 * time.Hour)},
+		{"rust", now.Add(-3 * 24 * time.Hour)},
+		{"rust", now.Add(-time.Hour)},
+		{"redis", now.Add(-time.Hour)},
+	}}
+	svc := tag.NewService(repo)
+
+	tags, err := svc.Trending(context.Background(), domain.DefaultTrendingTagsWindow, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.TagCount{{Name: "rust", Articles: 2}, {Name: "go", Articles: 1}, {Name: "redis", Articles: 1}}, tags)
+
+	// 统计起点取整到分钟，同一分钟内的请求使用同一份缓存
+	assert.Equal(t, repo.since, repo.since.Truncate(time.Minute))
+	assert.WithinDuration(t, now.Add(-domain.DefaultTrendingTagsWindow), repo.since, time.Minute)
+}
+
+func TestTrendingRejectsBadWindow(t *testing.T) {
+	svc := tag.NewService(&fakeTagRepo{})
+	for _, window := range []time.Duration{0, -time.Hour, domain.MaxTrendingTagsWindow + time.Hour} {
+		_, err := svc.Trending(context.Background(), window, 10)
+		assert.ErrorIs(t, err, domain.ErrBadParamInput, window)
+	}
+}
@@ -2,9 +2,12 @@ package user
 
 import (
 	"context"
+	"sort"
+	"strconv"
 	"time"
 
 	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/textutil"
 	"github.com/golang-jwt/jwt/v5"
 	"golang.org/x/crypto/bcrypt"
 )
@@ -13,13 +16,16 @@ type service struct {
 	userRepo  domain.UserRepository
 	jwtSecret []byte
 	ttl       time.Duration
+	signup    domain.SignupCache
 }
 
-func NewService(r domain.UserRepository, jwtSecret []byte, ttl time.Duration) *service {
+// NewService 创建用户服务。signup 为 nil 时注册令牌不生效，用户名查询也不限流
+func NewService(r domain.UserRepository, jwtSecret []byte, ttl time.Duration, signup domain.SignupCache) *service {
 	return &service{
 		userRepo:  r,
 		jwtSecret: jwtSecret,
 		ttl:       ttl,
+		signup:    signup,
 	}
 }
 
@@ -33,10 +39,27 @@ func checkPasswordHash(password, hash string) bool {
 	return err == nil
 }
 
+// normalizeBatch 规范化用户名时每次读取的用户数
+const normalizeBatch = 500
+
 func (s *service) Register(ctx context.Context, name, username, password string) error {
+	_, err := s.register(ctx, name, username, password)
+	return err
+}
+
+// register 创建用户并返回它，ID 已经回填
+func (s *service) register(ctx context.Context, name, username, password string) (domain.User, error) {
+	// 用户名统一存为小写，注册时输入的大小写保留在显示名中
+	if name = textutil.CleanLine(name); name == "" {
+		name = textutil.CleanLine(username)
+	}
+	username = normalizeUsername(username)
+	if username == "" {
+		return domain.User{}, domain.ErrBadParamInput
+	}
 	existingUser, err := s.userRepo.GetByUsername(ctx, username)
 	if err == nil && existingUser.ID != 0 {
-		return domain.ErrUserAlreadyExists
+		return domain.User{}, domain.ErrUserAlreadyExists
 	}
 
 	if password == "" {
@@ -44,19 +67,23 @@ func (s *service) Register(ctx context.Context, name, username, password string)
 	}
 	hashedPassword, err := hashPassword(password)
 	if err != nil {
-		return err
+		return domain.User{}, err
 	}
 
-	user := &domain.User{
+	user := domain.User{
 		Name:     name,
 		Username: username,
 		Password: hashedPassword,
+		Role:     domain.RoleUser,
 	}
-	return s.userRepo.Insert(ctx, user)
+	if err := s.userRepo.Insert(ctx, &user); err != nil {
+		return domain.User{}, err
+	}
+	return user, nil
 }
 
 func (s *service) Login(ctx context.Context, username, password string) (string, error) {
-	user, err := s.userRepo.GetByUsername(ctx, username)
+	user, err := s.userRepo.GetByUsername(ctx, normalizeUsername(username))
 	if err != nil {
 		return "", domain.ErrUserNotFound
 	}
@@ -64,18 +91,28 @@ func (s *service) Login(ctx context.Context, username, password string) (string,
 		return "", domain.ErrBadParamInput
 	}
 
-	token, err := s.generateJWT(user.ID, user.Username)
+	token, err := s.generateJWT(user.ID, user.Username, user.Role)
 	if err != nil {
 		return "", err
 	}
 	return token, nil
 }
 
-func (s *service) generateJWT(userID int64, username string) (string, error) {
+// normalizeUsername 在 domain.NormalizeUsername 之前去掉不可见字符，
+// 夹带零宽字符或 RTL override 的用户名与去掉它们之后的用户名是同一个账号
+func normalizeUsername(username string) string {
+	return domain.NormalizeUsername(textutil.CleanIdentifier(username))
+}
+
+func (s *service) generateJWT(userID int64, username, role string) (string, error) {
+	if role == "" {
+		role = domain.RoleUser
+	}
 	// 定义 Claims (载荷)
 	claims := jwt.MapClaims{
 		"user_id":  userID,
 		"username": username,
+		"role":     role,
 		"exp":      time.Now().Add(s.ttl).Unix(),
 		"iat":      time.Now().Unix(),
 	}
@@ -93,7 +130,7 @@ func (s *service) EditPassword(ctx context.Context, id int64, oldPassword, newPa
 		return domain.ErrUserNotFound
 	}
 	if !checkPasswordHash(oldPassword, user.Password) {
-		return domain.ErrInvalidCredentials
+		return domain.ErrBadParamInput
 	}
 
 	hashedPassword, err := hashPassword(newPassword)
@@ -104,3 +141,65 @@ func (s *service) EditPassword(ctx context.Context, id int64, oldPassword, newPa
 	user.Password = hashedPassword
 	return s.userRepo.Update(ctx, &user)
 }
+
+// List 分页获取用户列表，cursor 为上一页最后一个用户的ID
+func (s *service) List(ctx context.Context, cursor string, limit int64, search string) ([]domain.User, string, error) {
+	users, err := s.userRepo.List(ctx, cursor, limit, search)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if int64(len(users)) < limit {
+		return users, "", nil
+	}
+	return users, strconv.FormatInt(users[len(users)-1].ID, 10), nil
+}
+
+// NormalizeUsernames 把已有用户的用户名改为小写。先读出全部用户检查冲突，
+// 有只差大小写的用户名时不做任何修改，由管理员改名后重新执行
+func (s *service) NormalizeUsernames(ctx context.Context) ([]domain.UsernameCollision, error) {
+	groups := make(map[string][]domain.User)
+	cursor := ""
+	for {
+		users, next, err := s.List(ctx, cursor, normalizeBatch, "")
+		if err != nil {
+			return nil, err
+		}
+		for _, u := range users {
+			key := domain.NormalizeUsername(u.Username)
+			groups[key] = append(groups[key], u)
+		}
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+
+	var collisions []domain.UsernameCollision
+	for username, users := range groups {
+		if len(users) < 2 {
+			continue
+		}
+		c := domain.UsernameCollision{Username: username}
+		for _, u := range users {
+			c.IDs = append(c.IDs, u.ID)
+		}
+		collisions = append(collisions, c)
+	}
+	if len(collisions) > 0 {
+		sort.Slice(collisions, func(i, j int) bool { return collisions[i].Username < collisions[j].Username })
+		return collisions, nil
+	}
+
+	for username, users := range groups {
+		u := users[0]
+		if u.Username == username {
+			continue
+		}
+		u.Username = username
+		if err := s.userRepo.Update(ctx, &u); err != nil {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,184 @@
+package user_test
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/usecase/user"
+)
+
+// fakeUserRepo 按ID顺序保存用户，List 的语义与 mysql 实现一致，记录更新过的用户
+type fakeUserRepo struct {
+	domain.UserRepository
+	users   []domain.User
+	updated []domain.User
+}
+
+func (r *fakeUserRepo) Update(_ context.Context, u *domain.User) error {
+	r.updated = append(r.updated, *u)
+	return nil
+}
+
+func (r *fakeUserRepo) GetByUsername(_ context.Context, username string) (domain.User, error) {
+	for _, u := range r.users {
+		if u.Username == username {
+			return u, nil
+		}
+	}
+	return domain.User{}, domain.ErrNotFound
+}
+
+func (r *fakeUserRepo) Insert(_ context.Context, u *domain.User) error {
+	u.ID = int64(len(r.users) + 1)
+	r.users = append(r.users, *u)
+	return nil
+}
+
+func (r *fakeUserRepo) List(_ context.Context, cursor string, limit int64, search string) ([]domain.User, error) {
+	var lastID int64
+	if cursor != "" {
+		id, err := strconv.ParseInt(cursor, 10, 64)
+		if err != nil {
+			return nil, domain.ErrBadParamInput
+		}
+		lastID = id
+	}
+
+	var res []domain.User
+	for _, u := range r.users {
+		if u.ID <= lastID {
+			continue
+		}
+		if search != "" && !strings.HasPrefix(u.Username, search) && !strings.HasPrefix(u.Name, search) {
+			continue
+		}
+		res = append(res, u)
+		if int64(len(res)) == limit {
+			break
+		}
+	}
+	return res, nil
+}
+
+func newUsers() *fakeUserRepo {
+	return &fakeUserRepo{users: []domain.User{
+		{ID: 1, Username: "alice", Name: "Alice"},
+		{ID: 2, Username: "bob", Name: "Bob"},
+		{ID: 3, Username: "alex", Name: "Alex"},
+		{ID: 4, Username: "carol", Name: "Al Carol"},
+		{ID: 5, Username: "dave", Name: "Dave"},
+	}}
+}
+
+func TestListPaging(t *testing.T) {
+	svc := user.NewService(newUsers(), []byte("secret"), time.Hour, nil)
+	ctx := context.Background()
+
+	page, next, err := svc.List(ctx, "", 2, "")
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1, 2}, ids(page))
+	assert.Equal(t, "2", next)
+
+	page, next, err = svc.List(ctx, next, 2, "")
+	require.NoError(t, err)
+	assert.Equal(t, []int64{3, 4}, ids(page))
+	assert.Equal(t, "4", next)
+
+	page, next, err = svc.List(ctx, next, 2, "")
+	require.NoError(t, err)
+	assert.Equal(t, []int64{5}, ids(page))
+	assert.Empty(t, next)
+}
+
+func TestListSearch(t *testing.T) {
+	svc := user.NewService(newUsers(), []byte("secret"), time.Hour, nil)
+	ctx := context.Background()
+
+	page, next, err := svc.List(ctx, "", 10, "al")
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1, 3}, ids(page))
+	assert.Empty(t, next)
+
+	page, _, err = svc.List(ctx, "", 10, "Al")
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1, 3, 4}, ids(page))
+}
+
+func TestListBadCursor(t *testing.T) {
+	svc := user.NewService(newUsers(), []byte("secret"), time.Hour, nil)
+
+	_, _, err := svc.List(context.Background(), "abc", 10, "")
+	assert.ErrorIs(t, err, domain.ErrBadParamInput)
+}
+
+func TestNormalizeUsernames(t *testing.T) {
+	repo := &fakeUserRepo{users: []domain.User{
+		{ID: 1, Username: "alice", Name: "Alice"},
+		{ID: 2, Username: "Bob", Name: "Bob"},
+		{ID: 3, Username: "CAROL", Name: "Carol"},
+	}}
+	svc := user.NewService(repo, []byte("secret"), time.Hour, nil)
+
+	collisions, err := svc.NormalizeUsernames(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, collisions)
+	// 已经是小写的用户名不更新，显示名保持不变
+	assert.ElementsMatch(t, []domain.User{
+		{ID: 2, Username: "bob", Name: "Bob"},
+		{ID: 3, Username: "carol", Name: "Carol"},
+	}, repo.updated)
+}
+
+func TestNormalizeUsernamesReportsCollisions(t *testing.T) {
+	repo := &fakeUserRepo{users: []domain.User{
+		{ID: 1, Username: "alice"},
+		{ID: 2, Username: "Bob"},
+		{ID: 3, Username: "Alice"},
+		{ID: 4, Username: "bob"},
+		{ID: 5, Username: "Carol"},
+	}}
+	svc := user.NewService(repo, []byte("secret"), time.Hour, nil)
+
+	collisions, err := svc.NormalizeUsernames(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []domain.UsernameCollision{
+		{Username: "alice", IDs: []int64{1, 3}},
+		{Username: "bob", IDs: []int64{2, 4}},
+	}, collisions)
+	// 有冲突时一个都不改
+	assert.Empty(t, repo.updated)
+}
+
+func ids(users []domain.User) []int64 {
+	res := make([]int64, len(users))
+	for i := range users {
+		res[i] = users[i].ID
+	}
+	return res
+}
+
+func TestRegisterCleansUsername(t *testing.T) {
+	repo := newUsers()
+	svc := user.NewService(repo, []byte("secret"), time.Hour, nil)
+
+	// 零宽字符、连接符和 RTL override 拼出的 "alice" 就是 alice
+	for _, username := range []string{"ali\u200bce", "\u202eAlice", "al\ufeffice ", "al\u200dice"} {
+		err := svc.Register(context.Background(), "", username, "pw")
+		assert.ErrorIs(t, err, domain.ErrUserAlreadyExists, "username %q", username)
+	}
+
+	require.NoError(t, svc.Register(context.Background(), "E\u200bve\u202e", "\u200deve\u2060", "pw"))
+	created := repo.users[len(repo.users)-1]
+	assert.Equal(t, "eve", created.Username)
+	assert.Equal(t, "Eve", created.Name)
+
+	err := svc.Register(context.Background(), "", "\u200b\u200e", "pw")
+	assert.ErrorIs(t, err, domain.ErrBadParamInput)
+}
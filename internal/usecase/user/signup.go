@@ -0,0 +1,108 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// RegistrationTokenTTL 注册令牌的保存时间，只用来合并短时间内的重复提交
+	RegistrationTokenTTL = 10 * time.Minute
+
+	// registrationWait 重复提交等待第一次提交完成的最长时间，bcrypt 加密通常在百毫秒以内
+	registrationWait = 5 * time.Second
+	// registrationPollInterval 等待期间查询第一次提交结果的间隔
+	registrationPollInterval = 50 * time.Millisecond
+)
+
+// RegisterWithToken 是带客户端令牌的 Register。同一个令牌的第一次提交真正注册，
+// 之后的提交等第一次完成并返回它的结果，而不是用户名冲突。
+// 第一次注册失败时释放令牌，重试会重新注册；令牌用于其他用户名时返回 ErrBadParamInput
+func (s *service) RegisterWithToken(ctx context.Context, token, name, username, password string) error {
+	if s.signup == nil || token == "" {
+		return s.Register(ctx, name, username, password)
+	}
+	normalized := normalizeUsername(username)
+
+	deadline := time.Now().Add(registrationWait)
+	for {
+		claimed, err := s.signup.ClaimRegistration(ctx, token, RegistrationTokenTTL)
+		if err != nil {
+			// Redis 不可用时退化为普通注册，重复提交最多得到一个 409
+			logrus.Warnf("failed to claim registration token: %v", err)
+			return s.Register(ctx, name, username, password)
+		}
+		if claimed {
+			return s.registerClaimed(ctx, token, name, username, password)
+		}
+
+		res, err := s.signup.GetRegistration(ctx, token)
+		if err == nil {
+			if res.Username != normalized {
+				return domain.ErrBadParamInput
+			}
+			return nil
+		}
+		if !errors.Is(err, domain.ErrCacheMiss) {
+			return err
+		}
+		if time.Now().After(deadline) {
+			return domain.ErrConflict
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(registrationPollInterval):
+		}
+	}
+}
+
+// registerClaimed 为已认领的令牌注册，保存结果供重复提交读取
+func (s *service) registerClaimed(ctx context.Context, token, name, username, password string) error {
+	u, err := s.register(ctx, name, username, password)
+	if err != nil {
+		if err := s.signup.ReleaseRegistration(ctx, token); err != nil {
+			logrus.Warnf("failed to release registration token: %v", err)
+		}
+		return err
+	}
+
+	res := domain.Registration{UserID: u.ID, Username: u.Username}
+	if err := s.signup.SaveRegistration(ctx, token, res, RegistrationTokenTTL); err != nil {
+		logrus.Warnf("failed to save registration of user %d: %v", u.ID, err)
+	}
+	return nil
+}
+
+// UsernameAvailable 报告 username 规范化后是否还没有被注册。client 标识调用方，通常是 IP，
+// 一个窗口内超过 domain.UsernameCheckLimit 次后返回 ErrTooManyRequests。
+// 布隆过滤器只记录文章 ID，这里直接按唯一索引查数据库
+func (s *service) UsernameAvailable(ctx context.Context, client, username string) (bool, error) {
+	username = normalizeUsername(username)
+	if username == "" {
+		return false, domain.ErrBadParamInput
+	}
+
+	if s.signup != nil {
+		allowed, err := s.signup.AllowUsernameCheck(ctx, client, domain.UsernameCheckLimit, domain.UsernameCheckWindow)
+		if err != nil {
+			logrus.Warnf("failed to count username checks of %s: %v", client, err)
+		} else if !allowed {
+			return false, domain.ErrTooManyRequests
+		}
+	}
+
+	_, err := s.userRepo.GetByUsername(ctx, username)
+	if errors.Is(err, domain.ErrNotFound) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return false, nil
+}
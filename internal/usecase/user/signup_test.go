@@ -0,0 +1,91 @@
+package user_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+	myRedis "github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/repository/redis"
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/usecase/user"
+)
+
+func newSignupCache(t *testing.T) (*miniredis.Miniredis, domain.SignupCache) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	return mr, myRedis.NewSignupCache(client, "")
+}
+
+func TestRegisterWithTokenReturnsFirstResult(t *testing.T) {
+	ctx := context.Background()
+	_, signup := newSignupCache(t)
+	repo := &fakeUserRepo{}
+	svc := user.NewService(repo, []byte("secret"), time.Hour, signup)
+
+	require.NoError(t, svc.RegisterWithToken(ctx, "form-1", "Dave", "Dave", "pw123456"))
+	// 同一个令牌重复提交得到第一次的结果，不会再插入用户
+	require.NoError(t, svc.RegisterWithToken(ctx, "form-1", "Dave", "dave ", "pw123456"))
+	assert.Len(t, repo.users, 1)
+
+	// 令牌不能拿来注册别的用户名，不带令牌的重复注册仍然冲突
+	assert.ErrorIs(t, svc.RegisterWithToken(ctx, "form-1", "", "erin", "pw123456"), domain.ErrBadParamInput)
+	assert.ErrorIs(t, svc.RegisterWithToken(ctx, "", "", "dave", "pw123456"), domain.ErrConflict)
+	assert.Len(t, repo.users, 1)
+}
+
+func TestRegisterWithTokenReleasesFailedClaim(t *testing.T) {
+	ctx := context.Background()
+	mr, signup := newSignupCache(t)
+	repo := newUsers()
+	svc := user.NewService(repo, []byte("secret"), time.Hour, signup)
+
+	err := svc.RegisterWithToken(ctx, "form-1", "", "alice", "pw123456")
+	assert.ErrorIs(t, err, domain.ErrUserAlreadyExists)
+	assert.False(t, mr.Exists("signup:registration:form-1"))
+
+	// 改了用户名后用同一个令牌重试，照常注册
+	require.NoError(t, svc.RegisterWithToken(ctx, "form-1", "", "alice2", "pw123456"))
+	_, err = repo.GetByUsername(ctx, "alice2")
+	assert.NoError(t, err)
+}
+
+func TestUsernameAvailableIsRateLimited(t *testing.T) {
+	ctx := context.Background()
+	mr, signup := newSignupCache(t)
+	svc := user.NewService(newUsers(), []byte("secret"), time.Hour, signup)
+
+	available, err := svc.UsernameAvailable(ctx, "10.0.0.1", " Alice ")
+	require.NoError(t, err)
+	assert.False(t, available)
+	available, err = svc.UsernameAvailable(ctx, "10.0.0.1", "zoe")
+	require.NoError(t, err)
+	assert.True(t, available)
+
+	for range domain.UsernameCheckLimit - 2 {
+		_, err = svc.UsernameAvailable(ctx, "10.0.0.1", "zoe")
+		require.NoError(t, err)
+	}
+	_, err = svc.UsernameAvailable(ctx, "10.0.0.1", "zoe")
+	assert.ErrorIs(t, err, domain.ErrTooManyRequests)
+
+	// 其他客户端不受影响，窗口过去后恢复
+	_, err = svc.UsernameAvailable(ctx, "10.0.0.2", "zoe")
+	assert.NoError(t, err)
+	mr.FastForward(domain.UsernameCheckWindow)
+	_, err = svc.UsernameAvailable(ctx, "10.0.0.1", "zoe")
+	assert.NoError(t, err)
+}
+
+func TestUsernameAvailableRejectsEmpty(t *testing.T) {
+	svc := user.NewService(newUsers(), []byte("secret"), time.Hour, nil)
+
+	_, err := svc.UsernameAvailable(context.Background(), "10.0.0.1", "  ")
+	assert.ErrorIs(t, err, domain.ErrBadParamInput)
+}
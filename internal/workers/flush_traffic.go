@@ -0,0 +1,94 @@
+package workers
+
+import (
+	"context"
+	"time"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+	"github.com/sirupsen/logrus"
+)
+
+// FlushTrafficWorker 每天在零点后 At 把前 Days 个完整自然日的流量统计从 Redis 写入数据库。
+// 写入是覆盖式的，重复写入同一天没有副作用，所以启动时先补写一次，错过的夜间任务不会丢数据
+type FlushTrafficWorker struct {
+	ArticleCache domain.ArticleCache
+	TrafficRepo  domain.TrafficRepository
+
+	At   time.Duration
+	Days int
+	// Status 为 nil 时不保存运行记录
+	Status *StatusRecorder
+}
+
+func NewFlushTrafficWorker(ac domain.ArticleCache, tr domain.TrafficRepository, at time.Duration, days int) *FlushTrafficWorker {
+	return &FlushTrafficWorker{
+		ArticleCache: ac,
+		TrafficRepo:  tr,
+		At:           at,
+		Days:         days,
+	}
+}
+
+func (w *FlushTrafficWorker) Start(ctx context.Context) {
+	for {
+		if err := w.Flush(ctx, time.Now()); err != nil {
+			logrus.Errorf("failed to flush traffic stats: %v", err)
+		}
+
+		timer := time.NewTimer(time.Until(w.nextRun(time.Now())))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			logrus.Info("FlushTrafficWorker stopped")
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// nextRun 返回 now 之后最近一次执行的时间
+func (w *FlushTrafficWorker) nextRun(now time.Time) time.Time {
+	y, m, d := now.Date()
+	next := time.Date(y, m, d, 0, 0, 0, 0, now.Location()).Add(w.At)
+	if !next.After(now) {
+		next = time.Date(y, m, d+1, 0, 0, 0, 0, now.Location()).Add(w.At)
+	}
+	return next
+}
+
+// Flush 写入 now 之前 Days 个自然日的统计，当天还在累加，不写入。
+// 某一天失败时继续写其他天，返回最后一个错误；全部成功时保存运行记录
+func (w *FlushTrafficWorker) Flush(ctx context.Context, now time.Time) error {
+	start := time.Now()
+	y, m, d := now.Date()
+	today := time.Date(y, m, d, 0, 0, 0, 0, now.Location())
+
+	var (
+		lastErr error
+		stored  int64
+	)
+	for i := 1; i <= w.Days; i++ {
+		day := today.AddDate(0, 0, -i)
+		for _, metric := range domain.TrafficMetrics {
+			counts, err := w.ArticleCache.GetTraffic(ctx, metric, day)
+			if err != nil {
+				lastErr = err
+				continue
+			}
+			if counts.IsEmpty() {
+				continue
+			}
+			if err := w.TrafficRepo.StoreTraffic(ctx, day, metric, counts); err != nil {
+				lastErr = err
+				continue
+			}
+			stored++
+		}
+	}
+	if lastErr != nil {
+		w.Status.Failed(1)
+		return lastErr
+	}
+	w.Status.Flushed(ctx, start, stored)
+	return nil
+}
@@ -0,0 +1,62 @@
+package workers_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/workers"
+)
+
+// trafficCache 按 "日期/指标" 返回预置的计数
+type trafficCache struct {
+	domain.ArticleCache
+	counts map[string]domain.TrafficCounts
+}
+
+func (c trafficCache) GetTraffic(_ context.Context, metric domain.TrafficMetric, day time.Time) (domain.TrafficCounts, error) {
+	if counts, ok := c.counts[day.Format(domain.TrafficDateLayout)+"/"+string(metric)]; ok {
+		return counts, nil
+	}
+	return domain.NewTrafficCounts(), nil
+}
+
+type trafficDB struct {
+	domain.TrafficRepository
+	stored map[string]domain.TrafficCounts
+}
+
+func (f *trafficDB) StoreTraffic(_ context.Context, day time.Time, metric domain.TrafficMetric, counts domain.TrafficCounts) error {
+	f.stored[day.Format(domain.TrafficDateLayout)+"/"+string(metric)] = counts
+	return nil
+}
+
+func TestFlushTraffic(t *testing.T) {
+	views := domain.TrafficCounts{Referrers: map[string]int64{"google.com": 3}, Devices: map[string]int64{"mobile": 3}}
+	likes := domain.TrafficCounts{Referrers: map[string]int64{"direct": 1}, Devices: map[string]int64{"desktop": 1}}
+	cache := trafficCache{counts: map[string]domain.TrafficCounts{
+		"2026-10-16/views": views, // 当天还在累加，不写入
+		"2026-10-15/views": views,
+		"2026-10-15/likes": likes,
+		"2026-10-14/views": views,
+		"2026-10-13/views": views, // 超出 Days，不写入
+	}}
+	db := &trafficDB{stored: map[string]domain.TrafficCounts{}}
+	w := workers.NewFlushTrafficWorker(cache, db, 10*time.Minute, 2)
+
+	now := time.Date(2026, 10, 16, 0, 10, 0, 0, time.Local)
+	require.NoError(t, w.Flush(context.Background(), now))
+	assert.Equal(t, map[string]domain.TrafficCounts{
+		"2026-10-15/views": views,
+		"2026-10-15/likes": likes,
+		"2026-10-14/views": views,
+	}, db.stored)
+
+	// 重复执行是覆盖写入，结果不变
+	require.NoError(t, w.Flush(context.Background(), now))
+	assert.Len(t, db.stored, 3)
+}
@@ -0,0 +1,402 @@
+package workers
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+)
+
+const (
+	// importBloomBatch 导入成功的文章 ID 攒够这么多再批量加入布隆过滤器
+	importBloomBatch = 100
+	// importJobRetention 结束的导入任务在内存中保留的时间
+	importJobRetention = 24 * time.Hour
+	// maxImportTitleRunes article.title 为 varchar(45)
+	maxImportTitleRunes = 45
+)
+
+// importCreatedAtLayouts 支持的 created_at 格式，后者是 WordPress 导出的 post_date
+var importCreatedAtLayouts = []string{time.RFC3339, "2006-01-02 15:04:05"}
+
+// importRecord 导入的一条文章记录，tags 与创建文章时一样转为小写并去重
+type importRecord struct {
+	Title          string   `json:"title"`
+	Content        string   `json:"content"`
+	AuthorUsername string   `json:"author_username"`
+	CreatedAt      string   `json:"created_at"`
+	Tags           []string `json:"tags"`
+}
+
+type rawImportRecord struct {
+	index int
+	data  []byte
+}
+
+type parsedImportRecord struct {
+	index   int
+	article domain.Article
+	author  string
+}
+
+// ArticleImporter 在后台导入文章，由 Workers 个协程并发写入。
+// 任务进度只保存在当前实例的内存中，查询进度需要访问发起导入的实例
+type ArticleImporter struct {
+	ArticleSvc domain.ArticleUsecase
+	UserRepo   domain.UserRepository
+	BloomRepo  domain.BloomRepository
+	Workers    int
+
+	mu   sync.Mutex
+	jobs map[string]*importJob
+}
+
+var _ domain.ArticleImporter = (*ArticleImporter)(nil)
+
+func NewArticleImporter(as domain.ArticleUsecase, ur domain.UserRepository, b domain.BloomRepository, workers int) *ArticleImporter {
+	return &ArticleImporter{
+		ArticleSvc: as,
+		UserRepo:   ur,
+		BloomRepo:  b,
+		Workers:    workers,
+		jobs:       make(map[string]*importJob),
+	}
+}
+
+// importJob 一次导入的状态，authors 和 titles 只在本次导入内去重。
+// titles 只由解析协程访问，authors 由 authorMu 保护，其余字段由 mu 保护
+type importJob struct {
+	mu  sync.Mutex
+	job domain.ImportJob
+	ids []int64
+
+	titles map[string]bool
+
+	authorMu sync.Mutex
+	authors  map[string]int64
+}
+
+// Start 创建任务并在后台执行，任务不受请求 context 取消的影响
+func (im *ArticleImporter) Start(ctx context.Context, body []byte) (domain.ImportJob, error) {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return domain.ImportJob{}, domain.ErrBadParamInput
+	}
+
+	id, err := newImportJobID()
+	if err != nil {
+		return domain.ImportJob{}, err
+	}
+	j := &importJob{
+		job: domain.ImportJob{
+			ID:        id,
+			Status:    domain.ImportRunning,
+			StartedAt: time.Now(),
+		},
+		titles:  make(map[string]bool),
+		authors: make(map[string]int64),
+	}
+
+	im.mu.Lock()
+	for jid, other := range im.jobs {
+		snapshot := other.snapshot()
+		if snapshot.Status == domain.ImportRunning {
+			im.mu.Unlock()
+			return domain.ImportJob{}, domain.ErrConflict
+		}
+		if time.Since(*snapshot.FinishedAt) > importJobRetention {
+			delete(im.jobs, jid)
+		}
+	}
+	im.jobs[id] = j
+	im.mu.Unlock()
+
+	go im.run(context.WithoutCancel(ctx), j, body)
+	return j.snapshot(), nil
+}
+
+func (im *ArticleImporter) Get(_ context.Context, id string) (domain.ImportJob, error) {
+	im.mu.Lock()
+	j, ok := im.jobs[id]
+	im.mu.Unlock()
+	if !ok {
+		return domain.ImportJob{}, domain.ErrNotFound
+	}
+	return j.snapshot(), nil
+}
+
+// run 按输入顺序解析和校验记录，再交给固定数量的协程写入。
+// 同一批数据中标题重复时保留先出现的一条；解析出错时已读出的记录照常处理完
+func (im *ArticleImporter) run(ctx context.Context, j *importJob, body []byte) {
+	records := make(chan parsedImportRecord, im.Workers)
+	var wg sync.WaitGroup
+	for range im.Workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for rec := range records {
+				im.importOne(ctx, j, rec)
+			}
+		}()
+	}
+
+	readErr := decodeImportBody(body, func(raw rawImportRecord) {
+		j.mu.Lock()
+		j.job.Read++
+		j.mu.Unlock()
+
+		var rec importRecord
+		if err := json.Unmarshal(raw.data, &rec); err != nil {
+			j.fail(raw.index, "", err)
+			return
+		}
+		ar, err := rec.toDomain()
+		if err != nil {
+			j.fail(raw.index, rec.Title, err)
+			return
+		}
+		if j.titles[ar.Title] {
+			j.duplicate(raw.index, ar.Title)
+			return
+		}
+		j.titles[ar.Title] = true
+		records <- parsedImportRecord{index: raw.index, article: ar, author: rec.AuthorUsername}
+	})
+	close(records)
+	wg.Wait()
+	im.flushBloom(ctx, j, 0)
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	now := time.Now()
+	j.job.FinishedAt = &now
+	j.job.Status = domain.ImportDone
+	if readErr != nil {
+		j.job.Status = domain.ImportFailed
+		j.job.Error = readErr.Error()
+	}
+	logrus.Infof("article import %s %s: read=%d imported=%d duplicates=%d failed=%d",
+		j.job.ID, j.job.Status, j.job.Read, j.job.Imported, j.job.Duplicates, j.job.Failed)
+}
+
+func (im *ArticleImporter) importOne(ctx context.Context, j *importJob, rec parsedImportRecord) {
+	ar := rec.article
+	authorID, err := im.resolveAuthor(ctx, j, rec.author)
+	if err != nil {
+		j.fail(rec.index, ar.Title, fmt.Errorf("resolve author %q: %w", rec.author, err))
+		return
+	}
+	ar.User.ID = authorID
+
+	err = im.ArticleSvc.Import(ctx, &ar)
+	if errors.Is(err, domain.ErrConflict) {
+		j.duplicate(rec.index, ar.Title)
+		return
+	}
+	if err != nil {
+		j.fail(rec.index, ar.Title, err)
+		return
+	}
+
+	j.mu.Lock()
+	j.job.Imported++
+	j.ids = append(j.ids, ar.ID)
+	j.mu.Unlock()
+	im.flushBloom(ctx, j, importBloomBatch)
+}
+
+// resolveAuthor 按用户名查找作者，不存在时创建一个无法登录的账号，用户名不区分大小写。
+// 串行执行，避免多个协程同时创建同一个作者
+func (im *ArticleImporter) resolveAuthor(ctx context.Context, j *importJob, name string) (int64, error) {
+	username := domain.NormalizeUsername(name)
+	j.authorMu.Lock()
+	defer j.authorMu.Unlock()
+	if id, ok := j.authors[username]; ok {
+		return id, nil
+	}
+
+	u, err := im.UserRepo.GetByUsername(ctx, username)
+	if errors.Is(err, domain.ErrNotFound) {
+		u, err = im.createAuthor(ctx, username, name)
+		// 同名用户恰好在查询之后注册
+		if errors.Is(err, domain.ErrConflict) {
+			u, err = im.UserRepo.GetByUsername(ctx, username)
+		}
+	}
+	if err != nil {
+		return 0, err
+	}
+	j.authors[username] = u.ID
+	return u.ID, nil
+}
+
+func (im *ArticleImporter) createAuthor(ctx context.Context, username, name string) (domain.User, error) {
+	// 随机密码不会告知任何人，作者需要由管理员重置密码后才能登录。
+	// 256 位的随机密码无法穷举，用最低的 cost 即可，避免拖慢导入
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return domain.User{}, err
+	}
+	hashed, err := bcrypt.GenerateFromPassword([]byte(hex.EncodeToString(secret)), bcrypt.MinCost)
+	if err != nil {
+		return domain.User{}, err
+	}
+	u := domain.User{
+		Name:     name,
+		Username: username,
+		Password: string(hashed),
+		Role:     domain.RoleUser,
+	}
+	if err := im.UserRepo.Insert(ctx, &u); err != nil {
+		return domain.User{}, err
+	}
+	return u, nil
+}
+
+// flushBloom 已导入的 ID 达到 threshold 个时批量加入布隆过滤器，threshold 为 0 时全部加入
+func (im *ArticleImporter) flushBloom(ctx context.Context, j *importJob, threshold int) {
+	j.mu.Lock()
+	if len(j.ids) == 0 || len(j.ids) < threshold {
+		j.mu.Unlock()
+		return
+	}
+	ids := j.ids
+	j.ids = nil
+	j.mu.Unlock()
+
+	if err := im.BloomRepo.BulkAdd(ctx, ids); err != nil {
+		// 文章已经写入，布隆过滤器会在下次启动 InitBloomFilter 时补齐
+		logrus.Errorf("failed to add %d imported articles to bloom filter: %v", len(ids), err)
+	}
+}
+
+func (j *importJob) fail(index int, title string, err error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.job.Failed++
+	j.job.Records = append(j.job.Records, domain.ImportRecordResult{
+		Index:  index,
+		Title:  title,
+		Status: domain.BulkResultError,
+		Error:  err.Error(),
+	})
+}
+
+func (j *importJob) duplicate(index int, title string) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.job.Duplicates++
+	j.job.Records = append(j.job.Records, domain.ImportRecordResult{
+		Index:  index,
+		Title:  title,
+		Status: domain.BulkResultDuplicate,
+	})
+}
+
+// snapshot 返回任务的副本，Records 按输入顺序排列
+func (j *importJob) snapshot() domain.ImportJob {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	res := j.job
+	res.Records = make([]domain.ImportRecordResult, len(j.job.Records))
+	copy(res.Records, j.job.Records)
+	sort.Slice(res.Records, func(a, b int) bool {
+		return res.Records[a].Index < res.Records[b].Index
+	})
+	return res
+}
+
+func (r *importRecord) toDomain() (domain.Article, error) {
+	title := strings.TrimSpace(r.Title)
+	switch {
+	case title == "":
+		return domain.Article{}, errors.New("title is required")
+	case utf8.RuneCountInString(title) > maxImportTitleRunes:
+		return domain.Article{}, fmt.Errorf("title is longer than %d characters", maxImportTitleRunes)
+	case strings.TrimSpace(r.Content) == "":
+		return domain.Article{}, errors.New("content is required")
+	case strings.TrimSpace(r.AuthorUsername) == "":
+		return domain.Article{}, errors.New("author_username is required")
+	}
+
+	// 在解析阶段校验标签，不合法的记录直接报告，不会为它创建作者
+	tags, err := domain.NormalizeTags(r.Tags)
+	if err != nil {
+		return domain.Article{}, fmt.Errorf("invalid tags, expected at most %d tags of at most %d characters", domain.MaxTagsPerArticle, domain.MaxTagRunes)
+	}
+
+	ar := domain.Article{Title: title, Content: r.Content, Tags: tags}
+	if r.CreatedAt != "" {
+		createdAt, err := parseImportTime(r.CreatedAt)
+		if err != nil {
+			return domain.Article{}, err
+		}
+		ar.CreatedAt = createdAt
+		ar.UpdatedAt = createdAt
+	}
+	return ar, nil
+}
+
+func parseImportTime(s string) (time.Time, error) {
+	for _, layout := range importCreatedAtLayouts {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid created_at %q, expected RFC3339 or YYYY-MM-DD HH:MM:SS", s)
+}
+
+// decodeImportBody 逐条读出记录。以 [ 开头的按 JSON 数组解析，否则按每行一条的 NDJSON 解析。
+// NDJSON 中单行格式错误只影响该行；JSON 数组出现语法错误后无法继续，返回错误
+func decodeImportBody(body []byte, emit func(rawImportRecord)) error {
+	body = bytes.TrimSpace(body)
+	if body[0] != '[' {
+		scanner := bufio.NewScanner(bytes.NewReader(body))
+		scanner.Buffer(make([]byte, 0, 64*1024), len(body)+1)
+		index := 0
+		for scanner.Scan() {
+			line := bytes.TrimSpace(scanner.Bytes())
+			if len(line) == 0 {
+				continue
+			}
+			emit(rawImportRecord{index: index, data: bytes.Clone(line)})
+			index++
+		}
+		return scanner.Err()
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(body))
+	if _, err := dec.Token(); err != nil {
+		return err
+	}
+	for index := 0; dec.More(); index++ {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return fmt.Errorf("record %d: %w", index, err)
+		}
+		emit(rawImportRecord{index: index, data: raw})
+	}
+	_, err := dec.Token()
+	return err
+}
+
+func newImportJobID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
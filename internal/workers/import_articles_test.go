@@ -0,0 +1,196 @@
+package workers_test
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/workers"
+)
+
+// importArticles 模拟文章 usecase 的 Import，标题已存在时返回 ErrConflict
+type importArticles struct {
+	domain.ArticleUsecase
+	mu       sync.Mutex
+	articles map[string]domain.Article
+	nextID   int64
+}
+
+func (f *importArticles) Import(_ context.Context, ar *domain.Article) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.articles[ar.Title]; ok {
+		return domain.ErrConflict
+	}
+	f.nextID++
+	ar.ID = f.nextID
+	f.articles[ar.Title] = *ar
+	return nil
+}
+
+// importUsers 模拟用户表，记录创建过的用户
+type importUsers struct {
+	domain.UserRepository
+	mu      sync.Mutex
+	users   map[string]int64
+	created []string
+}
+
+func (f *importUsers) GetByUsername(_ context.Context, username string) (domain.User, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	id, ok := f.users[username]
+	if !ok {
+		return domain.User{}, domain.ErrNotFound
+	}
+	return domain.User{ID: id, Username: username}, nil
+}
+
+func (f *importUsers) Insert(_ context.Context, u *domain.User) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	u.ID = int64(len(f.users) + 100)
+	f.users[u.Username] = u.ID
+	f.created = append(f.created, u.Username)
+	return nil
+}
+
+// importBloom 记录每次 BulkAdd 的 ID
+type importBloom struct {
+	domain.BloomRepository
+	mu      sync.Mutex
+	batches [][]int64
+}
+
+func (f *importBloom) BulkAdd(_ context.Context, ids []int64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.batches = append(f.batches, ids)
+	return nil
+}
+
+func newImporter() (*workers.ArticleImporter, *importArticles, *importUsers, *importBloom) {
+	articles := &importArticles{articles: map[string]domain.Article{"existing": {ID: 1}}, nextID: 1}
+	users := &importUsers{users: map[string]int64{"alice": 7}}
+	bloom := &importBloom{}
+	return workers.NewArticleImporter(articles, users, bloom, 3), articles, users, bloom
+}
+
+func waitImport(t *testing.T, im *workers.ArticleImporter, id string) domain.ImportJob {
+	t.Helper()
+	var job domain.ImportJob
+	require.Eventually(t, func() bool {
+		var err error
+		job, err = im.Get(context.Background(), id)
+		require.NoError(t, err)
+		return job.Status != domain.ImportRunning
+	}, 5*time.Second, 5*time.Millisecond)
+	return job
+}
+
+func TestImportArticlesNDJSON(t *testing.T) {
+	im, articles, users, bloom := newImporter()
+	body := strings.Join([]string{
+		`{"title":"first","content":"c","author_username":"alice","created_at":"2019-05-01 10:00:00","tags":[" Go ","go","Redis"]}`,
+		`{"title":"existing","content":"c","author_username":"alice"}`,
+		`not json`,
+		`{"title":"second","content":"c","author_username":"bob","created_at":"2020-01-02T03:04:05Z"}`,
+		``,
+		`{"title":"first","content":"again","author_username":"bob"}`,
+		`{"title":"","content":"c","author_username":"bob"}`,
+		`{"title":"third","content":"c","author_username":"bob"}`,
+	}, "\n")
+
+	started, err := im.Start(context.Background(), []byte(body))
+	require.NoError(t, err)
+	job := waitImport(t, im, started.ID)
+
+	assert.Equal(t, domain.ImportDone, job.Status)
+	assert.Equal(t, 7, job.Read)
+	assert.Equal(t, 3, job.Imported)
+	assert.Equal(t, 2, job.Duplicates)
+	assert.Equal(t, 2, job.Failed)
+	require.Len(t, job.Records, 4)
+	assert.Equal(t, domain.ImportRecordResult{Index: 1, Title: "existing", Status: domain.BulkResultDuplicate}, job.Records[0])
+	assert.Equal(t, 2, job.Records[1].Index)
+	assert.Equal(t, domain.BulkResultError, job.Records[1].Status)
+	// 同一批中标题相同时保留先出现的一条
+	assert.Equal(t, domain.ImportRecordResult{Index: 4, Title: "first", Status: domain.BulkResultDuplicate}, job.Records[2])
+	assert.Equal(t, "c", articles.articles["first"].Content)
+	assert.Equal(t, 5, job.Records[3].Index)
+	assert.Contains(t, job.Records[3].Error, "title is required")
+
+	// 原始发布时间被保留，新作者只创建一次
+	assert.Equal(t, time.Date(2019, 5, 1, 10, 0, 0, 0, time.Local), articles.articles["first"].CreatedAt)
+	assert.Equal(t, []string{"go", "redis"}, articles.articles["first"].Tags)
+	assert.Nil(t, articles.articles["second"].Tags)
+	assert.True(t, time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC).Equal(articles.articles["second"].CreatedAt))
+	assert.Equal(t, []string{"bob"}, users.created)
+	assert.Equal(t, articles.articles["second"].User.ID, articles.articles["third"].User.ID)
+
+	// 不足一批的 ID 在结束时一次性加入布隆过滤器
+	require.Len(t, bloom.batches, 1)
+	assert.Len(t, bloom.batches[0], 3)
+}
+
+func TestImportArticlesJSONArray(t *testing.T) {
+	im, articles, users, _ := newImporter()
+	body := `[
+		{"title":"a","content":"c","author_username":"alice"},
+		{"title":"b","content":"c","author_username":"alice","created_at":"yesterday"},
+		{"title":"c","content":"c","author_username":"bob","tags":["1","2","3","4","5","6","7","8","9","10","11"]}
+	]`
+
+	started, err := im.Start(context.Background(), []byte(body))
+	require.NoError(t, err)
+	job := waitImport(t, im, started.ID)
+
+	assert.Equal(t, domain.ImportDone, job.Status)
+	assert.Equal(t, 1, job.Imported)
+	require.Len(t, job.Records, 2)
+	assert.Equal(t, 1, job.Records[0].Index)
+	assert.Contains(t, job.Records[0].Error, "invalid created_at")
+	// 标签超过上限的记录被报告，作者也不会被创建
+	assert.Equal(t, 2, job.Records[1].Index)
+	assert.Contains(t, job.Records[1].Error, "invalid tags")
+	assert.Equal(t, int64(7), articles.articles["a"].User.ID)
+	assert.Empty(t, users.created)
+}
+
+func TestImportArticlesTruncatedArray(t *testing.T) {
+	im, _, _, _ := newImporter()
+
+	started, err := im.Start(context.Background(), []byte(`[{"title":"a","content":"c","author_username":"alice"}, {"title":`))
+	require.NoError(t, err)
+	job := waitImport(t, im, started.ID)
+
+	// 语法错误之前的记录照常导入
+	assert.Equal(t, domain.ImportFailed, job.Status)
+	assert.Equal(t, 1, job.Imported)
+	assert.NotEmpty(t, job.Error)
+}
+
+func TestImportArticlesRejectsConcurrentJobs(t *testing.T) {
+	im, articles, _, _ := newImporter()
+	// 持有锁让第一个任务卡在写入上
+	articles.mu.Lock()
+	first, err := im.Start(context.Background(), []byte(`{"title":"a","content":"c","author_username":"alice"}`))
+	require.NoError(t, err)
+
+	_, err = im.Start(context.Background(), []byte(`{"title":"b","content":"c","author_username":"alice"}`))
+	assert.ErrorIs(t, err, domain.ErrConflict)
+
+	articles.mu.Unlock()
+	waitImport(t, im, first.ID)
+	_, err = im.Start(context.Background(), []byte(`{"title":"b","content":"c","author_username":"alice"}`))
+	assert.NoError(t, err)
+
+	_, err = im.Get(context.Background(), "unknown")
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
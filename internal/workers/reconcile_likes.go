@@ -0,0 +1,95 @@
+package workers
+
+import (
+	"context"
+	"time"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+	"github.com/sirupsen/logrus"
+)
+
+// likesReconcileCursor 点赞校准任务在 CursorStore 中的名字
+const likesReconcileCursor = "reconcile_likes"
+
+// LikesReconciler 分页比对 article.likes 与 user_likes 的真实数量并修正偏差。
+// 每页之间暂停 Pause 以限制对数据库的压力，进度保存在 CursorStore 中，中断后下次从断点继续
+type LikesReconciler struct {
+	ArticleDBRepo domain.ArticleDBRepository
+	ArticleCache  domain.ArticleCache
+	Cursors       domain.CursorStore
+	BatchSize     int
+	Pause         time.Duration
+}
+
+var _ domain.LikesReconciler = (*LikesReconciler)(nil)
+
+func NewLikesReconciler(ar domain.ArticleDBRepository, ac domain.ArticleCache, cs domain.CursorStore, batchSize int, pause time.Duration) *LikesReconciler {
+	return &LikesReconciler{
+		ArticleDBRepo: ar,
+		ArticleCache:  ac,
+		Cursors:       cs,
+		BatchSize:     batchSize,
+		Pause:         pause,
+	}
+}
+
+// Run 从上次保存的进度开始校准，全部完成后进度归零，下一次从头开始
+func (r *LikesReconciler) Run(ctx context.Context) (domain.LikesReconcileSummary, error) {
+	var summary domain.LikesReconcileSummary
+	cursor, err := r.Cursors.GetCursor(ctx, likesReconcileCursor)
+	if err != nil {
+		return summary, err
+	}
+	summary.Cursor = cursor
+
+	for {
+		batch, err := r.ArticleDBRepo.ReconcileLikes(ctx, cursor, r.BatchSize)
+		if err != nil {
+			return summary, err
+		}
+		summary.Checked += int64(batch.Checked)
+		r.record(ctx, &summary, batch.Repaired)
+
+		if batch.Checked < r.BatchSize {
+			summary.Done = true
+			summary.Cursor = 0
+			return summary, r.Cursors.SetCursor(ctx, likesReconcileCursor, 0)
+		}
+
+		cursor = batch.LastID
+		summary.Cursor = cursor
+		if err := r.Cursors.SetCursor(ctx, likesReconcileCursor, cursor); err != nil {
+			return summary, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return summary, ctx.Err()
+		case <-time.After(r.Pause):
+		}
+	}
+}
+
+// record 汇总修正结果，并把缓存中的点赞数同步为修正后的值
+func (r *LikesReconciler) record(ctx context.Context, summary *domain.LikesReconcileSummary, repaired []domain.LikeDrift) {
+	if len(repaired) == 0 {
+		return
+	}
+
+	aids := make([]int64, len(repaired))
+	likes := make([]int64, len(repaired))
+	for i, d := range repaired {
+		aids[i], likes[i] = d.ArticleID, d.Actual
+		drift := d.Stored - d.Actual
+		if drift < 0 {
+			drift = -drift
+		}
+		summary.LargestDrift = max(summary.LargestDrift, drift)
+	}
+	summary.Repaired += int64(len(repaired))
+
+	if err := r.ArticleCache.MSetLikeCount(ctx, aids, likes); err != nil {
+		logrus.Errorf("failed to update like counts in cache after reconcile: %v", err)
+	}
+	logrus.Infof("reconciled likes of %d articles, largest drift %d", len(repaired), summary.LargestDrift)
+}
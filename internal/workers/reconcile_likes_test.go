@@ -0,0 +1,126 @@
+package workers_test
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/workers"
+)
+
+var errDB = errors.New("db is down")
+
+// likesDB 在内存中模拟 articles.likes 与 user_likes 的数量
+type likesDB struct {
+	domain.ArticleDBRepository
+	stored    map[int64]int64 // articles.likes
+	userLikes map[int64]int64 // 每篇文章 user_likes 的行数
+	failAfter int             // 大于 0 时第 failAfter+1 次调用返回错误
+	calls     int
+}
+
+func (f *likesDB) ReconcileLikes(_ context.Context, afterID int64, limit int) (domain.LikesReconcileBatch, error) {
+	f.calls++
+	if f.failAfter > 0 && f.calls > f.failAfter {
+		return domain.LikesReconcileBatch{}, errDB
+	}
+
+	var ids []int64
+	for id := range f.stored {
+		if id > afterID {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	if len(ids) > limit {
+		ids = ids[:limit]
+	}
+
+	var batch domain.LikesReconcileBatch
+	for _, id := range ids {
+		if f.stored[id] != f.userLikes[id] {
+			batch.Repaired = append(batch.Repaired, domain.LikeDrift{ArticleID: id, Stored: f.stored[id], Actual: f.userLikes[id]})
+			f.stored[id] = f.userLikes[id]
+		}
+		batch.LastID = id
+	}
+	batch.Checked = len(ids)
+	return batch, nil
+}
+
+type likesCache struct {
+	domain.ArticleCache
+	likes map[int64]int64
+}
+
+func (c *likesCache) MSetLikeCount(_ context.Context, aids, likes []int64) error {
+	for i, aid := range aids {
+		c.likes[aid] = likes[i]
+	}
+	return nil
+}
+
+type memCursors map[string]int64
+
+func (m memCursors) GetCursor(_ context.Context, name string) (int64, error) { return m[name], nil }
+
+func (m memCursors) SetCursor(_ context.Context, name string, cursor int64) error {
+	m[name] = cursor
+	return nil
+}
+
+// seedDrift 准备 7 篇文章，其中 1、4、7 的点赞数与 user_likes 不一致
+func seedDrift() *likesDB {
+	return &likesDB{
+		stored:    map[int64]int64{1: 10, 2: 3, 3: 0, 4: 2, 5: 8, 6: 1, 7: 0},
+		userLikes: map[int64]int64{1: 4, 2: 3, 3: 0, 4: 9, 5: 8, 6: 1, 7: 1},
+	}
+}
+
+func TestLikesReconcilerConverges(t *testing.T) {
+	db := seedDrift()
+	cache := &likesCache{likes: map[int64]int64{}}
+	cursors := memCursors{}
+	r := workers.NewLikesReconciler(db, cache, cursors, 3, 0)
+
+	summary, err := r.Run(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, db.userLikes, db.stored)
+	assert.Equal(t, map[int64]int64{1: 4, 4: 9, 7: 1}, cache.likes)
+	assert.Equal(t, domain.LikesReconcileSummary{Checked: 7, Repaired: 3, LargestDrift: 7, Done: true}, summary)
+	assert.Zero(t, cursors["reconcile_likes"])
+
+	// 再次运行没有需要修正的文章
+	summary, err = r.Run(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), summary.Checked)
+	assert.Zero(t, summary.Repaired)
+}
+
+func TestLikesReconcilerResumes(t *testing.T) {
+	db := seedDrift()
+	db.failAfter = 1
+	cursors := memCursors{}
+	r := workers.NewLikesReconciler(db, &likesCache{likes: map[int64]int64{}}, cursors, 3, 0)
+
+	// 第一批完成后数据库出错，进度停在第一批末尾
+	summary, err := r.Run(context.Background())
+	require.ErrorIs(t, err, errDB)
+	assert.False(t, summary.Done)
+	assert.Equal(t, int64(3), summary.Checked)
+	assert.Equal(t, int64(3), cursors["reconcile_likes"])
+
+	// 恢复后从断点继续，不再重复检查已完成的文章
+	db.failAfter = 0
+	summary, err = r.Run(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), summary.Checked)
+	assert.True(t, summary.Done)
+	assert.Equal(t, db.userLikes, db.stored)
+}
@@ -0,0 +1,55 @@
+package workers
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+	"github.com/sirupsen/logrus"
+)
+
+// StatusRecorder 在每次成功写入后保存 worker 的运行记录，事后排查时可以知道最后一次同步是什么时候。
+// 方法都可以在 nil 上调用，没有配置记录的 worker 不受影响
+type StatusRecorder struct {
+	Repo     domain.WorkerStatusRepository
+	Name     string
+	Interval time.Duration
+
+	errors atomic.Int64
+}
+
+func NewStatusRecorder(repo domain.WorkerStatusRepository, name string, interval time.Duration) *StatusRecorder {
+	return &StatusRecorder{
+		Repo:     repo,
+		Name:     name,
+		Interval: interval,
+	}
+}
+
+// Failed 记录 n 次失败，计入之后每条运行记录的 Errors
+func (r *StatusRecorder) Failed(n int64) {
+	if r == nil || n <= 0 {
+		return
+	}
+	r.errors.Add(n)
+}
+
+// Flushed 保存一次从 start 开始、写入了 items 条数据的成功运行。保存失败只记录日志，不影响 worker
+func (r *StatusRecorder) Flushed(ctx context.Context, start time.Time, items int64) {
+	if r == nil {
+		return
+	}
+	now := time.Now()
+	status := domain.WorkerStatus{
+		Name:       r.Name,
+		LastRun:    now,
+		Items:      items,
+		DurationMs: now.Sub(start).Milliseconds(),
+		Errors:     r.errors.Load(),
+		IntervalMs: r.Interval.Milliseconds(),
+	}
+	if err := r.Repo.SetWorkerStatus(ctx, status); err != nil {
+		logrus.Warnf("failed to save status of worker %s: %v", r.Name, err)
+	}
+}
@@ -0,0 +1,72 @@
+package workers_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/workers"
+)
+
+// statusStore 保存每个 worker 最近一次的运行记录
+type statusStore struct {
+	saved map[string]domain.WorkerStatus
+}
+
+func (s *statusStore) SetWorkerStatus(_ context.Context, status domain.WorkerStatus) error {
+	s.saved[status.Name] = status
+	return nil
+}
+
+func (s *statusStore) GetWorkerStatus(_ context.Context, name string) (domain.WorkerStatus, error) {
+	status, ok := s.saved[name]
+	if !ok {
+		return domain.WorkerStatus{}, domain.ErrCacheMiss
+	}
+	return status, nil
+}
+
+// failingTrafficDB 写入总是失败
+type failingTrafficDB struct {
+	domain.TrafficRepository
+}
+
+func (failingTrafficDB) StoreTraffic(context.Context, time.Time, domain.TrafficMetric, domain.TrafficCounts) error {
+	return errors.New("mysql is down")
+}
+
+func TestFlushRecordsWorkerStatus(t *testing.T) {
+	views := domain.TrafficCounts{Referrers: map[string]int64{"google.com": 3}, Devices: map[string]int64{"mobile": 3}}
+	cache := trafficCache{counts: map[string]domain.TrafficCounts{"2026-10-15/views": views}}
+	store := &statusStore{saved: map[string]domain.WorkerStatus{}}
+	now := time.Date(2026, 10, 16, 0, 10, 0, 0, time.Local)
+
+	// 写入失败时不更新记录，只累计错误次数
+	w := workers.NewFlushTrafficWorker(cache, failingTrafficDB{}, 10*time.Minute, 1)
+	w.Status = workers.NewStatusRecorder(store, domain.WorkerFlushTraffic, 24*time.Hour)
+	require.Error(t, w.Flush(context.Background(), now))
+	assert.Empty(t, store.saved)
+
+	w.TrafficRepo = &trafficDB{stored: map[string]domain.TrafficCounts{}}
+	before := time.Now()
+	require.NoError(t, w.Flush(context.Background(), now))
+
+	status, ok := store.saved[domain.WorkerFlushTraffic]
+	require.True(t, ok)
+	assert.Equal(t, domain.WorkerFlushTraffic, status.Name)
+	assert.False(t, status.LastRun.Before(before))
+	assert.Equal(t, int64(1), status.Items)
+	assert.Equal(t, int64(1), status.Errors)
+	assert.Equal(t, (24 * time.Hour).Milliseconds(), status.IntervalMs)
+}
+
+func TestFlushWithoutStatusRecorder(t *testing.T) {
+	// 没有配置记录时照常写入
+	w := workers.NewFlushTrafficWorker(trafficCache{}, &trafficDB{stored: map[string]domain.TrafficCounts{}}, 10*time.Minute, 1)
+	require.NoError(t, w.Flush(context.Background(), time.Now()))
+}
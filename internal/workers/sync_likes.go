@@ -14,9 +14,14 @@ type LikeTask struct {
 	Action    domain.LikeAction
 }
 
+// SyncLikesInterval 点赞任务凑不满一批时，每隔这么久写入一次数据库
+const SyncLikesInterval = 1 * time.Second
+
 type syncLikesWorker struct {
 	ArticleDBRepo domain.ArticleDBRepository
-	ch            chan LikeTask
+	// Status 为 nil 时不保存运行记录
+	Status *StatusRecorder
+	ch     chan LikeTask
 }
 
 func NewSyncLikesWorker(ar domain.ArticleDBRepository) *syncLikesWorker {
@@ -35,8 +40,13 @@ func (s syncLikesWorker) Send(likeRecord domain.UserLike, action domain.LikeActi
 	}
 }
 
+// QueueDepth returns the number of tasks waiting in the channel
+func (s syncLikesWorker) QueueDepth() int {
+	return len(s.ch)
+}
+
 func (s syncLikesWorker) Start(ctx context.Context) {
-	ticker := time.NewTicker(1 * time.Second)
+	ticker := time.NewTicker(SyncLikesInterval)
 	defer ticker.Stop()
 
 	const batchSize = 500
@@ -54,7 +64,12 @@ func (s syncLikesWorker) Start(ctx context.Context) {
 			batch = make([]LikeTask, 0)
 		case <-ctx.Done():
 			logrus.Info("shuting down SyncLikesWorker, flushing remain tasks...")
-			s.flush(ctx, batch)
+			// 取出通道中剩下的任务，最后一批不随 ctx 取消
+			for len(s.ch) > 0 {
+				batch = append(batch, <-s.ch)
+			}
+			s.flush(context.WithoutCancel(ctx), batch)
+			return
 		}
 	}
 }
@@ -64,6 +79,7 @@ type taskKey struct {
 }
 
 func (s syncLikesWorker) flush(ctx context.Context, batch []LikeTask) {
+	start := time.Now()
 	tasks := make(map[taskKey]domain.LikeAction)
 	for i := range batch {
 		key := taskKey{
@@ -89,5 +105,10 @@ func (s syncLikesWorker) flush(ctx context.Context, batch []LikeTask) {
 			logrus.Errorf("Unsuported action: %v", action)
 		}
 	}
-	_ = s.ArticleDBRepo.ApplyLikeChanges(ctx, changes)
+	if err := s.ArticleDBRepo.ApplyLikeChanges(ctx, changes); err != nil {
+		logrus.Errorf("SyncLikesWorker failed to apply %d like changes: %v", len(tasks), err)
+		s.Status.Failed(int64(len(tasks)))
+		return
+	}
+	s.Status.Flushed(ctx, start, int64(len(tasks)))
 }
@@ -0,0 +1,57 @@
+package workers_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/workers"
+)
+
+// likeChangesDB 记录每次写入的点赞变化，ctx 已取消时像数据库一样返回错误
+type likeChangesDB struct {
+	domain.ArticleDBRepository
+	mu      sync.Mutex
+	calls   int
+	applied []domain.UserLike
+}
+
+func (f *likeChangesDB) ApplyLikeChanges(ctx context.Context, changes domain.LikeStateChanges) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls++
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	f.applied = append(f.applied, changes.ToAdd...)
+	return nil
+}
+
+func TestSyncLikesFlushesOnShutdown(t *testing.T) {
+	db := &likeChangesDB{}
+	worker := workers.NewSyncLikesWorker(db)
+	worker.Send(domain.UserLike{ArticleID: 1, UserID: 7}, domain.Like)
+	worker.Send(domain.UserLike{ArticleID: 2, UserID: 7}, domain.Like)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	done := make(chan struct{})
+	go func() {
+		worker.Start(ctx)
+		close(done)
+	}()
+
+	// 关闭时写入最后一批后退出，不会反复 flush
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		require.FailNow(t, "worker did not stop after ctx was canceled")
+	}
+	assert.Equal(t, 1, db.calls)
+	assert.ElementsMatch(t, []domain.UserLike{{ArticleID: 1, UserID: 7}, {ArticleID: 2, UserID: 7}}, db.applied)
+}
@@ -9,15 +9,27 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+// SyncViewsWorker 每隔 Interval 把 Redis 中缓冲的浏览量写入数据库。
+// 缓冲的浏览量在 Redis 被清空时会丢失，为了限制流量大时的损失，每隔 CheckpointInterval 检查一次缓冲总量，
+// 达到 CheckpointThreshold 时不等周期结束提前写入。CheckpointThreshold 为 0 时不做检查
 type SyncViewsWorker struct {
 	ArticleDBRepo domain.ArticleDBRepository
 	ArticleCache  domain.ArticleCache
+
+	Interval            time.Duration
+	CheckpointInterval  time.Duration
+	CheckpointThreshold int64
+	// Status 为 nil 时不保存运行记录
+	Status *StatusRecorder
 }
 
-func NewSyncViewWorker(ar domain.ArticleDBRepository, ac domain.ArticleCache) *SyncViewsWorker {
+func NewSyncViewWorker(ar domain.ArticleDBRepository, ac domain.ArticleCache, interval, checkpointInterval time.Duration, checkpointThreshold int64) *SyncViewsWorker {
 	return &SyncViewsWorker{
-		ArticleDBRepo: ar,
-		ArticleCache:  ac,
+		ArticleDBRepo:       ar,
+		ArticleCache:        ac,
+		Interval:            interval,
+		CheckpointInterval:  checkpointInterval,
+		CheckpointThreshold: checkpointThreshold,
 	}
 }
 
@@ -45,9 +57,17 @@ func (s *SyncViewsWorker) safeRun(ctx context.Context) {
 		}
 	}()
 
-	ticker := time.NewTicker(1 * time.Minute)
+	ticker := time.NewTicker(s.Interval)
 	defer ticker.Stop()
 
+	// 不做检查时 checkpoints 为 nil，对应的 case 永远不会触发
+	var checkpoints <-chan time.Time
+	if s.CheckpointThreshold > 0 {
+		checkpointTicker := time.NewTicker(s.CheckpointInterval)
+		defer checkpointTicker.Stop()
+		checkpoints = checkpointTicker.C
+	}
+
 	for {
 		select {
 		case <-ctx.Done():
@@ -55,35 +75,53 @@ func (s *SyncViewsWorker) safeRun(ctx context.Context) {
 			return
 		case <-ticker.C:
 			s.sync(context.Background())
+		case <-checkpoints:
+			s.checkpoint(context.Background())
 		}
 	}
 }
 
 func (s *SyncViewsWorker) syncViews(ctx context.Context) {
+	start := time.Now()
 	views, err := s.ArticleCache.FetchAndResetViews(ctx)
 	if err != nil {
 		log.Printf("SyncViewsWorker failed to get views from redis: %v", err)
+		s.Status.Failed(1)
 		return
 	}
 
-	if len(views) == 0 {
-		return
-	}
-
+	// 没有浏览量时也保存记录，说明 worker 仍在正常运行
+	var written, failed int64
 	for id, view := range views {
 		err = s.ArticleDBRepo.AddViews(ctx, id, view)
 		if err != nil {
 			logrus.Warnf("failed to update views: %v", err)
+			failed++
 			continue
 		}
-
+		written++
 	}
+	s.Status.Failed(failed)
+	s.Status.Flushed(ctx, start, written)
 }
 
 func (s *SyncViewsWorker) sync(ctx context.Context) {
 	s.syncViews(ctx)
 }
 
+// checkpoint 缓冲的浏览量达到阈值时提前写入数据库
+func (s *SyncViewsWorker) checkpoint(ctx context.Context) {
+	buffered, err := s.ArticleCache.SumBufferedViews(ctx)
+	if err != nil {
+		logrus.Warnf("SyncViewsWorker failed to check buffered views: %v", err)
+		return
+	}
+	if buffered >= s.CheckpointThreshold {
+		logrus.Debugf("SyncViewsWorker checkpoint: %d views buffered", buffered)
+		s.syncViews(ctx)
+	}
+}
+
 func (s *SyncViewsWorker) flush(ctx context.Context) {
 	s.syncViews(ctx)
 }
@@ -0,0 +1,86 @@
+package workers_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/domain"
+	"github.com/Guyuepp/Go-Clean-Architecture-Blog/internal/workers"
+)
+
+// viewsBuffer 模拟 Redis 中的浏览量缓冲
+type viewsBuffer struct {
+	domain.ArticleCache
+	mu       sync.Mutex
+	buffered map[int64]int64
+}
+
+func (c *viewsBuffer) SumBufferedViews(context.Context) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var sum int64
+	for _, v := range c.buffered {
+		sum += v
+	}
+	return sum, nil
+}
+
+func (c *viewsBuffer) FetchAndResetViews(context.Context) (map[int64]int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	res := c.buffered
+	c.buffered = map[int64]int64{}
+	return res, nil
+}
+
+type viewsDB struct {
+	domain.ArticleDBRepository
+	mu    sync.Mutex
+	views map[int64]int64
+}
+
+func (f *viewsDB) AddViews(_ context.Context, id int64, delta int64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.views[id] += delta
+	return nil
+}
+
+func (f *viewsDB) viewsOf(id int64) int64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.views[id]
+}
+
+func TestSyncViewsCheckpointsBeforeInterval(t *testing.T) {
+	cache := &viewsBuffer{buffered: map[int64]int64{1: 3, 2: 2}}
+	db := &viewsDB{views: map[int64]int64{}}
+	// 周期写入在测试期间不会触发，只有达到阈值的检查会写入
+	worker := workers.NewSyncViewWorker(db, cache, time.Hour, 10*time.Millisecond, 5)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		worker.Start(ctx)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	assert.Eventually(t, func() bool {
+		return db.viewsOf(1) == 3 && db.viewsOf(2) == 2
+	}, time.Second, 10*time.Millisecond)
+
+	// 未达到阈值的浏览量留在缓冲中，等周期写入
+	cache.mu.Lock()
+	cache.buffered[1] = 4
+	cache.mu.Unlock()
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, int64(3), db.viewsOf(1))
+}